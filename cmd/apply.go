@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/rapl"
+)
+
+var applyOpts struct {
+	power  int64
+	dryRun bool
+	force  bool
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a fixed power cap to all RAPL domains",
+	Long: `Write a power limit to every RAPL power_limit_uw file of this machine.
+The limit must lie between RAPL_MIN_POWER and the hardware maximum (or
+RAPL_MAX_POWER when set) unless --force is given. Node annotations are not
+updated, and a running daemon overrides the limit at its next cycle.`,
+	Example: `  powercap apply --power 25000000
+  powercap apply --power 25000000 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+}
+
+func init() {
+	applyCmd.Flags().Int64Var(&applyOpts.power, "power", 0, "power limit to apply in µW (required)")
+	applyCmd.Flags().BoolVar(&applyOpts.dryRun, "dry-run", false, "show the files that would be written without writing them")
+	applyCmd.Flags().BoolVar(&applyOpts.force, "force", false, "allow limits outside RAPL_MIN_POWER and the hardware maximum")
+	applyCmd.MarkFlagRequired("power")
+	rootCmd.AddCommand(applyCmd)
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	if applyOpts.power <= 0 {
+		return fmt.Errorf("--power must be positive")
+	}
+
+	raplMgr := rapl.NewManager(logger)
+	raplMgr.SetBackend(rapl.Backend(cfg.PowerBackend), cfg.CPUFreqMaxPower)
+	raplMgr.SetRoot(cfg.SysfsRoot)
+	if err := raplMgr.DiscoverDomains(); err != nil {
+		return fmt.Errorf("failed to discover RAPL domains: %w", err)
+	}
+
+	maxPower, err := raplMgr.FindMaxPowerValue()
+	if err != nil {
+		return fmt.Errorf("failed to find max power value: %w", err)
+	}
+
+	if !applyOpts.force {
+		if applyOpts.power > maxPower {
+			return fmt.Errorf("power %d µW exceeds the hardware maximum %d µW (use --force to override)", applyOpts.power, maxPower)
+		}
+		if cfg.RaplMaxPower.IsSet() && applyOpts.power > cfg.RaplMaxPower.Resolve(maxPower) {
+			return fmt.Errorf("power %d µW exceeds RAPL_MAX_POWER %s (%d µW) (use --force to override)",
+				applyOpts.power, cfg.RaplMaxPower, cfg.RaplMaxPower.Resolve(maxPower))
+		}
+		if applyOpts.power < cfg.RaplLimit {
+			return fmt.Errorf("power %d µW is below RAPL_MIN_POWER %d µW (use --force to override)", applyOpts.power, cfg.RaplLimit)
+		}
+	}
+
+	if applyOpts.dryRun {
+		for _, domain := range raplMgr.GetDomains() {
+			for _, constraint := range domain.Constraints {
+				fmt.Printf("would write %d to %s (current %s)\n", applyOpts.power, constraint.Path, constraint.Value)
+			}
+		}
+		return nil
+	}
+
+	if errs := raplMgr.ApplyPowerLimits(applyOpts.power); len(errs) > 0 {
+		for _, err := range errs {
+			logger.Printf("❌ %v", err)
+		}
+		return fmt.Errorf("failed to write %d power limit file(s)", len(errs))
+	}
+
+	logger.Printf("✅ Applied power cap %d µW (%.1f W) to %d RAPL domain(s)",
+		applyOpts.power, float64(applyOpts.power)/1000000, len(raplMgr.GetDomains()))
+	return nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/power"
+	"kcas/new/internal/rapl"
+	"kcas/new/pkg/providers"
+)
+
+var checkOpts struct {
+	skipKubernetes bool
+	skipProvider   bool
+	timeout        time.Duration
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that the node can run the power manager",
+	Long: `Run preflight checks without changing anything: configuration, timezone,
+RAPL domain discovery and write access, energy counters, Kubernetes (or Nomad)
+node access and a test fetch from the market data provider. Exits non-zero if
+any check fails.`,
+	Example: `  powercap check
+  powercap check --skip-kubernetes --skip-provider`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+func init() {
+	checkCmd.Flags().BoolVar(&checkOpts.skipKubernetes, "skip-kubernetes", false, "skip the Kubernetes (or Nomad) node check")
+	checkCmd.Flags().BoolVar(&checkOpts.skipProvider, "skip-provider", false, "skip the provider fetch check")
+	checkCmd.Flags().DurationVar(&checkOpts.timeout, "timeout", 30*time.Second, "timeout of network checks")
+	rootCmd.AddCommand(checkCmd)
+}
+
+// check is a named preflight check returning a detail message
+type check struct {
+	name string
+	run  func() (string, error)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	// Keep the output readable: component logs are discarded
+	quiet := log.New(io.Discard, "", 0)
+	raplMgr := rapl.NewManager(quiet)
+	raplMgr.SetBackend(rapl.Backend(cfg.PowerBackend), cfg.CPUFreqMaxPower)
+	raplMgr.SetRoot(cfg.SysfsRoot)
+
+	checks := []check{
+		{"configuration", func() (string, error) {
+			if err := providers.NewProviderFactory().ValidateProviderConfig(cfg); err != nil {
+				return "", err
+			}
+			source := "environment"
+			if cfg.ConfigFile != "" {
+				source = cfg.ConfigFile + " + environment"
+			}
+			return fmt.Sprintf("node %s, provider %s (%s)", cfg.NodeName, cfg.DataProvider, source), nil
+		}},
+		{"timezone", func() (string, error) {
+			loc, err := time.LoadLocation(cfg.Timezone)
+			if err != nil {
+				return "", err
+			}
+			return "market time " + time.Now().In(loc).Format("15:04:05 MST") + ", display time " +
+				time.Now().In(cfg.DisplayLocation()).Format("15:04:05 MST"), nil
+		}},
+		{"rapl domains", func() (string, error) {
+			if err := raplMgr.DiscoverDomains(); err != nil {
+				return "", err
+			}
+			maxPower, err := raplMgr.FindMaxPowerValue()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d %s domain(s), max power %.1f W", len(raplMgr.GetDomains()), raplMgr.Backend(), float64(maxPower)/1000000), nil
+		}},
+		{"rapl write access", func() (string, error) {
+			count := 0
+			for _, domain := range raplMgr.GetDomains() {
+				for _, constraint := range domain.Constraints {
+					f, err := os.OpenFile(constraint.Path, os.O_WRONLY, 0)
+					if err != nil {
+						return "", err
+					}
+					f.Close()
+					count++
+				}
+			}
+			if count == 0 {
+				return "", fmt.Errorf("no power limit files found")
+			}
+			return fmt.Sprintf("%d power limit file(s) writable", count), nil
+		}},
+		{"energy counters", func() (string, error) {
+			if !raplMgr.HasEnergyCounters() && cfg.MeterAddr != "" {
+				return "none, power measured by " + cfg.MeterAddr, nil
+			}
+			sample, err := raplMgr.ReadEnergy()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d counter(s) readable", len(sample.Counters)), nil
+		}},
+	}
+
+	if !checkOpts.skipKubernetes {
+		checks = append(checks, check{cfg.Orchestrator + " node", func() (string, error) {
+			nodes, err := power.NewNodeClient(cfg)
+			if err != nil {
+				return "", err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), checkOpts.timeout)
+			defer cancel()
+			node, err := nodes.GetNode(ctx, cfg.NodeName)
+			if err != nil {
+				return "", err
+			}
+			_, initialized := node.Annotations[cfg.InitAnnotation]
+			return fmt.Sprintf("node %s found (initialized: %v)", node.Name, initialized), nil
+		}})
+	}
+
+	if !checkOpts.skipProvider {
+		checks = append(checks, check{"provider fetch", func() (string, error) {
+			provider, err := providers.NewProviderFactory().CreateProvider(cfg)
+			if err != nil {
+				return "", err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), checkOpts.timeout)
+			defer cancel()
+			data, err := provider.FetchData(ctx, time.Now().In(cfg.MarketLocation()))
+			if err != nil {
+				return "", err
+			}
+			if len(data) == 0 {
+				return "", fmt.Errorf("provider returned no data")
+			}
+			return fmt.Sprintf("%d data points from %s", len(data), provider.GetName()), nil
+		}})
+	}
+
+	failed := 0
+	for _, c := range checks {
+		detail, err := c.run()
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %-18s %v\n", c.name, err)
+			continue
+		}
+		fmt.Printf("✅ %-18s %s\n", c.name, detail)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
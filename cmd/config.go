@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/config"
+	"kcas/new/pkg/providers"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the effective configuration",
+	// Configuration subcommands load the configuration themselves to report its problems
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the effective configuration",
+	Long: `Load the configuration from flags, environment and config file and check
+every setting: number and duration formats, provider parameters, the data
+refresh cron expression, timezone, data directory, log file and exporter
+addresses. All problems are printed at once and the command exits non-zero
+if any is found, so it can run in CI or as an initContainer.`,
+	Example: `  powercap config validate
+  powercap config validate --config /etc/powercap/config.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configHashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "Print the hash of the effective configuration",
+	Long: `Print the hash of the configuration resolved from flags, environment and
+config file. A running manager publishes the hash of its configuration in the
+config-hash node annotation, so comparing both verifies a rollout.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loaded, err := config.LoadWithFlags(flagOverrides(cmd))
+		if err != nil {
+			return err
+		}
+		fmt.Println(config.Hash(loaded))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configHashCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	loaded, err := config.LoadWithFlags(flagOverrides(cmd))
+
+	var problems []string
+	var validationErr *config.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problems = append(problems, validationErr.Problems...)
+	case err != nil:
+		// The config file itself could not be read, nothing else can be checked
+		fmt.Printf("❌ %v\n", err)
+		return fmt.Errorf("configuration is invalid")
+	}
+
+	// Skip semantic checks of settings that already failed to parse
+	unparsed := make(map[string]bool)
+	for _, problem := range problems {
+		unparsed[strings.SplitN(problem, ":", 2)[0]] = true
+	}
+	for _, problem := range config.Validate(loaded) {
+		if !unparsed[strings.SplitN(problem, ":", 2)[0]] {
+			problems = append(problems, problem)
+		}
+	}
+	if err := providers.NewProviderFactory().ValidateProviderConfig(loaded); err != nil {
+		problems = append(problems, fmt.Sprintf("%s/%s: %v", config.EnvDataProvider, config.EnvProviderParams, err))
+	}
+
+	source := "environment and defaults"
+	if loaded.ConfigFile != "" {
+		source = loaded.ConfigFile + ", environment and defaults"
+	}
+	if loaded.Profile != "" {
+		source += " with profile " + loaded.Profile
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("❌ %s\n", problem)
+		}
+		return fmt.Errorf("configuration from %s has %d problem(s)", source, len(problems))
+	}
+
+	fmt.Printf("✅ Configuration from %s is valid\n", source)
+	return nil
+}
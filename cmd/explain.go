@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/api"
+	"kcas/new/internal/power"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explain the running daemon's latest power decision",
+	Long: `Query the local HTTP API (HTTP_ADDR) of the running daemon and print the
+inputs of its most recent decision: market period, volumes, hardware
+maximum, formula, clamping and fallbacks.`,
+	Args: cobra.NoArgs,
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+// runExplain prints the inputs of the running manager's most recent decision
+func runExplain(cmd *cobra.Command, args []string) error {
+	var decision power.PowerDecision
+	if err := api.NewClient(cfg.HTTPAddr).GetJSON("/explain", &decision); err != nil {
+		return fmt.Errorf("failed to explain decision: %w", err)
+	}
+
+	out, err := json.MarshalIndent(decision, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
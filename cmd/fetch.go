@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/pkg/providers"
+)
+
+var fetchOpts struct {
+	date  string
+	save  bool
+	limit int
+}
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch market data from the configured provider",
+	Long: `Fetch one day of market data from the configured provider (DATA_PROVIDER)
+and print it. With --save the data is also written to the daily CSV file
+used by the daemon. Does not need Kubernetes or RAPL access.`,
+	Example: `  powercap fetch
+  powercap fetch --date 2025-10-09 --save`,
+	Args: cobra.NoArgs,
+	RunE: runFetch,
+}
+
+func init() {
+	fetchCmd.Flags().StringVar(&fetchOpts.date, "date", "", "delivery date to fetch (YYYY-MM-DD, default today)")
+	fetchCmd.Flags().BoolVar(&fetchOpts.save, "save", false, "write the data to the daily CSV file")
+	fetchCmd.Flags().IntVar(&fetchOpts.limit, "limit", 0, "number of data points to print (0 prints all)")
+	rootCmd.AddCommand(fetchCmd)
+}
+
+func runFetch(cmd *cobra.Command, args []string) error {
+	date, err := parseDate(fetchOpts.date, marketLocation())
+	if err != nil {
+		return err
+	}
+
+	provider, err := providers.NewProviderFactory().CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	logger.Printf("Fetching data for %s from %s provider...", date.Format("2006-01-02"), provider.GetName())
+	data, err := provider.FetchData(context.Background(), date)
+	if err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+	logger.Printf("Successfully fetched %d data points", len(data))
+
+	fmt.Printf("%-12s %12s %14s\n", "PERIOD", "VOLUME (MWh)", "PRICE ("+datastore.PriceUnit(cfg.Currency)+")")
+	for i, point := range data {
+		if fetchOpts.limit > 0 && i >= fetchOpts.limit {
+			fmt.Printf("... and %d more data points\n", len(data)-i)
+			break
+		}
+		fmt.Printf("%-12s %12.1f %14.2f\n", point.Period, point.Volume, point.Price)
+	}
+
+	if fetchOpts.save {
+		ds := datastore.NewCSVDataStore(logger)
+		ds.SetProvider(provider)
+		ds.SetDirectory(cfg.DataDir)
+		ds.SetCurrency(cfg.Currency)
+		if err := ds.SaveData(date, data); err != nil {
+			return fmt.Errorf("failed to store data: %w", err)
+		}
+		logger.Printf("✅ Saved %s", filepath.Join(cfg.DataDir, provider.GetDataPath(date)))
+	}
+	return nil
+}
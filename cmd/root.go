@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/logging"
+)
+
+// Shared state initialised before any subcommand runs
+var (
+	configFile string
+	cfg        *config.Config
+	logger     *log.Logger
+	logWriter  *logging.Writer
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "powercap",
+	Short: "Market-driven RAPL power capping for Kubernetes nodes",
+	Long: `powercap adjusts the RAPL power limits of a node according to energy
+market data (EPEX volumes by default) and publishes the applied cap as
+node annotations.
+
+Without a subcommand it runs the power manager daemon, like "powercap run".`,
+	SilenceUsage:      true,
+	PersistentPreRunE: setup,
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if logWriter != nil {
+			logWriter.Close()
+		}
+	},
+	RunE: runDaemon,
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&configFile, "config", "",
+		"path to a YAML configuration file (overrides "+config.EnvConfigFile+")")
+
+	// Every setting can be given as a flag, taking precedence over its environment variable
+	for _, setting := range config.Settings {
+		flags.String(setting.FlagName(), setting.Default, setting.Description+" ($"+setting.Env+")")
+		if setting.IsBool() {
+			flags.Lookup(setting.FlagName()).NoOptDefVal = "true"
+		}
+	}
+}
+
+// Execute runs the command selected by the command-line arguments
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// setup loads the configuration and sets up logging for every subcommand
+func setup(cmd *cobra.Command, args []string) error {
+	var err error
+	cfg, err = config.LoadWithFlags(flagOverrides(cmd))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logger, logWriter, err = logging.New(logging.Options{
+		Prefix:      "[PowerManager] ",
+		Quiet:       cfg.LogQuiet,
+		DedupWindow: cfg.LogDedupWindow,
+		FilePath:    cfg.LogFile,
+		MaxSizeMB:   cfg.LogMaxSizeMB,
+		MaxBackups:  cfg.LogMaxBackups,
+		Location:    cfg.DisplayLocation(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+	return nil
+}
+
+// flagOverrides returns the settings given on the command line, keyed by environment variable name
+func flagOverrides(cmd *cobra.Command) map[string]string {
+	overrides := make(map[string]string)
+	if configFile != "" {
+		overrides[config.EnvConfigFile] = configFile
+	}
+	for _, setting := range config.Settings {
+		if flag := cmd.Flags().Lookup(setting.FlagName()); flag != nil && flag.Changed {
+			overrides[setting.Env] = flag.Value.String()
+		}
+	}
+	return overrides
+}
+
+// marketLocation returns the market timezone, warning when it falls back to UTC
+func marketLocation() *time.Location {
+	loc := cfg.MarketLocation()
+	if loc.String() != cfg.Timezone {
+		logger.Printf("Warning: Unknown timezone %s, using %s for market periods", cfg.Timezone, loc)
+	} else {
+		logger.Printf("🌍 Market timezone: %s (current time: %s)", loc, time.Now().In(loc).Format("15:04:05 MST"))
+	}
+	return loc
+}
+
+// parseDate parses a YYYY-MM-DD date in the market timezone, defaulting to today
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Now().In(loc), nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", value, err)
+	}
+	return date, nil
+}
@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/api"
+	"kcas/new/internal/battery"
+	"kcas/new/internal/config"
+	"kcas/new/internal/errreport"
+	"kcas/new/internal/grpcapi"
+	"kcas/new/internal/meter"
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/modbus"
+	"kcas/new/internal/mqtt"
+	"kcas/new/internal/mqtt/publisher"
+	"kcas/new/internal/nats"
+	"kcas/new/internal/power"
+	"kcas/new/internal/snmp"
+	"kcas/new/internal/systemd"
+	"kcas/new/internal/ups"
+	"kcas/new/internal/version"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the power manager daemon",
+	Long: `Run the power manager: initialise the Kubernetes node annotations, then
+adjust the RAPL power cap every STABILISATION_TIME from the current market
+period. Requires in-cluster Kubernetes access (a Nomad agent with
+ORCHESTRATOR=nomad, nothing with ORCHESTRATOR=standalone) and writable RAPL
+sysfs files.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+}
+
+// runDaemon starts the power manager and blocks until it stops
+func runDaemon(cmd *cobra.Command, args []string) error {
+	logger.Printf("Starting professional power management system, version %s", version.Get())
+	if cfg.ConfigFile != "" {
+		logger.Printf("📄 Loaded configuration file %s (environment variables take precedence)", cfg.ConfigFile)
+	}
+	if cfg.Profile != "" {
+		logger.Printf("🎛️  Using configuration profile %s", cfg.Profile)
+	}
+
+	reporter, err := errreport.New(errreport.Options{
+		Backend:         cfg.ErrorReporting,
+		DSN:             cfg.SentryDSN,
+		WebhookURL:      cfg.ErrorWebhookURL,
+		Environment:     cfg.ErrorEnvironment,
+		RepeatThreshold: cfg.ErrorRepeatThreshold,
+		Tags: map[string]string{
+			"node":     cfg.NodeName,
+			"provider": cfg.DataProvider,
+		},
+	}, logger)
+	if err != nil {
+		logger.Printf("Warning: Failed to set up error reporting: %v", err)
+		reporter = errreport.NopReporter{}
+	}
+	defer reporter.Flush(5 * time.Second)
+	defer func() {
+		if r := recover(); r != nil {
+			reporter.CapturePanic(r, map[string]string{"operation": "main"})
+			reporter.Flush(5 * time.Second)
+			panic(r)
+		}
+	}()
+
+	// Background exporters and servers are waited for, after the context is
+	// cancelled, so they can flush
+	var background sync.WaitGroup
+	defer background.Wait()
+
+	// Cancel the context on SIGTERM (pod termination) or SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// A node of a policy experiment runs the profile named by its label
+	flags := flagOverrides(cmd)
+	experimentArm := ""
+	if cfg.ExperimentLabel != "" {
+		experimentArm = selectExperimentArm(ctx, flags)
+	}
+
+	// Initialize power manager
+	pm, err := power.NewManager(ctx, cfg, logger)
+	if err != nil {
+		reporter.CaptureError(err, map[string]string{"operation": "init"})
+		return fmt.Errorf("failed to initialize power manager: %w", err)
+	}
+	pm.SetErrorReporter(reporter)
+	if cfg.ExperimentLabel != "" {
+		pm.SetExperimentArm(experimentArm)
+	}
+
+	// Compare the running configuration with the declared one every cycle
+	pm.SetConfigLoader(func() (*config.Config, error) {
+		return config.LoadWithFlags(flags)
+	})
+
+	// Push metrics and cap change events to DogStatsD when configured
+	if cfg.StatsDAddr != "" {
+		exporter, err := metrics.NewStatsDExporter(pm.Metrics(), cfg.StatsDAddr, cfg.StatsDPrefix,
+			append([]string{"node:" + cfg.NodeName}, cfg.StatsDTags...), cfg.StatsDFlushInterval, logger)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up statsd exporter: %v", err)
+		} else {
+			pm.SetEventSink(exporter)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				exporter.Run(ctx)
+			}()
+			logger.Printf("📈 Exporting metrics to DogStatsD at %s", cfg.StatsDAddr)
+		}
+	}
+
+	// Push metrics to a Pushgateway after every cycle when configured
+	if cfg.PushgatewayURL != "" {
+		pusher, err := metrics.NewPusher(pm.Metrics(), cfg.PushgatewayURL, cfg.PushgatewayJob, cfg.NodeName, "powercap_", logger)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up Pushgateway pusher: %v", err)
+		} else {
+			pm.SetCycleHook(pusher.Trigger)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				pusher.Run(ctx)
+			}()
+			logger.Printf("📤 Pushing metrics to Pushgateway %s (job %s)", pusher.Gateway(), cfg.PushgatewayJob)
+		}
+	}
+
+	// Expose power state to DCIM tools over SNMP when configured
+	if cfg.SNMPAddr != "" {
+		baseOID, err := snmp.ParseOID(cfg.SNMPBaseOID)
+		if err != nil {
+			logger.Printf("Warning: Invalid SNMP base OID: %v", err)
+		} else {
+			agent := snmp.NewAgent(cfg.SNMPAddr, cfg.SNMPCommunity, logger)
+			snmp.RegisterPowerMIB(agent, baseOID, cfg.NodeName, pm.Metrics())
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				if err := agent.Run(ctx); err != nil {
+					logger.Printf("Warning: SNMP agent stopped: %v", err)
+				}
+			}()
+			logger.Printf("📡 SNMP agent listening on %s (base OID %s)", cfg.SNMPAddr, cfg.SNMPBaseOID)
+		}
+	}
+
+	// Expose power state to building-management systems over Modbus TCP when configured
+	if cfg.ModbusAddr != "" {
+		server := modbus.NewServer(cfg.ModbusAddr, byte(cfg.ModbusUnitID),
+			modbus.NewPowerRegisters(pm.Metrics(), pm, cfg.ModbusAllowOverride, logger), logger)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Run(ctx); err != nil {
+				logger.Printf("Warning: Modbus server stopped: %v", err)
+			}
+		}()
+		logger.Printf("🏢 Modbus TCP server listening on %s (unit %d, overrides allowed: %v)",
+			cfg.ModbusAddr, cfg.ModbusUnitID, cfg.ModbusAllowOverride)
+	}
+
+	// Publish the node state over MQTT when configured
+	if cfg.MQTTBroker != "" {
+		states := publisher.New(mqtt.Options{
+			Broker:    cfg.MQTTBroker,
+			ClientID:  cfg.MQTTClientID,
+			Username:  cfg.MQTTUsername,
+			Password:  cfg.MQTTPassword,
+			KeepAlive: cfg.MQTTKeepAlive,
+		}, cfg.MQTTTopicPrefix, cfg.NodeName, cfg.MQTTDiscoveryPrefix, pm, logger)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			states.Run(ctx)
+		}()
+		logger.Printf("📨 Publishing node state to MQTT broker %s", cfg.MQTTBroker)
+	}
+
+	// Publish decisions and health changes to NATS when configured
+	if cfg.NATSURL != "" {
+		publisher := nats.NewPublisher(cfg.NATSURL, cfg.NATSToken, cfg.NATSSubjectPrefix, cfg.NodeName,
+			cfg.NATSJetStream, pm, logger)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			publisher.Run(ctx)
+		}()
+		logger.Printf("📬 Publishing decisions to NATS (JetStream: %v)", cfg.NATSJetStream)
+	}
+
+	// Drop the cap to the floor while the UPS runs on battery when configured
+	if cfg.UPSAddr != "" {
+		source, err := ups.NewSource(cfg.UPSAddr)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up UPS monitoring: %v", err)
+		} else {
+			monitor := ups.NewMonitor(source, cfg.UPSPollInterval, pm.SetOnBattery, logger)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				monitor.Run(ctx)
+			}()
+			logger.Printf("🔋 Monitoring the UPS every %v", cfg.UPSPollInterval)
+		}
+	}
+
+	// Read the node wall power from an external meter when configured
+	if cfg.MeterAddr != "" {
+		m, err := meter.New(cfg.MeterAddr)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up the power meter: %v", err)
+		} else {
+			pm.SetWallMeter(m)
+			logger.Printf("🔌 Reading wall power from %s", m.Name())
+		}
+	}
+
+	// Shave the site peak from the building meter when configured
+	if cfg.SiteMeterAddr != "" {
+		m, err := meter.New(cfg.SiteMeterAddr)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up the site meter: %v", err)
+		} else {
+			monitor := meter.NewMonitor(m, cfg.SiteMeterInterval, pm.SetSiteDemand, logger)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				monitor.Run(ctx)
+			}()
+			logger.Printf("🏢 Peak shaving above %.0f%% of %.1f kW from %s", cfg.PeakShavingStart*100, float64(cfg.PeakThreshold)/1e9, m.Name())
+		}
+	}
+
+	// Follow the site battery, and the solar production, when configured
+	if cfg.BatterySoCAddr != "" {
+		source, err := battery.NewSource(cfg.BatterySoCAddr)
+		if err != nil {
+			logger.Printf("Warning: Failed to set up the battery source: %v", err)
+		} else {
+			monitor := battery.NewMonitor(source, cfg.BatteryPollInterval, pm.SetBatterySoC, logger)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				monitor.Run(ctx)
+			}()
+			logger.Printf("🔆 Following the battery state of charge from %s (low %.0f%%, high %.0f%%)", source.Name(), cfg.BatteryLowSoC, cfg.BatteryHighSoC)
+		}
+		if cfg.PVMeterAddr != "" {
+			m, err := meter.New(cfg.PVMeterAddr)
+			if err != nil {
+				logger.Printf("Warning: Failed to set up the PV meter: %v", err)
+			} else {
+				monitor := meter.NewMonitor(m, cfg.BatteryPollInterval, pm.SetPVPower, logger)
+				background.Add(1)
+				go func() {
+					defer background.Done()
+					monitor.Run(ctx)
+				}()
+				logger.Printf("🔆 Reading solar production from %s", m.Name())
+			}
+		}
+	}
+
+	// Serve the local HTTP API
+	if cfg.HTTPAddr != "" {
+		server := api.NewServer(cfg.HTTPAddr, pm, logger)
+		if len(cfg.AdminAPITokens) > 0 {
+			server.EnableAdmin(pm, cfg.AdminAPITokens)
+			logger.Printf("🔑 Admin API enabled for %d client(s)", len(cfg.AdminAPITokens))
+		}
+		if cfg.DRWebhookSecret != "" {
+			server.EnableDemandResponse(pm, cfg.DRWebhookSecret)
+			logger.Printf("📉 Demand-response webhook enabled (max duration %v)", cfg.DRMaxDuration)
+		}
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Run(ctx); err != nil {
+				logger.Printf("Warning: %v", err)
+			}
+		}()
+	}
+
+	// Serve the gRPC control API
+	if cfg.GRPCAddr != "" {
+		server := grpcapi.NewServer(cfg.GRPCAddr, pm, cfg.AdminAPITokens, logger)
+		if cfg.GRPCTLSCert != "" {
+			server.EnableTLS(cfg.GRPCTLSCert, cfg.GRPCTLSKey)
+		}
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := server.Run(ctx); err != nil {
+				logger.Printf("Warning: %v", err)
+			}
+		}()
+	}
+
+	// Apply the last saved cap until the first cycle computes a fresh one
+	if err := pm.RestoreLastState(); err != nil {
+		logger.Printf("Warning: %v", err)
+	}
+
+	// Load initial data
+	today := time.Now()
+	if err := pm.LoadData(today); err != nil {
+		logger.Printf("Warning: Failed to load initial data: %v", err)
+		logger.Println("System will attempt to generate data automatically")
+	}
+
+	// Initialize the node annotations
+	if err := pm.InitializeNode(); err != nil {
+		reporter.CaptureError(err, map[string]string{"operation": "initialize-node"})
+		return fmt.Errorf("failed to initialize node: %w", err)
+	}
+
+	// Signal readiness to systemd (Type=notify) and feed its watchdog while
+	// the control loop makes progress
+	if ok, err := systemd.Notify("READY=1\nSTATUS=Starting main cycle"); err != nil {
+		logger.Printf("Warning: %v", err)
+	} else if ok {
+		logger.Println("🐧 Notified systemd of readiness")
+	}
+	if timeout := systemd.WatchdogInterval(); timeout > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			systemd.RunWatchdog(ctx, timeout, func() bool {
+				return pm.Health().Status != power.HealthStalled
+			}, func() string {
+				health := pm.Health()
+				if decision, ok := pm.LastDecision(); ok {
+					return fmt.Sprintf("%s, cap %.1f W (period %s)", health.Status, float64(decision.AppliedPower)/1000000, decision.Period)
+				}
+				return health.Status
+			}, logger)
+		}()
+		logger.Printf("🐕 Feeding the systemd watchdog every %v", timeout/2)
+	}
+
+	// Start the power management cycle
+	logger.Println("Power management system ready - starting main cycle")
+	pm.Run() // This will block until a signal cancels the context
+	stop()
+	if _, err := systemd.Notify("STOPPING=1"); err != nil {
+		logger.Printf("Warning: %v", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := pm.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("Warning: Shutdown incomplete: %v", err)
+		reporter.CaptureError(err, map[string]string{"operation": "shutdown"})
+	}
+	logger.Println("Power manager stopped")
+	return nil
+}
+
+// selectExperimentArm reads the experiment arm of the node from its
+// EXPERIMENT_LABEL label and reloads the configuration with the profile it
+// names, adding it to flags so that drift checks use it too. The declared
+// configuration is kept, and no arm returned, when the label is missing or
+// names an unknown profile.
+func selectExperimentArm(ctx context.Context, flags map[string]string) string {
+	labelCtx, cancel := context.WithTimeout(ctx, cfg.CycleTimeout)
+	defer cancel()
+	arm, err := power.ExperimentArm(labelCtx, cfg)
+	if err != nil {
+		logger.Printf("Warning: Failed to read the experiment label %s, running the declared configuration: %v", cfg.ExperimentLabel, err)
+		return ""
+	}
+	if arm == "" {
+		logger.Printf("🧪 No %s label on the node, running the declared configuration (arm %s)", cfg.ExperimentLabel, power.ExperimentDefault)
+		return ""
+	}
+
+	flags[config.EnvProfile] = arm
+	armCfg, err := config.LoadWithFlags(flags)
+	if err != nil {
+		delete(flags, config.EnvProfile)
+		logger.Printf("Warning: Experiment arm %s is not a usable profile, running the declared configuration: %v", arm, err)
+		return ""
+	}
+	cfg = armCfg
+	logger.Printf("🧪 Experiment arm %s: running profile %s", arm, arm)
+	return arm
+}
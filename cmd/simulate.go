@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/power"
+	"kcas/new/pkg/providers"
+)
+
+// simulateMaxDays bounds the date range of a simulation, each day being
+// fetched when not stored
+const simulateMaxDays = 366
+
+// periodHours is the length of a market period
+const periodHours = 0.25
+
+var simulateOpts struct {
+	date      string
+	from      string
+	to        string
+	provider  string
+	maxPower  int64
+	reference string
+	format    string
+	output    string
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Compute the power cap of every market period of a day or date range",
+	Long: `Load (or fetch) market data for a day or a date range and compute the
+power cap the daemon would apply in each period, without touching Kubernetes
+or RAPL. The report lists every period and summarises each day and the whole
+range: the lowest, highest and average cap, the energy drawn at the caps and
+its cost, against running uncapped at the hardware maximum.`,
+	Example: `  powercap simulate
+  powercap simulate --date 2025-10-09 --max-power 65000000
+  powercap simulate --from 2025-10-01 --to 2025-10-07 --provider energy-charts --reference average
+  powercap simulate --from 2025-10-01 --to 2025-10-31 --format csv --output october.csv`,
+	Args: cobra.NoArgs,
+	RunE: runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().StringVar(&simulateOpts.date, "date", "", "day to simulate (YYYY-MM-DD, default today)")
+	simulateCmd.Flags().StringVar(&simulateOpts.from, "from", "", "first day of a range to simulate (YYYY-MM-DD)")
+	simulateCmd.Flags().StringVar(&simulateOpts.to, "to", "", "last day of the range, included (YYYY-MM-DD, default --from)")
+	simulateCmd.Flags().StringVar(&simulateOpts.provider, "provider", "", "market data provider (default DATA_PROVIDER)")
+	simulateCmd.Flags().Int64Var(&simulateOpts.maxPower, "max-power", 40000000, "hardware maximum power in µW used as the source power")
+	simulateCmd.Flags().StringVar(&simulateOpts.reference, "reference", "", "reference volume: max, average or percent (default POWER_CALC_MODE)")
+	simulateCmd.Flags().StringVar(&simulateOpts.format, "format", "table", "report format: table, csv or json")
+	simulateCmd.Flags().StringVar(&simulateOpts.output, "output", "", "file the report is written to (default stdout)")
+	rootCmd.AddCommand(simulateCmd)
+}
+
+// simulationPeriod is the cap computed for one market period
+type simulationPeriod struct {
+	Date     string  `json:"date"`
+	Period   string  `json:"period"`
+	Volume   float64 `json:"volume_mwh"`
+	Price    float64 `json:"price"`
+	SourceUW int64   `json:"source_uw"`
+	CapUW    int64   `json:"cap_uw"`
+	Clamp    string  `json:"clamp"`
+}
+
+// simulationSummary summarises the caps of a day or of the whole range
+type simulationSummary struct {
+	Date             string  `json:"date,omitempty"` // Empty for the whole range
+	Periods          int     `json:"periods"`
+	MinCapUW         int64   `json:"min_cap_uw"`
+	MaxCapUW         int64   `json:"max_cap_uw"`
+	AvgCapUW         float64 `json:"avg_cap_uw"`
+	MinPowerPeriods  int     `json:"min_power_periods"` // Periods capped at RAPL_MIN_POWER
+	SpikePeriods     int     `json:"spike_periods"`
+	EnergyWh         float64 `json:"energy_wh"`          // Drawn at the caps
+	Cost             float64 `json:"cost"`               // Of EnergyWh at the period prices
+	UncappedEnergyWh float64 `json:"uncapped_energy_wh"` // Drawn at the hardware maximum
+	UncappedCost     float64 `json:"uncapped_cost"`
+}
+
+// add adds a period to the summary
+func (s *simulationSummary) add(p simulationPeriod, maxPower int64) {
+	if s.Periods == 0 || p.CapUW < s.MinCapUW {
+		s.MinCapUW = p.CapUW
+	}
+	if p.CapUW > s.MaxCapUW {
+		s.MaxCapUW = p.CapUW
+	}
+	s.AvgCapUW += (float64(p.CapUW) - s.AvgCapUW) / float64(s.Periods+1)
+	s.Periods++
+	switch p.Clamp {
+	case power.ClampMinPower:
+		s.MinPowerPeriods++
+	case power.ClampPriceSpike:
+		s.SpikePeriods++
+	}
+	energy := float64(p.CapUW) / 1000000 * periodHours
+	uncapped := float64(maxPower) / 1000000 * periodHours
+	s.EnergyWh += energy
+	s.Cost += energy / 1000000 * p.Price
+	s.UncappedEnergyWh += uncapped
+	s.UncappedCost += uncapped / 1000000 * p.Price
+}
+
+// simulationReport is the outcome of a simulation
+type simulationReport struct {
+	Provider   string              `json:"provider"`
+	Reference  string              `json:"reference"`
+	Currency   string              `json:"currency"`
+	MaxPowerUW int64               `json:"max_power_uw"`
+	MinPowerUW int64               `json:"min_power_uw"`
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Skipped    []string            `json:"skipped,omitempty"` // Days without usable data
+	Days       []simulationSummary `json:"days"`
+	Summary    simulationSummary   `json:"summary"`
+	Periods    []simulationPeriod  `json:"periods"`
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	// Keep a CSV or JSON report on stdout free of log lines
+	if simulateOpts.format != "table" && simulateOpts.output == "" {
+		logger.SetOutput(os.Stderr)
+	}
+	from, to, err := simulateRange(marketLocation())
+	if err != nil {
+		return err
+	}
+	if simulateOpts.maxPower <= 0 {
+		return fmt.Errorf("--max-power must be positive")
+	}
+	switch simulateOpts.format {
+	case "table", "csv", "json":
+	default:
+		return fmt.Errorf("invalid format %q (expected table, csv or json)", simulateOpts.format)
+	}
+
+	reference := simulateOpts.reference
+	if reference == "" {
+		reference = cfg.PowerCalcMode
+	}
+	if reference != "max" && reference != "average" && reference != "percent" {
+		return fmt.Errorf("invalid reference %q (expected max, average or percent)", reference)
+	}
+
+	providerCfg := *cfg
+	if simulateOpts.provider != "" && !strings.EqualFold(simulateOpts.provider, cfg.DataProvider) {
+		// PROVIDER_URL belongs to the configured provider
+		providerCfg.DataProvider = strings.ToLower(simulateOpts.provider)
+		providerCfg.ProviderURL = ""
+	}
+	provider, err := providers.NewProviderFactory().CreateProvider(&providerCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create provider: %w", err)
+	}
+
+	// Load the stored CSVs, fetching them from the provider when missing
+	ds := datastore.NewCSVDataStore(logger)
+	ds.SetProvider(provider)
+	ds.SetDirectory(cfg.DataDir)
+	ds.SetLocation(from.Location())
+	ds.SetCurrency(cfg.Currency)
+	ds.SetSmoothingWindow(cfg.SmoothingWindow)
+
+	report := simulationReport{
+		Provider:   providerCfg.DataProvider,
+		Reference:  reference,
+		Currency:   cfg.Currency,
+		MaxPowerUW: simulateOpts.maxPower,
+		MinPowerUW: cfg.RaplLimit,
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+	}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		periods, err := simulateDay(ds, date, reference)
+		if err != nil {
+			if from.Equal(to) {
+				return err
+			}
+			logger.Printf("⚠️  Skipping %s: %v", date.Format("2006-01-02"), err)
+			report.Skipped = append(report.Skipped, date.Format("2006-01-02"))
+			continue
+		}
+		day := simulationSummary{Date: date.Format("2006-01-02")}
+		for _, period := range periods {
+			day.add(period, simulateOpts.maxPower)
+			report.Summary.add(period, simulateOpts.maxPower)
+		}
+		report.Days = append(report.Days, day)
+		report.Periods = append(report.Periods, periods...)
+	}
+	if len(report.Periods) == 0 {
+		return fmt.Errorf("no usable market data between %s and %s", report.From, report.To)
+	}
+
+	out := io.Writer(os.Stdout)
+	if simulateOpts.output != "" {
+		file, err := os.Create(simulateOpts.output)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	switch simulateOpts.format {
+	case "csv":
+		err = writeSimulationCSV(out, report)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	default:
+		writeSimulationTable(out, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if simulateOpts.output != "" {
+		logger.Printf("📝 Report of %d periods written to %s", len(report.Periods), simulateOpts.output)
+	}
+	return nil
+}
+
+// simulateRange returns the first and last day to simulate, from --date or
+// --from and --to
+func simulateRange(loc *time.Location) (time.Time, time.Time, error) {
+	if simulateOpts.from == "" {
+		if simulateOpts.to != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to needs --from")
+		}
+		date, err := parseDate(simulateOpts.date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		return date, date, nil
+	}
+	if simulateOpts.date != "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("--date and --from are exclusive")
+	}
+
+	from, err := parseDate(simulateOpts.from, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to := from
+	if simulateOpts.to != "" {
+		if to, err = parseDate(simulateOpts.to, loc); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--to %s is before --from %s", simulateOpts.to, simulateOpts.from)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > simulateMaxDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("the range spans %d days, more than %d", days, simulateMaxDays)
+	}
+	return from, to, nil
+}
+
+// simulateDay computes the cap of every period of a day
+func simulateDay(ds *datastore.CSVDataStore, date time.Time, reference string) ([]simulationPeriod, error) {
+	data, err := ds.LoadData(context.Background(), date)
+	if err != nil {
+		return nil, err
+	}
+	referenceVolume := ds.GetReferenceVolume(reference)
+	if referenceVolume <= 0 {
+		return nil, fmt.Errorf("no usable %s volume in market data", reference)
+	}
+
+	maxPower := simulateOpts.maxPower
+	adminMax := cfg.RaplMaxPower.Resolve(maxPower)
+	logger.Printf("Simulating %d periods for %s (reference %s volume %.1f MWh, max power %.1f W, min power %.1f W)",
+		len(data), date.Format("2006-01-02"), reference, referenceVolume, float64(maxPower)/1000000, float64(cfg.RaplLimit)/1000000)
+
+	// On closed days with CLOSED_DAY_CAP, the closed day cap replaces the
+	// market-based cap
+	closedCap := int64(-1)
+	if cal, err := cfg.MarketCalendar(); err == nil && cal != nil && cfg.ClosedDayCap.IsSet() {
+		if closed, reason := cal.Closed(date); closed {
+			closedCap = cfg.ClosedDayCap.Resolve(maxPower)
+			if closedCap < cfg.RaplLimit {
+				closedCap = cfg.RaplLimit
+			}
+			if cfg.RaplMaxPower.IsSet() && closedCap > adminMax {
+				closedCap = adminMax
+			}
+			logger.Printf("Market closed (%s): capping every period to %.1f W", reason, float64(closedCap)/1000000)
+		}
+	}
+
+	median := datastore.MedianPrice(data)
+	periods := make([]simulationPeriod, 0, len(data))
+	for _, point := range data {
+		// Rule of three: currentVolume / referenceVolume = currentPower / maxPower
+		source := int64(math.Round(point.Volume / referenceVolume * float64(maxPower)))
+		capPower, clamp := source, power.ClampNone
+		if capPower > maxPower {
+			capPower, clamp = maxPower, power.ClampHardwareMax
+		} else if capPower <= cfg.RaplLimit {
+			capPower, clamp = cfg.RaplLimit, power.ClampMinPower
+		}
+		if cfg.RaplMaxPower.IsSet() && capPower > adminMax {
+			capPower, clamp = adminMax, power.ClampAdminMax
+		}
+		if closedCap >= 0 {
+			capPower, clamp = closedCap, power.ClampClosedDay
+		} else if capPower > cfg.RaplLimit && datastore.IsPriceSpike(point.Price, median, cfg.PriceSpikeThreshold, cfg.PriceSpikeFactor) {
+			capPower, clamp = cfg.RaplLimit, power.ClampPriceSpike
+		}
+
+		periods = append(periods, simulationPeriod{
+			Date:     date.Format("2006-01-02"),
+			Period:   point.Period,
+			Volume:   point.Volume,
+			Price:    point.Price,
+			SourceUW: source,
+			CapUW:    capPower,
+			Clamp:    clamp,
+		})
+	}
+	return periods, nil
+}
+
+// writeSimulationTable prints the periods, a summary per day and one of
+// the whole range
+func writeSimulationTable(out io.Writer, report simulationReport) {
+	fmt.Fprintf(out, "%-10s %-12s %12s %14s %12s %12s  %s\n", "DATE", "PERIOD", "VOLUME (MWh)",
+		"PRICE ("+datastore.PriceUnit(report.Currency)+")", "SOURCE (W)", "CAP (W)", "CLAMP")
+	for _, p := range report.Periods {
+		fmt.Fprintf(out, "%-10s %-12s %12.1f %14.2f %12.1f %12.1f  %s\n",
+			p.Date, p.Period, p.Volume, p.Price, float64(p.SourceUW)/1000000, float64(p.CapUW)/1000000, p.Clamp)
+	}
+
+	fmt.Fprintln(out)
+	if len(report.Days) > 1 {
+		for _, day := range report.Days {
+			writeSimulationSummary(out, day.Date, day, report.Currency)
+		}
+	}
+	writeSimulationSummary(out, report.From+" to "+report.To, report.Summary, report.Currency)
+	if len(report.Skipped) > 0 {
+		fmt.Fprintf(out, "Skipped without data: %s\n", strings.Join(report.Skipped, ", "))
+	}
+}
+
+// writeSimulationSummary prints one summary line
+func writeSimulationSummary(out io.Writer, label string, s simulationSummary, currency string) {
+	saved := 0.0
+	if s.UncappedEnergyWh > 0 {
+		saved = (1 - s.EnergyWh/s.UncappedEnergyWh) * 100
+	}
+	fmt.Fprintf(out, "%s: %d periods, cap %.1f-%.1f W (average %.1f W), %d at the minimum, %d price spikes; "+
+		"%.2f kWh costing %.2f %s, %.0f%% below %.2f kWh (%.2f %s) uncapped\n",
+		label, s.Periods, float64(s.MinCapUW)/1000000, float64(s.MaxCapUW)/1000000, s.AvgCapUW/1000000,
+		s.MinPowerPeriods, s.SpikePeriods, s.EnergyWh/1000, s.Cost, currency, saved,
+		s.UncappedEnergyWh/1000, s.UncappedCost, currency)
+}
+
+// writeSimulationCSV writes one row per period
+func writeSimulationCSV(out io.Writer, report simulationReport) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"date", "period", "volume_mwh", "price", "source_uw", "cap_uw", "clamp"}); err != nil {
+		return err
+	}
+	for _, p := range report.Periods {
+		if err := w.Write([]string{
+			p.Date,
+			p.Period,
+			strconv.FormatFloat(p.Volume, 'f', -1, 64),
+			strconv.FormatFloat(p.Price, 'f', -1, 64),
+			strconv.FormatInt(p.SourceUW, 10),
+			strconv.FormatInt(p.CapUW, 10),
+			p.Clamp,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/api"
+	"kcas/new/internal/power"
+)
+
+var statusJSON bool
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the running daemon's state",
+	Long: `Query the local HTTP API (HTTP_ADDR) of the running daemon and print the
+applied cap, RAPL limits, last decision, market data and provider health.`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print the raw JSON status")
+	rootCmd.AddCommand(statusCmd)
+}
+
+// runStatus prints the running manager's current state
+func runStatus(cmd *cobra.Command, args []string) error {
+	var status power.Status
+	if err := api.NewClient(cfg.HTTPAddr).GetJSON("/status", &status); err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+
+	if statusJSON {
+		out, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Node:          %s (running since %s)\n", status.Node, status.StartedAt.Format(time.RFC3339))
+	if status.Version != "" {
+		fmt.Printf("Version:       %s\n", status.Version)
+	}
+	fmt.Printf("Applied cap:   %.1f W\n", float64(status.AppliedCapUW)/1000000)
+
+	fmt.Println("RAPL domains:")
+	for _, domain := range status.Domains {
+		for _, constraint := range domain.Constraints {
+			if constraint.ReadFailed {
+				fmt.Printf("  %-16s %s: unreadable\n", domain.ID, constraint.Path)
+				continue
+			}
+			fmt.Printf("  %-16s %s: %.1f W\n", domain.ID, constraint.Path, float64(constraint.LimitUW)/1000000)
+		}
+	}
+
+	if d := status.LastDecision; d != nil {
+		fmt.Println("Last decision:")
+		fmt.Printf("  at %s, period %s\n", d.Timestamp.Format(time.RFC3339), d.Period)
+		fmt.Printf("  volume %.1f / %.1f MWh → source %.1f W, applied %.1f W (clamp: %s)\n",
+			d.Volume, d.ReferenceVolume, float64(d.SourcePower)/1000000, float64(d.AppliedPower)/1000000, d.Clamp)
+		if d.EnergyWh > 0 {
+			fmt.Printf("  energy %.1f Wh since the previous cycle (%.4f %s), %.1f Wh today (%.2f %s)\n",
+				d.EnergyWh, d.Cost, d.Currency, d.DailyEnergyWh, d.DailyCost, d.Currency)
+		}
+		for _, fallback := range d.Fallbacks {
+			fmt.Printf("  fallback: %s\n", fallback)
+		}
+	} else {
+		fmt.Println("Last decision: none yet")
+	}
+
+	if s := status.Savings; s != nil {
+		fmt.Printf("Savings:       %.2f kWh (%.2f %s) today, %.2f kWh (%.2f %s) since %s, against %s\n",
+			s.Today.EnergyWh/1000, s.Today.Cost, s.Currency, s.Total.EnergyWh/1000, s.Total.Cost, s.Currency,
+			s.Total.Since.Format(time.RFC3339), s.Baseline)
+	}
+
+	fmt.Printf("Market data:   %d points, max volume %.1f MWh", status.Data.Points, status.Data.MaxVolume)
+	if status.Data.Age != "" {
+		fmt.Printf(", loaded %s ago", status.Data.Age)
+	}
+	fmt.Println()
+
+	p := status.Provider
+	fmt.Printf("Provider:      %s", p.Provider)
+	switch {
+	case p.LastAttempt.IsZero():
+		fmt.Println(" (no fetch attempted yet)")
+	case p.ConsecutiveFailures > 0:
+		fmt.Printf(" UNHEALTHY: %d consecutive failure(s), last error: %s\n", p.ConsecutiveFailures, p.LastError)
+	default:
+		fmt.Printf(" healthy, last fetch %s (%v)\n", p.LastSuccess.Format(time.RFC3339), p.LastDuration)
+	}
+	if len(status.Providers) > 1 {
+		for _, h := range status.Providers {
+			marker := " "
+			if h.Active {
+				marker = "*"
+			}
+			fmt.Printf("  %s %-10s score %.2f (success %.0f%%, complete %.0f%%, %.1fs)\n",
+				marker, h.Provider, h.Score, h.SuccessRate*100, h.Completeness*100, h.LatencyS)
+		}
+	}
+	return nil
+}
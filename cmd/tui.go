@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/api"
+	"kcas/new/internal/tui"
+)
+
+var tuiOpts struct {
+	token    string
+	interval time.Duration
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Show a live dashboard of the running daemon in the terminal",
+	Long: `Query the local HTTP API (HTTP_ADDR) of the running daemon and show the
+applied cap, measured power, current period, today's cap curve and the
+recent decisions, refreshed live. The curve and decisions come from the
+admin API: the first token of ADMIN_API_TOKENS is used unless --token is set.
+Press q to quit and r to refresh.`,
+	Example: `  powercap tui
+  powercap tui --interval 5s --token "$POWERCAP_TOKEN"`,
+	Args: cobra.NoArgs,
+	RunE: runTUI,
+}
+
+func init() {
+	tuiCmd.Flags().StringVar(&tuiOpts.token, "token", "", "admin API token (default the first of ADMIN_API_TOKENS)")
+	tuiCmd.Flags().DurationVar(&tuiOpts.interval, "interval", 2*time.Second, "refresh interval")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// runTUI draws the dashboard until the user quits
+func runTUI(cmd *cobra.Command, args []string) error {
+	if tuiOpts.interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	client := api.NewClient(cfg.HTTPAddr)
+	token := tuiOpts.token
+	if token == "" {
+		token = firstToken(cfg.AdminAPITokens)
+	}
+	client.SetToken(token)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return tui.NewDashboard(client, token != "", tuiOpts.interval).Run(ctx)
+}
+
+// firstToken returns the token of the alphabetically first client, empty if none
+func firstToken(tokens map[string]string) string {
+	token, first := "", ""
+	for known, name := range tokens {
+		if first == "" || name < first {
+			token, first = known, name
+		}
+	}
+	return token
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"kcas/new/internal/version"
+)
+
+var versionOpts struct {
+	json bool
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Args:  cobra.NoArgs,
+	// The version is printed without loading the configuration
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := version.Get()
+		if versionOpts.json {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+		fmt.Printf("powercap %s\n", info)
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionOpts.json, "json", false, "print the build information as JSON")
+	rootCmd.AddCommand(versionCmd)
+}
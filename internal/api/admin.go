@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/power"
+)
+
+// Controller exposes the manager operations of the admin API
+type Controller interface {
+	// DecisionHistory returns up to limit of the most recent decisions
+	DecisionHistory(limit int) []power.PowerDecision
+
+	// Schedule computes the cap of every period of the current day
+	Schedule() ([]power.PlannedCap, error)
+
+	// Forecast computes the caps expected over the next hours
+	Forecast(hours int) ([]power.ForecastCap, error)
+
+	// MarketData returns the market data of the loaded day
+	MarketData() []datastore.MarketDataPoint
+
+	// Currency returns the currency of the market prices
+	Currency() string
+
+	// TriggerRefresh fetches today's market data again
+	TriggerRefresh() error
+
+	// SetOverride pins the cap until the override expires
+	SetOverride(override power.Override) error
+
+	// ClearOverride removes the active override
+	ClearOverride(clearedBy string) (power.Override, bool)
+
+	// ActiveOverride returns the override in effect, if any
+	ActiveOverride() (power.Override, bool)
+
+	// SubscribeDecisions streams new decisions until the returned function is called
+	SubscribeDecisions() (<-chan power.PowerDecision, func())
+}
+
+// clientKey is the context key of the authenticated client name
+type clientKey struct{}
+
+// overrideRequest is the body of PUT /api/v1/override
+type overrideRequest struct {
+	PowerUW  int64  `json:"power_uw"`
+	Disable  bool   `json:"disable"`  // Disable capping instead of pinning power_uw
+	Duration string `json:"duration"` // Go duration, e.g. "30m"
+	Reason   string `json:"reason"`
+}
+
+// EnableAdmin serves the authenticated admin API under /api/v1/ and the web
+// dashboard using it under /ui/. tokens maps bearer tokens to the client
+// names recorded with overrides.
+func (s *Server) EnableAdmin(ctrl Controller, tokens map[string]string) {
+	s.ctrl = ctrl
+	s.tokens = tokens
+
+	s.mux.Handle("/api/v1/state", s.authenticate(s.handleStatus))
+	s.mux.Handle("/api/v1/decisions", s.authenticate(s.handleDecisions))
+	s.mux.Handle("/api/v1/decisions/stream", s.authenticate(s.handleDecisionStream))
+	s.mux.Handle("/api/v1/schedule", s.authenticate(s.handleSchedule))
+	s.mux.Handle("/api/v1/forecast", s.authenticate(s.handleForecast))
+	s.mux.Handle("/api/v1/data", s.authenticate(s.handleData))
+	s.mux.Handle("/api/v1/refresh", s.authenticate(s.handleRefresh))
+	s.mux.Handle("/api/v1/override", s.authenticate(s.handleOverride))
+	s.mux.Handle("/ui/", s.handleUI())
+	s.mux.Handle("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+}
+
+// authenticate rejects requests without a known bearer token
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="powercap"`)
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		client := ""
+		for known, name := range s.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				client = name
+			}
+		}
+		if client == "" {
+			s.logger.Printf("⚠️  Rejected admin API request %s %s from %s: invalid token", r.Method, r.URL.Path, r.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, "invalid bearer token")
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+	})
+}
+
+// clientName returns the authenticated client of a request
+func clientName(r *http.Request) string {
+	name, _ := r.Context().Value(clientKey{}).(string)
+	return name
+}
+
+// handleDecisions returns the recent decisions, oldest first
+func (s *Server) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, s.ctrl.DecisionHistory(limit))
+}
+
+// handleSchedule returns the cap of every period of the current day as JSON
+// or CSV
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := s.ctrl.Schedule()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if format == formatJSON {
+		writeJSON(w, http.StatusOK, schedule)
+		return
+	}
+
+	priceHeader := "Price (" + datastore.PriceUnit(s.ctrl.Currency()) + ")"
+	rows := [][]string{{"Period", "Volume (MWh)", priceHeader, "Source Power (µW)", "Cap (µW)", "Clamp"}}
+	for _, planned := range schedule {
+		rows = append(rows, []string{
+			planned.Period,
+			strconv.FormatFloat(planned.Volume, 'f', 1, 64),
+			strconv.FormatFloat(planned.Price, 'f', 2, 64),
+			strconv.FormatInt(planned.SourcePower, 10),
+			strconv.FormatInt(planned.CapUW, 10),
+			planned.Clamp,
+		})
+	}
+	writeCSV(w, "schedule.csv", rows)
+}
+
+// handleForecast returns the caps expected over the next hours (24 by
+// default, up to 48) as JSON or CSV
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	hours := 24
+	if value := r.URL.Query().Get("hours"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 48 {
+			writeError(w, http.StatusBadRequest, "hours must be an integer between 1 and 48")
+			return
+		}
+		hours = n
+	}
+
+	forecast, err := s.ctrl.Forecast(hours)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if format == formatJSON {
+		writeJSON(w, http.StatusOK, forecast)
+		return
+	}
+
+	rows := [][]string{{"Start", "End", "Period", "Volume (MWh)", "Price (" + datastore.PriceUnit(s.ctrl.Currency()) + ")", "Cap (µW)", "Clamp", "Source"}}
+	for _, expected := range forecast {
+		rows = append(rows, []string{
+			expected.Start.Format(time.RFC3339),
+			expected.End.Format(time.RFC3339),
+			expected.Period,
+			strconv.FormatFloat(expected.Volume, 'f', 1, 64),
+			strconv.FormatFloat(expected.Price, 'f', 2, 64),
+			strconv.FormatInt(expected.CapUW, 10),
+			expected.Clamp,
+			expected.Source,
+		})
+	}
+	writeCSV(w, "forecast.csv", rows)
+}
+
+// handleData returns the market data of the current day as JSON or CSV
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	data := s.ctrl.MarketData()
+	if len(data) == 0 {
+		writeError(w, http.StatusServiceUnavailable, "no market data loaded")
+		return
+	}
+	if format == formatJSON {
+		writeJSON(w, http.StatusOK, data)
+		return
+	}
+
+	// Same layout as the daily CSV files
+	rows := [][]string{{"Period", "Volume (MWh)", "Price (" + datastore.PriceUnit(s.ctrl.Currency()) + ")"}}
+	for _, point := range data {
+		rows = append(rows, []string{
+			point.Period,
+			strconv.FormatFloat(point.Volume, 'f', 1, 64),
+			strconv.FormatFloat(point.Price, 'f', 2, 64),
+		})
+	}
+	writeCSV(w, "market-data.csv", rows)
+}
+
+// handleRefresh fetches today's market data again
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	s.logger.Printf("🔄 Data refresh requested by %s", clientName(r))
+	if err := s.ctrl.TriggerRefresh(); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "refreshed"})
+}
+
+// handleOverride reads, sets or clears the manual override
+func (s *Server) handleOverride(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		override, ok := s.ctrl.ActiveOverride()
+		if !ok {
+			writeError(w, http.StatusNotFound, "no active override")
+			return
+		}
+		writeJSON(w, http.StatusOK, override)
+
+	case http.MethodPut:
+		var req overrideRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			writeError(w, http.StatusBadRequest, `duration must be a positive Go duration such as "30m"`)
+			return
+		}
+
+		now := time.Now()
+		override := power.Override{
+			PowerUW:   req.PowerUW,
+			Disable:   req.Disable,
+			Reason:    req.Reason,
+			SetBy:     clientName(r),
+			SetAt:     now,
+			ExpiresAt: now.Add(duration),
+		}
+		if err := s.ctrl.SetOverride(override); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, override)
+
+	case http.MethodDelete:
+		override, ok := s.ctrl.ClearOverride(clientName(r))
+		if !ok {
+			writeError(w, http.StatusNotFound, "no active override")
+			return
+		}
+		writeJSON(w, http.StatusOK, override)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Client queries the HTTP API of a running power manager
+type Client struct {
+	baseURL    string
+	token      string // Bearer token of the admin API, empty for the public endpoints only
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the API listening on addr. An address
+// without host (":9090") is reached through the loopback interface.
+func NewClient(addr string) *Client {
+	host, port, err := net.SplitHostPort(addr)
+	if err == nil && (host == "" || host == "0.0.0.0" || host == "::") {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+
+	return &Client{
+		baseURL:    "http://" + addr,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetToken sets the bearer token sent to the admin API
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// GetJSON fetches path and decodes the JSON response into out
+func (c *Client) GetJSON(path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach power manager at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("power manager returned %d: %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("power manager returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
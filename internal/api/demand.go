@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/power"
+)
+
+// Demand-response webhook signature headers
+const (
+	SignatureHeader = "X-Powercap-Signature" // "sha256=" and the hex HMAC of timestamp + "." + body
+	TimestampHeader = "X-Powercap-Timestamp" // Unix time of the request in seconds
+)
+
+// maxSignatureSkew is the largest accepted difference between the request
+// timestamp and the local clock, limiting replays
+const maxSignatureSkew = 5 * time.Minute
+
+// DemandResponder handles the shed requests of a demand-response aggregator
+type DemandResponder interface {
+	// ShedLoad converts a shed request into a temporary cap
+	ShedLoad(req power.ShedRequest) (power.DemandEvent, error)
+
+	// DemandEvents returns the recent events with their compliance
+	DemandEvents() []power.DemandEvent
+
+	// DemandEvent returns the event with the given id
+	DemandEvent(id string) (power.DemandEvent, bool)
+}
+
+// shedRequest is the body of POST /api/v1/demand-response
+type shedRequest struct {
+	ID       string  `json:"id"`
+	ReduceW  float64 `json:"reduce_w"`
+	Duration string  `json:"duration"` // Go duration, e.g. "30m"
+}
+
+// EnableDemandResponse serves the demand-response webhook under
+// /api/v1/demand-response, authenticated with an HMAC-SHA256 of each request
+func (s *Server) EnableDemandResponse(dr DemandResponder, secret string) {
+	s.dr = dr
+	s.drSecret = []byte(secret)
+
+	s.mux.Handle("/api/v1/demand-response", s.verifySignature(s.handleShed))
+	s.mux.Handle("/api/v1/demand-response/", s.verifySignature(s.handleDemandEvent))
+}
+
+// verifySignature rejects requests without a valid, recent signature
+func (s *Server) verifySignature(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "failed to read body: "+err.Error())
+			return
+		}
+
+		seconds, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "missing or invalid "+TimestampHeader+" header")
+			return
+		}
+		if skew := time.Since(time.Unix(seconds, 0)); skew > maxSignatureSkew || skew < -maxSignatureSkew {
+			writeError(w, http.StatusUnauthorized, "request timestamp is too far from the server clock")
+			return
+		}
+
+		signature, found := strings.CutPrefix(r.Header.Get(SignatureHeader), "sha256=")
+		got, err := hex.DecodeString(signature)
+		if !found || err != nil || !hmac.Equal(got, Sign(s.drSecret, seconds, body)) {
+			s.logger.Printf("⚠️  Rejected demand-response request %s %s from %s: invalid signature", r.Method, r.URL.Path, r.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	})
+}
+
+// Sign returns the HMAC-SHA256 of a demand-response request
+func Sign(secret []byte, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// handleShed accepts a shed request (POST) or lists the recent events (GET)
+func (s *Server) handleShed(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.dr.DemandEvents())
+
+	case http.MethodPost:
+		var req shedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, `duration must be a Go duration such as "30m"`)
+			return
+		}
+
+		event, err := s.dr.ShedLoad(power.ShedRequest{
+			ID:       req.ID,
+			ReduceUW: int64(req.ReduceW * 1000000),
+			Duration: duration,
+		})
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, event)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleDemandEvent returns the compliance report of one event
+func (s *Server) handleDemandEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	event, ok := s.dr.DemandEvent(strings.TrimPrefix(r.URL.Path, "/api/v1/demand-response/"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown event")
+		return
+	}
+	writeJSON(w, http.StatusOK, event)
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/power"
+)
+
+// StateProvider exposes the manager state served by the API
+type StateProvider interface {
+	// LastDecision returns the most recent power decision
+	LastDecision() (power.PowerDecision, bool)
+
+	// Status returns a snapshot of the manager state
+	Status() power.Status
+
+	// Health evaluates whether the control loop is making progress
+	Health() power.Health
+
+	// Metrics returns the registry exposed on /metrics
+	Metrics() *metrics.Registry
+}
+
+// Server is the local HTTP API of the power manager
+type Server struct {
+	addr   string
+	state  StateProvider
+	logger *log.Logger
+	mux    *http.ServeMux
+
+	ctrl   Controller        // Manager operations of the admin API, nil when disabled
+	tokens map[string]string // Admin API bearer tokens mapped to client names
+
+	dr       DemandResponder // Demand-response handler, nil when disabled
+	drSecret []byte          // HMAC key of the demand-response webhook
+}
+
+// NewServer creates an API server bound to addr
+func NewServer(addr string, state StateProvider, logger *log.Logger) *Server {
+	s := &Server{
+		addr:   addr,
+		state:  state,
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/explain", s.handleExplain)
+	s.mux.HandleFunc("/status", s.handleStatus)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	s.mux.Handle("/metrics", metrics.Handler(state.Metrics(), "powercap_"))
+
+	return s
+}
+
+// Run serves requests until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Cancel request contexts on shutdown so decision streams end
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Printf("🌐 HTTP API listening on %s", s.addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP API server failed: %w", err)
+	}
+	return nil
+}
+
+// handleExplain returns the inputs and outcome of the most recent decision
+func (s *Server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	decision, ok := s.state.LastDecision()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "no decision has been made yet")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, decision)
+}
+
+// handleStatus returns a snapshot of the manager state
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.state.Status())
+}
+
+// handleHealth reports control loop health, failing with 503 when the loop
+// is stalled or failing repeatedly
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	health := s.state.Health()
+
+	status := http.StatusOK
+	if !health.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+// writeJSON writes v as an indented JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// Export formats
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// exportFormat returns the format requested with ?format= or the Accept
+// header, JSON by default. It writes an error for unknown formats.
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case formatJSON, formatCSV:
+		return format, true
+	case "":
+		if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			return formatCSV, true
+		}
+		return formatJSON, true
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q (expected json or csv)", format))
+		return "", false
+	}
+}
+
+// writeCSV writes rows as a CSV attachment
+func writeCSV(w http.ResponseWriter, filename string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(rows)
+}
+
+// writeError writes a JSON error document
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
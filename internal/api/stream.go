@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamKeepAlive is the interval of comment lines keeping idle streams open
+// through proxies
+const streamKeepAlive = 30 * time.Second
+
+// handleDecisionStream sends every decision as a Server-Sent Event until the
+// client disconnects
+func (s *Server) handleDecisionStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	decisions, unsubscribe := s.ctrl.SubscribeDecisions()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Start with the latest decision so clients render without waiting a cycle
+	if last, ok := s.state.LastDecision(); ok {
+		if err := writeEvent(w, last); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	s.logger.Printf("📺 Decision stream opened by %s", clientName(r))
+	defer s.logger.Printf("📺 Decision stream closed by %s", clientName(r))
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case decision, ok := <-decisions:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, decision); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a value as a "decision" event
+func writeEvent(w http.ResponseWriter, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: decision\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,275 @@
+// Dashboard of the power manager, reading the admin API with a bearer token
+// kept in the session storage of the tab.
+"use strict";
+
+const refreshInterval = 10000;
+const tokenKey = "powercap-token";
+
+const $ = (id) => document.getElementById(id);
+
+let timer = null;
+
+function token() {
+  return sessionStorage.getItem(tokenKey) || "";
+}
+
+// api calls the admin API, returning the decoded JSON body
+async function api(path, options = {}) {
+  const headers = Object.assign({ Authorization: "Bearer " + token() }, options.headers || {});
+  const response = await fetch("/api/v1/" + path, Object.assign({}, options, { headers }));
+  if (response.status === 401) {
+    signOut("Invalid or expired token");
+    throw new Error("unauthorized");
+  }
+  const body = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    const error = new Error(body.error || response.statusText);
+    error.status = response.status;
+    throw error;
+  }
+  return body;
+}
+
+function watts(uw) {
+  return (uw / 1e6).toFixed(1) + " W";
+}
+
+function priceUnit(currency) {
+  const symbols = { EUR: "€", GBP: "£", USD: "$" };
+  return (symbols[currency] || currency || "€") + "/MWh";
+}
+
+function time(value) {
+  return new Date(value).toLocaleTimeString([], { hour: "2-digit", minute: "2-digit", second: "2-digit" });
+}
+
+function setText(id, text) {
+  $(id).textContent = text;
+}
+
+function renderState(state) {
+  setText("node", state.node);
+  setText("version", state.version);
+  setText("cap", watts(state.applied_cap_uw));
+  setText("measured", state.measured_power_uw ? watts(state.measured_power_uw) : "unknown");
+
+  const d = state.last_decision;
+  if (d) {
+    setText("clamp", "clamp: " + d.clamp);
+    setText("hardware", "hardware max " + watts(d.hardware_max_uw));
+    setText("period", d.period);
+    setText("market", "volume " + d.volume_mwh.toFixed(1) + " / " + d.reference_volume_mwh.toFixed(1) +
+      ", price " + d.price_eur_mwh.toFixed(2) + " " + priceUnit(d.currency));
+  } else {
+    setText("clamp", "no decision yet");
+  }
+
+  const p = state.provider;
+  setText("provider", p.provider || "—");
+  let data = state.data.points + " points";
+  if (state.data.age) {
+    data += ", loaded " + state.data.age + " ago";
+  }
+  if (p.consecutive_failures > 0) {
+    data += " — " + p.consecutive_failures + " failed fetch(es): " + p.last_error;
+  }
+  setText("data", data);
+
+  const o = state.override;
+  if (o) {
+    const pinned = o.disable ? "capping disabled" : watts(o.power_uw);
+    setText("override-state", "Active: " + pinned + " by " + o.set_by + " until " + time(o.expires_at) +
+      (o.reason ? " (" + o.reason + ")" : ""));
+  } else {
+    setText("override-state", "No active override.");
+  }
+  $("override-clear").disabled = !o;
+}
+
+function renderSchedule(schedule, current) {
+  const svg = $("chart");
+  svg.replaceChildren();
+  const axis = $("axis");
+  axis.replaceChildren();
+  if (!schedule.length) {
+    return;
+  }
+
+  const ns = "http://www.w3.org/2000/svg";
+  const width = 960, height = 240, top = 16;
+  const max = Math.max(...schedule.map((p) => p.cap_uw));
+  const barWidth = width / schedule.length;
+
+  schedule.forEach((planned, i) => {
+    const barHeight = max > 0 ? (planned.cap_uw / max) * (height - top) : 0;
+    const rect = document.createElementNS(ns, "rect");
+    rect.setAttribute("x", (i * barWidth).toFixed(2));
+    rect.setAttribute("y", (height - barHeight).toFixed(2));
+    rect.setAttribute("width", Math.max(barWidth - 1, 1).toFixed(2));
+    rect.setAttribute("height", barHeight.toFixed(2));
+    if (planned.period === current) {
+      rect.classList.add("current");
+    } else if (planned.clamp === "price_spike") {
+      rect.classList.add("price_spike");
+    }
+    const title = document.createElementNS(ns, "title");
+    title.textContent = planned.period + ": " + watts(planned.cap_uw) + " (volume " +
+      planned.volume_mwh.toFixed(1) + ", price " + planned.price_eur_mwh.toFixed(2) + ", clamp " + planned.clamp + ")";
+    rect.appendChild(title);
+    svg.appendChild(rect);
+  });
+
+  const label = document.createElementNS(ns, "text");
+  label.setAttribute("x", "4");
+  label.setAttribute("y", "12");
+  label.textContent = watts(max);
+  svg.appendChild(label);
+
+  for (let quarter = 0; quarter < 4; quarter++) {
+    const span = document.createElement("span");
+    span.textContent = schedule[Math.floor((quarter * schedule.length) / 4)].period.split("-")[0];
+    axis.appendChild(span);
+  }
+  const end = document.createElement("span");
+  end.textContent = schedule[schedule.length - 1].period.split("-")[1] || "";
+  axis.appendChild(end);
+}
+
+function renderDecisions(decisions) {
+  const rows = decisions.slice().reverse().map((d) => {
+    const tr = document.createElement("tr");
+    const cells = [
+      [time(d.timestamp), false],
+      [d.period, false],
+      [d.volume_mwh.toFixed(1), true],
+      [d.price_eur_mwh.toFixed(2), true],
+      [watts(d.source_power_uw), true],
+      [watts(d.applied_power_uw), true],
+      [d.clamp, false],
+    ];
+    for (const [text, number] of cells) {
+      const td = document.createElement("td");
+      td.textContent = text;
+      if (number) {
+        td.className = "number";
+      }
+      tr.appendChild(td);
+    }
+    return tr;
+  });
+  $("decisions").replaceChildren(...rows);
+}
+
+async function refresh() {
+  fetch("/healthz").then((r) => r.json()).then((health) => {
+    const pill = $("health");
+    pill.textContent = health.status;
+    pill.className = "pill " + health.status;
+    pill.title = health.reason || "";
+  }).catch(() => {});
+
+  try {
+    const state = await api("state");
+    renderState(state);
+    const [schedule, decisions] = await Promise.all([
+      api("schedule").catch((error) => (error.status === 503 ? [] : Promise.reject(error))),
+      api("decisions?limit=50"),
+    ]);
+    renderSchedule(schedule, state.last_decision ? state.last_decision.period : "");
+    renderDecisions(decisions);
+    setText("error", "");
+  } catch (error) {
+    if (error.message !== "unauthorized") {
+      setText("error", "Failed to refresh: " + error.message);
+    }
+  }
+}
+
+function showDashboard() {
+  $("login").hidden = true;
+  $("dashboard").hidden = false;
+  $("signout").hidden = false;
+  refresh();
+  clearInterval(timer);
+  timer = setInterval(refresh, refreshInterval);
+}
+
+function signOut(message) {
+  sessionStorage.removeItem(tokenKey);
+  clearInterval(timer);
+  $("dashboard").hidden = true;
+  $("signout").hidden = true;
+  $("login").hidden = false;
+  setText("login-error", message || "");
+}
+
+$("login-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  sessionStorage.setItem(tokenKey, $("token").value.trim());
+  $("token").value = "";
+  setText("login-error", "");
+  showDashboard();
+});
+
+$("signout").addEventListener("click", () => signOut());
+
+$("refresh").addEventListener("click", async () => {
+  $("refresh").disabled = true;
+  try {
+    await api("refresh", { method: "POST" });
+    await refresh();
+  } catch (error) {
+    setText("error", "Refresh failed: " + error.message);
+  } finally {
+    $("refresh").disabled = false;
+  }
+});
+
+$("override-disable").addEventListener("change", () => {
+  $("override-power").disabled = $("override-disable").checked;
+});
+
+$("override-form").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const disable = $("override-disable").checked;
+  const request = {
+    disable,
+    duration: $("override-duration").value,
+    reason: $("override-reason").value,
+  };
+  if (!disable) {
+    const power = parseFloat($("override-power").value);
+    if (!(power > 0)) {
+      setText("override-error", "Enter a cap in watts or disable capping");
+      return;
+    }
+    request.power_uw = Math.round(power * 1e6);
+  }
+  try {
+    await api("override", {
+      method: "PUT",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(request),
+    });
+    setText("override-error", "");
+    await refresh();
+  } catch (error) {
+    setText("override-error", error.message);
+  }
+});
+
+$("override-clear").addEventListener("click", async () => {
+  try {
+    await api("override", { method: "DELETE" });
+    setText("override-error", "");
+    await refresh();
+  } catch (error) {
+    setText("override-error", error.message);
+  }
+});
+
+if (token()) {
+  showDashboard();
+} else {
+  signOut();
+}
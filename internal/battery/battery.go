@@ -0,0 +1,108 @@
+// Package battery reads the state of charge of the battery storage of a
+// site, from the local API of its inverter or from an MQTT topic.
+package battery
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// readTimeout bounds a reading
+const readTimeout = 5 * time.Second
+
+// Source reads the state of charge of a battery
+type Source interface {
+	// Name describes the source for logs, without credentials
+	Name() string
+	// StateOfCharge returns the charge of the battery in %
+	StateOfCharge(ctx context.Context) (float64, error)
+}
+
+// NewSource creates the source of an address:
+//
+//	http(s)://host/path#field.path                     Inverter API returning JSON
+//	mqtt(s)://[user:pass@]broker[:port]/topic[#field]  Retained or periodic MQTT messages
+//
+// An MQTT payload is either a plain number or JSON with the field.
+func NewSource(addr string) (Source, error) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid battery address %q", addr)
+	}
+
+	var field []string
+	if u.Fragment != "" {
+		field = strings.Split(u.Fragment, ".")
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if field == nil {
+			return nil, fmt.Errorf("missing JSON field in battery address, e.g. http://host/status#soc")
+		}
+		return newHTTPSource(u, field), nil
+	case "mqtt", "mqtts":
+		topic := strings.TrimPrefix(u.Path, "/")
+		if topic == "" {
+			return nil, fmt.Errorf("missing topic in battery address, e.g. mqtt://broker/inverter/soc")
+		}
+		return newMQTTSource(u, topic, field), nil
+	default:
+		return nil, fmt.Errorf("unsupported battery scheme %q, expected http, https, mqtt or mqtts", u.Scheme)
+	}
+}
+
+// Monitor polls a battery source and passes every reading on
+type Monitor struct {
+	source    Source
+	interval  time.Duration
+	onReading func(soc float64)
+	logger    *log.Logger
+}
+
+// NewMonitor creates a monitor calling onReading with every state of charge read
+func NewMonitor(source Source, interval time.Duration, onReading func(soc float64), logger *log.Logger) *Monitor {
+	return &Monitor{source: source, interval: interval, onReading: onReading, logger: logger}
+}
+
+// Run polls the source until the context is cancelled. Failed reads are
+// logged once until the source answers again.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failing := false
+	for {
+		poll, cancel := context.WithTimeout(ctx, readTimeout)
+		soc, err := m.source.StateOfCharge(poll)
+		cancel()
+
+		switch {
+		case err != nil:
+			if !failing && ctx.Err() == nil {
+				m.logger.Printf("⚠️  Failed to read battery state of charge from %s: %v", m.source.Name(), err)
+				failing = true
+			}
+		case soc < 0 || soc > 100:
+			m.logger.Printf("⚠️  Ignoring battery state of charge %.1f%% from %s", soc, m.source.Name())
+		default:
+			if failing {
+				m.logger.Printf("✅ Battery state of charge readable again from %s", m.source.Name())
+				failing = false
+			}
+			m.onReading(soc)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if closer, ok := m.source.(interface{ Close() }); ok {
+				closer.Close()
+			}
+			return
+		}
+	}
+}
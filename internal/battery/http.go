@@ -0,0 +1,61 @@
+package battery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"kcas/new/internal/meter"
+)
+
+// httpSource reads the state of charge from a field of a JSON document, e.g.
+// the local API of a hybrid inverter
+type httpSource struct {
+	url    string
+	name   string // URL without credentials
+	field  []string
+	client *http.Client
+}
+
+func newHTTPSource(u *url.URL, field []string) *httpSource {
+	target := *u
+	target.Fragment = ""
+	return &httpSource{url: target.String(), name: target.Redacted(), field: field, client: &http.Client{}}
+}
+
+func (s *httpSource) Name() string {
+	return s.name + " (" + strings.Join(s.field, ".") + ")"
+}
+
+func (s *httpSource) StateOfCharge(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("battery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return 0, fmt.Errorf("%s returned %s: %s", s.name, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var document interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return 0, fmt.Errorf("invalid JSON from %s: %w", s.name, err)
+	}
+	soc, err := meter.Lookup(document, s.field)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", s.Name(), err)
+	}
+	return soc, nil
+}
@@ -0,0 +1,148 @@
+package battery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/meter"
+	"kcas/new/internal/mqtt"
+)
+
+// maxMessageAge is how long the last MQTT message stays valid
+const maxMessageAge = 15 * time.Minute
+
+// firstMessageWait is how long the first reading waits for the retained
+// message after subscribing
+const firstMessageWait = 2 * time.Second
+
+// mqttSource keeps the last state of charge published on a topic, connecting
+// again on the next reading when the connection is lost
+type mqttSource struct {
+	opts  mqtt.Options
+	name  string
+	topic string
+	field []string
+
+	first     chan struct{} // Closed on the first message
+	firstOnce sync.Once
+
+	mu         sync.Mutex
+	client     *mqtt.Client
+	soc        float64
+	receivedAt time.Time
+	err        error // Why the last message could not be used
+}
+
+func newMQTTSource(u *url.URL, topic string, field []string) *mqttSource {
+	scheme := "tcp"
+	if u.Scheme == "mqtts" {
+		scheme = "ssl"
+	}
+	opts := mqtt.Options{
+		Broker:    scheme + "://" + u.Host,
+		ClientID:  fmt.Sprintf("powercap-battery-%d", time.Now().UnixNano()%1000000),
+		KeepAlive: time.Minute,
+	}
+	if u.User != nil {
+		opts.Username = u.User.Username()
+		opts.Password, _ = u.User.Password()
+	}
+	name := "mqtt://" + u.Host + "/" + topic
+	if field != nil {
+		name += " (" + strings.Join(field, ".") + ")"
+	}
+	return &mqttSource{opts: opts, name: name, topic: topic, field: field, first: make(chan struct{})}
+}
+
+func (s *mqttSource) Name() string {
+	return s.name
+}
+
+func (s *mqttSource) StateOfCharge(ctx context.Context) (float64, error) {
+	if err := s.connect(ctx); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.err != nil:
+		return 0, s.err
+	case s.receivedAt.IsZero():
+		return 0, fmt.Errorf("no message received yet on %s", s.topic)
+	case time.Since(s.receivedAt) > maxMessageAge:
+		return 0, fmt.Errorf("no message on %s since %s", s.topic, s.receivedAt.Format(time.RFC3339))
+	}
+	return s.soc, nil
+}
+
+// connect subscribes to the topic unless connected, waiting briefly for the
+// first message the first time
+func (s *mqttSource) connect(ctx context.Context) error {
+	s.mu.Lock()
+	if s.client != nil && s.client.Err() == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	client, err := mqtt.Dial(s.opts)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := client.Subscribe(s.topic, s.receive); err != nil {
+		client.Close()
+		s.mu.Unlock()
+		return fmt.Errorf("failed to subscribe to %s: %w", s.topic, err)
+	}
+	s.client = client
+	s.mu.Unlock()
+
+	timer := time.NewTimer(firstMessageWait)
+	defer timer.Stop()
+	select {
+	case <-s.first:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// receive parses a message, as a plain number or a JSON document
+func (s *mqttSource) receive(topic string, payload []byte) {
+	var soc float64
+	var err error
+	if s.field == nil {
+		soc, err = strconv.ParseFloat(strings.TrimSpace(string(payload)), 64)
+	} else {
+		var document interface{}
+		if err = json.Unmarshal(payload, &document); err == nil {
+			soc, err = meter.Lookup(document, s.field)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.err = fmt.Errorf("invalid message on %s: %w", topic, err)
+		return
+	}
+	s.soc, s.receivedAt, s.err = soc, time.Now(), nil
+	s.firstOnce.Do(func() { close(s.first) })
+}
+
+// Close disconnects from the broker
+func (s *mqttSource) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
@@ -0,0 +1,170 @@
+// Package budget divides the node power target among several capping
+// actuators (CPU packages, DRAM, GPUs) in priority order, squeezing the first
+// ones down to their floor before taking power from the next, instead of
+// every actuator giving up the same fraction of its range.
+package budget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Actuator names (BUDGET_SPLIT)
+const (
+	CPU  = "cpu"  // RAPL package zones, capped by the market-based limit
+	DRAM = "dram" // RAPL dram subzones
+	GPU  = "gpu"  // GPU power caps exposed through hwmon
+)
+
+// Known lists the actuator names
+var Known = []string{CPU, DRAM, GPU}
+
+// Actuator is a capping backend receiving a share of the node budget. Its
+// range and limit cover all of its zones, the limit being split evenly
+// among them.
+type Actuator struct {
+	Name     string
+	Zones    int   // Number of zones capped
+	MinPower int64 // Floor of all the zones together (µW)
+	MaxPower int64 // Maximum of all the zones together (µW)
+
+	files []string // Limit file of every zone, nil for the CPU actuator written through RAPL
+}
+
+// NewCPU creates the CPU actuator of the given RAPL packages, each capped
+// between the minimum power and the hardware maximum
+func NewCPU(packages int, minPower, maxPower int64) Actuator {
+	return Actuator{
+		Name:     CPU,
+		Zones:    packages,
+		MinPower: int64(packages) * minPower,
+		MaxPower: int64(packages) * maxPower,
+	}
+}
+
+// ZoneLimit returns the limit of each zone for a limit of the actuator
+func (a Actuator) ZoneLimit(limit int64) int64 {
+	if a.Zones <= 0 {
+		return limit
+	}
+	return limit / int64(a.Zones)
+}
+
+// Apply writes the limit of each zone to the limit files
+func (a Actuator) Apply(limit int64) []error {
+	value := strconv.FormatInt(a.ZoneLimit(limit), 10)
+	var errs []error
+	for _, path := range a.files {
+		if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// Split divides a node budget among actuators listed in squeeze order.
+// fraction is the share of the node's capping range the market allows, 1
+// at the hardware maximum and 0 at the minimum: the same total power is
+// shed as if every actuator gave up (1 - fraction) of its range, but it is
+// taken from the first actuators first. It returns the limit of each
+// actuator, in order.
+func Split(fraction float64, actuators []Actuator) []int64 {
+	fraction = max(0, min(1, fraction))
+	var span int64
+	for _, a := range actuators {
+		span += a.MaxPower - a.MinPower
+	}
+	shed := int64((1 - fraction) * float64(span))
+
+	limits := make([]int64, len(actuators))
+	for i, a := range actuators {
+		take := min(shed, a.MaxPower-a.MinPower)
+		limits[i] = a.MaxPower - take
+		shed -= take
+	}
+	return limits
+}
+
+// DiscoverDRAM finds the dram subzones of the RAPL packages. Each keeps at
+// least floor of its maximum.
+func DiscoverDRAM(root, raplBase string, floor float64) (Actuator, error) {
+	zones, err := filepath.Glob(filepath.Join(root, raplBase, "intel-rapl:*", "intel-rapl:*:*"))
+	if err != nil {
+		return Actuator{}, err
+	}
+	actuator := Actuator{Name: DRAM}
+	for _, zone := range zones {
+		if name, err := readString(filepath.Join(zone, "name")); err != nil || name != "dram" {
+			continue
+		}
+		maxPower, err := readInt(filepath.Join(zone, "constraint_0_max_power_uw"))
+		if err != nil || maxPower <= 0 {
+			continue
+		}
+		actuator.add(filepath.Join(zone, "constraint_0_power_limit_uw"), int64(floor*float64(maxPower)), maxPower)
+	}
+	if actuator.Zones == 0 {
+		return Actuator{}, fmt.Errorf("no RAPL dram subzone found under %s", filepath.Join(root, raplBase))
+	}
+	return actuator, nil
+}
+
+// DiscoverGPU finds the GPUs exposing a power cap through hwmon (amdgpu,
+// i915/xe). Each keeps at least floor of its maximum, or the lowest cap the
+// driver accepts when higher.
+func DiscoverGPU(root string, floor float64) (Actuator, error) {
+	caps, err := filepath.Glob(filepath.Join(root, "/sys/class/drm/card*/device/hwmon/hwmon*/power1_cap"))
+	if err != nil {
+		return Actuator{}, err
+	}
+	actuator := Actuator{Name: GPU}
+	for _, path := range caps {
+		maxPower, err := readInt(path + "_max")
+		if err != nil || maxPower <= 0 {
+			continue
+		}
+		minPower := int64(floor * float64(maxPower))
+		if driverMin, err := readInt(path + "_min"); err == nil && driverMin > minPower {
+			minPower = driverMin
+		}
+		actuator.add(path, minPower, maxPower)
+	}
+	if actuator.Zones == 0 {
+		return Actuator{}, fmt.Errorf("no GPU power cap found under %s", filepath.Join(root, "/sys/class/drm"))
+	}
+	return actuator, nil
+}
+
+// add adds a zone to the actuator. All zones receiving the same limit, the
+// range of the actuator is that of its most constrained zone times the
+// number of zones.
+func (a *Actuator) add(path string, minPower, maxPower int64) {
+	if a.Zones > 0 {
+		minPower = max(minPower, a.MinPower/int64(a.Zones))
+		maxPower = min(maxPower, a.MaxPower/int64(a.Zones))
+	}
+	a.Zones++
+	a.MinPower, a.MaxPower = min(minPower, maxPower)*int64(a.Zones), maxPower*int64(a.Zones)
+	a.files = append(a.files, path)
+}
+
+// readString reads a sysfs file
+func readString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readInt reads an integer sysfs file
+func readInt(path string) (int64, error) {
+	value, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
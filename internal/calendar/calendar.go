@@ -0,0 +1,110 @@
+// Package calendar tells which days the electricity market is closed or
+// thin: weekends and the public holidays of a country, plus configured dates.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Calendar knows the closed days of one market
+type Calendar struct {
+	country  string
+	holidays []holiday
+	weekend  map[time.Weekday]bool
+	extra    map[string]bool // Additional closed dates, YYYY-MM-DD
+}
+
+// New creates the calendar of a country (ISO 3166 code, empty for no public
+// holidays) closing on the given weekdays and extra dates (YYYY-MM-DD)
+func New(country string, weekend []time.Weekday, extra []string) (*Calendar, error) {
+	c := &Calendar{
+		country: strings.ToUpper(country),
+		weekend: make(map[time.Weekday]bool),
+		extra:   make(map[string]bool),
+	}
+	if c.country != "" {
+		holidays, ok := countries[c.country]
+		if !ok {
+			return nil, fmt.Errorf("no holiday calendar for country %q, supported: %s",
+				country, strings.Join(Countries(), ", "))
+		}
+		c.holidays = holidays
+	}
+	for _, day := range weekend {
+		c.weekend[day] = true
+	}
+	for _, date := range extra {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+		c.extra[date] = true
+	}
+	return c, nil
+}
+
+// Closed reports whether the market is closed on the day of t, in the
+// location of t, with the reason: the holiday name, "weekend" or "closed day"
+func (c *Calendar) Closed(t time.Time) (bool, string) {
+	if name, ok := c.Holiday(t); ok {
+		return true, name
+	}
+	if c.extra[t.Format("2006-01-02")] {
+		return true, "closed day"
+	}
+	if c.weekend[t.Weekday()] {
+		return true, "weekend"
+	}
+	return false, ""
+}
+
+// Holiday returns the public holiday on the day of t, if any
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	year, month, day := t.Date()
+	for _, h := range c.holidays {
+		if m, d := h.date(year); m == month && d == day {
+			return h.name, true
+		}
+	}
+	return "", false
+}
+
+// Country returns the country of the holidays, empty if none
+func (c *Calendar) Country() string {
+	return c.country
+}
+
+// Countries returns the countries with a holiday calendar
+func Countries() []string {
+	codes := make([]string, 0, len(countries))
+	for code := range countries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ParseWeekdays parses a list such as "sat,sun" or "saturday, sunday"
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			full := strings.ToLower(day.String())
+			if name == full || name == full[:3] {
+				days, found = append(days, day), true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday %q", name)
+		}
+	}
+	return days, nil
+}
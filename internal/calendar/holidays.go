@@ -0,0 +1,112 @@
+package calendar
+
+import "time"
+
+// holiday is a public holiday: on a fixed date, a number of days after
+// Easter Sunday, or the nth weekday of a month (-1 for the last)
+type holiday struct {
+	name    string
+	month   time.Month
+	day     int
+	easter  *int
+	weekday time.Weekday
+	nth     int
+}
+
+// date returns the month and day of the holiday in a year
+func (h holiday) date(year int) (time.Month, int) {
+	switch {
+	case h.easter != nil:
+		t := easter(year).AddDate(0, 0, *h.easter)
+		return t.Month(), t.Day()
+	case h.nth != 0:
+		return h.month, nthWeekday(year, h.month, h.weekday, h.nth)
+	default:
+		return h.month, h.day
+	}
+}
+
+// fixed is a holiday on the same date every year
+func fixed(name string, month time.Month, day int) holiday {
+	return holiday{name: name, month: month, day: day}
+}
+
+// afterEaster is a holiday a number of days after Easter Sunday
+func afterEaster(name string, days int) holiday {
+	return holiday{name: name, easter: &days}
+}
+
+// nthOf is a holiday on the nth weekday of a month, -1 for the last
+func nthOf(name string, nth int, weekday time.Weekday, month time.Month) holiday {
+	return holiday{name: name, month: month, weekday: weekday, nth: nth}
+}
+
+// Movable feasts shared by most calendars
+var (
+	goodFriday     = afterEaster("Good Friday", -2)
+	easterMonday   = afterEaster("Easter Monday", 1)
+	ascension      = afterEaster("Ascension Day", 39)
+	whitMonday     = afterEaster("Whit Monday", 50)
+	corpusChristi  = afterEaster("Corpus Christi", 60)
+	newYear        = fixed("New Year's Day", time.January, 1)
+	labourDay      = fixed("Labour Day", time.May, 1)
+	assumption     = fixed("Assumption Day", time.August, 15)
+	allSaints      = fixed("All Saints' Day", time.November, 1)
+	christmas      = fixed("Christmas Day", time.December, 25)
+	stStephensDay  = fixed("St Stephen's Day", time.December, 26)
+	epiphany       = fixed("Epiphany", time.January, 6)
+	immaculateConc = fixed("Immaculate Conception", time.December, 8)
+)
+
+// countries holds the national public holidays of each supported country.
+// Regional holidays and weekend substitutes are not included; add them with
+// CALENDAR_HOLIDAYS.
+var countries = map[string][]holiday{
+	"AT": {newYear, epiphany, easterMonday, labourDay, ascension, whitMonday, corpusChristi, assumption,
+		fixed("National Day", time.October, 26), allSaints, immaculateConc, christmas, stStephensDay},
+	"BE": {newYear, easterMonday, labourDay, ascension, whitMonday, fixed("National Day", time.July, 21),
+		assumption, allSaints, fixed("Armistice Day", time.November, 11), christmas},
+	"CH": {newYear, goodFriday, easterMonday, ascension, whitMonday, fixed("National Day", time.August, 1),
+		christmas, stStephensDay},
+	"DE": {newYear, goodFriday, easterMonday, labourDay, ascension, whitMonday,
+		fixed("German Unity Day", time.October, 3), christmas, stStephensDay},
+	"ES": {newYear, epiphany, goodFriday, labourDay, assumption, fixed("National Day", time.October, 12),
+		allSaints, fixed("Constitution Day", time.December, 6), immaculateConc, christmas},
+	"FR": {newYear, easterMonday, labourDay, fixed("Victory in Europe Day", time.May, 8), ascension, whitMonday,
+		fixed("Bastille Day", time.July, 14), assumption, allSaints, fixed("Armistice Day", time.November, 11), christmas},
+	"GB": {newYear, goodFriday, easterMonday, nthOf("Early May Bank Holiday", 1, time.Monday, time.May),
+		nthOf("Spring Bank Holiday", -1, time.Monday, time.May), nthOf("Summer Bank Holiday", -1, time.Monday, time.August),
+		christmas, fixed("Boxing Day", time.December, 26)},
+	"IT": {newYear, epiphany, easterMonday, fixed("Liberation Day", time.April, 25), labourDay,
+		fixed("Republic Day", time.June, 2), assumption, allSaints, immaculateConc, christmas, stStephensDay},
+	"NL": {newYear, goodFriday, easterMonday, fixed("King's Day", time.April, 27), ascension, whitMonday,
+		christmas, stStephensDay},
+	"PL": {newYear, epiphany, easterMonday, labourDay, fixed("Constitution Day", time.May, 3), corpusChristi,
+		assumption, allSaints, fixed("Independence Day", time.November, 11), christmas, stStephensDay},
+}
+
+// easter returns Easter Sunday of a year (anonymous Gregorian algorithm)
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday returns the day of the nth weekday of a month, -1 for the last
+func nthWeekday(year int, month time.Month, weekday time.Weekday, nth int) int {
+	if nth < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		return last.Day() - (int(last.Weekday())-int(weekday)+7)%7
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return 1 + (int(weekday)-int(first.Weekday())+7)%7 + 7*(nth-1)
+}
@@ -0,0 +1,107 @@
+// Package cgroup throttles the CPU bandwidth of selected cgroup v2 slices
+// through their cpu.max files, a software complement to RAPL: low-priority
+// workloads give up their CPU time as the cap drops, leaving the capped
+// package's power to the others rather than slowing every workload alike.
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// BasePath is the mount point of the cgroup v2 hierarchy
+	BasePath = "/sys/fs/cgroup"
+
+	// Period is the CPU bandwidth period written to cpu.max (µs)
+	Period = 100000
+
+	// Unlimited is the cpu.max quota of a slice that is not throttled
+	Unlimited = "max"
+)
+
+// Throttler sets the CPU bandwidth of cgroup slices from the power cap
+type Throttler struct {
+	root    string   // Directory holding the host's sys tree, empty for /
+	slices  []string // Slices throttled, relative to BasePath
+	cpus    float64  // CPUs of the node, the bandwidth of an unthrottled slice
+	minCPUs float64  // Bandwidth left to each slice at the minimum cap
+}
+
+// NewThrottler creates a throttler of the given slices, relative to the
+// cgroup v2 mount point, e.g. kubepods.slice/kubepods-besteffort.slice
+func NewThrottler(root string, slices []string, minCPUs float64) *Throttler {
+	return &Throttler{
+		root:    root,
+		slices:  slices,
+		cpus:    float64(runtime.NumCPU()),
+		minCPUs: minCPUs,
+	}
+}
+
+// Slices returns the throttled slices
+func (t *Throttler) Slices() []string {
+	return t.slices
+}
+
+// CPUs returns the bandwidth, in CPUs, left to each slice under a cap:
+// every CPU at or above the hardware maximum, minCPUs at or below the
+// minimum power, and proportionally to the cap in between
+func (t *Throttler) CPUs(capPower, minPower, maxPower int64) float64 {
+	if capPower >= maxPower || maxPower <= minPower {
+		return t.cpus
+	}
+	if capPower <= minPower {
+		return t.minCPUs
+	}
+	share := float64(capPower-minPower) / float64(maxPower-minPower)
+	return t.minCPUs + share*(t.cpus-t.minCPUs)
+}
+
+// Apply writes the cpu.max of every slice for a cap and returns the
+// bandwidth set, in CPUs, with an error per slice that could not be written
+func (t *Throttler) Apply(capPower, minPower, maxPower int64) (float64, []error) {
+	cpus := t.CPUs(capPower, minPower, maxPower)
+	value := fmt.Sprintf("%s %d", Unlimited, Period)
+	if cpus < t.cpus {
+		value = fmt.Sprintf("%d %d", int64(cpus*Period), Period)
+	}
+	return cpus, t.write(value)
+}
+
+// Restore lifts the bandwidth limit of every slice
+func (t *Throttler) Restore() []error {
+	return t.write(fmt.Sprintf("%s %d", Unlimited, Period))
+}
+
+// Quotas reads the cpu.max of every slice, by slice
+func (t *Throttler) Quotas() map[string]string {
+	quotas := make(map[string]string, len(t.slices))
+	for _, slice := range t.slices {
+		data, err := os.ReadFile(t.path(slice))
+		if err != nil {
+			continue
+		}
+		quotas[slice] = strings.TrimSpace(string(data))
+	}
+	return quotas
+}
+
+// write writes a cpu.max value to every slice
+func (t *Throttler) write(value string) []error {
+	var errs []error
+	for _, slice := range t.slices {
+		if err := os.WriteFile(t.path(slice), []byte(value), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("writing cpu.max of %s: %w", slice, err))
+		}
+	}
+	return errs
+}
+
+// path returns the cpu.max file of a slice
+func (t *Throttler) path(slice string) string {
+	return filepath.Join(t.root, BasePath, slice, "cpu.max")
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PowerCeiling is an administrative limit on the applied power, given in µW
+// or as a percentage of the hardware maximum
+type PowerCeiling struct {
+	Absolute int64   // Limit in µW, 0 if unset
+	Percent  float64 // Limit as a percentage of the hardware maximum, 0 if unset
+}
+
+// ParsePowerCeiling parses "30000000" (µW) or "80%"; an empty value means no ceiling
+func ParsePowerCeiling(value string) (PowerCeiling, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return PowerCeiling{}, nil
+	}
+
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return PowerCeiling{}, fmt.Errorf("invalid percentage %q, expected a value in (0, 100]", value)
+		}
+		return PowerCeiling{Percent: percent}, nil
+	}
+
+	absolute, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || absolute <= 0 {
+		return PowerCeiling{}, fmt.Errorf("invalid power %q, expected a positive number of µW or a percentage", value)
+	}
+	return PowerCeiling{Absolute: absolute}, nil
+}
+
+// IsSet reports whether a ceiling is configured
+func (c PowerCeiling) IsSet() bool {
+	return c.Absolute > 0 || c.Percent > 0
+}
+
+// Resolve returns the maximum power allowed for the given hardware maximum
+func (c PowerCeiling) Resolve(hardwareMax int64) int64 {
+	limit := hardwareMax
+	switch {
+	case c.Absolute > 0:
+		limit = c.Absolute
+	case c.Percent > 0:
+		limit = int64(float64(hardwareMax) * c.Percent / 100)
+	}
+	if limit > hardwareMax {
+		limit = hardwareMax
+	}
+	return limit
+}
+
+// String returns the ceiling in its configuration form
+func (c PowerCeiling) String() string {
+	switch {
+	case c.Absolute > 0:
+		return strconv.FormatInt(c.Absolute, 10)
+	case c.Percent > 0:
+		return strconv.FormatFloat(c.Percent, 'f', -1, 64) + "%"
+	}
+	return ""
+}
+
+// ParsePower parses a power as a number of µW ("250000000") or with a unit
+// ("250kW", "800 W", "1.5MW"); an empty value is 0
+func ParsePower(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		scale  float64
+	}{{"MW", 1e12}, {"kW", 1e9}, {"W", 1e6}}
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			number, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), 64)
+			if err != nil || number <= 0 {
+				return 0, fmt.Errorf("invalid power %q, expected e.g. 250kW", value)
+			}
+			return int64(number * unit.scale), nil
+		}
+	}
+
+	power, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || power <= 0 {
+		return 0, fmt.Errorf("invalid power %q, expected a positive number of µW or a value with a unit (W, kW, MW)", value)
+	}
+	return power, nil
+}
@@ -3,91 +3,851 @@ package config
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"kcas/new/internal/calendar"
+	"kcas/new/internal/features"
+	"kcas/new/internal/hotplug"
 )
 
 // Environment variable names
 const (
 	EnvNodeName          = "NODE_NAME"
 	EnvStabilisationTime = "STABILISATION_TIME"
+	EnvAdjustIntervalMin = "ADJUST_INTERVAL_MIN" // Interval when adjacent periods differ strongly (0 keeps STABILISATION_TIME)
+	EnvAdjustIntervalMax = "ADJUST_INTERVAL_MAX" // Interval on flat days (0 keeps STABILISATION_TIME)
 	EnvRaplLimit         = "RAPL_MIN_POWER"
-	EnvTimezone          = "TIMEZONE"
+	EnvRaplMaxPower      = "RAPL_MAX_POWER"    // Administrative ceiling in µW or % of the hardware maximum
+	EnvCapDeadband       = "CAP_DEADBAND"      // Smallest cap change written to RAPL, in µW or % of the hardware maximum
+	EnvPmaxSource        = "PMAX_SOURCE"       // Where the hardware maximum is read: annotation or live
+	EnvPmaxRefresh       = "PMAX_REFRESH"      // Interval between live reads of the hardware maximum (0 = every cycle)
+	EnvPmaxRecheck       = "PMAX_RECHECK"      // Interval between checks of the annotated hardware maximum against RAPL (0 disables)
+	EnvPowerBackend      = "POWER_BACKEND"     // Power capping interface: auto, intel-rapl, dtpm or cpufreq
+	EnvCPUFreqMaxPower   = "CPUFREQ_MAX_POWER" // Power of the CPUs at their highest frequency in µW (cpufreq backend)
+	EnvSysfsRoot         = "SYSFS_ROOT"        // Directory holding the host's sys tree, e.g. /host (empty for /)
+	EnvCgroupSlices      = "CGROUP_SLICES"     // cgroup v2 slices whose cpu.max follows the cap, e.g. kubepods.slice/kubepods-besteffort.slice (empty disables)
+	EnvCgroupMinCPUs     = "CGROUP_MIN_CPUS"   // CPU bandwidth left to each slice at the minimum cap
+	EnvIdleRelaxBelow    = "IDLE_RELAX_BELOW"  // Cap, in µW or % of the hardware maximum, at or below which deep C-states are allowed (empty disables)
+	EnvIdleLatency       = "IDLE_LATENCY_US"   // CPU resume latency kept above IDLE_RELAX_BELOW (µs, 0 keeps the original settings)
+	EnvBudgetSplit       = "BUDGET_SPLIT"      // Actuators sharing the node budget, squeezed first to last, e.g. gpu,dram,cpu (empty caps the CPU only)
+	EnvBudgetFloor       = "BUDGET_FLOOR"      // Share of their maximum the DRAM and GPU actuators keep
+	EnvTimezone          = "TIMEZONE"          // Market timezone used for period math
+	EnvDisplayTimezone   = "DISPLAY_TIMEZONE"  // Timezone of log timestamps (empty for the system timezone)
 	EnvPowerCalcMode     = "POWER_CALC_MODE"
+	EnvShadowCalcModes   = "SHADOW_CALC_MODES" // Calculation modes evaluated every cycle without being applied, e.g. average,percent
+	EnvPeriodFallback    = "PERIOD_FALLBACK"   // Neighbouring periods searched on each side when the current one has no data (0 disables)
+	EnvSmoothingWindow   = "SMOOTHING_WINDOW"  // Periods of the centered moving average applied to the volumes (odd, 0 disables)
+	EnvForecastModel     = "FORECAST_MODEL"    // Model of the days not published yet: average, ewma or weekday
+	EnvForecastDays      = "FORECAST_DAYS"     // Stored days (weeks for weekday) the forecast model is built from
+	EnvForecastAlpha     = "FORECAST_ALPHA"    // Weight of the most recent day in the ewma forecast model
+	EnvCycleTimeout      = "CYCLE_TIMEOUT"     // Deadline of an adjustment cycle: node API calls, meter reads and RAPL writes
+	EnvRefreshTimeout    = "REFRESH_TIMEOUT"   // Deadline of a market data refresh, across all its provider requests
+
+	// Shutdown behaviour
+	EnvRestoreOnExit   = "RESTORE_LIMITS_ON_EXIT" // Restore the hardware maximum when the manager stops
+	EnvShutdownTimeout = "SHUTDOWN_TIMEOUT"       // Time allowed for the shutdown steps
+	EnvRestoreLastCap  = "RESTORE_LAST_CAP"       // Save the applied cap and decisions, and restore them at startup
+	EnvLastStateFile   = "LAST_STATE_FILE"        // File of the saved cap and decisions (default <DATA_DIR>/powercap-last.json)
+
+	// Orchestrator holding the node state
+	EnvOrchestrator    = "ORCHESTRATOR"      // kubernetes, nomad or standalone
+	EnvStateFile       = "STATE_FILE"        // State file of a standalone node (default <DATA_DIR>/powercap-state.json)
+	EnvNomadAddr       = "NOMAD_ADDR"        // Address of the Nomad HTTP API
+	EnvNomadToken      = "NOMAD_TOKEN"       // Nomad ACL token (needs node:write)
+	EnvNomadCACert     = "NOMAD_CACERT"      // CA certificate of a TLS-enabled Nomad API
+	EnvNomadClientCert = "NOMAD_CLIENT_CERT" // Client certificate for mutual TLS
+	EnvNomadClientKey  = "NOMAD_CLIENT_KEY"  // Key of the client certificate
+
+	// Kubernetes annotations
+	EnvAnnotationPrefix = "ANNOTATION_PREFIX" // Prefix of the node annotations written by the manager
+	EnvInitAnnotation   = "INIT_ANNOTATION"   // Annotation marking a node as initialized
 
 	// Provider configuration
-	EnvDataProvider    = "DATA_PROVIDER"     // epex, mock, static
-	EnvProviderURL     = "PROVIDER_URL"      // Base URL for data provider
-	EnvProviderParams  = "PROVIDER_PARAMS"   // Additional parameters (JSON format)
-	EnvDataRefreshCron = "DATA_REFRESH_CRON" // Cron expression for data refresh
+	EnvDataProvider    = "DATA_PROVIDER"           // epex, mock, static, energy-charts, watttime, eco2mix, carbonintensity, solar, jsonapi, csvurl, file, mqtt, exec, replay, composite
+	EnvProviderURL     = "PROVIDER_URL"            // Base URL for data provider
+	EnvProviderParams  = "PROVIDER_PARAMS"         // Additional parameters (JSON format)
+	EnvDataRefreshCron = "DATA_REFRESH_CRON"       // Cron expression for data refresh
+	EnvDataRetryCron   = "DATA_REFRESH_RETRY_CRON" // Cron expression retrying failed refreshes (empty disables)
+	EnvDataDir         = "DATA_DIR"                // Directory of the daily market data CSV files
+
+	// Failure handling
+	EnvBackoffMax       = "FAILURE_BACKOFF_MAX" // Longest wait between failing adjustment cycles (0 disables the backoff)
+	EnvFailoverProvider = "FAILOVER_PROVIDER"   // Data providers, in order, used while DATA_PROVIDER keeps failing (empty disables)
+	EnvFailoverAfter    = "FAILOVER_AFTER"      // Consecutive failed fetches before switching to FAILOVER_PROVIDER
+	EnvProviderMinScore = "PROVIDER_MIN_SCORE"  // Health score below which the active provider is demoted (0 switches on failures only)
+	EnvFetchMinPeriods  = "FETCH_MIN_PERIODS"   // Periods with data below which a fetch counts as failed (0 disables)
+	EnvFallbackDays     = "FALLBACK_DAYS"       // Previous days searched for stored data when today's cannot be fetched (0 disables)
+	EnvFallbackDecay    = "FALLBACK_DECAY"      // Share of the cap above the minimum given up per day of age of fallback data
+	EnvBackfillDays     = "BACKFILL_DAYS"       // Previous days fetched on refresh when not stored, e.g. after downtime (0 disables)
+
+	// Canary rollout of raised caps
+	EnvCanaryWindow  = "CANARY_WINDOW"  // Time a raised cap runs on the canary domains before the others (0 disables)
+	EnvCanaryDomains = "CANARY_DOMAINS" // Domains receiving a raised cap first, e.g. intel-rapl:0 (default: the first domain)
+
+	// Provider request configuration
+	EnvProviderTimeout   = "PROVIDER_TIMEOUT"    // Timeout of provider requests
+	EnvProviderUserAgent = "PROVIDER_USER_AGENT" // User-Agent of provider requests (empty for the provider default)
+	EnvProviderHeaders   = "PROVIDER_HEADERS"    // Extra headers of provider requests (JSON format)
+	EnvProviderParallel  = "PROVIDER_PARALLEL"   // Requests run at once when a provider fetches several sources
+	EnvProviderCacheTTL  = "PROVIDER_CACHE_TTL"  // Time a fetched day is reused instead of fetching it again (0 disables)
+	EnvProviderCacheDir  = "PROVIDER_CACHE_DIR"  // Directory of the provider cache, kept across restarts (default: DATA_DIR/provider-cache)
+
+	// Currency configuration
+	EnvCurrency         = "CURRENCY"           // Currency of stored prices, reports and budgets (ISO 4217 code)
+	EnvProviderCurrency = "PROVIDER_CURRENCY"  // Currency of the provider prices (empty for CURRENCY)
+	EnvCurrencyRate     = "CURRENCY_RATE"      // Units of CURRENCY per unit of PROVIDER_CURRENCY (empty for the ECB rates)
+	EnvCurrencyRatesURL = "CURRENCY_RATES_URL" // ECB reference rates used without a static rate
+
+	// Market calendar configuration
+	EnvCalendarCountry   = "CALENDAR_COUNTRY"    // Country of the public holidays closing the market (empty disables)
+	EnvCalendarWeekend   = "CALENDAR_WEEKEND"    // Weekdays the market is closed, e.g. sat,sun
+	EnvCalendarHolidays  = "CALENDAR_HOLIDAYS"   // Additional closed dates, YYYY-MM-DD
+	EnvClosedDayProvider = "CLOSED_DAY_PROVIDER" // Data provider used on closed days (empty keeps DATA_PROVIDER)
+	EnvClosedDayCap      = "CLOSED_DAY_CAP"      // Cap on closed days in µW or % of the hardware maximum (empty keeps the market-based cap)
+
+	// Price spike configuration
+	EnvPriceSpikeThreshold = "PRICE_SPIKE_THRESHOLD" // Price per MWh above which the floor is enforced (0 disables)
+	EnvPriceSpikeFactor    = "PRICE_SPIKE_FACTOR"    // Multiple of the daily median price above which the floor is enforced (0 disables)
+
+	// Savings estimate configuration
+	EnvSavingsBaseline = "SAVINGS_BASELINE" // Power the node would draw uncapped: hardware_max or measured
+	EnvCarbonIntensity = "CARBON_INTENSITY" // Grid carbon intensity in gCO2eq/kWh (0 disables the CO2 estimate)
+
+	// Logging configuration
+	EnvLogQuiet       = "LOG_QUIET"        // Only log decisions, warnings and errors
+	EnvLogDedupWindow = "LOG_DEDUP_WINDOW" // Window during which identical messages are suppressed
+	EnvLogFile        = "LOG_FILE"         // Optional log file path
+	EnvLogMaxSizeMB   = "LOG_MAX_SIZE_MB"  // Log file size triggering rotation
+	EnvLogMaxBackups  = "LOG_MAX_BACKUPS"  // Number of rotated log files to keep
+
+	// Error reporting configuration
+	EnvErrorReporting    = "ERROR_REPORTING"               // sentry, webhook, none
+	EnvSentryDSN         = "SENTRY_DSN"                    // Sentry DSN
+	EnvErrorWebhookURL   = "ERROR_WEBHOOK_URL"             // Generic JSON webhook for error events
+	EnvErrorEnvironment  = "ERROR_REPORT_ENVIRONMENT"      // Environment name attached to events
+	EnvErrorRepeatReport = "ERROR_REPORT_REPEAT_THRESHOLD" // Re-report an ongoing error every N occurrences
+
+	// StatsD/DogStatsD configuration
+	EnvStatsDAddr          = "STATSD_ADDR"           // host:port of the DogStatsD agent (empty disables)
+	EnvStatsDPrefix        = "STATSD_PREFIX"         // Metric name prefix
+	EnvStatsDTags          = "STATSD_TAGS"           // Extra constant tags, comma-separated key:value
+	EnvStatsDFlushInterval = "STATSD_FLUSH_INTERVAL" // Interval between flushes
+
+	// SNMP agent configuration
+	EnvSNMPAddr      = "SNMP_ADDR"      // UDP listen address of the SNMP agent (empty disables)
+	EnvSNMPCommunity = "SNMP_COMMUNITY" // Read-only community string
+	EnvSNMPBaseOID   = "SNMP_BASE_OID"  // Root OID of the POWERCAP-MIB objects
+
+	// HTTP API configuration
+	EnvHTTPAddr       = "HTTP_ADDR"        // Listen address of the local HTTP API (empty disables)
+	EnvAdminAPITokens = "ADMIN_API_TOKENS" // Bearer tokens of the admin API as name=token pairs (empty disables)
+	EnvGRPCAddr       = "GRPC_ADDR"        // Listen address of the gRPC control API (empty disables)
+	EnvGRPCTLSCert    = "GRPC_TLS_CERT"    // Certificate served by the gRPC control API
+	EnvGRPCTLSKey     = "GRPC_TLS_KEY"     // Key of the gRPC certificate
+
+	// Modbus TCP server configuration
+	EnvModbusAddr          = "MODBUS_ADDR"           // Listen address of the Modbus TCP server (empty disables)
+	EnvModbusUnitID        = "MODBUS_UNIT_ID"        // Unit identifier answered, 0 for any
+	EnvModbusAllowOverride = "MODBUS_ALLOW_OVERRIDE" // Accept override writes from Modbus clients
+
+	// MQTT publisher configuration
+	EnvMQTTBroker          = "MQTT_BROKER"           // Broker URL, e.g. tcp://host:1883 (empty disables)
+	EnvMQTTTopicPrefix     = "MQTT_TOPIC_PREFIX"     // Topic prefix, followed by the node name
+	EnvMQTTClientID        = "MQTT_CLIENT_ID"        // Client identifier (default powercap-<node>)
+	EnvMQTTUsername        = "MQTT_USERNAME"         // Broker user name
+	EnvMQTTPassword        = "MQTT_PASSWORD"         // Broker password
+	EnvMQTTKeepAlive       = "MQTT_KEEPALIVE"        // Keep-alive interval of the connection
+	EnvMQTTDiscoveryPrefix = "MQTT_DISCOVERY_PREFIX" // Home Assistant discovery prefix (empty disables discovery)
+
+	// Pushgateway configuration
+	EnvPushgatewayURL = "PUSHGATEWAY_URL" // Pushgateway base URL (empty disables)
+	EnvPushgatewayJob = "PUSHGATEWAY_JOB" // Job label of the pushed metrics
+
+	// NATS publisher configuration
+	EnvNATSURL           = "NATS_URL"            // Server URL, e.g. nats://host:4222 (empty disables)
+	EnvNATSSubjectPrefix = "NATS_SUBJECT_PREFIX" // Subject prefix, followed by the node name
+	EnvNATSToken         = "NATS_TOKEN"          // Authentication token
+	EnvNATSJetStream     = "NATS_JETSTREAM"      // Wait for JetStream acknowledgements
+
+	// UPS monitoring configuration
+	EnvUPSAddr         = "UPS_ADDR"          // nut://[user:pass@]host[:port]/ups or apcupsd://host[:port] (empty disables)
+	EnvUPSPollInterval = "UPS_POLL_INTERVAL" // Interval between UPS status reads
+
+	// External power meter configuration
+	EnvMeterAddr = "METER_ADDR" // shelly://, tplink://, snmp://, modbus://, p1:// or http(s):// meter of the node wall power (empty disables)
+
+	// Peak shaving configuration
+	EnvSiteMeterAddr     = "SITE_METER_ADDR"     // Meter of the whole site, same schemes as METER_ADDR (empty disables)
+	EnvSiteMeterInterval = "SITE_METER_INTERVAL" // Interval between site meter reads
+	EnvPeakThreshold     = "PEAK_THRESHOLD"      // Site demand not to exceed (demand-charge threshold), in µW or with a unit
+	EnvPeakShavingStart  = "PEAK_SHAVING_START"  // Fraction of the threshold at which caps start being reduced
+
+	// Battery storage configuration
+	EnvBatterySoCAddr      = "BATTERY_SOC_ADDR"      // http(s)://host/path#field or mqtt(s)://broker/topic[#field] (empty disables)
+	EnvBatteryPollInterval = "BATTERY_POLL_INTERVAL" // Interval between state of charge reads
+	EnvBatteryLowSoC       = "BATTERY_LOW_SOC"       // State of charge (%) at or below which the cap drops to the minimum
+	EnvBatteryHighSoC      = "BATTERY_HIGH_SOC"      // State of charge (%) at or above which the node may run at full power
+	EnvPVMeterAddr         = "PV_METER_ADDR"         // Meter of the solar production, same schemes as METER_ADDR (empty for none)
+	EnvPVMinPower          = "PV_MIN_POWER"          // Solar production above which PV counts as producing
+
+	// Demand-response webhook configuration
+	EnvDRWebhookSecret = "DR_WEBHOOK_SECRET" // HMAC key of the demand-response webhook (empty disables)
+	EnvDRMaxDuration   = "DR_MAX_DURATION"   // Longest shed request accepted
+	EnvDROfflineCPUs   = "DR_OFFLINE_CPUS"   // CPUs taken offline when the minimum cap cannot meet a shed request, e.g. 4-7 (empty disables)
+
+	// Feature gates
+	EnvFeatureGates = "FEATURE_GATES" // Comma-separated Name=bool pairs enabling gated behaviours
+
+	// Policy experiments
+	EnvExperimentLabel = "EXPERIMENT_LABEL" // Node label whose value names the profile of the node's experiment arm (empty disables)
 )
 
 // Default values
 const (
-	DefaultStabilisationTime = "300"
+	DefaultStabilisationTime = "5m"
+	DefaultAdjustInterval    = "0s" // Fixed STABILISATION_TIME
 	DefaultRaplLimit         = "10000000"
 	DefaultTimezone          = "Europe/Paris"
 	DefaultPowerCalcMode     = "max"
+	DefaultPeriodFallback    = "4" // One hour on each side
+	DefaultSmoothingWindow   = "0" // Raw volumes
+	DefaultForecastModel     = "average"
+	DefaultForecastDays      = "7"
+	DefaultForecastAlpha     = "0.5"
+	DefaultPmaxSource        = "annotation"
+	DefaultPmaxRefresh       = "0s"
+	DefaultPmaxRecheck       = "24h"
+	DefaultPowerBackend      = "auto"
+	DefaultCgroupMinCPUs     = "0.5"
+	DefaultIdleLatency       = "0"
+	DefaultBudgetFloor       = "0.3"
+	DefaultCycleTimeout      = "1m"
+	DefaultRefreshTimeout    = "5m"
+
+	// Shutdown defaults
+	DefaultRestoreOnExit   = "false"
+	DefaultShutdownTimeout = "10s"
+	DefaultRestoreLastCap  = "true"
+
+	// Orchestrator defaults
+	DefaultOrchestrator = "kubernetes"
+	DefaultNomadAddr    = "http://127.0.0.1:4646"
+
+	// Annotation defaults
+	DefaultAnnotationPrefix = "rapl/"
+	DefaultInitAnnotation   = "power-manager/initialized"
+
+	// Nomad metadata key defaults, usable in job constraints as ${meta.rapl.pmax}
+	DefaultNomadMetaPrefix = "rapl."
+	DefaultNomadInitKey    = "power-manager.initialized"
 
 	// Provider defaults
-	DefaultDataProvider    = "epex"
-	DefaultProviderURL     = "https://www.epexspot.com/en/market-results"
-	DefaultProviderParams  = `{"market_area":"FR","auction":"IDA1","modality":"Auction","sub_modality":"Intraday"}`
-	DefaultDataRefreshCron = "0 0 * * *" // Every day at midnight
+	DefaultDataProvider     = "epex"
+	DefaultProviderURL      = "https://www.epexspot.com/en/market-results"
+	DefaultProviderParams   = `{"market_area":"FR","auction":"IDA1","modality":"Auction","sub_modality":"Intraday"}`
+	DefaultDataRefreshCron  = ""          // Provider default, midnight if it has none
+	DefaultDataRetryCron    = "0 * * * *" // Every hour
+	DefaultDataDir          = "."
+	DefaultProviderTimeout  = "30s"
+	DefaultProviderParallel = "4"
+	DefaultProviderCacheTTL = "0s" // Disabled
+
+	// Failure handling defaults
+	DefaultBackoffMax       = "30m"
+	DefaultFailoverAfter    = "3"
+	DefaultProviderMinScore = "0.5"
+	DefaultFetchMinPeriods  = "0"
+	DefaultFallbackDays     = "7"
+	DefaultFallbackDecay    = "0.1"
+	DefaultBackfillDays     = "0"
+
+	// Canary rollout defaults
+	DefaultCanaryWindow = "0s" // Disabled
+
+	// Default currency values
+	DefaultCurrency         = "EUR"
+	DefaultCurrencyRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	// Default market calendar values
+	DefaultCalendarWeekend = "sat,sun"
+
+	// Default price spike values
+	DefaultPriceSpikeThreshold = "0" // Disabled
+	DefaultPriceSpikeFactor    = "0" // Disabled
+
+	// Default savings estimate values
+	DefaultSavingsBaseline = "hardware_max"
+	DefaultCarbonIntensity = "0" // No CO2 estimate
+
+	// Logging defaults
+	DefaultLogQuiet       = "false"
+	DefaultLogDedupWindow = "0s" // Disabled
+	DefaultLogMaxSizeMB   = "50"
+	DefaultLogMaxBackups  = "3"
+
+	// Error reporting defaults
+	DefaultErrorReporting    = "none"
+	DefaultErrorEnvironment  = "production"
+	DefaultErrorRepeatReport = "10"
+
+	// StatsD defaults
+	DefaultStatsDPrefix        = "powercap."
+	DefaultStatsDFlushInterval = "10s"
+
+	// SNMP defaults (base OID uses the example enterprise arc; set a registered PEN in production)
+	DefaultSNMPCommunity = "public"
+	DefaultSNMPBaseOID   = "1.3.6.1.4.1.99999.1"
+
+	// HTTP API defaults
+	DefaultHTTPAddr = "127.0.0.1:9090"
+
+	// Modbus defaults
+	DefaultModbusUnitID        = "1"
+	DefaultModbusAllowOverride = "false"
+
+	// MQTT defaults
+	DefaultMQTTTopicPrefix = "powercap"
+	DefaultMQTTKeepAlive   = "60s"
+
+	// Pushgateway defaults
+	DefaultPushgatewayJob = "powercap"
+
+	// NATS defaults
+	DefaultNATSSubjectPrefix = "powercap"
+	DefaultNATSJetStream     = "false"
+
+	// Demand-response defaults
+	DefaultDRMaxDuration = "4h"
+
+	// UPS defaults
+	DefaultUPSPollInterval = "5s"
+
+	// Battery storage defaults
+	DefaultBatteryPollInterval = "30s"
+	DefaultBatteryLowSoC       = "20"
+	DefaultBatteryHighSoC      = "95"
+	DefaultPVMinPower          = "100W"
+
+	// Peak shaving defaults
+	DefaultSiteMeterInterval = "10s"
+	DefaultPeakShavingStart  = "0.9"
 )
 
 // Config holds the application configuration
 type Config struct {
 	StabilisationTime time.Duration
+	AdjustIntervalMin time.Duration // Interval when adjacent periods differ strongly (0 keeps StabilisationTime)
+	AdjustIntervalMax time.Duration // Interval on flat days (0 keeps StabilisationTime)
 	RaplLimit         int64
+	RaplMaxPower      PowerCeiling  // Administrative ceiling below the hardware maximum
+	CapDeadband       PowerCeiling  // Smallest cap change written to RAPL, in µW or % of the hardware maximum (unset writes every cycle)
+	PmaxSource        string        // Where the hardware maximum is read: "annotation" or "live"
+	PmaxRefresh       time.Duration // Interval between live reads of the hardware maximum
+	PmaxRecheck       time.Duration // Interval between checks of the annotated hardware maximum against RAPL
+	PowerBackend      string        // Power capping interface: auto, intel-rapl, dtpm or cpufreq
+	CPUFreqMaxPower   int64         // Power of the CPUs at their highest frequency in µW (cpufreq backend)
+	SysfsRoot         string        // Directory holding the host's sys tree (empty for /)
+	CgroupSlices      []string      // cgroup v2 slices whose cpu.max follows the cap (empty disables)
+	CgroupMinCPUs     float64       // CPU bandwidth left to each slice at the minimum cap
+	IdleRelaxBelow    PowerCeiling  // Cap at or below which deep C-states are allowed (unset disables)
+	IdleLatency       int64         // CPU resume latency kept above IdleRelaxBelow (µs, 0 keeps the original settings)
+	BudgetSplit       []string      // Actuators sharing the node budget, squeezed first to last (empty caps the CPU only)
+	BudgetFloor       float64       // Share of their maximum the DRAM and GPU actuators keep
 	NodeName          string
-	Timezone          string // Timezone for time calculations
-	PowerCalcMode     string // Power calculation mode: "max" or "average"
+	Timezone          string        // Market timezone used for period math
+	DisplayTimezone   string        // Timezone of log timestamps (empty for the system timezone)
+	PowerCalcMode     string        // Power calculation mode: "max", "average" or "percent"
+	ShadowCalcModes   []string      // Calculation modes evaluated every cycle without being applied
+	PeriodFallback    int           // Neighbouring periods searched on each side when the current one has no data
+	SmoothingWindow   int           // Periods of the centered moving average applied to the volumes (0 disables)
+	ForecastModel     string        // Model of the days not published yet: "average", "ewma" or "weekday"
+	ForecastDays      int           // Stored days (weeks for weekday) the forecast model is built from
+	ForecastAlpha     float64       // Weight of the most recent day in the ewma forecast model
+	CycleTimeout      time.Duration // Deadline of an adjustment cycle
+	RefreshTimeout    time.Duration // Deadline of a market data refresh
+
+	// Shutdown behaviour
+	RestoreOnExit   bool          // Restore the hardware maximum when the manager stops
+	ShutdownTimeout time.Duration // Time allowed for the shutdown steps
+	RestoreLastCap  bool          // Save the applied cap and decisions, and restore them at startup
+	LastStateFile   string        // File of the saved cap and decisions (empty for the default)
+
+	// Orchestrator holding the node state
+	Orchestrator    string // "kubernetes", "nomad" or "standalone"
+	StateFile       string // State file of a standalone node (empty for the default)
+	NomadAddr       string // Address of the Nomad HTTP API
+	NomadToken      string // Nomad ACL token
+	NomadCACert     string // CA certificate of a TLS-enabled Nomad API
+	NomadClientCert string // Client certificate for mutual TLS
+	NomadClientKey  string // Key of the client certificate
+
+	// Kubernetes annotation keys, or Nomad node metadata keys
+	AnnotationPrefix string // Prefix of the node annotations, e.g. "rapl/" or "power.example.com/"
+	InitAnnotation   string // Annotation marking a node as initialized
 
 	// Provider configuration
 	DataProvider    string            // Type of data provider
 	ProviderURL     string            // Base URL for provider
 	ProviderParams  map[string]string // Additional provider parameters
-	DataRefreshCron string            // Cron expression for data refresh
+	DataRefreshCron string            // Cron expression for data refresh (empty for the provider default)
+	DataRetryCron   string            // Cron expression retrying failed refreshes (empty disables)
+	DataDir         string            // Directory of the daily market data CSV files
+
+	// Failure handling
+	BackoffMax        time.Duration // Longest wait between failing adjustment cycles (0 disables the backoff)
+	FailoverProviders []string      // Data providers, in order, used while DataProvider keeps failing (empty disables)
+	FailoverAfter     int           // Consecutive failed fetches before switching to FailoverProviders
+	ProviderMinScore  float64       // Health score below which the active provider is demoted (0 switches on failures only)
+	FetchMinPeriods   int           // Periods with data below which a fetch counts as failed (0 disables)
+	FallbackDays      int           // Previous days searched for stored data when today's cannot be fetched (0 disables)
+	FallbackDecay     float64       // Share of the cap above the minimum given up per day of age of fallback data
+	BackfillDays      int           // Previous days fetched on refresh when not stored (0 disables)
+
+	// Canary rollout of raised caps
+	CanaryWindow  time.Duration // Time a raised cap runs on the canary domains before the others (0 disables)
+	CanaryDomains []string      // Domains receiving a raised cap first (empty for the first domain)
+
+	// Provider request configuration
+	ProviderTimeout   time.Duration     // Provider request timeout
+	ProviderUserAgent string            // User-Agent of provider requests (empty for the provider default)
+	ProviderHeaders   map[string]string // Extra headers of provider requests
+	ProviderParallel  int               // Requests run at once when a provider fetches several sources
+	ProviderCacheTTL  time.Duration     // Time a fetched day is reused instead of fetching it again (0 disables)
+	ProviderCacheDir  string            // Directory of the provider cache, kept across restarts
+
+	// Currency configuration
+	Currency         string  // Currency of stored prices, reports and budgets
+	ProviderCurrency string  // Currency of the provider prices
+	CurrencyRate     float64 // Units of Currency per unit of ProviderCurrency (0 for the ECB rates)
+	CurrencyRatesURL string  // ECB reference rates URL
+
+	// Market calendar configuration
+	CalendarCountry   string         // Country of the public holidays closing the market
+	CalendarWeekend   []time.Weekday // Weekdays the market is closed
+	CalendarHolidays  []string       // Additional closed dates, YYYY-MM-DD
+	ClosedDayProvider string         // Data provider used on closed days (empty keeps DataProvider)
+	ClosedDayCap      PowerCeiling   // Cap on closed days (unset keeps the market-based cap)
+
+	// Price spike configuration
+	PriceSpikeThreshold float64 // Price per MWh above which the floor is enforced (0 disables)
+	PriceSpikeFactor    float64 // Multiple of the daily median price above which the floor is enforced (0 disables)
+
+	// Savings estimate configuration
+	SavingsBaseline string  // Power the node would draw uncapped: "hardware_max" or "measured"
+	CarbonIntensity float64 // Grid carbon intensity in gCO2eq/kWh (0 disables the CO2 estimate)
+
+	// Logging configuration
+	LogQuiet       bool          // Only log decisions, warnings and errors
+	LogDedupWindow time.Duration // Window for suppressing repeated messages
+	LogFile        string        // Optional log file path
+	LogMaxSizeMB   int           // Log file size triggering rotation
+	LogMaxBackups  int           // Number of rotated log files to keep
+
+	// Error reporting configuration
+	ErrorReporting       string // Error reporting backend
+	SentryDSN            string // Sentry DSN
+	ErrorWebhookURL      string // Generic JSON webhook URL
+	ErrorEnvironment     string // Environment name attached to events
+	ErrorRepeatThreshold int    // Re-report an ongoing error every N occurrences
+
+	// StatsD configuration
+	StatsDAddr          string        // DogStatsD agent address (empty disables)
+	StatsDPrefix        string        // Metric name prefix
+	StatsDTags          []string      // Extra constant tags
+	StatsDFlushInterval time.Duration // Interval between flushes
+
+	// SNMP agent configuration
+	SNMPAddr      string // UDP listen address (empty disables)
+	SNMPCommunity string // Read-only community string
+	SNMPBaseOID   string // Root OID of the POWERCAP-MIB objects
+
+	// HTTP API configuration
+	HTTPAddr       string            // Listen address of the local HTTP API (empty disables)
+	AdminAPITokens map[string]string // Admin API bearer tokens mapped to client names
+	GRPCAddr       string            // Listen address of the gRPC control API (empty disables)
+	GRPCTLSCert    string            // Certificate served by the gRPC control API
+	GRPCTLSKey     string            // Key of the gRPC certificate
+
+	// Modbus TCP server configuration
+	ModbusAddr          string // Listen address (empty disables)
+	ModbusUnitID        int    // Unit identifier answered, 0 for any
+	ModbusAllowOverride bool   // Accept override writes from Modbus clients
+
+	// MQTT publisher configuration
+	MQTTBroker          string        // Broker URL (empty disables)
+	MQTTTopicPrefix     string        // Topic prefix, followed by the node name
+	MQTTClientID        string        // Client identifier
+	MQTTUsername        string        // Broker user name
+	MQTTPassword        string        // Broker password
+	MQTTKeepAlive       time.Duration // Keep-alive interval of the connection
+	MQTTDiscoveryPrefix string        // Home Assistant discovery prefix (empty disables discovery)
+
+	// Pushgateway configuration
+	PushgatewayURL string // Pushgateway base URL (empty disables)
+	PushgatewayJob string // Job label of the pushed metrics
+
+	// NATS publisher configuration
+	NATSURL           string // Server URL (empty disables)
+	NATSSubjectPrefix string // Subject prefix, followed by the node name
+	NATSToken         string // Authentication token
+	NATSJetStream     bool   // Wait for JetStream acknowledgements
+
+	// UPS monitoring configuration
+	UPSAddr         string        // UPS daemon address (empty disables)
+	UPSPollInterval time.Duration // Interval between UPS status reads
+
+	// External power meter configuration
+	MeterAddr string // Meter of the node wall power (empty disables)
+
+	// Peak shaving configuration
+	SiteMeterAddr     string        // Meter of the whole site (empty disables)
+	SiteMeterInterval time.Duration // Interval between site meter reads
+	PeakThreshold     int64         // Site demand not to exceed (µW)
+	PeakShavingStart  float64       // Fraction of the threshold at which caps start being reduced
+
+	// Battery storage configuration
+	BatterySoCAddr      string        // State of charge source (empty disables)
+	BatteryPollInterval time.Duration // Interval between state of charge reads
+	BatteryLowSoC       float64       // State of charge (%) at or below which the cap drops to the minimum
+	BatteryHighSoC      float64       // State of charge (%) at or above which the node may run at full power
+	PVMeterAddr         string        // Meter of the solar production (empty for none)
+	PVMinPower          int64         // Solar production above which PV counts as producing (µW)
+
+	// Demand-response webhook configuration
+	DRWebhookSecret string        // HMAC key of the demand-response webhook (empty disables)
+	DRMaxDuration   time.Duration // Longest shed request accepted
+	DROfflineCPUs   []int         // CPUs taken offline when the minimum cap cannot meet a shed request
+
+	// Feature gates
+	Features features.Gates // State of the gated behaviours
+
+	// Policy experiments
+	ExperimentLabel string // Node label whose value names the profile of the node's experiment arm (empty disables)
+
+	ConfigFile string // Configuration file the settings were read from (empty if none)
+	Profile    string // Selected profile (empty if none)
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables and the optional config file
 func Load() (*Config, error) {
+	return LoadWithFlags(nil)
+}
+
+// LoadWithFlags loads configuration, giving precedence to command-line flag
+// values keyed by environment variable name. When settings cannot be parsed
+// it returns a *ValidationError listing all of them, along with a Config
+// holding every valid setting.
+func LoadWithFlags(flags map[string]string) (*Config, error) {
+	src, err := newSource(flags)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src}
+
 	// NODE_NAME is required for Kubernetes, but we can provide a default for local testing
-	nodeName := os.Getenv(EnvNodeName)
+	nodeName := src.get(EnvNodeName, "")
 	if nodeName == "" {
 		// For local/Docker testing, use a default node name
 		nodeName = "local-node"
 	}
 
-	stabilisationTime, err := time.ParseDuration(getEnvOrDefault(EnvStabilisationTime, DefaultStabilisationTime) + "s")
+	stabilisationTime := p.duration(EnvStabilisationTime, DefaultStabilisationTime)
+	adjustIntervalMin := p.duration(EnvAdjustIntervalMin, DefaultAdjustInterval)
+	adjustIntervalMax := p.duration(EnvAdjustIntervalMax, DefaultAdjustInterval)
+	raplLimit := p.int64(EnvRaplLimit, DefaultRaplLimit)
+	cpufreqMaxPower := p.int64(EnvCPUFreqMaxPower, "0")
+	cgroupMinCPUs := p.float64(EnvCgroupMinCPUs, DefaultCgroupMinCPUs)
+	idleLatency := p.int64(EnvIdleLatency, DefaultIdleLatency)
+	budgetFloor := p.float64(EnvBudgetFloor, DefaultBudgetFloor)
+	raplMaxPower, err := ParsePowerCeiling(src.get(EnvRaplMaxPower, ""))
 	if err != nil {
-		return nil, fmt.Errorf("invalid stabilisation time: %w", err)
+		p.addProblem(EnvRaplMaxPower, "%v", err)
 	}
-
-	raplLimit, err := strconv.ParseInt(getEnvOrDefault(EnvRaplLimit, DefaultRaplLimit), 10, 64)
+	idleRelaxBelow, err := ParsePowerCeiling(src.get(EnvIdleRelaxBelow, ""))
+	if err != nil {
+		p.addProblem(EnvIdleRelaxBelow, "%v", err)
+	}
+	capDeadband, err := ParsePowerCeiling(src.get(EnvCapDeadband, ""))
 	if err != nil {
-		return nil, fmt.Errorf("invalid RAPL limit: %w", err)
+		p.addProblem(EnvCapDeadband, "%v", err)
 	}
 
+	pmaxRefresh := p.duration(EnvPmaxRefresh, DefaultPmaxRefresh)
+	pmaxRecheck := p.duration(EnvPmaxRecheck, DefaultPmaxRecheck)
+	periodFallback := p.int(EnvPeriodFallback, DefaultPeriodFallback)
+	smoothingWindow := p.int(EnvSmoothingWindow, DefaultSmoothingWindow)
+	forecastDays := p.int(EnvForecastDays, DefaultForecastDays)
+	forecastAlpha := p.float64(EnvForecastAlpha, DefaultForecastAlpha)
+	cycleTimeout := p.duration(EnvCycleTimeout, DefaultCycleTimeout)
+	refreshTimeout := p.duration(EnvRefreshTimeout, DefaultRefreshTimeout)
+	restoreOnExit := p.bool(EnvRestoreOnExit, DefaultRestoreOnExit)
+	shutdownTimeout := p.duration(EnvShutdownTimeout, DefaultShutdownTimeout)
+	restoreLastCap := p.bool(EnvRestoreLastCap, DefaultRestoreLastCap)
+
 	// Load provider configuration
-	providerParams, err := parseProviderParams(getEnvOrDefault(EnvProviderParams, DefaultProviderParams))
+	providerParams, err := parseProviderParams(src.get(EnvProviderParams, DefaultProviderParams))
+	if err != nil {
+		p.addProblem(EnvProviderParams, "%v", err)
+		providerParams = make(map[string]string)
+	}
+	for key, value := range src.providerSecrets {
+		providerParams[key] = value
+	}
+	if err := resolveParamFiles(providerParams); err != nil {
+		p.addProblem(EnvProviderParams, "%v", err)
+	}
+
+	providerTimeout := p.duration(EnvProviderTimeout, DefaultProviderTimeout)
+	providerParallel := p.int(EnvProviderParallel, DefaultProviderParallel)
+	providerCacheTTL := p.duration(EnvProviderCacheTTL, DefaultProviderCacheTTL)
+	backoffMax := p.duration(EnvBackoffMax, DefaultBackoffMax)
+	failoverAfter := p.int(EnvFailoverAfter, DefaultFailoverAfter)
+	providerMinScore := p.float64(EnvProviderMinScore, DefaultProviderMinScore)
+	fetchMinPeriods := p.int(EnvFetchMinPeriods, DefaultFetchMinPeriods)
+	fallbackDays := p.int(EnvFallbackDays, DefaultFallbackDays)
+	fallbackDecay := p.float64(EnvFallbackDecay, DefaultFallbackDecay)
+	backfillDays := p.int(EnvBackfillDays, DefaultBackfillDays)
+	canaryWindow := p.duration(EnvCanaryWindow, DefaultCanaryWindow)
+	var providerHeaders map[string]string
+	if headers := src.get(EnvProviderHeaders, ""); headers != "" {
+		if err := json.Unmarshal([]byte(headers), &providerHeaders); err != nil {
+			p.addProblem(EnvProviderHeaders, "invalid JSON object of header names to values: %v", err)
+		}
+	}
+
+	currency := strings.ToUpper(src.get(EnvCurrency, DefaultCurrency))
+	providerCurrency := strings.ToUpper(src.get(EnvProviderCurrency, currency))
+	var currencyRate float64
+	if src.get(EnvCurrencyRate, "") != "" {
+		currencyRate = p.float64(EnvCurrencyRate, "")
+	}
+
+	calendarWeekend, err := calendar.ParseWeekdays(src.get(EnvCalendarWeekend, DefaultCalendarWeekend))
+	if err != nil {
+		p.addProblem(EnvCalendarWeekend, "%v", err)
+	}
+	closedDayCap, err := ParsePowerCeiling(src.get(EnvClosedDayCap, ""))
 	if err != nil {
-		return nil, fmt.Errorf("invalid provider params: %w", err)
+		p.addProblem(EnvClosedDayCap, "%v", err)
 	}
 
-	return &Config{
+	priceSpikeThreshold := p.float64(EnvPriceSpikeThreshold, DefaultPriceSpikeThreshold)
+	priceSpikeFactor := p.float64(EnvPriceSpikeFactor, DefaultPriceSpikeFactor)
+	carbonIntensity := p.float64(EnvCarbonIntensity, DefaultCarbonIntensity)
+
+	// Load logging configuration
+	logQuiet := p.bool(EnvLogQuiet, DefaultLogQuiet)
+	logDedupWindow := p.duration(EnvLogDedupWindow, DefaultLogDedupWindow)
+	logMaxSizeMB := p.int(EnvLogMaxSizeMB, DefaultLogMaxSizeMB)
+	logMaxBackups := p.int(EnvLogMaxBackups, DefaultLogMaxBackups)
+
+	errorRepeatThreshold := p.int(EnvErrorRepeatReport, DefaultErrorRepeatReport)
+	statsdFlushInterval := p.duration(EnvStatsDFlushInterval, DefaultStatsDFlushInterval)
+	drMaxDuration := p.duration(EnvDRMaxDuration, DefaultDRMaxDuration)
+	drOfflineCPUs, err := hotplug.ParseCPUList(src.get(EnvDROfflineCPUs, ""))
+	if err != nil {
+		p.addProblem(EnvDROfflineCPUs, "%v", err)
+	}
+	upsPollInterval := p.duration(EnvUPSPollInterval, DefaultUPSPollInterval)
+	siteMeterInterval := p.duration(EnvSiteMeterInterval, DefaultSiteMeterInterval)
+	batteryPollInterval := p.duration(EnvBatteryPollInterval, DefaultBatteryPollInterval)
+	batteryLowSoC := p.float64(EnvBatteryLowSoC, DefaultBatteryLowSoC)
+	batteryHighSoC := p.float64(EnvBatteryHighSoC, DefaultBatteryHighSoC)
+	pvMinPower, err := ParsePower(src.get(EnvPVMinPower, DefaultPVMinPower))
+	if err != nil {
+		p.addProblem(EnvPVMinPower, "%v", err)
+	}
+	peakShavingStart := p.float64(EnvPeakShavingStart, DefaultPeakShavingStart)
+	peakThreshold, err := ParsePower(src.get(EnvPeakThreshold, ""))
+	if err != nil {
+		p.addProblem(EnvPeakThreshold, "%v", err)
+	}
+	mqttKeepAlive := p.duration(EnvMQTTKeepAlive, DefaultMQTTKeepAlive)
+	natsJetStream := p.bool(EnvNATSJetStream, DefaultNATSJetStream)
+	modbusUnitID := p.int(EnvModbusUnitID, DefaultModbusUnitID)
+	modbusAllowOverride := p.bool(EnvModbusAllowOverride, DefaultModbusAllowOverride)
+
+	adminTokens, err := parseTokens(src.get(EnvAdminAPITokens, ""))
+	if err != nil {
+		p.addProblem(EnvAdminAPITokens, "%v", err)
+	}
+
+	// Nomad metadata keys are dotted so that job constraints can use them
+	orchestrator := src.get(EnvOrchestrator, DefaultOrchestrator)
+	annotationPrefix, initAnnotation := DefaultAnnotationPrefix, DefaultInitAnnotation
+	if orchestrator == "nomad" {
+		annotationPrefix, initAnnotation = DefaultNomadMetaPrefix, DefaultNomadInitKey
+	}
+
+	featureGates, err := features.Parse(src.get(EnvFeatureGates, ""))
+	if err != nil {
+		p.addProblem(EnvFeatureGates, "%v", err)
+	}
+
+	cfg := &Config{
 		StabilisationTime: stabilisationTime,
+		AdjustIntervalMin: adjustIntervalMin,
+		AdjustIntervalMax: adjustIntervalMax,
 		RaplLimit:         raplLimit,
+		RaplMaxPower:      raplMaxPower,
+		CapDeadband:       capDeadband,
+		PmaxSource:        src.get(EnvPmaxSource, DefaultPmaxSource),
+		PmaxRefresh:       pmaxRefresh,
+		PmaxRecheck:       pmaxRecheck,
+		PowerBackend:      src.get(EnvPowerBackend, DefaultPowerBackend),
+		CPUFreqMaxPower:   cpufreqMaxPower,
+		SysfsRoot:         src.get(EnvSysfsRoot, ""),
+		CgroupSlices:      splitList(src.get(EnvCgroupSlices, "")),
+		CgroupMinCPUs:     cgroupMinCPUs,
+		IdleRelaxBelow:    idleRelaxBelow,
+		IdleLatency:       idleLatency,
+		BudgetSplit:       splitList(src.get(EnvBudgetSplit, "")),
+		BudgetFloor:       budgetFloor,
 		NodeName:          nodeName,
-		Timezone:          getEnvOrDefault(EnvTimezone, DefaultTimezone),
-		PowerCalcMode:     getEnvOrDefault(EnvPowerCalcMode, DefaultPowerCalcMode),
-		DataProvider:      getEnvOrDefault(EnvDataProvider, DefaultDataProvider),
-		ProviderURL:       getEnvOrDefault(EnvProviderURL, DefaultProviderURL),
+		Timezone:          src.get(EnvTimezone, DefaultTimezone),
+		DisplayTimezone:   src.get(EnvDisplayTimezone, ""),
+		PowerCalcMode:     src.get(EnvPowerCalcMode, DefaultPowerCalcMode),
+		ShadowCalcModes:   splitList(src.get(EnvShadowCalcModes, "")),
+		PeriodFallback:    periodFallback,
+		SmoothingWindow:   smoothingWindow,
+		ForecastModel:     strings.ToLower(src.get(EnvForecastModel, DefaultForecastModel)),
+		ForecastDays:      forecastDays,
+		ForecastAlpha:     forecastAlpha,
+		CycleTimeout:      cycleTimeout,
+		RefreshTimeout:    refreshTimeout,
+		RestoreOnExit:     restoreOnExit,
+		ShutdownTimeout:   shutdownTimeout,
+		RestoreLastCap:    restoreLastCap,
+		LastStateFile:     src.get(EnvLastStateFile, ""),
+		Orchestrator:      orchestrator,
+		StateFile:         src.get(EnvStateFile, ""),
+		NomadAddr:         src.get(EnvNomadAddr, DefaultNomadAddr),
+		NomadToken:        src.get(EnvNomadToken, ""),
+		NomadCACert:       src.get(EnvNomadCACert, ""),
+		NomadClientCert:   src.get(EnvNomadClientCert, ""),
+		NomadClientKey:    src.get(EnvNomadClientKey, ""),
+		AnnotationPrefix:  src.get(EnvAnnotationPrefix, annotationPrefix),
+		InitAnnotation:    src.get(EnvInitAnnotation, initAnnotation),
+		DataProvider:      src.get(EnvDataProvider, DefaultDataProvider),
+		ProviderURL:       src.get(EnvProviderURL, DefaultProviderURL),
 		ProviderParams:    providerParams,
-		DataRefreshCron:   getEnvOrDefault(EnvDataRefreshCron, DefaultDataRefreshCron),
-	}, nil
+		DataRefreshCron:   src.get(EnvDataRefreshCron, DefaultDataRefreshCron),
+		DataRetryCron:     src.get(EnvDataRetryCron, DefaultDataRetryCron),
+		DataDir:           src.get(EnvDataDir, DefaultDataDir),
+		ProviderTimeout:   providerTimeout,
+		ProviderUserAgent: src.get(EnvProviderUserAgent, ""),
+		ProviderHeaders:   providerHeaders,
+		ProviderParallel:  providerParallel,
+		ProviderCacheTTL:  providerCacheTTL,
+		ProviderCacheDir:  src.get(EnvProviderCacheDir, ""),
+		BackoffMax:        backoffMax,
+		FailoverProviders: splitList(strings.ToLower(src.get(EnvFailoverProvider, ""))),
+		FailoverAfter:     failoverAfter,
+		ProviderMinScore:  providerMinScore,
+		FetchMinPeriods:   fetchMinPeriods,
+		FallbackDays:      fallbackDays,
+		FallbackDecay:     fallbackDecay,
+		BackfillDays:      backfillDays,
+		CanaryWindow:      canaryWindow,
+		CanaryDomains:     splitList(src.get(EnvCanaryDomains, "")),
+		Currency:          currency,
+		ProviderCurrency:  providerCurrency,
+		CurrencyRate:      currencyRate,
+		CurrencyRatesURL:  src.get(EnvCurrencyRatesURL, DefaultCurrencyRatesURL),
+
+		CalendarCountry:   strings.ToUpper(src.get(EnvCalendarCountry, "")),
+		CalendarWeekend:   calendarWeekend,
+		CalendarHolidays:  splitList(src.get(EnvCalendarHolidays, "")),
+		ClosedDayProvider: strings.ToLower(src.get(EnvClosedDayProvider, "")),
+		ClosedDayCap:      closedDayCap,
+
+		PriceSpikeThreshold: priceSpikeThreshold,
+		PriceSpikeFactor:    priceSpikeFactor,
+		LogQuiet:            logQuiet,
+		LogDedupWindow:      logDedupWindow,
+		LogFile:             src.get(EnvLogFile, ""),
+		LogMaxSizeMB:        logMaxSizeMB,
+		LogMaxBackups:       logMaxBackups,
+
+		SavingsBaseline: strings.ToLower(src.get(EnvSavingsBaseline, DefaultSavingsBaseline)),
+		CarbonIntensity: carbonIntensity,
+
+		ErrorReporting:       src.get(EnvErrorReporting, DefaultErrorReporting),
+		SentryDSN:            src.get(EnvSentryDSN, ""),
+		ErrorWebhookURL:      src.get(EnvErrorWebhookURL, ""),
+		ErrorEnvironment:     src.get(EnvErrorEnvironment, DefaultErrorEnvironment),
+		ErrorRepeatThreshold: errorRepeatThreshold,
+
+		StatsDAddr:          src.get(EnvStatsDAddr, ""),
+		StatsDPrefix:        src.get(EnvStatsDPrefix, DefaultStatsDPrefix),
+		StatsDTags:          splitList(src.get(EnvStatsDTags, "")),
+		StatsDFlushInterval: statsdFlushInterval,
+
+		SNMPAddr:      src.get(EnvSNMPAddr, ""),
+		SNMPCommunity: src.get(EnvSNMPCommunity, DefaultSNMPCommunity),
+		SNMPBaseOID:   src.get(EnvSNMPBaseOID, DefaultSNMPBaseOID),
+
+		HTTPAddr:       src.get(EnvHTTPAddr, DefaultHTTPAddr),
+		AdminAPITokens: adminTokens,
+		GRPCAddr:       src.get(EnvGRPCAddr, ""),
+		GRPCTLSCert:    src.get(EnvGRPCTLSCert, ""),
+		GRPCTLSKey:     src.get(EnvGRPCTLSKey, ""),
+
+		ModbusAddr:          src.get(EnvModbusAddr, ""),
+		ModbusUnitID:        modbusUnitID,
+		ModbusAllowOverride: modbusAllowOverride,
+
+		MQTTBroker:          src.get(EnvMQTTBroker, ""),
+		MQTTTopicPrefix:     src.get(EnvMQTTTopicPrefix, DefaultMQTTTopicPrefix),
+		MQTTClientID:        src.get(EnvMQTTClientID, "powercap-"+nodeName),
+		MQTTUsername:        src.get(EnvMQTTUsername, ""),
+		MQTTPassword:        src.get(EnvMQTTPassword, ""),
+		MQTTKeepAlive:       mqttKeepAlive,
+		MQTTDiscoveryPrefix: src.get(EnvMQTTDiscoveryPrefix, ""),
+
+		PushgatewayURL: src.get(EnvPushgatewayURL, ""),
+		PushgatewayJob: src.get(EnvPushgatewayJob, DefaultPushgatewayJob),
+
+		NATSURL:           src.get(EnvNATSURL, ""),
+		NATSSubjectPrefix: src.get(EnvNATSSubjectPrefix, DefaultNATSSubjectPrefix),
+		NATSToken:         src.get(EnvNATSToken, ""),
+		NATSJetStream:     natsJetStream,
+
+		UPSAddr:         src.get(EnvUPSAddr, ""),
+		UPSPollInterval: upsPollInterval,
+
+		MeterAddr: src.get(EnvMeterAddr, ""),
+
+		SiteMeterAddr:     src.get(EnvSiteMeterAddr, ""),
+		SiteMeterInterval: siteMeterInterval,
+		PeakThreshold:     peakThreshold,
+		PeakShavingStart:  peakShavingStart,
+
+		BatterySoCAddr:      src.get(EnvBatterySoCAddr, ""),
+		BatteryPollInterval: batteryPollInterval,
+		BatteryLowSoC:       batteryLowSoC,
+		BatteryHighSoC:      batteryHighSoC,
+		PVMeterAddr:         src.get(EnvPVMeterAddr, ""),
+		PVMinPower:          pvMinPower,
+
+		DRWebhookSecret: src.get(EnvDRWebhookSecret, ""),
+		DRMaxDuration:   drMaxDuration,
+		DROfflineCPUs:   drOfflineCPUs,
+
+		Features: featureGates,
+
+		ExperimentLabel: src.get(EnvExperimentLabel, ""),
+
+		ConfigFile: src.path,
+		Profile:    src.profileName,
+	}
+
+	if len(p.problems) > 0 {
+		return cfg, &ValidationError{Problems: p.problems}
+	}
+	return cfg, nil
 }
 
 // parseProviderParams parses provider parameters from JSON string
@@ -99,10 +859,81 @@ func parseProviderParams(jsonStr string) (map[string]string, error) {
 	return params, nil
 }
 
-// getEnvOrDefault returns environment variable value or default if not set
-func getEnvOrDefault(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists && value != "" {
-		return value
+// parseTokens parses comma-separated name=token pairs into a map of tokens
+// to client names. A bare token belongs to the client "admin".
+func parseTokens(value string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, item := range splitList(value) {
+		name, token, found := strings.Cut(item, "=")
+		if !found {
+			name, token = "admin", item
+		}
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if name == "" || token == "" {
+			return nil, fmt.Errorf("invalid token entry, expected name=token")
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("token of %s is used by another client", name)
+		}
+		tokens[token] = name
+	}
+	return tokens, nil
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// StateFilePath returns the state file of a standalone node
+func (c *Config) StateFilePath() string {
+	if c.StateFile != "" {
+		return c.StateFile
+	}
+	return filepath.Join(c.DataDir, "powercap-state.json")
+}
+
+// LastStatePath returns the file of the saved cap and decisions
+func (c *Config) LastStatePath() string {
+	if c.LastStateFile != "" {
+		return c.LastStateFile
+	}
+	return filepath.Join(c.DataDir, "powercap-last.json")
+}
+
+// MarketLocation returns the market timezone, falling back to UTC if it is unknown
+func (c *Config) MarketLocation() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// MarketCalendar returns the calendar of the market closed days, nil when
+// neither CALENDAR_COUNTRY nor CALENDAR_HOLIDAYS is set
+func (c *Config) MarketCalendar() (*calendar.Calendar, error) {
+	if c.CalendarCountry == "" && len(c.CalendarHolidays) == 0 {
+		return nil, nil
+	}
+	return calendar.New(c.CalendarCountry, c.CalendarWeekend, c.CalendarHolidays)
+}
+
+// DisplayLocation returns the timezone of log timestamps, the system
+// timezone if none or an unknown one is configured
+func (c *Config) DisplayLocation() *time.Location {
+	if c.DisplayTimezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.DisplayTimezone)
+	if err != nil {
+		return time.Local
 	}
-	return defaultValue
+	return loc
 }
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Configuration file location
+const (
+	EnvConfigFile     = "CONFIG_FILE"               // Path to the YAML configuration file
+	DefaultConfigFile = "/etc/powercap/config.yaml" // Used only when present
+)
+
+// source resolves setting values with the precedence
+// flag > environment variable > secret file > profile > configuration file > default
+type source struct {
+	flags   map[string]string // Values given on the command line, keyed by env name
+	secrets map[string]string // Values read from *_FILE variables and the secrets directory
+	path    string            // Loaded configuration file, empty if none
+	file    map[string]string
+	profile map[string]string // Values of the selected profile
+
+	profileName  string
+	fileProfiles map[string]map[string]string // Profiles defined in the configuration file
+
+	providerSecrets map[string]string // Provider parameters read from the secrets directory
+}
+
+// newSource loads the configuration file, if any, and the secret files
+func newSource(flags map[string]string) (*source, error) {
+	s := &source{flags: flags, file: map[string]string{}}
+
+	path, explicit := flags[EnvConfigFile]
+	if !explicit {
+		path, explicit = os.LookupEnv(EnvConfigFile)
+	}
+	if !explicit || path == "" {
+		path = DefaultConfigFile
+	}
+
+	values, profiles, err := loadConfigFile(path)
+	switch {
+	case err == nil:
+		s.path, s.file, s.fileProfiles = path, values, profiles
+	case !os.IsNotExist(err) || explicit:
+		return nil, fmt.Errorf("failed to load config file %s: %w", path, err)
+	}
+
+	if err := s.loadSecrets(); err != nil {
+		return nil, err
+	}
+	if err := s.selectProfile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// get returns the value of a setting from the flags, the environment, the secrets, the profile, the config file or the default
+func (s *source) get(key, defaultValue string) string {
+	if value, exists := s.flags[key]; exists {
+		return value
+	}
+	if value, exists := os.LookupEnv(key); exists && value != "" {
+		return value
+	}
+	if value, exists := s.secrets[key]; exists && value != "" {
+		return value
+	}
+	if value, exists := s.profile[key]; exists && value != "" {
+		return value
+	}
+	if value, exists := s.file[key]; exists && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// loadConfigFile reads a flat YAML mapping of settings. Keys are the
+// environment variable names, case-insensitive and with "-" or "_"
+// separators (e.g. stabilisation_time or STABILISATION_TIME). Nested mappings
+// such as provider_params are converted to JSON, lists to comma-separated values.
+func loadConfigFile(path string) (map[string]string, map[string]map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	// The profiles section maps profile names to their own settings
+	profiles := make(map[string]map[string]string)
+	if section, exists := raw[profilesKey]; exists {
+		named, ok := section.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("%s must be a mapping of profile names to settings", profilesKey)
+		}
+		for name, body := range named {
+			settings, ok := body.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("profile %s must be a mapping of settings", name)
+			}
+			values, err := parseSettings(settings)
+			if err != nil {
+				return nil, nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+			profiles[name] = values
+		}
+		delete(raw, profilesKey)
+	}
+
+	values, err := parseSettings(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, profiles, nil
+}
+
+// parseSettings converts a mapping of setting keys to environment-style values
+func parseSettings(raw map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		name := settingName(key)
+		str, err := settingString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		values[name] = str
+	}
+	return values, nil
+}
+
+// settingString converts a YAML value to the string form used by environment variables
+func settingString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			str, err := settingString(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		// Nested mappings are JSON settings; stringify their scalar values
+		flat := make(map[string]string, len(v))
+		for key, item := range v {
+			str, err := settingString(item)
+			if err != nil {
+				return "", err
+			}
+			flat[key] = str
+		}
+		encoded, err := json.Marshal(flat)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
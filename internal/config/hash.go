@@ -0,0 +1,23 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Hash returns a short fingerprint of the effective configuration. Two
+// configurations resolving to the same settings have the same hash,
+// whatever their source.
+func Hash(cfg *Config) string {
+	effective := *cfg
+	// Where the settings came from does not change their effect
+	effective.ConfigFile = ""
+
+	encoded, err := json.Marshal(effective)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:8])
+}
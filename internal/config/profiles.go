@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Profile selection
+const (
+	EnvProfile = "PROFILE" // Named bundle of settings applied below explicit values
+
+	// profilesKey is the config file section defining custom profiles
+	profilesKey = "profiles"
+)
+
+// builtinProfiles bundle calculator, provider and bounds settings for common
+// deployments. Profiles of the same name in the config file replace them.
+var builtinProfiles = map[string]map[string]string{
+	// dev uses generated data and a short cycle for local testing
+	"dev": {
+		EnvDataProvider:      "mock",
+		EnvStabilisationTime: "30s",
+		EnvLogDedupWindow:    "0s",
+	},
+	// conservative caps relative to the average volume, so most periods run
+	// near the hardware maximum, and adjusts rarely
+	"conservative": {
+		EnvPowerCalcMode:     "average",
+		EnvStabilisationTime: "15m",
+		EnvRaplLimit:         "20000000",
+	},
+	// aggressive follows the market closely down to a low floor
+	"aggressive": {
+		EnvPowerCalcMode:     "max",
+		EnvStabilisationTime: "1m",
+		EnvRaplLimit:         "5000000",
+	},
+}
+
+// selectProfile applies the profile named by PROFILE, if any
+func (s *source) selectProfile() error {
+	name := s.get(EnvProfile, "")
+	if name == "" {
+		return nil
+	}
+
+	profiles := Profiles(s.fileProfiles)
+	profile, exists := profiles[name]
+	if !exists {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown profile %q. Available profiles: %v", name, names)
+	}
+
+	s.profileName = name
+	s.profile = profile
+	return nil
+}
+
+// Profiles returns the built-in profiles merged with the given custom ones
+func Profiles(custom map[string]map[string]string) map[string]map[string]string {
+	profiles := make(map[string]map[string]string, len(builtinProfiles)+len(custom))
+	for name, settings := range builtinProfiles {
+		profiles[name] = settings
+	}
+	for name, settings := range custom {
+		profiles[name] = settings
+	}
+	return profiles
+}
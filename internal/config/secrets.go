@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Secret files
+const (
+	EnvSecretsDir = "SECRETS_DIR" // Directory of a mounted Kubernetes Secret
+
+	// fileSuffix marks a setting whose value is read from a file, e.g. SENTRY_DSN_FILE
+	fileSuffix = "_FILE"
+
+	// providerSecretPrefix marks secrets directory entries holding provider
+	// parameters, e.g. "provider.api_token"
+	providerSecretPrefix = "provider."
+)
+
+// loadSecrets reads settings from *_FILE variables (environment or config
+// file) and from the files of the secrets directory. A file in the secrets
+// directory named after a setting (e.g. SENTRY_DSN) provides its value; a
+// file named provider.<param> provides a provider parameter.
+func (s *source) loadSecrets() error {
+	s.secrets = make(map[string]string)
+	s.providerSecrets = make(map[string]string)
+
+	// *_FILE keys of the config file are resolved like their environment variants
+	for key, path := range s.file {
+		name := strings.TrimSuffix(key, fileSuffix)
+		if name == key || !isSetting(name) || path == "" {
+			continue
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		delete(s.file, key)
+		if _, exists := s.file[name]; !exists {
+			s.file[name] = value
+		}
+	}
+
+	if dir := s.get(EnvSecretsDir, ""); dir != "" {
+		if err := s.loadSecretsDir(dir); err != nil {
+			return err
+		}
+	}
+
+	// Explicit *_FILE variables take precedence over the secrets directory
+	for _, setting := range Settings {
+		path := os.Getenv(setting.Env + fileSuffix)
+		if path == "" {
+			continue
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s%s: %w", setting.Env, fileSuffix, err)
+		}
+		s.secrets[setting.Env] = value
+	}
+
+	return nil
+}
+
+// loadSecretsDir reads the files of a mounted secret directory
+func (s *source) loadSecretsDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s: %w", EnvSecretsDir, dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		// Skip the hidden ..data links Kubernetes creates in secret volumes
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(name, providerSecretPrefix):
+			value, err := readSecretFile(path)
+			if err != nil {
+				return err
+			}
+			s.providerSecrets[strings.TrimPrefix(name, providerSecretPrefix)] = value
+		case isSetting(settingName(name)):
+			value, err := readSecretFile(path)
+			if err != nil {
+				return err
+			}
+			s.secrets[settingName(name)] = value
+		}
+	}
+	return nil
+}
+
+// readSecretFile reads a secret value, ignoring surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// resolveParamFiles replaces provider parameters ending in "_file" with the
+// content of the file they point to, e.g. api_token_file → api_token
+func resolveParamFiles(params map[string]string) error {
+	for key, path := range params {
+		name := strings.TrimSuffix(key, strings.ToLower(fileSuffix))
+		if name == key {
+			continue
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read provider parameter %s: %w", key, err)
+		}
+		delete(params, key)
+		params[name] = value
+	}
+	return nil
+}
+
+// settingName normalises a config key or file name to its environment variable name
+func settingName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// isSetting reports whether name is a known setting
+func isSetting(name string) bool {
+	for _, setting := range Settings {
+		if setting.Env == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,607 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"kcas/new/internal/budget"
+	"kcas/new/internal/calendar"
+	"kcas/new/internal/features"
+)
+
+// ValidationError lists every problem found in the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error joins all problems into one message
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// parser reads typed settings from a source, collecting every problem
+// instead of stopping at the first one
+type parser struct {
+	src      *source
+	problems []string
+}
+
+func (p *parser) addProblem(key, format string, args ...interface{}) {
+	p.problems = append(p.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// duration parses a Go duration such as "90s" or "1h30m", or a bare number of seconds
+func (p *parser) duration(key, defaultValue string) time.Duration {
+	value := p.src.get(key, defaultValue)
+	d, err := ParseDuration(value)
+	if err != nil {
+		p.addProblem(key, "%v", err)
+	}
+	return d
+}
+
+// ParseDuration parses a Go duration such as "90s", "5m" or "1h30m". A bare
+// number is a number of seconds, as in earlier versions.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, expected a duration such as \"90s\", \"5m\" or \"1h30m\", or a number of seconds", value)
+	}
+	return d, nil
+}
+
+func (p *parser) int64(key, defaultValue string) int64 {
+	value := p.src.get(key, defaultValue)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		p.addProblem(key, "invalid integer %q", value)
+	}
+	return n
+}
+
+func (p *parser) float64(key, defaultValue string) float64 {
+	value := p.src.get(key, defaultValue)
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.addProblem(key, "invalid number %q", value)
+	}
+	return f
+}
+
+func (p *parser) int(key, defaultValue string) int {
+	return int(p.int64(key, defaultValue))
+}
+
+func (p *parser) bool(key, defaultValue string) bool {
+	value := p.src.get(key, defaultValue)
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.addProblem(key, "invalid boolean %q, expected true or false", value)
+	}
+	return b
+}
+
+// Validate checks the semantics of a loaded configuration and returns every
+// problem found, each prefixed with the setting it concerns
+func Validate(cfg *Config) []string {
+	var problems []string
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	if cfg.StabilisationTime <= 0 {
+		add(EnvStabilisationTime, "must be positive, got %v", cfg.StabilisationTime)
+	}
+	switch {
+	case cfg.AdjustIntervalMin < 0 || cfg.AdjustIntervalMax < 0:
+		add(EnvAdjustIntervalMin, "the adaptive interval bounds must not be negative")
+	case (cfg.AdjustIntervalMin > 0) != (cfg.AdjustIntervalMax > 0):
+		add(EnvAdjustIntervalMin, "must be set together with %s", EnvAdjustIntervalMax)
+	case cfg.AdjustIntervalMin > cfg.AdjustIntervalMax:
+		add(EnvAdjustIntervalMin, "must not exceed %s (%v), got %v", EnvAdjustIntervalMax, cfg.AdjustIntervalMax, cfg.AdjustIntervalMin)
+	}
+	if cfg.RaplLimit <= 0 {
+		add(EnvRaplLimit, "must be a positive number of µW, got %d", cfg.RaplLimit)
+	}
+	switch cfg.PowerBackend {
+	case "auto", "intel-rapl", "dtpm":
+	case "cpufreq":
+		if cfg.CPUFreqMaxPower <= 0 {
+			add(EnvCPUFreqMaxPower, "must be set with %s=cpufreq, the power of the CPUs at their highest frequency in µW", EnvPowerBackend)
+		}
+	default:
+		add(EnvPowerBackend, "must be auto, intel-rapl, dtpm or cpufreq, got %q", cfg.PowerBackend)
+	}
+	for _, slice := range cfg.CgroupSlices {
+		if filepath.IsAbs(slice) || strings.Contains(slice, "..") {
+			add(EnvCgroupSlices, "must list slices relative to the cgroup v2 mount point, got %q", slice)
+		}
+	}
+	if cfg.CgroupMinCPUs <= 0 {
+		add(EnvCgroupMinCPUs, "must be a positive number of CPUs, got %g", cfg.CgroupMinCPUs)
+	}
+	if len(cfg.BudgetSplit) > 0 {
+		seen := make(map[string]bool)
+		for _, name := range cfg.BudgetSplit {
+			if !slices.Contains(budget.Known, name) {
+				add(EnvBudgetSplit, "unknown actuator %q, expected one of %v", name, budget.Known)
+			} else if seen[name] {
+				add(EnvBudgetSplit, "lists %s twice", name)
+			}
+			seen[name] = true
+		}
+		if !seen[budget.CPU] {
+			add(EnvBudgetSplit, "must list %s, which carries the market-based cap", budget.CPU)
+		}
+		if seen[budget.GPU] && !cfg.Features.Enabled(features.GPUCapping) {
+			add(EnvBudgetSplit, "%s needs the %s feature gate", budget.GPU, features.GPUCapping)
+		}
+	}
+	if cfg.BudgetFloor < 0 || cfg.BudgetFloor >= 1 {
+		add(EnvBudgetFloor, "must be a share between 0 and 1, got %g", cfg.BudgetFloor)
+	}
+	if cfg.IdleLatency < 0 {
+		add(EnvIdleLatency, "must be a positive number of µs, got %d", cfg.IdleLatency)
+	}
+	if cfg.CPUFreqMaxPower < 0 {
+		add(EnvCPUFreqMaxPower, "must be a positive number of µW, got %d", cfg.CPUFreqMaxPower)
+	} else if cfg.CPUFreqMaxPower > 0 && cfg.CPUFreqMaxPower < cfg.RaplLimit {
+		add(EnvCPUFreqMaxPower, "%d µW is below %s %d µW; lower the minimum on small boards", cfg.CPUFreqMaxPower, EnvRaplLimit, cfg.RaplLimit)
+	}
+	if cfg.RaplMaxPower.Absolute > 0 && cfg.RaplMaxPower.Absolute < cfg.RaplLimit {
+		add(EnvRaplMaxPower, "ceiling %d µW is below %s %d µW; the ceiling would always win", cfg.RaplMaxPower.Absolute, EnvRaplLimit, cfg.RaplLimit)
+	}
+	if cfg.PmaxSource != "annotation" && cfg.PmaxSource != "live" {
+		add(EnvPmaxSource, "unknown source %q, expected annotation or live", cfg.PmaxSource)
+	}
+	if cfg.PmaxRefresh < 0 {
+		add(EnvPmaxRefresh, "must not be negative, got %v", cfg.PmaxRefresh)
+	}
+	if cfg.PmaxRecheck < 0 {
+		add(EnvPmaxRecheck, "must not be negative, got %v", cfg.PmaxRecheck)
+	}
+	if cfg.CycleTimeout <= 0 {
+		add(EnvCycleTimeout, "must be positive, got %v", cfg.CycleTimeout)
+	}
+	if cfg.RefreshTimeout <= 0 {
+		add(EnvRefreshTimeout, "must be positive, got %v", cfg.RefreshTimeout)
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		add(EnvShutdownTimeout, "must be positive, got %v", cfg.ShutdownTimeout)
+	}
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		add(EnvTimezone, "unknown timezone %q (use an IANA name such as Europe/Paris)", cfg.Timezone)
+	}
+	if cfg.DisplayTimezone != "" {
+		if _, err := time.LoadLocation(cfg.DisplayTimezone); err != nil {
+			add(EnvDisplayTimezone, "unknown timezone %q (use an IANA name such as Europe/Paris)", cfg.DisplayTimezone)
+		}
+	}
+	if cfg.PowerCalcMode != "max" && cfg.PowerCalcMode != "average" && cfg.PowerCalcMode != "percent" {
+		add(EnvPowerCalcMode, "unknown mode %q, expected max, average or percent", cfg.PowerCalcMode)
+	}
+	if cfg.PeriodFallback < 0 {
+		add(EnvPeriodFallback, "must not be negative, got %d", cfg.PeriodFallback)
+	}
+	if cfg.SmoothingWindow < 0 || cfg.SmoothingWindow > 0 && cfg.SmoothingWindow%2 == 0 {
+		add(EnvSmoothingWindow, "must be 0 or an odd number of periods, got %d", cfg.SmoothingWindow)
+	}
+	switch cfg.ForecastModel {
+	case "average", "ewma", "weekday":
+	default:
+		add(EnvForecastModel, "unknown model %q, expected average, ewma or weekday", cfg.ForecastModel)
+	}
+	if cfg.ForecastDays < 1 {
+		add(EnvForecastDays, "must be at least 1, got %d", cfg.ForecastDays)
+	}
+	if cfg.ForecastAlpha <= 0 || cfg.ForecastAlpha > 1 {
+		add(EnvForecastAlpha, "must be in (0, 1], got %g", cfg.ForecastAlpha)
+	}
+	shadows := map[string]bool{}
+	for _, mode := range cfg.ShadowCalcModes {
+		switch {
+		case mode != "max" && mode != "average" && mode != "percent":
+			add(EnvShadowCalcModes, "unknown mode %q, expected max, average or percent", mode)
+		case mode == cfg.PowerCalcMode:
+			add(EnvShadowCalcModes, "mode %q is the active %s", mode, EnvPowerCalcMode)
+		case shadows[mode]:
+			add(EnvShadowCalcModes, "mode %q is listed twice", mode)
+		}
+		shadows[mode] = true
+	}
+
+	switch cfg.Orchestrator {
+	case "kubernetes", "standalone":
+		// Annotation keys must be valid Kubernetes qualified names
+		if errs := validation.IsQualifiedName(cfg.AnnotationPrefix + "pmax"); len(errs) > 0 {
+			add(EnvAnnotationPrefix, "%q does not form valid annotation keys: %s", cfg.AnnotationPrefix, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsQualifiedName(cfg.InitAnnotation); len(errs) > 0 {
+			add(EnvInitAnnotation, "invalid annotation key %q: %s", cfg.InitAnnotation, strings.Join(errs, ", "))
+		}
+	case "nomad":
+		if u, err := url.Parse(cfg.NomadAddr); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(EnvNomadAddr, "expected an http(s) URL, got %q", cfg.NomadAddr)
+		}
+		if (cfg.NomadClientCert == "") != (cfg.NomadClientKey == "") {
+			add(EnvNomadClientKey, "%s and %s must be set together", EnvNomadClientCert, EnvNomadClientKey)
+		}
+		// Metadata keys must be usable in job interpolation, e.g. ${meta.rapl.pmax}
+		if !isNomadMetaKey(cfg.AnnotationPrefix + "pmax") {
+			add(EnvAnnotationPrefix, "%q does not form valid Nomad metadata keys (letters, digits, '.', '_' and '-')", cfg.AnnotationPrefix)
+		}
+		if !isNomadMetaKey(cfg.InitAnnotation) {
+			add(EnvInitAnnotation, "invalid Nomad metadata key %q (letters, digits, '.', '_' and '-')", cfg.InitAnnotation)
+		}
+	default:
+		add(EnvOrchestrator, "unknown orchestrator %q, expected kubernetes, nomad or standalone", cfg.Orchestrator)
+	}
+	if cfg.Orchestrator == "standalone" {
+		if err := checkWritableDir(filepath.Dir(cfg.StateFilePath())); err != nil {
+			add(EnvStateFile, "%v", err)
+		}
+	}
+	if cfg.RestoreLastCap && cfg.LastStateFile != "" {
+		if err := checkWritableDir(filepath.Dir(cfg.LastStateFile)); err != nil {
+			add(EnvLastStateFile, "%v", err)
+		}
+	}
+
+	for _, schedule := range [][2]string{{EnvDataRefreshCron, cfg.DataRefreshCron}, {EnvDataRetryCron, cfg.DataRetryCron}} {
+		if schedule[1] == "" {
+			continue
+		}
+		if _, err := cron.ParseStandard(schedule[1]); err != nil {
+			add(schedule[0], "invalid cron expression %q: %v", schedule[1], err)
+		}
+	}
+	if cfg.ProviderTimeout <= 0 {
+		add(EnvProviderTimeout, "must be positive, got %v", cfg.ProviderTimeout)
+	}
+	if cfg.ProviderParallel < 1 {
+		add(EnvProviderParallel, "must be at least 1, got %d", cfg.ProviderParallel)
+	}
+	if cfg.ProviderCacheTTL < 0 {
+		add(EnvProviderCacheTTL, "must not be negative, got %v", cfg.ProviderCacheTTL)
+	}
+	if cfg.BackoffMax < 0 {
+		add(EnvBackoffMax, "must not be negative, got %v", cfg.BackoffMax)
+	}
+	if cfg.FailoverAfter < 1 {
+		add(EnvFailoverAfter, "must be at least 1, got %d", cfg.FailoverAfter)
+	}
+	if cfg.FallbackDays < 0 {
+		add(EnvFallbackDays, "must not be negative, got %d", cfg.FallbackDays)
+	}
+	if cfg.FallbackDecay < 0 || cfg.FallbackDecay >= 1 {
+		add(EnvFallbackDecay, "must be in [0, 1), got %g", cfg.FallbackDecay)
+	}
+	if cfg.BackfillDays < 0 || cfg.BackfillDays > 366 {
+		add(EnvBackfillDays, "must be in [0, 366], got %d", cfg.BackfillDays)
+	}
+	if cfg.CanaryWindow < 0 {
+		add(EnvCanaryWindow, "must not be negative, got %v", cfg.CanaryWindow)
+	}
+	for i, provider := range cfg.FailoverProviders {
+		if provider == strings.ToLower(cfg.DataProvider) {
+			add(EnvFailoverProvider, "must differ from %s %q", EnvDataProvider, cfg.DataProvider)
+		} else if slices.Contains(cfg.FailoverProviders[:i], provider) {
+			add(EnvFailoverProvider, "lists %s twice", provider)
+		}
+	}
+	if cfg.FetchMinPeriods < 0 || cfg.FetchMinPeriods > 96 {
+		add(EnvFetchMinPeriods, "must be between 0 and 96, the periods of a day, got %d", cfg.FetchMinPeriods)
+	}
+	if cfg.ProviderMinScore < 0 || cfg.ProviderMinScore > 1 {
+		add(EnvProviderMinScore, "must be a score between 0 and 1, got %g", cfg.ProviderMinScore)
+	}
+	for _, code := range [][2]string{{EnvCurrency, cfg.Currency}, {EnvProviderCurrency, cfg.ProviderCurrency}} {
+		if !isCurrencyCode(code[1]) {
+			add(code[0], "invalid currency %q, expected a three-letter ISO 4217 code such as EUR", code[1])
+		}
+	}
+	if cfg.CurrencyRate < 0 {
+		add(EnvCurrencyRate, "must be positive, got %v", cfg.CurrencyRate)
+	}
+	if cfg.CurrencyRate == 0 && cfg.Currency != cfg.ProviderCurrency {
+		if _, err := url.ParseRequestURI(cfg.CurrencyRatesURL); err != nil {
+			add(EnvCurrencyRatesURL, "invalid URL %q", cfg.CurrencyRatesURL)
+		}
+	}
+	if _, err := calendar.New(cfg.CalendarCountry, nil, nil); err != nil {
+		add(EnvCalendarCountry, "%v", err)
+	}
+	if _, err := calendar.New("", nil, cfg.CalendarHolidays); err != nil {
+		add(EnvCalendarHolidays, "%v", err)
+	}
+	if (cfg.ClosedDayProvider != "" || cfg.ClosedDayCap.IsSet()) && cfg.CalendarCountry == "" && len(cfg.CalendarHolidays) == 0 {
+		add(EnvCalendarCountry, "must be set (or %s) for %s or %s", EnvCalendarHolidays, EnvClosedDayProvider, EnvClosedDayCap)
+	}
+	if cfg.PriceSpikeThreshold < 0 {
+		add(EnvPriceSpikeThreshold, "must not be negative, got %v", cfg.PriceSpikeThreshold)
+	}
+	if cfg.PriceSpikeFactor != 0 && cfg.PriceSpikeFactor <= 1 {
+		add(EnvPriceSpikeFactor, "must be greater than 1 (or 0 to disable), got %v", cfg.PriceSpikeFactor)
+	}
+	switch cfg.SavingsBaseline {
+	case "hardware_max", "measured":
+	default:
+		add(EnvSavingsBaseline, "unknown baseline %q, expected hardware_max or measured", cfg.SavingsBaseline)
+	}
+	if cfg.CarbonIntensity < 0 {
+		add(EnvCarbonIntensity, "must not be negative, got %v", cfg.CarbonIntensity)
+	}
+	if err := checkWritableDir(cfg.DataDir); err != nil {
+		add(EnvDataDir, "%v", err)
+	}
+
+	if cfg.LogDedupWindow < 0 {
+		add(EnvLogDedupWindow, "must not be negative")
+	}
+	if cfg.LogMaxSizeMB <= 0 {
+		add(EnvLogMaxSizeMB, "must be positive, got %d", cfg.LogMaxSizeMB)
+	}
+	if cfg.LogMaxBackups < 0 {
+		add(EnvLogMaxBackups, "must not be negative, got %d", cfg.LogMaxBackups)
+	}
+	if cfg.LogFile != "" {
+		if err := checkWritableDir(filepath.Dir(cfg.LogFile)); err != nil {
+			add(EnvLogFile, "%v", err)
+		}
+	}
+
+	switch strings.ToLower(cfg.ErrorReporting) {
+	case "", "none":
+	case "sentry":
+		if cfg.SentryDSN == "" {
+			add(EnvSentryDSN, "required when %s=sentry", EnvErrorReporting)
+		}
+	case "webhook":
+		if cfg.ErrorWebhookURL == "" {
+			add(EnvErrorWebhookURL, "required when %s=webhook", EnvErrorReporting)
+		}
+	default:
+		add(EnvErrorReporting, "unknown backend %q, expected sentry, webhook or none", cfg.ErrorReporting)
+	}
+	if cfg.ErrorRepeatThreshold < 0 {
+		add(EnvErrorRepeatReport, "must not be negative, got %d", cfg.ErrorRepeatThreshold)
+	}
+
+	if cfg.StatsDAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.StatsDAddr); err != nil {
+			add(EnvStatsDAddr, "expected host:port, got %q", cfg.StatsDAddr)
+		}
+		if cfg.StatsDFlushInterval <= 0 {
+			add(EnvStatsDFlushInterval, "must be positive, got %v", cfg.StatsDFlushInterval)
+		}
+	}
+	if cfg.SNMPAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.SNMPAddr); err != nil {
+			add(EnvSNMPAddr, "expected host:port, got %q", cfg.SNMPAddr)
+		}
+	}
+	if cfg.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.HTTPAddr); err != nil {
+			add(EnvHTTPAddr, "expected host:port, got %q", cfg.HTTPAddr)
+		}
+	} else if len(cfg.AdminAPITokens) > 0 {
+		add(EnvAdminAPITokens, "the admin API is served on %s, which is disabled", EnvHTTPAddr)
+	}
+	if cfg.ModbusAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.ModbusAddr); err != nil {
+			add(EnvModbusAddr, "expected host:port, got %q", cfg.ModbusAddr)
+		}
+		if cfg.ModbusUnitID < 0 || cfg.ModbusUnitID > 255 {
+			add(EnvModbusUnitID, "must be between 0 and 255, got %d", cfg.ModbusUnitID)
+		}
+	}
+	if cfg.MQTTBroker != "" {
+		broker := cfg.MQTTBroker
+		if !strings.Contains(broker, "://") {
+			broker = "tcp://" + broker
+		}
+		u, err := url.Parse(broker)
+		switch {
+		case err != nil || u.Hostname() == "":
+			add(EnvMQTTBroker, "expected tcp://host:port or ssl://host:port, got %q", cfg.MQTTBroker)
+		case u.Scheme != "tcp" && u.Scheme != "mqtt" && u.Scheme != "ssl" && u.Scheme != "tls" && u.Scheme != "mqtts":
+			add(EnvMQTTBroker, "unsupported scheme %q (expected tcp or ssl)", u.Scheme)
+		}
+		if cfg.MQTTTopicPrefix == "" || strings.ContainsAny(cfg.MQTTTopicPrefix, "#+") {
+			add(EnvMQTTTopicPrefix, "must be a non-empty topic without wildcards, got %q", cfg.MQTTTopicPrefix)
+		}
+		if cfg.MQTTKeepAlive < time.Second || cfg.MQTTKeepAlive > 18*time.Hour {
+			add(EnvMQTTKeepAlive, "must be between 1s and 18h, got %v", cfg.MQTTKeepAlive)
+		}
+	}
+	if cfg.PushgatewayURL != "" {
+		if u, err := url.Parse(cfg.PushgatewayURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(EnvPushgatewayURL, "expected an http(s) URL, got %q", cfg.PushgatewayURL)
+		}
+		if cfg.PushgatewayJob == "" {
+			add(EnvPushgatewayJob, "must not be empty")
+		}
+	}
+	if cfg.NATSURL != "" {
+		natsURL := cfg.NATSURL
+		if !strings.Contains(natsURL, "://") {
+			natsURL = "nats://" + natsURL
+		}
+		u, err := url.Parse(natsURL)
+		switch {
+		case err != nil || u.Hostname() == "":
+			add(EnvNATSURL, "expected nats://host:port or tls://host:port, got %q", cfg.NATSURL)
+		case u.Scheme != "nats" && u.Scheme != "tls":
+			add(EnvNATSURL, "unsupported scheme %q (expected nats or tls)", u.Scheme)
+		}
+		if cfg.NATSSubjectPrefix == "" || strings.ContainsAny(cfg.NATSSubjectPrefix, "*> ") {
+			add(EnvNATSSubjectPrefix, "must be a non-empty subject without wildcards or spaces, got %q", cfg.NATSSubjectPrefix)
+		}
+	}
+	if cfg.UPSAddr != "" {
+		u, err := url.Parse(cfg.UPSAddr)
+		switch {
+		case err != nil || u.Hostname() == "":
+			add(EnvUPSAddr, "expected nut://host/ups or apcupsd://host, got %q", cfg.UPSAddr)
+		case u.Scheme == "nut" && strings.Trim(u.Path, "/") == "":
+			add(EnvUPSAddr, "missing UPS name, expected nut://host/ups")
+		case u.Scheme != "nut" && u.Scheme != "apcupsd":
+			add(EnvUPSAddr, "unsupported scheme %q (expected nut or apcupsd)", u.Scheme)
+		}
+		if cfg.UPSPollInterval <= 0 {
+			add(EnvUPSPollInterval, "must be positive, got %v", cfg.UPSPollInterval)
+		}
+	}
+	if cfg.MeterAddr != "" {
+		validateMeterAddr(EnvMeterAddr, cfg.MeterAddr, add)
+	}
+	if cfg.SiteMeterAddr != "" {
+		validateMeterAddr(EnvSiteMeterAddr, cfg.SiteMeterAddr, add)
+		if cfg.PeakThreshold <= 0 {
+			add(EnvPeakThreshold, "must be set with %s", EnvSiteMeterAddr)
+		}
+		if cfg.PeakShavingStart <= 0 || cfg.PeakShavingStart >= 1 {
+			add(EnvPeakShavingStart, "must be in (0, 1), got %g", cfg.PeakShavingStart)
+		}
+		if cfg.SiteMeterInterval <= 0 {
+			add(EnvSiteMeterInterval, "must be positive, got %v", cfg.SiteMeterInterval)
+		}
+	}
+	if cfg.BatterySoCAddr != "" {
+		u, err := url.Parse(cfg.BatterySoCAddr)
+		switch {
+		case err != nil || u.Hostname() == "":
+			add(EnvBatterySoCAddr, "expected http(s)://host/path#field or mqtt://broker/topic, got %q", cfg.BatterySoCAddr)
+		case (u.Scheme == "http" || u.Scheme == "https") && u.Fragment == "":
+			add(EnvBatterySoCAddr, "missing JSON field, expected %s://host/path#field", u.Scheme)
+		case (u.Scheme == "mqtt" || u.Scheme == "mqtts") && strings.Trim(u.Path, "/") == "":
+			add(EnvBatterySoCAddr, "missing topic, expected %s://broker/topic", u.Scheme)
+		case u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mqtt" && u.Scheme != "mqtts":
+			add(EnvBatterySoCAddr, "unsupported scheme %q (expected http, https, mqtt or mqtts)", u.Scheme)
+		}
+		if cfg.BatteryLowSoC < 0 || cfg.BatteryHighSoC > 100 || cfg.BatteryLowSoC >= cfg.BatteryHighSoC {
+			add(EnvBatteryLowSoC, "expected 0 <= %s < %s <= 100, got %g and %g", EnvBatteryLowSoC, EnvBatteryHighSoC, cfg.BatteryLowSoC, cfg.BatteryHighSoC)
+		}
+		if cfg.BatteryPollInterval <= 0 {
+			add(EnvBatteryPollInterval, "must be positive, got %v", cfg.BatteryPollInterval)
+		}
+	}
+	if cfg.PVMeterAddr != "" {
+		validateMeterAddr(EnvPVMeterAddr, cfg.PVMeterAddr, add)
+		if cfg.BatterySoCAddr == "" {
+			add(EnvPVMeterAddr, "only used with %s", EnvBatterySoCAddr)
+		}
+	}
+	if cfg.DRWebhookSecret != "" {
+		if cfg.HTTPAddr == "" {
+			add(EnvDRWebhookSecret, "the demand-response webhook is served on %s, which is disabled", EnvHTTPAddr)
+		}
+		if cfg.DRMaxDuration <= 0 {
+			add(EnvDRMaxDuration, "must be positive, got %v", cfg.DRMaxDuration)
+		}
+	} else if len(cfg.DROfflineCPUs) > 0 {
+		add(EnvDROfflineCPUs, "only used with %s, which receives the shed requests", EnvDRWebhookSecret)
+	}
+	if cfg.GRPCAddr != "" {
+		host, _, err := net.SplitHostPort(cfg.GRPCAddr)
+		if err != nil {
+			add(EnvGRPCAddr, "expected host:port, got %q", cfg.GRPCAddr)
+		} else if cfg.GRPCTLSCert == "" && !isLoopback(host) {
+			// The bearer tokens would cross the network in clear text
+			add(EnvGRPCAddr, "%q is not a loopback address, set %s and %s to serve it over TLS", cfg.GRPCAddr, EnvGRPCTLSCert, EnvGRPCTLSKey)
+		}
+		if len(cfg.AdminAPITokens) == 0 {
+			add(EnvGRPCAddr, "requires %s to authenticate clients", EnvAdminAPITokens)
+		}
+	}
+	if (cfg.GRPCTLSCert == "") != (cfg.GRPCTLSKey == "") {
+		add(EnvGRPCTLSKey, "%s and %s must be set together", EnvGRPCTLSCert, EnvGRPCTLSKey)
+	}
+
+	return problems
+}
+
+// checkWritableDir verifies that dir exists and files can be created in it
+func checkWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("directory %s is not accessible: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".powercap-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// isNomadMetaKey reports whether key is a Nomad node metadata key that can be
+// interpolated in job specifications
+func isNomadMetaKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isLoopback reports whether a listen host only accepts local connections,
+// an empty host listening on every interface
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isCurrencyCode reports whether code looks like an ISO 4217 code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMeterAddr checks the scheme and required parts of a meter address
+func validateMeterAddr(key, addr string, add func(key, format string, args ...interface{})) {
+	u, err := url.Parse(addr)
+	switch {
+	case err != nil || u.Hostname() == "":
+		add(key, "expected a meter address such as shelly://host, got %q", addr)
+	case (u.Scheme == "snmp" || u.Scheme == "modbus") && strings.Trim(u.Path, "/") == "":
+		add(key, "missing OID or register, expected %s://host/<oid or register>", u.Scheme)
+	case (u.Scheme == "http" || u.Scheme == "https") && u.Fragment == "":
+		add(key, "missing JSON field, expected %s://host/path#field", u.Scheme)
+	case u.Scheme != "shelly" && u.Scheme != "tplink" && u.Scheme != "snmp" && u.Scheme != "modbus" && u.Scheme != "http" && u.Scheme != "https":
+		add(key, "unsupported scheme %q (expected shelly, tplink, snmp, modbus, http or https)", u.Scheme)
+	}
+}
@@ -0,0 +1,129 @@
+// Package cpuidle switches the idle policy of the CPUs between a tight one,
+// keeping wake-up latency low, and a relaxed one letting idle cores reach
+// their deepest C-states. It writes the PM QoS resume latency of every CPU
+// (power/pm_qos_resume_latency_us) and disables the cpuidle states exiting
+// slower than the tight latency (cpuidle/state<N>/disable).
+package cpuidle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BasePath is the sysfs directory of the CPUs
+const BasePath = "/sys/devices/system/cpu"
+
+// noConstraint is the PM QoS resume latency placing no constraint on the
+// idle states
+const noConstraint = "0"
+
+// Policy writes the idle policy of every CPU
+type Policy struct {
+	root      string            // Directory holding the host's sys tree, empty for /
+	latencyUS int64             // Resume latency of the tight policy (µs), 0 for the original one
+	original  map[string]string // Content of every file written, as first read
+}
+
+// NewPolicy creates a policy whose tight setting keeps the resume latency of
+// the CPUs within latencyUS, or goes back to the settings found before the
+// first change when latencyUS is 0
+func NewPolicy(root string, latencyUS int64) *Policy {
+	return &Policy{root: root, latencyUS: latencyUS, original: make(map[string]string)}
+}
+
+// Tighten writes back the settings found before the first change, then
+// constrains the resume latency of the CPUs and disables the idle states
+// exiting slower than it when a latency is set
+func (p *Policy) Tighten() []error {
+	errs := p.Restore()
+	if p.latencyUS > 0 {
+		errs = append(errs, p.apply(false)...)
+	}
+	return errs
+}
+
+// Relax lifts the resume latency constraint and enables every idle state
+func (p *Policy) Relax() []error {
+	return p.apply(true)
+}
+
+// Restore writes back the settings found before the first change
+func (p *Policy) Restore() []error {
+	var errs []error
+	for path, value := range p.original {
+		if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// apply writes the relaxed or tight policy to every CPU
+func (p *Policy) apply(relaxed bool) []error {
+	cpus, err := filepath.Glob(filepath.Join(p.root, BasePath, "cpu[0-9]*"))
+	if err != nil {
+		return []error{err}
+	}
+	if len(cpus) == 0 {
+		return []error{fmt.Errorf("no CPU found under %s", filepath.Join(p.root, BasePath))}
+	}
+
+	var errs []error
+	latency := strconv.FormatInt(p.latencyUS, 10)
+	if relaxed {
+		latency = noConstraint
+	}
+	for _, cpu := range cpus {
+		if err := p.write(filepath.Join(cpu, "power", "pm_qos_resume_latency_us"), latency); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+
+		states, _ := filepath.Glob(filepath.Join(cpu, "cpuidle", "state[0-9]*"))
+		for _, state := range states {
+			exit, err := readInt(filepath.Join(state, "latency"))
+			if err != nil {
+				continue
+			}
+			disable := "0"
+			if !relaxed && exit > p.latencyUS {
+				disable = "1"
+			}
+			if err := p.write(filepath.Join(state, "disable"), disable); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// write writes a value unless the file already holds it, recording the
+// content it replaces the first time
+func (p *Policy) write(path, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	current := strings.TrimSpace(string(data))
+	if current == value {
+		return nil
+	}
+	if _, seen := p.original[path]; !seen {
+		p.original[path] = current
+	}
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// readInt reads an integer sysfs file
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
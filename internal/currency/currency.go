@@ -0,0 +1,156 @@
+// Package currency converts market prices between currencies, with a static
+// rate or the daily reference rates of the European Central Bank.
+package currency
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultECBURL publishes the euro reference rates of the day
+const DefaultECBURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ecbRefresh is how long the ECB rates are reused; they change once a day
+const ecbRefresh = 6 * time.Hour
+
+// Converter returns how many units of one currency buy one unit of another
+type Converter interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Static converts with a fixed rate between two currencies
+type Static struct {
+	From, To string
+	Value    float64 // Units of To per unit of From
+}
+
+// Rate returns the fixed rate, or its inverse for the opposite direction
+func (s Static) Rate(ctx context.Context, from, to string) (float64, error) {
+	switch {
+	case strings.EqualFold(from, to):
+		return 1, nil
+	case strings.EqualFold(from, s.From) && strings.EqualFold(to, s.To):
+		return s.Value, nil
+	case strings.EqualFold(from, s.To) && strings.EqualFold(to, s.From):
+		return 1 / s.Value, nil
+	default:
+		return 0, fmt.Errorf("no rate from %s to %s", from, to)
+	}
+}
+
+// ECB converts with the euro reference rates published every working day by
+// the European Central Bank, crossing through the euro
+type ECB struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	rates     map[string]float64 // Units of each currency per euro
+	date      string             // Publication date of the rates
+	fetchedAt time.Time
+}
+
+// NewECB creates a converter reading the rates at url, DefaultECBURL if empty
+func NewECB(url string) *ECB {
+	if url == "" {
+		url = DefaultECBURL
+	}
+	return &ECB{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Rate returns the rate from one currency to another
+func (e *ECB) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+
+	rates, err := e.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	fromRate, ok := rates[from]
+	if !ok {
+		return 0, fmt.Errorf("the ECB publishes no rate for %s", from)
+	}
+	toRate, ok := rates[to]
+	if !ok {
+		return 0, fmt.Errorf("the ECB publishes no rate for %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// Date returns the publication date of the rates in use, empty before the first fetch
+func (e *ECB) Date() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.date
+}
+
+// load returns the cached rates, fetching them when older than ecbRefresh.
+// The previous rates are kept when a fetch fails.
+func (e *ECB) load(ctx context.Context) (map[string]float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.rates != nil && time.Since(e.fetchedAt) < ecbRefresh {
+		return e.rates, nil
+	}
+	rates, date, err := e.fetch(ctx)
+	if err != nil {
+		if e.rates != nil {
+			return e.rates, nil
+		}
+		return nil, err
+	}
+	e.rates, e.date, e.fetchedAt = rates, date, time.Now()
+	return rates, nil
+}
+
+// fetch downloads and parses the reference rates
+func (e *ECB) fetch(ctx context.Context) (map[string]float64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch ECB rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch ECB rates: HTTP %d", resp.StatusCode)
+	}
+
+	// <Cube><Cube time="2024-05-02"><Cube currency="USD" rate="1.0702"/>...
+	var envelope struct {
+		Cube struct {
+			Day struct {
+				Time  string `xml:"time,attr"`
+				Rates []struct {
+					Currency string  `xml:"currency,attr"`
+					Rate     float64 `xml:"rate,attr"`
+				} `xml:"Cube"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, "", fmt.Errorf("invalid ECB rates: %w", err)
+	}
+
+	rates := map[string]float64{"EUR": 1}
+	for _, rate := range envelope.Cube.Day.Rates {
+		if rate.Rate > 0 {
+			rates[strings.ToUpper(rate.Currency)] = rate.Rate
+		}
+	}
+	if len(rates) == 1 {
+		return nil, "", fmt.Errorf("no rates in the ECB publication")
+	}
+	return rates, envelope.Cube.Day.Time, nil
+}
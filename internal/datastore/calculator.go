@@ -7,13 +7,24 @@ import (
 )
 
 // MarketBasedCalculator implements PowerCalculator using market data
-type MarketBasedCalculator struct{}
+type MarketBasedCalculator struct {
+	location *time.Location // Market timezone of the periods, nil for the time's own
+}
+
+// PercentReference is the reference of volumes given as percentages, such as
+// a renewable share: 100% runs at the hardware maximum
+const PercentReference = 100.0
 
 // NewMarketBasedCalculator creates a new market-based power calculator
 func NewMarketBasedCalculator() *MarketBasedCalculator {
 	return &MarketBasedCalculator{}
 }
 
+// SetLocation sets the market timezone in which periods are computed
+func (calc *MarketBasedCalculator) SetLocation(loc *time.Location) {
+	calc.location = loc
+}
+
 // CalculatePower calculates power using rule of three based on market volumes
 func (calc *MarketBasedCalculator) CalculatePower(maxSource float64, referenceVolume float64, currentTime time.Time, data []MarketDataPoint) int64 {
 	currentPeriod := calc.GetCurrentPeriod(currentTime)
@@ -27,23 +38,32 @@ func (calc *MarketBasedCalculator) CalculatePower(maxSource float64, referenceVo
 		}
 	}
 
+	return calc.PowerForVolume(maxSource, referenceVolume, currentVolume)
+}
+
+// PowerForVolume calculates the power of a period from its volume, without
+// searching the data; 0 when the volume or the reference is missing
+func (calc *MarketBasedCalculator) PowerForVolume(maxSource float64, referenceVolume float64, volume float64) int64 {
 	// If no data found, return 0
-	if currentVolume == 0 {
+	if volume == 0 {
 		return 0
 	}
 
-	// Apply rule of three: if maxSource corresponds to referenceVolume, what corresponds to currentVolume?
+	// Apply rule of three: if maxSource corresponds to referenceVolume, what corresponds to volume?
 	// referenceVolume can be either maxVolume or avgVolume depending on configuration
 	if referenceVolume == 0 {
 		return 0
 	}
 
-	power := (currentVolume / referenceVolume) * maxSource
+	power := (volume / referenceVolume) * maxSource
 	return int64(math.Round(power))
 }
 
 // GetCurrentPeriod returns the current 15-minute market period
 func (calc *MarketBasedCalculator) GetCurrentPeriod(currentTime time.Time) string {
+	if calc.location != nil {
+		currentTime = currentTime.In(calc.location)
+	}
 	hour := currentTime.Hour()
 	minute := currentTime.Minute()
 
@@ -51,17 +71,15 @@ func (calc *MarketBasedCalculator) GetCurrentPeriod(currentTime time.Time) strin
 	periodStart := (minute / 15) * 15
 	periodEnd := periodStart + 15
 
-	if periodEnd == 60 {
-		// Handle transition to next hour
-		return fmt.Sprintf("%02d:%02d-%02d:00", hour, periodStart, (hour+1)%24)
-	}
-
-	periodStr := fmt.Sprintf("%02d:%02d-%02d:%02d", hour, periodStart, hour, periodEnd)
-
-	// Handle special case for the last period of the day
+	// The last period of the day ends at 24:00, as the providers write it
 	if hour == 23 && periodStart == 45 {
 		return "23:45-24:00"
 	}
 
-	return periodStr
+	if periodEnd == 60 {
+		// Handle transition to next hour
+		return fmt.Sprintf("%02d:%02d-%02d:00", hour, periodStart, hour+1)
+	}
+
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", hour, periodStart, hour, periodEnd)
 }
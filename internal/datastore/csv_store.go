@@ -1,22 +1,54 @@
 package datastore
 
 import (
+	"bufio"
 	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"kcas/new/internal/number"
 )
 
 // CSVDataStore implements DataStore interface for CSV-based storage
 type CSVDataStore struct {
-	provider    MarketDataProvider
+	// The provider, swapped by failovers while the refreshes, the APIs and
+	// the forecast read it
+	providerMu sync.RWMutex
+	provider   MarketDataProvider
+
+	// The current data, written by the refreshes and read by the adjustment
+	// cycle, the APIs and the forecast
+	mu          sync.RWMutex
 	currentData []MarketDataPoint
-	maxVolume   float64 // Cached maximum volume for the current day
-	avgVolume   float64 // Cached average volume for the current day
-	logger      *log.Logger
+	periodIndex map[string]int // Position of each period in currentData
+	maxVolume   float64        // Cached maximum volume for the current day
+	avgVolume   float64        // Cached average volume for the current day
+	lastUpdate  time.Time
+	dataDate    time.Time // Market day of the current data, zero if none is loaded
+
+	dir       string         // Directory of the CSV files, empty for the working directory
+	location  *time.Location // Market timezone of the daily files, nil for the date's own
+	currency  string         // Currency of the prices, empty for EUR
+	fallback  int            // Previous days searched for stored data when a day cannot be fetched
+	smoothing int            // Periods of the moving average applied to the volumes, 0 for none
+	logger    *log.Logger
+
+	statusMu    sync.RWMutex
+	fetchStatus FetchStatus
+
+	// The days a provider failed to serve on a backfill, not requested again
+	// until the backfill ends on a later market day
+	unservedMu sync.Mutex
+	unservedTo string          // Last day of the backfill the days were found on
+	unserved   map[string]bool // By provider and day
 }
 
 // NewCSVDataStore creates a new CSV-based data store
@@ -24,31 +56,88 @@ func NewCSVDataStore(logger *log.Logger) *CSVDataStore {
 	return &CSVDataStore{
 		logger:      logger,
 		currentData: make([]MarketDataPoint, 0),
+		fallback:    1,
 	}
 }
 
 // SetProvider sets the market data provider
 func (ds *CSVDataStore) SetProvider(provider MarketDataProvider) {
+	ds.providerMu.Lock()
+	defer ds.providerMu.Unlock()
 	ds.provider = provider
 }
 
+// currentProvider returns the market data provider, nil if none is set
+func (ds *CSVDataStore) currentProvider() MarketDataProvider {
+	ds.providerMu.RLock()
+	defer ds.providerMu.RUnlock()
+	return ds.provider
+}
+
+// SetDirectory sets the directory holding the CSV files
+func (ds *CSVDataStore) SetDirectory(dir string) {
+	ds.dir = dir
+}
+
+// SetLocation sets the market timezone deciding which day a date belongs to
+func (ds *CSVDataStore) SetLocation(loc *time.Location) {
+	ds.location = loc
+}
+
+// SetCurrency sets the currency of the stored prices, written in the CSV header
+func (ds *CSVDataStore) SetCurrency(currency string) {
+	ds.currency = currency
+}
+
+// SetFallbackDays sets how many previous days are searched for stored data
+// when the requested day cannot be fetched, 0 disabling the fallback
+func (ds *CSVDataStore) SetFallbackDays(days int) {
+	ds.fallback = days
+}
+
+// SetSmoothingWindow sets the number of periods of the centered moving
+// average applied to the volumes of the current and read data; the stored
+// files keep the published volumes
+func (ds *CSVDataStore) SetSmoothingWindow(periods int) {
+	ds.smoothing = periods
+}
+
+// marketDate converts a date to the market timezone
+func (ds *CSVDataStore) marketDate(date time.Time) time.Time {
+	if ds.location != nil {
+		return date.In(ds.location)
+	}
+	return date
+}
+
+// dataPath returns the CSV file of the given date for a provider
+func (ds *CSVDataStore) dataPath(provider MarketDataProvider, date time.Time) string {
+	return filepath.Join(ds.dir, provider.GetDataPath(ds.marketDate(date)))
+}
+
 // LoadData loads market data for the given date
-func (ds *CSVDataStore) LoadData(date time.Time) ([]MarketDataPoint, error) {
-	if ds.provider == nil {
+func (ds *CSVDataStore) LoadData(ctx context.Context, date time.Time) ([]MarketDataPoint, error) {
+	provider := ds.currentProvider()
+	if provider == nil {
 		return nil, fmt.Errorf("no market data provider set")
 	}
 
-	filePath := ds.provider.GetDataPath(date)
+	filePath := ds.dataPath(provider, date)
+	dataDate := ds.marketDate(date)
 
 	// Check if file exists, if not try to generate it
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		ds.logger.Printf("Data file %s not found, attempting to generate...", filePath)
-		if err := ds.RefreshData(context.Background(), date); err != nil {
+		if err := ds.RefreshData(ctx, date); err != nil {
 			ds.logger.Printf("Failed to generate data: %v", err)
-			// Try yesterday's file as fallback
-			yesterday := date.AddDate(0, 0, -1)
-			filePath = ds.provider.GetDataPath(yesterday)
-			ds.logger.Printf("Trying fallback file: %s", filePath)
+			// Use a previous day's data until the day's can be fetched
+			fallback, ok := ds.fallbackDate(dataDate)
+			if !ok {
+				return nil, fmt.Errorf("no data for %s, nor stored for the %d previous days: %w", dataDate.Format("2006-01-02"), ds.fallback, err)
+			}
+			dataDate = fallback
+			filePath = ds.dataPath(provider, dataDate)
+			ds.logger.Printf("⚠️  Falling back to the stale data of %s from %s", dataDate.Format("2006-01-02"), filePath)
 		}
 	}
 
@@ -57,135 +146,414 @@ func (ds *CSVDataStore) LoadData(date time.Time) ([]MarketDataPoint, error) {
 		return nil, fmt.Errorf("failed to load data from %s: %w", filePath, err)
 	}
 
-	ds.currentData = data
-	ds.updateVolumeMetrics(data)
-	return data, nil
+	current := ds.setCurrent(data, dataDate)
+	return append([]MarketDataPoint(nil), current...), nil
+}
+
+// fallbackDate returns the stored day standing in for a day that cannot be
+// fetched: the same weekday of the previous weeks first, as the market
+// follows the week, then the most recent days
+func (ds *CSVDataStore) fallbackDate(date time.Time) (time.Time, bool) {
+	var candidates []int
+	for age := 7; age <= ds.fallback; age += 7 {
+		candidates = append(candidates, age)
+	}
+	for age := 1; age <= ds.fallback; age++ {
+		if age%7 != 0 {
+			candidates = append(candidates, age)
+		}
+	}
+
+	for _, age := range candidates {
+		if day := date.AddDate(0, 0, -age); ds.HasData(day) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // SaveData saves market data to CSV file
 func (ds *CSVDataStore) SaveData(date time.Time, data []MarketDataPoint) error {
-	if ds.provider == nil {
+	provider := ds.currentProvider()
+	if provider == nil {
 		return fmt.Errorf("no market data provider set")
 	}
 
-	filePath := ds.provider.GetDataPath(date)
+	filePath := ds.dataPath(provider, date)
 	if err := ds.saveToCSV(filePath, data); err != nil {
 		return err
 	}
 
 	// Update internal state after successful save
-	ds.currentData = data
-	ds.updateVolumeMetrics(data)
+	ds.setCurrent(data, ds.marketDate(date))
 
 	return nil
 }
 
-// GetCurrentData returns the currently loaded data
+// GetCurrentData returns a copy of the currently loaded data
 func (ds *CSVDataStore) GetCurrentData() []MarketDataPoint {
-	return ds.currentData
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return append([]MarketDataPoint(nil), ds.currentData...)
+}
+
+// PeriodIndex returns the position of a period in the current data, -1 if
+// it has no data point
+func (ds *CSVDataStore) PeriodIndex(period string) int {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if i, ok := ds.periodIndex[period]; ok {
+		return i
+	}
+	return -1
+}
+
+// CurrentPoint returns the data point of a period in the current data
+func (ds *CSVDataStore) CurrentPoint(period string) (MarketDataPoint, bool) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	data := ds.currentData
+	if i, ok := ds.periodIndex[period]; ok && i < len(data) && data[i].Period == period {
+		return data[i], true
+	}
+	return MarketDataPoint{}, false
 }
 
 // GetMaxVolume returns the cached maximum volume for the current day
 func (ds *CSVDataStore) GetMaxVolume() float64 {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
 	return ds.maxVolume
 }
 
+// GetDataDate returns the market day of the current data, zero if none is loaded
+func (ds *CSVDataStore) GetDataDate() time.Time {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return ds.dataDate
+}
+
+// GetLastUpdate returns when the current data was last loaded or refreshed
+func (ds *CSVDataStore) GetLastUpdate() time.Time {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return ds.lastUpdate
+}
+
+// GetFetchStatus returns the outcome of recent provider fetches
+func (ds *CSVDataStore) GetFetchStatus() FetchStatus {
+	ds.statusMu.RLock()
+	defer ds.statusMu.RUnlock()
+
+	status := ds.fetchStatus
+	if provider := ds.currentProvider(); provider != nil {
+		status.Provider = provider.GetName()
+	}
+	return status
+}
+
 // GetAvgVolume returns the cached average volume for the current day
 func (ds *CSVDataStore) GetAvgVolume() float64 {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
 	return ds.avgVolume
 }
 
 // GetReferenceVolume returns either max or average volume based on mode
 func (ds *CSVDataStore) GetReferenceVolume(mode string) float64 {
 	switch mode {
+	case "percent":
+		return PercentReference
 	case "average":
-		return ds.avgVolume
+		return ds.GetAvgVolume()
 	case "max":
 		fallthrough
 	default:
-		return ds.maxVolume
+		return ds.GetMaxVolume()
 	}
 }
 
 // RefreshData refreshes data for the given date by fetching from provider
 func (ds *CSVDataStore) RefreshData(ctx context.Context, date time.Time) error {
-	if ds.provider == nil {
-		ds.logger.Printf("❌ No market data provider set for refresh operation")
+	data, err := ds.fetch(ctx, ds.currentProvider(), date)
+	if err != nil {
+		return err
+	}
+
+	ds.logger.Printf("💾 Saving fetched data to CSV...")
+	if err := ds.SaveData(date, data); err != nil {
+		ds.logger.Printf("❌ Failed to save data: %v", err)
+		return fmt.Errorf("failed to save data: %w", err)
+	}
+
+	ds.logger.Printf("✅ Successfully refreshed data for %s", date.Format("2006-01-02"))
+	return nil
+}
+
+// FetchData fetches the data of the given date from a provider, such as a
+// failover candidate, recording the outcome in the fetch status without
+// storing the data
+func (ds *CSVDataStore) FetchData(ctx context.Context, provider MarketDataProvider, date time.Time) ([]MarketDataPoint, error) {
+	return ds.fetch(ctx, provider, date)
+}
+
+// PrefetchData fetches and stores data for the given date, e.g. the next day
+// once published, leaving the current data unchanged
+func (ds *CSVDataStore) PrefetchData(ctx context.Context, date time.Time) error {
+	provider := ds.currentProvider()
+	data, err := ds.fetch(ctx, provider, date)
+	if err != nil {
+		return err
+	}
+
+	filePath := ds.dataPath(provider, date)
+	if err := ds.saveToCSV(filePath, data); err != nil {
+		ds.logger.Printf("❌ Failed to save prefetched data: %v", err)
+		return fmt.Errorf("failed to save data: %w", err)
+	}
+
+	ds.logger.Printf("✅ Prefetched data for %s into %s", date.Format("2006-01-02"), filePath)
+	return nil
+}
+
+// BackfillData fetches and stores the days from from to to that are not
+// stored yet, e.g. the days missed while the manager was down, leaving the
+// current data unchanged. Providers fetching ranges get the missing days in
+// one request, from the first to the last. A day the provider failed to
+// serve is not requested again until to moves on to a later day.
+func (ds *CSVDataStore) BackfillData(ctx context.Context, from, to time.Time) error {
+	provider := ds.currentProvider()
+	if provider == nil {
 		return fmt.Errorf("no market data provider set")
 	}
+	from, to = ds.midnight(from), ds.midnight(to)
+
+	var missing []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !ds.HasData(day) && !ds.isUnserved(provider, day, to) {
+			missing = append(missing, day)
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		err := ds.PrefetchData(ctx, missing[0])
+		if err != nil {
+			ds.markUnserved(provider, missing[0], to)
+		}
+		return err
+	}
+
+	first, last := missing[0], missing[len(missing)-1]
+	ds.logger.Printf("🔄 Backfilling %d missing days from %s to %s using provider '%s'...",
+		len(missing), first.Format("2006-01-02"), last.Format("2006-01-02"), provider.GetName())
+
+	startTime := time.Now()
+	days, err := FetchRange(ctx, provider, first, last)
+	ds.recordFetch(startTime, time.Since(startTime), err)
+
+	stored := 0
+	for _, day := range missing {
+		data := days[day.Format("2006-01-02")]
+		if len(data) == 0 {
+			ds.markUnserved(provider, day, to)
+			continue
+		}
+		if err := ds.saveToCSV(ds.dataPath(provider, day), data); err != nil {
+			return fmt.Errorf("failed to save data: %w", err)
+		}
+		stored++
+	}
+	ds.logger.Printf("✅ Backfilled %d of %d missing days", stored, len(missing))
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+	if stored < len(missing) {
+		return fmt.Errorf("no data retrieved from provider for %d of %d days", len(missing)-stored, len(missing))
+	}
+	return nil
+}
+
+// unservedKey returns the key of a provider's day in the unserved days
+func (ds *CSVDataStore) unservedKey(provider MarketDataProvider, day time.Time) string {
+	return provider.GetName() + " " + ds.marketDate(day).Format("2006-01-02")
+}
+
+// isUnserved reports whether the provider failed to serve the day on a
+// backfill ending on to
+func (ds *CSVDataStore) isUnserved(provider MarketDataProvider, day, to time.Time) bool {
+	ds.unservedMu.Lock()
+	defer ds.unservedMu.Unlock()
+	return ds.unservedDays(to)[ds.unservedKey(provider, day)]
+}
+
+// markUnserved records that the provider failed to serve the day on a
+// backfill ending on to
+func (ds *CSVDataStore) markUnserved(provider MarketDataProvider, day, to time.Time) {
+	ds.unservedMu.Lock()
+	defer ds.unservedMu.Unlock()
+	ds.unservedDays(to)[ds.unservedKey(provider, day)] = true
+}
+
+// unservedDays returns the days unserved on backfills ending on to, those of
+// earlier backfills being forgotten. The caller holds unservedMu.
+func (ds *CSVDataStore) unservedDays(to time.Time) map[string]bool {
+	if end := to.Format("2006-01-02"); ds.unserved == nil || ds.unservedTo != end {
+		ds.unserved, ds.unservedTo = make(map[string]bool), end
+	}
+	return ds.unserved
+}
+
+// midnight returns the start of the market day of a date
+func (ds *CSVDataStore) midnight(date time.Time) time.Time {
+	date = ds.marketDate(date)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
+
+// ApplyPush stores the data of a day updated by a push provider. Today's
+// data replaces the current data, even when a previous day stands in for it,
+// and the data of other days is stored for later.
+func (ds *CSVDataStore) ApplyPush(ctx context.Context, date, today time.Time) error {
+	if ds.marketDate(date).Format("2006-01-02") == ds.marketDate(today).Format("2006-01-02") {
+		return ds.RefreshData(ctx, date)
+	}
+	return ds.PrefetchData(ctx, date)
+}
+
+// ReadData returns the stored data of the given date, without fetching it or
+// changing the current data
+func (ds *CSVDataStore) ReadData(date time.Time) ([]MarketDataPoint, error) {
+	provider := ds.currentProvider()
+	if provider == nil {
+		return nil, fmt.Errorf("no market data provider set")
+	}
+	data, err := ds.loadFromCSV(ds.dataPath(provider, date))
+	if err != nil {
+		return nil, err
+	}
+	return Smooth(data, ds.smoothing), nil
+}
+
+// HasData reports whether the CSV file for the given date exists
+func (ds *CSVDataStore) HasData(date time.Time) bool {
+	provider := ds.currentProvider()
+	if provider == nil {
+		return false
+	}
+	_, err := os.Stat(ds.dataPath(provider, date))
+	return err == nil
+}
+
+// fetch retrieves data for the given date from a provider and records the outcome
+func (ds *CSVDataStore) fetch(ctx context.Context, provider MarketDataProvider, date time.Time) ([]MarketDataPoint, error) {
+	if provider == nil {
+		ds.logger.Printf("❌ No market data provider set for refresh operation")
+		return nil, fmt.Errorf("no market data provider set")
+	}
+	date = ds.marketDate(date)
 
 	ds.logger.Printf("🔄 Refreshing market data for %s using provider '%s'...",
-		date.Format("2006-01-02"), ds.provider.GetName())
+		date.Format("2006-01-02"), provider.GetName())
 
 	startTime := time.Now()
-	data, err := ds.provider.FetchData(ctx, date)
+	data, err := provider.FetchData(ctx, date)
 	fetchDuration := time.Since(startTime)
 
 	if err != nil {
 		ds.logger.Printf("❌ Failed to fetch data from provider '%s' after %v: %v",
-			ds.provider.GetName(), fetchDuration, err)
-		return fmt.Errorf("failed to fetch data: %w", err)
+			provider.GetName(), fetchDuration, err)
+		ds.recordFetch(startTime, fetchDuration, err)
+		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
 	if len(data) == 0 {
-		ds.logger.Printf("❌ No data retrieved from provider '%s'", ds.provider.GetName())
-		return fmt.Errorf("no data retrieved from provider")
+		ds.logger.Printf("❌ No data retrieved from provider '%s'", provider.GetName())
+		err := fmt.Errorf("no data retrieved from provider")
+		ds.recordFetch(startTime, fetchDuration, err)
+		return nil, err
 	}
+	ds.recordFetch(startTime, fetchDuration, nil)
 
 	ds.logger.Printf("✅ Successfully fetched %d data points from '%s' in %v",
-		len(data), ds.provider.GetName(), fetchDuration)
+		len(data), provider.GetName(), fetchDuration)
 
 	// Log sample of fetched data
-	if len(data) > 0 {
-		ds.logger.Printf("   📊 Sample fetched data:")
-		sampleCount := 3
-		if len(data) < sampleCount {
-			sampleCount = len(data)
-		}
-		for i := 0; i < sampleCount; i++ {
-			ds.logger.Printf("      %s: %.1f MWh @ %.2f €/MWh",
-				data[i].Period, data[i].Volume, data[i].Price)
-		}
-		if len(data) > sampleCount {
-			ds.logger.Printf("      ... and %d more data points", len(data)-sampleCount)
-		}
+	ds.logger.Printf("   📊 Sample fetched data:")
+	sampleCount := 3
+	if len(data) < sampleCount {
+		sampleCount = len(data)
 	}
-
-	ds.logger.Printf("💾 Saving fetched data to CSV...")
-	if err := ds.SaveData(date, data); err != nil {
-		ds.logger.Printf("❌ Failed to save data: %v", err)
-		return fmt.Errorf("failed to save data: %w", err)
+	for i := 0; i < sampleCount; i++ {
+		ds.logger.Printf("      %s: %.1f MWh @ %.2f %s",
+			data[i].Period, data[i].Volume, data[i].Price, PriceUnit(ds.currency))
+	}
+	if len(data) > sampleCount {
+		ds.logger.Printf("      ... and %d more data points", len(data)-sampleCount)
 	}
 
-	ds.currentData = data
-	ds.updateVolumeMetrics(data)
-	ds.logger.Printf("✅ Successfully refreshed data for %s", date.Format("2006-01-02"))
-	return nil
+	return data, nil
 }
 
-// updateVolumeMetrics calculates and caches the maximum and average volume from the dataset
-func (ds *CSVDataStore) updateVolumeMetrics(data []MarketDataPoint) {
-	ds.logger.Printf("📊 Calculating volume metrics from %d data points...", len(data))
+// recordFetch updates the fetch status after a provider call
+func (ds *CSVDataStore) recordFetch(startTime time.Time, duration time.Duration, err error) {
+	ds.statusMu.Lock()
+	defer ds.statusMu.Unlock()
 
-	ds.maxVolume = 0.0
-	ds.avgVolume = 0.0
-	var maxVolumeTime string
-	var totalVolume float64
+	ds.fetchStatus.LastAttempt = startTime
+	ds.fetchStatus.LastDuration = duration
+	if err != nil {
+		ds.fetchStatus.LastError = err.Error()
+		ds.fetchStatus.ConsecutiveFailures++
+		ds.fetchStatus.TotalFailures++
+		return
+	}
+	ds.fetchStatus.TotalSuccesses++
+	ds.fetchStatus.LastSuccess = startTime
+	ds.fetchStatus.LastError = ""
+	ds.fetchStatus.ConsecutiveFailures = 0
+}
 
-	for _, point := range data {
+// setCurrent makes the smoothed data of a market day current, caching its
+// maximum and average volume and indexing its periods, and returns it. The
+// data is copied, so the caller's slice is never shared.
+func (ds *CSVDataStore) setCurrent(data []MarketDataPoint, date time.Time) []MarketDataPoint {
+	current := append([]MarketDataPoint(nil), Smooth(data, ds.smoothing)...)
+	ds.logger.Printf("📊 Calculating volume metrics from %d data points...", len(current))
+
+	index := make(map[string]int, len(current))
+	for i, point := range current {
+		if _, exists := index[point.Period]; !exists {
+			index[point.Period] = i
+		}
+	}
+
+	var maxVolume, avgVolume, totalVolume float64
+	var maxVolumeTime string
+	for _, point := range current {
 		totalVolume += point.Volume
-		if point.Volume > ds.maxVolume {
-			ds.maxVolume = point.Volume
+		if point.Volume > maxVolume {
+			maxVolume = point.Volume
 			maxVolumeTime = point.Period
 		}
 	}
-
-	if len(data) > 0 {
-		ds.avgVolume = totalVolume / float64(len(data))
+	if len(current) > 0 {
+		avgVolume = totalVolume / float64(len(current))
 	}
 
-	ds.logger.Printf("✅ Maximum volume calculated: %.1f MWh at period %s", ds.maxVolume, maxVolumeTime)
-	ds.logger.Printf("📊 Average volume calculated: %.1f MWh", ds.avgVolume)
+	ds.mu.Lock()
+	ds.currentData = current
+	ds.periodIndex = index
+	ds.dataDate = date
+	ds.lastUpdate = time.Now()
+	ds.maxVolume = maxVolume
+	ds.avgVolume = avgVolume
+	ds.mu.Unlock()
+
+	ds.logger.Printf("✅ Maximum volume calculated: %.1f MWh at period %s", maxVolume, maxVolumeTime)
+	ds.logger.Printf("📊 Average volume calculated: %.1f MWh", avgVolume)
+	return current
 }
 
 // loadFromCSV loads data from a CSV file
@@ -196,44 +564,93 @@ func (ds *CSVDataStore) loadFromCSV(filePath string) ([]MarketDataPoint, error)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
+	parsed, err := ParseCSV(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, skipped := range parsed.Skipped {
+		ds.logger.Printf("Warning: %s", skipped)
+	}
+	return parsed.Points, nil
+}
+
+// CSVData is the content of a market data CSV file
+type CSVData struct {
+	Header  []string
+	Points  []MarketDataPoint
+	Skipped []string // Why malformed lines were skipped
+}
+
+// ParseCSV parses market data in the format of the daily CSV files: a
+// header, then period, volume and price on every line
+func ParseCSV(r io.Reader) (CSVData, error) {
+	// Files written with a European spreadsheet separate fields with ';'
+	// and decimals with ','
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.ReadString('\n')
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(header), buffered))
+	if strings.Contains(header, ";") && !strings.Contains(header, ",") {
+		reader.Comma = ';'
+	}
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return CSVData{}, fmt.Errorf("failed to read CSV: %w", err)
 	}
 
 	if len(records) < 2 {
-		return nil, fmt.Errorf("CSV file has insufficient data")
+		return CSVData{}, fmt.Errorf("CSV file has insufficient data")
 	}
 
-	var data []MarketDataPoint
+	parse := number.Parse
+	if reader.Comma == ';' {
+		// A lone comma is a decimal comma there, "1,234" being 1.234
+		parse = number.ParseDecimalComma
+	}
+
+	parsed := CSVData{Header: records[0]}
 	// Skip header row
 	for i, record := range records[1:] {
 		if len(record) != 3 {
-			ds.logger.Printf("Warning: Skipping malformed record at line %d", i+2)
+			parsed.Skipped = append(parsed.Skipped, fmt.Sprintf("Skipping malformed record at line %d", i+2))
 			continue
 		}
 
-		volume, err := strconv.ParseFloat(record[1], 64)
+		volume, err := parse(record[1])
 		if err != nil {
-			ds.logger.Printf("Warning: Invalid volume at line %d: %v", i+2, err)
+			parsed.Skipped = append(parsed.Skipped, fmt.Sprintf("Invalid volume at line %d: %v", i+2, err))
 			continue
 		}
 
-		price, err := strconv.ParseFloat(record[2], 64)
+		price, err := parse(record[2])
 		if err != nil {
-			ds.logger.Printf("Warning: Invalid price at line %d: %v", i+2, err)
+			parsed.Skipped = append(parsed.Skipped, fmt.Sprintf("Invalid price at line %d: %v", i+2, err))
 			continue
 		}
 
-		data = append(data, MarketDataPoint{
+		parsed.Points = append(parsed.Points, MarketDataPoint{
 			Period: record[0],
 			Volume: volume,
 			Price:  price,
 		})
 	}
 
-	return data, nil
+	return parsed, nil
+}
+
+// CheckCSVHeader checks that a header names the period, volume and price
+// columns, in that order, as the daily CSV files do
+func CheckCSVHeader(header []string) error {
+	expected := []string{"period", "volume", "price"}
+	if len(header) != len(expected) {
+		return fmt.Errorf("CSV header has %d columns, expected Period, Volume and Price", len(header))
+	}
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !strings.HasPrefix(name, expected[i]) {
+			return fmt.Errorf("CSV column %d is %q, expected %s", i+1, column, expected[i])
+		}
+	}
+	return nil
 }
 
 // saveToCSV saves data to a CSV file
@@ -248,7 +665,7 @@ func (ds *CSVDataStore) saveToCSV(filePath string, data []MarketDataPoint) error
 	defer writer.Flush()
 
 	// Write header
-	header := []string{"Period", "Volume (MWh)", "Price (€/MWh)"}
+	header := []string{"Period", "Volume (MWh)", "Price (" + PriceUnit(ds.currency) + ")"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
@@ -7,9 +7,35 @@ import (
 
 // MarketDataPoint represents a single data point in the market data
 type MarketDataPoint struct {
-	Period string  `csv:"Period"`        // Time period (e.g., "00:00-00:15")
-	Volume float64 `csv:"Volume (MWh)"`  // Volume in MWh
-	Price  float64 `csv:"Price (€/MWh)"` // Price in €/MWh
+	Period string  `csv:"Period" json:"period"`               // Time period (e.g., "00:00-00:15")
+	Volume float64 `csv:"Volume (MWh)" json:"volume_mwh"`     // Volume in MWh
+	Price  float64 `csv:"Price (€/MWh)" json:"price_eur_mwh"` // Price per MWh in the configured currency, € by default
+}
+
+// currencySymbols are the symbols shown instead of common currency codes
+var currencySymbols = map[string]string{"EUR": "€", "GBP": "£", "USD": "$"}
+
+// PriceUnit returns the unit of prices in a currency, e.g. €/MWh or SEK/MWh
+func PriceUnit(currency string) string {
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol + "/MWh"
+	}
+	if currency == "" {
+		return "€/MWh"
+	}
+	return currency + "/MWh"
+}
+
+// FetchStatus describes the outcome of recent provider fetches
+type FetchStatus struct {
+	Provider            string        `json:"provider"`
+	LastAttempt         time.Time     `json:"last_attempt"`
+	LastSuccess         time.Time     `json:"last_success"`
+	LastError           string        `json:"last_error,omitempty"`
+	LastDuration        time.Duration `json:"last_duration_ns"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	TotalSuccesses      int           `json:"total_successes"`
+	TotalFailures       int           `json:"total_failures"`
 }
 
 // MarketDataProvider defines the interface for market data providers
@@ -24,10 +50,48 @@ type MarketDataProvider interface {
 	GetDataPath(date time.Time) string
 }
 
+// RefreshScheduler is implemented by providers that publish data on a known schedule
+type RefreshScheduler interface {
+	// DefaultRefreshCron returns a cron expression running after new data is published
+	DefaultRefreshCron() string
+}
+
+// IntradayProvider is implemented by providers publishing measurements
+// through the day, whose data for the current day is fetched again on every
+// refresh and which have nothing to prefetch for tomorrow
+type IntradayProvider interface {
+	// Intraday reports whether the data of the current day keeps changing
+	Intraday() bool
+}
+
+// PushProvider is implemented by providers receiving data as it is
+// published, e.g. over a message broker, rather than fetching it on request.
+// Their FetchData returns what has been received for a day.
+type PushProvider interface {
+	// Subscribe receives data until the context ends, sending the market day
+	// of every update; a nil channel means the provider does not push
+	Subscribe(ctx context.Context) (<-chan time.Time, error)
+}
+
+// RangeProvider is implemented by providers fetching several days in one
+// request, used to backfill missing days and prefetch tomorrow's
+type RangeProvider interface {
+	// FetchRange fetches the days from from to to, both included, keyed by
+	// market day (YYYY-MM-DD); days without data are left out
+	FetchRange(ctx context.Context, from, to time.Time) (map[string][]MarketDataPoint, error)
+}
+
+// ClockProvider is implemented by providers replaying recorded data on a
+// clock of their own, which the market periods then follow
+type ClockProvider interface {
+	// Clock returns the current time of the replay, nil for the system clock
+	Clock() func() time.Time
+}
+
 // DataStore manages market data storage and retrieval
 type DataStore interface {
-	// LoadData loads market data for the given date
-	LoadData(date time.Time) ([]MarketDataPoint, error)
+	// LoadData loads market data for the given date, fetching it if not stored
+	LoadData(ctx context.Context, date time.Time) ([]MarketDataPoint, error)
 
 	// SaveData saves market data to storage
 	SaveData(date time.Time, data []MarketDataPoint) error
@@ -35,12 +99,54 @@ type DataStore interface {
 	// GetCurrentData returns the currently loaded data
 	GetCurrentData() []MarketDataPoint
 
+	// PeriodIndex returns the position of a period in the current data, -1 if missing
+	PeriodIndex(period string) int
+
+	// CurrentPoint returns the data point of a period in the current data
+	CurrentPoint(period string) (MarketDataPoint, bool)
+
 	// GetMaxVolume returns the maximum volume for the current day
 	GetMaxVolume() float64
 
+	// GetReferenceVolume returns the volume matching the hardware maximum in a
+	// calculation mode: max, average or percent
+	GetReferenceVolume(mode string) float64
+
+	// GetLastUpdate returns when the current data was last loaded or refreshed
+	GetLastUpdate() time.Time
+
+	// GetDataDate returns the market day of the current data, which is the
+	// previous day when the requested day could not be fetched
+	GetDataDate() time.Time
+
+	// GetFetchStatus returns the outcome of recent provider fetches
+	GetFetchStatus() FetchStatus
+
 	// RefreshData refreshes data for the given date
 	RefreshData(ctx context.Context, date time.Time) error
 
+	// FetchData fetches the data of the given date from a provider, which
+	// need not be the store's, recording the outcome without storing the data
+	FetchData(ctx context.Context, provider MarketDataProvider, date time.Time) ([]MarketDataPoint, error)
+
+	// PrefetchData fetches and stores data for the given date without making it current
+	PrefetchData(ctx context.Context, date time.Time) error
+
+	// BackfillData fetches and stores the days from from to to that are not
+	// stored yet, without making them current. The days the provider failed
+	// to serve are skipped until to moves on to a later day.
+	BackfillData(ctx context.Context, from, to time.Time) error
+
+	// ApplyPush stores the data of a day updated by a push provider, making it
+	// current when it is today's
+	ApplyPush(ctx context.Context, date, today time.Time) error
+
+	// ReadData returns the stored data of the given date without making it current
+	ReadData(date time.Time) ([]MarketDataPoint, error)
+
+	// HasData reports whether data for the given date is already stored
+	HasData(date time.Time) bool
+
 	// SetProvider sets the market data provider
 	SetProvider(provider MarketDataProvider)
 }
@@ -50,6 +156,9 @@ type PowerCalculator interface {
 	// CalculatePower calculates power for the current time
 	CalculatePower(maxSource float64, maxVolume float64, currentTime time.Time, data []MarketDataPoint) int64
 
+	// PowerForVolume calculates the power of a period from its volume
+	PowerForVolume(maxSource float64, referenceVolume float64, volume float64) int64
+
 	// GetCurrentPeriod returns the current market period
 	GetCurrentPeriod(currentTime time.Time) string
 }
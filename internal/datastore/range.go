@@ -0,0 +1,29 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FetchRange fetches the days from from to to, both included, in one request
+// when the provider fetches ranges, day by day otherwise. The days fetched
+// are returned along with the errors of the others.
+func FetchRange(ctx context.Context, provider MarketDataProvider, from, to time.Time) (map[string][]MarketDataPoint, error) {
+	if ranged, ok := provider.(RangeProvider); ok {
+		return ranged.FetchRange(ctx, from, to)
+	}
+
+	days := make(map[string][]MarketDataPoint)
+	var errs []error
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		data, err := provider.FetchData(ctx, day)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", day.Format("2006-01-02"), err))
+			continue
+		}
+		days[day.Format("2006-01-02")] = data
+	}
+	return days, errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+package datastore
+
+// Smooth returns the data with each volume replaced by the centered moving
+// average of the volumes of window periods, shrinking at the ends of the
+// day. Periods without volume are left at zero and out of their neighbours'
+// averages, so that they still count as missing. Prices are kept as
+// published. A window of 1 or less returns the data unchanged.
+func Smooth(data []MarketDataPoint, window int) []MarketDataPoint {
+	if window <= 1 || len(data) == 0 {
+		return data
+	}
+
+	half := window / 2
+	smoothed := make([]MarketDataPoint, len(data))
+	for i, point := range data {
+		smoothed[i] = point
+		if point.Volume == 0 {
+			continue
+		}
+
+		var sum float64
+		var count int
+		for j := max(0, i-half); j <= min(len(data)-1, i+half); j++ {
+			if data[j].Volume != 0 {
+				sum += data[j].Volume
+				count++
+			}
+		}
+		smoothed[i].Volume = sum / float64(count)
+	}
+	return smoothed
+}
@@ -0,0 +1,32 @@
+package datastore
+
+import "sort"
+
+// MedianPrice returns the median price of the data, 0 without data
+func MedianPrice(data []MarketDataPoint) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	prices := make([]float64, len(data))
+	for i, point := range data {
+		prices[i] = point.Price
+	}
+	sort.Float64s(prices)
+
+	middle := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return (prices[middle-1] + prices[middle]) / 2
+	}
+	return prices[middle]
+}
+
+// IsPriceSpike reports whether a price is a spike: above the absolute
+// threshold, or above factor times the median price of the day. A zero
+// threshold or factor disables that rule, and the relative rule needs a
+// positive median.
+func IsPriceSpike(price, median, threshold, factor float64) bool {
+	if threshold > 0 && price > threshold {
+		return true
+	}
+	return factor > 0 && median > 0 && price > factor*median
+}
@@ -0,0 +1,155 @@
+package errreport
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reporter sends errors and panics to an external error-tracking service
+type Reporter interface {
+	// CaptureError reports an error with contextual tags
+	CaptureError(err error, tags map[string]string)
+
+	// CapturePanic reports a recovered panic value with contextual tags
+	CapturePanic(value interface{}, tags map[string]string)
+
+	// Flush waits until buffered events are delivered or the timeout expires
+	Flush(timeout time.Duration)
+}
+
+// Options configures error reporting
+type Options struct {
+	Backend         string            // "sentry", "webhook" or "none"
+	DSN             string            // Sentry DSN
+	WebhookURL      string            // Generic JSON webhook URL
+	Environment     string            // Environment name attached to events
+	RepeatThreshold int               // Report an error again after this many consecutive repeats
+	Tags            map[string]string // Tags attached to every event (node, provider, domains...)
+}
+
+// New creates a reporter for the configured backend. Errors are
+// de-duplicated: an error is reported when first seen and then again every
+// RepeatThreshold consecutive occurrences.
+func New(opts Options, logger *log.Logger) (Reporter, error) {
+	var backend Reporter
+
+	switch strings.ToLower(opts.Backend) {
+	case "", "none":
+		return NopReporter{}, nil
+
+	case "sentry":
+		if opts.DSN == "" {
+			return nil, fmt.Errorf("sentry error reporting requires a DSN")
+		}
+		sentry, err := newSentryReporter(opts)
+		if err != nil {
+			return nil, err
+		}
+		backend = sentry
+
+	case "webhook":
+		if opts.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook error reporting requires a URL")
+		}
+		backend = newWebhookReporter(opts, logger)
+
+	default:
+		return nil, fmt.Errorf("unknown error reporting backend: %s. Supported backends: sentry, webhook, none", opts.Backend)
+	}
+
+	logger.Printf("🛰️  Error reporting enabled (backend: %s)", opts.Backend)
+	return &repeatFilter{
+		backend:   backend,
+		threshold: opts.RepeatThreshold,
+		baseTags:  opts.Tags,
+		counts:    make(map[string]int),
+	}, nil
+}
+
+// NopReporter discards all events
+type NopReporter struct{}
+
+// CaptureError does nothing
+func (NopReporter) CaptureError(err error, tags map[string]string) {}
+
+// CapturePanic does nothing
+func (NopReporter) CapturePanic(value interface{}, tags map[string]string) {}
+
+// Flush does nothing
+func (NopReporter) Flush(timeout time.Duration) {}
+
+// repeatFilter forwards new errors and every Nth repeat of a known error
+type repeatFilter struct {
+	mu        sync.Mutex
+	backend   Reporter
+	threshold int
+	baseTags  map[string]string
+	counts    map[string]int
+}
+
+// CaptureError reports err unless it is a recent repeat
+func (r *repeatFilter) CaptureError(err error, tags map[string]string) {
+	if err == nil {
+		return
+	}
+
+	key := err.Error()
+
+	r.mu.Lock()
+	r.counts[key]++
+	count := r.counts[key]
+	r.mu.Unlock()
+
+	if count > 1 && (r.threshold <= 0 || (count-1)%r.threshold != 0) {
+		return
+	}
+
+	merged := r.mergeTags(tags)
+	if count > 1 {
+		merged["repeat_count"] = fmt.Sprintf("%d", count)
+	}
+	r.backend.CaptureError(err, merged)
+}
+
+// CapturePanic always reports panics
+func (r *repeatFilter) CapturePanic(value interface{}, tags map[string]string) {
+	r.backend.CapturePanic(value, r.mergeTags(tags))
+}
+
+// Flush flushes the backend
+func (r *repeatFilter) Flush(timeout time.Duration) {
+	r.backend.Flush(timeout)
+}
+
+// Reset forgets all repeat counts, e.g. after a successful cycle
+func (r *repeatFilter) Reset() {
+	r.mu.Lock()
+	r.counts = make(map[string]int)
+	r.mu.Unlock()
+}
+
+func (r *repeatFilter) mergeTags(tags map[string]string) map[string]string {
+	merged := make(map[string]string, len(r.baseTags)+len(tags))
+	for k, v := range r.baseTags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Resetter is implemented by reporters that track repeated errors
+type Resetter interface {
+	Reset()
+}
+
+// ResetRepeats clears repeat tracking on reporters that support it
+func ResetRepeats(r Reporter) {
+	if resetter, ok := r.(Resetter); ok {
+		resetter.Reset()
+	}
+}
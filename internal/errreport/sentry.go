@@ -0,0 +1,50 @@
+package errreport
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// sentryReporter forwards events to Sentry
+type sentryReporter struct {
+	hub *sentry.Hub
+}
+
+// newSentryReporter initializes a dedicated Sentry client
+func newSentryReporter(opts Options) (*sentryReporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         opts.DSN,
+		Environment: opts.Environment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize sentry client: %w", err)
+	}
+
+	return &sentryReporter{
+		hub: sentry.NewHub(client, sentry.NewScope()),
+	}, nil
+}
+
+// CaptureError sends an error event with tags
+func (r *sentryReporter) CaptureError(err error, tags map[string]string) {
+	r.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		r.hub.CaptureException(err)
+	})
+}
+
+// CapturePanic sends a fatal event for a recovered panic
+func (r *sentryReporter) CapturePanic(value interface{}, tags map[string]string) {
+	r.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		scope.SetLevel(sentry.LevelFatal)
+		r.hub.Recover(value)
+	})
+}
+
+// Flush waits for queued events to be sent
+func (r *sentryReporter) Flush(timeout time.Duration) {
+	r.hub.Flush(timeout)
+}
@@ -0,0 +1,107 @@
+package errreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// webhookEvent is the JSON document posted to the webhook
+type webhookEvent struct {
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	Host        string            `json:"host,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Stack       string            `json:"stack,omitempty"`
+}
+
+// webhookReporter posts events as JSON to a generic HTTP endpoint
+type webhookReporter struct {
+	url         string
+	environment string
+	host        string
+	client      *http.Client
+	logger      *log.Logger
+	wg          sync.WaitGroup
+}
+
+// newWebhookReporter creates a reporter posting to the given URL
+func newWebhookReporter(opts Options, logger *log.Logger) *webhookReporter {
+	host, _ := os.Hostname()
+	return &webhookReporter{
+		url:         opts.WebhookURL,
+		environment: opts.Environment,
+		host:        host,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+	}
+}
+
+// CaptureError posts an error event asynchronously
+func (r *webhookReporter) CaptureError(err error, tags map[string]string) {
+	r.send(webhookEvent{
+		Level:   "error",
+		Message: err.Error(),
+		Tags:    tags,
+	})
+}
+
+// CapturePanic posts a fatal event including the stack trace
+func (r *webhookReporter) CapturePanic(value interface{}, tags map[string]string) {
+	r.send(webhookEvent{
+		Level:   "fatal",
+		Message: fmt.Sprintf("panic: %v", value),
+		Tags:    tags,
+		Stack:   string(debug.Stack()),
+	})
+}
+
+// Flush waits for in-flight posts, up to timeout
+func (r *webhookReporter) Flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (r *webhookReporter) send(event webhookEvent) {
+	event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	event.Environment = r.environment
+	event.Host = r.host
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Printf("⚠️  Failed to encode error report: %v", err)
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			r.logger.Printf("⚠️  Failed to send error report: %v", err)
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			r.logger.Printf("⚠️  Error report rejected with status: %d", resp.StatusCode)
+		}
+	}()
+}
@@ -0,0 +1,103 @@
+// Package features gates risky behaviours so they can ship disabled and be
+// enabled per fleet through the FEATURE_GATES setting.
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Feature names a gated behaviour
+type Feature string
+
+// Known features
+const (
+	GPUCapping Feature = "GPUCapping" // Cap GPU power alongside the RAPL domains
+	Eviction   Feature = "Eviction"   // Evict pods when the cap cannot be met
+	Taints     Feature = "Taints"     // Taint nodes running under a low cap
+	ClosedLoop Feature = "ClosedLoop" // Correct the cap from measured power
+)
+
+// defaults lists every known feature with its default state
+var defaults = map[Feature]bool{
+	GPUCapping: false,
+	Eviction:   false,
+	Taints:     false,
+	ClosedLoop: false,
+}
+
+// Gates holds the state of every known feature
+type Gates map[Feature]bool
+
+// Parse reads feature gates from a comma-separated list of Name=bool pairs
+// (e.g. "ClosedLoop=true,Taints=false") or from a JSON object, as produced by
+// a feature_gates mapping in the configuration file. Features not listed keep
+// their default state.
+func Parse(spec string) (Gates, error) {
+	gates := make(Gates, len(defaults))
+	for feature, enabled := range defaults {
+		gates[feature] = enabled
+	}
+
+	values := make(map[string]string)
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "{") {
+		if err := json.Unmarshal([]byte(spec), &values); err != nil {
+			return gates, fmt.Errorf("invalid JSON object of feature gates: %w", err)
+		}
+	} else {
+		for _, pair := range strings.Split(spec, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			name, value, found := strings.Cut(pair, "=")
+			if !found {
+				return gates, fmt.Errorf("invalid feature gate %q, expected Name=true or Name=false", pair)
+			}
+			values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	for name, value := range values {
+		feature := Feature(name)
+		if _, known := defaults[feature]; !known {
+			return gates, fmt.Errorf("unknown feature %q. Known features: %v", name, Known())
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return gates, fmt.Errorf("invalid value %q for feature %s, expected true or false", value, name)
+		}
+		gates[feature] = enabled
+	}
+	return gates, nil
+}
+
+// Enabled reports whether a feature is enabled
+func (g Gates) Enabled(feature Feature) bool {
+	if enabled, exists := g[feature]; exists {
+		return enabled
+	}
+	return defaults[feature]
+}
+
+// String formats the gates as sorted Name=bool pairs
+func (g Gates) String() string {
+	pairs := make([]string, 0, len(defaults))
+	for _, feature := range Known() {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", feature, g.Enabled(feature)))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Known returns the names of every known feature, sorted
+func Known() []Feature {
+	names := make([]Feature, 0, len(defaults))
+	for feature := range defaults {
+		names = append(names, feature)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
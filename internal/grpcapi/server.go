@@ -0,0 +1,292 @@
+// Package grpcapi serves the PowerCap gRPC control API defined in
+// proto/powercap/v1/powercap.proto.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"kcas/new/internal/power"
+	pb "kcas/new/pkg/api/powercapv1"
+)
+
+// Controller exposes the manager operations served over gRPC
+type Controller interface {
+	Status() power.Status
+	Schedule() ([]power.PlannedCap, error)
+	SetOverride(override power.Override) error
+	ClearOverride(clearedBy string) (power.Override, bool)
+	TriggerRefresh() error
+	SubscribeDecisions() (<-chan power.PowerDecision, func())
+}
+
+// clientKey is the context key of the authenticated client name
+type clientKey struct{}
+
+// Server implements the PowerCap service
+type Server struct {
+	pb.UnimplementedPowerCapServer
+
+	addr   string
+	ctrl   Controller
+	tokens map[string]string // Bearer tokens mapped to client names
+	logger *log.Logger
+
+	certFile, keyFile string // TLS certificate and key, empty for plaintext
+}
+
+// NewServer creates a gRPC server bound to addr, authenticating clients with tokens
+func NewServer(addr string, ctrl Controller, tokens map[string]string, logger *log.Logger) *Server {
+	return &Server{
+		addr:   addr,
+		ctrl:   ctrl,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+// EnableTLS serves the API over TLS with a certificate and its key, so that
+// bearer tokens are not sent in clear text
+func (s *Server) EnableTLS(certFile, keyFile string) {
+	s.certFile = certFile
+	s.keyFile = keyFile
+}
+
+// Run serves requests until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	serverOpts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.authenticateUnary),
+		grpc.StreamInterceptor(s.authenticateStream),
+	}
+	if s.certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("gRPC API failed to load its certificate: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("gRPC API failed to listen: %w", err)
+	}
+
+	server := grpc.NewServer(serverOpts...)
+	pb.RegisterPowerCapServer(server, s)
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	s.logger.Printf("🛰️  gRPC API listening on %s", s.addr)
+	if err := server.Serve(listener); err != nil {
+		return fmt.Errorf("gRPC API server failed: %w", err)
+	}
+	return nil
+}
+
+// authenticate returns a context carrying the client named by the bearer
+// token of the call metadata
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, found := strings.CutPrefix(value, "Bearer ")
+		if !found {
+			continue
+		}
+		for known, name := range s.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				return context.WithValue(ctx, clientKey{}, name), nil
+			}
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+func (s *Server) authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authenticateStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream is a stream whose context carries the authenticated client
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// clientName returns the authenticated client of a call
+func clientName(ctx context.Context) string {
+	name, _ := ctx.Value(clientKey{}).(string)
+	return name
+}
+
+// GetStatus returns the current state of the manager
+func (s *Server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.Status, error) {
+	st := s.ctrl.Status()
+	resp := &pb.Status{
+		Node:          st.Node,
+		Version:       st.Version,
+		StartedAt:     timestamppb.New(st.StartedAt),
+		AppliedCapUw:  st.AppliedCapUW,
+		DataPoints:    int32(st.Data.Points),
+		DataUpdatedAt: timestamp(st.Data.UpdatedAt),
+		Override:      overrideProto(st.Override),
+	}
+	if st.LastDecision != nil {
+		resp.LastDecision = decisionProto(*st.LastDecision)
+	}
+	return resp, nil
+}
+
+// GetSchedule returns the cap of every period of the current day
+func (s *Server) GetSchedule(ctx context.Context, req *pb.GetScheduleRequest) (*pb.Schedule, error) {
+	schedule, err := s.ctrl.Schedule()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &pb.Schedule{Periods: make([]*pb.PlannedCap, 0, len(schedule))}
+	for _, planned := range schedule {
+		resp.Periods = append(resp.Periods, &pb.PlannedCap{
+			Period:        planned.Period,
+			VolumeMwh:     planned.Volume,
+			PriceEurMwh:   planned.Price,
+			SourcePowerUw: planned.SourcePower,
+			CapUw:         planned.CapUW,
+			Clamp:         planned.Clamp,
+		})
+	}
+	return resp, nil
+}
+
+// SetOverride pins the cap until the override expires
+func (s *Server) SetOverride(ctx context.Context, req *pb.SetOverrideRequest) (*pb.Override, error) {
+	if req.GetDuration() == nil || req.GetDuration().AsDuration() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration must be positive")
+	}
+
+	now := time.Now()
+	override := power.Override{
+		PowerUW:   req.GetPowerUw(),
+		Disable:   req.GetDisable(),
+		Reason:    req.GetReason(),
+		SetBy:     clientName(ctx),
+		SetAt:     now,
+		ExpiresAt: now.Add(req.GetDuration().AsDuration()),
+	}
+	if err := s.ctrl.SetOverride(override); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return overrideProto(&override), nil
+}
+
+// ClearOverride removes the active override
+func (s *Server) ClearOverride(ctx context.Context, req *pb.ClearOverrideRequest) (*pb.Override, error) {
+	override, ok := s.ctrl.ClearOverride(clientName(ctx))
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no active override")
+	}
+	return overrideProto(&override), nil
+}
+
+// TriggerRefresh fetches today's market data again
+func (s *Server) TriggerRefresh(ctx context.Context, req *pb.TriggerRefreshRequest) (*pb.TriggerRefreshResponse, error) {
+	s.logger.Printf("🔄 Data refresh requested by %s over gRPC", clientName(ctx))
+	if err := s.ctrl.TriggerRefresh(); err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.TriggerRefreshResponse{}, nil
+}
+
+// StreamDecisions sends every decision until the client goes away
+func (s *Server) StreamDecisions(req *pb.StreamDecisionsRequest, stream pb.PowerCap_StreamDecisionsServer) error {
+	decisions, unsubscribe := s.ctrl.SubscribeDecisions()
+	defer unsubscribe()
+
+	for {
+		select {
+		case decision, ok := <-decisions:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(decisionProto(decision)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// decisionProto converts a decision to its protobuf form
+func decisionProto(d power.PowerDecision) *pb.Decision {
+	return &pb.Decision{
+		Timestamp:          timestamppb.New(d.Timestamp),
+		Node:               d.Node,
+		Provider:           d.Provider,
+		Period:             d.Period,
+		PeriodFound:        d.PeriodFound,
+		VolumeMwh:          d.Volume,
+		PriceEurMwh:        d.Price,
+		ReferenceVolumeMwh: d.ReferenceVolume,
+		HardwareMaxUw:      d.HardwareMax,
+		AdminMaxUw:         d.AdminMax,
+		MinPowerUw:         d.MinPower,
+		SourcePowerUw:      d.SourcePower,
+		AppliedPowerUw:     d.AppliedPower,
+		Clamp:              d.Clamp,
+		Fallbacks:          d.Fallbacks,
+		Override:           overrideProto(d.Override),
+	}
+}
+
+// overrideProto converts an override to its protobuf form, nil for none
+func overrideProto(o *power.Override) *pb.Override {
+	if o == nil {
+		return nil
+	}
+	return &pb.Override{
+		PowerUw:   o.PowerUW,
+		Disable:   o.Disable,
+		Reason:    o.Reason,
+		SetBy:     o.SetBy,
+		SetAt:     timestamppb.New(o.SetAt),
+		ExpiresAt: timestamppb.New(o.ExpiresAt),
+	}
+}
+
+// timestamp converts a time, leaving zero times unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
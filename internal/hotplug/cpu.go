@@ -0,0 +1,110 @@
+// Package hotplug takes CPU cores offline and back online through the
+// kernel's CPU hotplug files, /sys/devices/system/cpu/cpu<N>/online.
+package hotplug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BasePath is the sysfs directory of the CPUs
+const BasePath = "/sys/devices/system/cpu"
+
+// ParseCPUList reads a CPU list in the kernel format, e.g. "4-7,12", into
+// sorted CPU numbers. CPU 0 cannot be taken offline on most systems and is
+// rejected.
+func ParseCPUList(list string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(item, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || from < 0 {
+			return nil, fmt.Errorf("invalid CPU %q", item)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || to < from {
+				return nil, fmt.Errorf("invalid CPU range %q", item)
+			}
+		}
+		if from == 0 {
+			return nil, fmt.Errorf("CPU 0 cannot be taken offline")
+		}
+		for cpu := from; cpu <= to; cpu++ {
+			seen[cpu] = true
+		}
+	}
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// Offliner switches a set of CPUs offline and back online
+type Offliner struct {
+	root string // Directory holding the host's sys tree, empty for /
+	cpus []int  // CPUs switched
+}
+
+// NewOffliner creates an offliner of the given CPUs
+func NewOffliner(root string, cpus []int) *Offliner {
+	return &Offliner{root: root, cpus: cpus}
+}
+
+// CPUs returns the CPUs switched
+func (o *Offliner) CPUs() []int {
+	return o.cpus
+}
+
+// Offline takes the CPUs offline and returns those it switched, with an
+// error per CPU that could not be switched
+func (o *Offliner) Offline() ([]int, []error) {
+	return o.set(false)
+}
+
+// Online brings the CPUs back online and returns those it switched, with an
+// error per CPU that could not be switched
+func (o *Offliner) Online() ([]int, []error) {
+	return o.set(true)
+}
+
+// set writes the online state of every CPU not already in it
+func (o *Offliner) set(online bool) ([]int, []error) {
+	want := "0"
+	if online {
+		want = "1"
+	}
+	var switched []int
+	var errs []error
+	for _, cpu := range o.cpus {
+		path := o.path(cpu)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading state of cpu%d: %w", cpu, err))
+			continue
+		}
+		if strings.TrimSpace(string(data)) == want {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(want), 0644); err != nil {
+			errs = append(errs, fmt.Errorf("switching cpu%d online=%s: %w", cpu, want, err))
+			continue
+		}
+		switched = append(switched, cpu)
+	}
+	return switched, errs
+}
+
+// path returns the online file of a CPU
+func (o *Offliner) path(cpu int) string {
+	return filepath.Join(o.root, BasePath, fmt.Sprintf("cpu%d", cpu), "online")
+}
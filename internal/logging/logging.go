@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DecisionPrefix marks the one-line summary logged for every applied decision.
+// Quiet mode always lets these lines through.
+const DecisionPrefix = "📋 Decision:"
+
+// timestampLayout mirrors log.LstdFlags|log.Lmicroseconds
+const timestampLayout = "2006/01/02 15:04:05.000000"
+
+// importantMarkers identify error and warning lines kept in quiet mode
+var importantMarkers = []string{"❌", "⚠️", "Warning", "Failed", "failed", "Error", "error"}
+
+// Options configures the log output pipeline
+type Options struct {
+	Prefix      string         // Prefix written before each line (e.g. "[PowerManager] ")
+	Quiet       bool           // Only log decisions, warnings and errors
+	DedupWindow time.Duration  // Suppress identical messages seen within this window (0 disables)
+	FilePath    string         // Optional file receiving a copy of the output
+	MaxSizeMB   int            // Rotate the file once it grows beyond this size
+	MaxBackups  int            // Number of rotated files to keep
+	Location    *time.Location // Timezone of the timestamps (nil for the system timezone)
+}
+
+// dedupEntry tracks a recently logged message
+type dedupEntry struct {
+	lastSeen   time.Time
+	suppressed int
+}
+
+// Writer filters, de-duplicates and formats log messages before writing them out
+type Writer struct {
+	mu      sync.Mutex
+	opts    Options
+	out     io.Writer
+	file    *rotatingFile
+	seen    map[string]*dedupEntry
+	nowFunc func() time.Time
+}
+
+// New creates a logger whose output goes through the filtering pipeline.
+// The returned Writer must be closed to release the log file, if any.
+func New(opts Options) (*log.Logger, *Writer, error) {
+	w := &Writer{
+		opts:    opts,
+		out:     os.Stdout,
+		seen:    make(map[string]*dedupEntry),
+		nowFunc: time.Now,
+	}
+
+	if opts.FilePath != "" {
+		file, err := newRotatingFile(opts.FilePath, int64(opts.MaxSizeMB)*1024*1024, opts.MaxBackups)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w.file = file
+		w.out = io.MultiWriter(os.Stdout, file)
+	}
+
+	// Prefix and timestamp are added by the Writer so that de-duplication
+	// compares the bare message
+	return log.New(w, "", 0), w, nil
+}
+
+// Write implements io.Writer for a single formatted log message
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.opts.Quiet && !isImportant(msg) {
+		return len(p), nil
+	}
+
+	now := w.nowFunc()
+	if w.opts.DedupWindow > 0 {
+		w.pruneSeen(now)
+
+		if entry, ok := w.seen[msg]; ok {
+			if now.Sub(entry.lastSeen) < w.opts.DedupWindow {
+				entry.suppressed++
+				return len(p), nil
+			}
+		}
+		w.seen[msg] = &dedupEntry{lastSeen: now}
+	}
+
+	if err := w.writeLine(now, msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes suppression summaries and closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.nowFunc()
+	for msg, entry := range w.seen {
+		if entry.suppressed > 0 {
+			w.writeSuppressed(now, msg, entry.suppressed)
+		}
+	}
+	w.seen = make(map[string]*dedupEntry)
+
+	if w.file != nil {
+		return w.file.Close()
+	}
+	return nil
+}
+
+// pruneSeen drops expired entries, reporting how many repeats were hidden
+func (w *Writer) pruneSeen(now time.Time) {
+	for msg, entry := range w.seen {
+		if now.Sub(entry.lastSeen) < w.opts.DedupWindow {
+			continue
+		}
+		if entry.suppressed > 0 {
+			w.writeSuppressed(now, msg, entry.suppressed)
+		}
+		delete(w.seen, msg)
+	}
+}
+
+func (w *Writer) writeSuppressed(now time.Time, msg string, count int) {
+	w.writeLine(now, fmt.Sprintf("🔇 Suppressed %d repeat(s) of: %s", count, msg))
+}
+
+func (w *Writer) writeLine(now time.Time, msg string) error {
+	if w.opts.Location != nil {
+		now = now.In(w.opts.Location)
+	}
+	line := fmt.Sprintf("%s%s %s\n", w.opts.Prefix, now.Format(timestampLayout), msg)
+	_, err := io.WriteString(w.out, line)
+	return err
+}
+
+// isImportant reports whether a message must be kept in quiet mode
+func isImportant(msg string) bool {
+	if strings.Contains(msg, DecisionPrefix) {
+		return true
+	}
+	for _, marker := range importantMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
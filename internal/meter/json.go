@@ -0,0 +1,101 @@
+package meter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// jsonMeter reads the power, in W, from a field of a JSON document served
+// over HTTP, e.g. the REST API of a PDU or a home-automation bridge
+type jsonMeter struct {
+	url    string
+	name   string // URL without credentials
+	field  []string
+	client *http.Client
+}
+
+func newJSON(u *url.URL) (*jsonMeter, error) {
+	if u.Fragment == "" {
+		return nil, fmt.Errorf("missing JSON field in meter address, e.g. http://host/status#power")
+	}
+	field := strings.Split(u.Fragment, ".")
+
+	target := *u
+	target.Fragment = ""
+	return &jsonMeter{url: target.String(), name: target.Redacted(), field: field, client: &http.Client{}}, nil
+}
+
+func (j *jsonMeter) Name() string {
+	return j.name + " (" + strings.Join(j.field, ".") + ")"
+}
+
+func (j *jsonMeter) ReadPower(ctx context.Context) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("meter request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return 0, fmt.Errorf("%s returned %s: %s", j.name, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var document interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return 0, fmt.Errorf("invalid JSON from %s: %w", j.name, err)
+	}
+	value, err := Lookup(document, j.field)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", j.Name(), err)
+	}
+	return watts(value), nil
+}
+
+// Lookup follows a path of object keys and array indexes in a decoded JSON
+// document to a number, given as a JSON number or a numeric string
+func Lookup(document interface{}, path []string) (float64, error) {
+	current := document
+	for _, key := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return 0, fmt.Errorf("no field %q", key)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return 0, fmt.Errorf("no element %q in an array of %d", key, len(node))
+			}
+			current = node[index]
+		default:
+			return 0, fmt.Errorf("cannot look up %q in a %T", key, current)
+		}
+	}
+
+	switch value := current.(type) {
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	default:
+		return 0, fmt.Errorf("value is a %T, not a number", current)
+	}
+}
@@ -0,0 +1,81 @@
+// Package meter reads power from an external meter: a smart plug (Shelly,
+// TP-Link Kasa), a smart PDU outlet over SNMP, a Modbus TCP energy meter,
+// the P1 port of a DSMR smart meter or an HTTP endpoint returning JSON.
+package meter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readTimeout bounds a reading when the context has no deadline
+const readTimeout = 5 * time.Second
+
+// Meter measures the power drawn by the node at the wall
+type Meter interface {
+	// Name describes the meter for logs, without credentials
+	Name() string
+	// ReadPower returns the current power in µW
+	ReadPower(ctx context.Context) (int64, error)
+}
+
+// New creates the meter of an address:
+//
+//	shelly://[user:pass@]host[/channel]            Shelly plug or relay (Gen1 or Gen2+)
+//	tplink://host[:port][/child-id]                TP-Link Kasa plug or strip outlet
+//	snmp://community@host[:port]/oid[?scale=10]    Smart PDU outlet (SNMPv2c)
+//	modbus://host[:port]/register[?unit=1&type=float32&scale=1000&input=true&swap=true]
+//	                                               Modbus TCP energy meter
+//	p1://host[:port]                               DSMR smart meter P1 port, through a TCP bridge
+//	http(s)://host/path#field.path                 JSON endpoint, value in W
+//
+// scale multiplies the raw SNMP or Modbus value to get watts, e.g. 0.1 for
+// deciwatts or 1000 for kW. Modbus values are int32 by default, high word
+// first unless swap is set.
+func New(addr string) (Meter, error) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid meter address %q", addr)
+	}
+	path := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "shelly":
+		channel := 0
+		if path != "" {
+			if channel, err = strconv.Atoi(path); err != nil || channel < 0 {
+				return nil, fmt.Errorf("invalid Shelly channel %q", path)
+			}
+		}
+		return newShelly(u, channel), nil
+	case "tplink":
+		return newTPLink(u, path), nil
+	case "snmp":
+		return newSNMP(u, path)
+	case "modbus":
+		return newModbus(u, path)
+	case "p1":
+		return newP1(u), nil
+	case "http", "https":
+		return newJSON(u)
+	default:
+		return nil, fmt.Errorf("unsupported meter scheme %q, expected shelly, tplink, snmp, modbus, p1, http or https", u.Scheme)
+	}
+}
+
+// withTimeout bounds a reading by readTimeout unless ctx has a deadline
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, readTimeout)
+}
+
+// watts converts a power in W to µW
+func watts(w float64) int64 {
+	return int64(w * 1000000)
+}
@@ -0,0 +1,168 @@
+package meter
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+)
+
+// Modbus function codes of register reads
+const (
+	modbusReadHoldingRegisters = 0x03
+	modbusReadInputRegisters   = 0x04
+)
+
+// modbusTransaction numbers the Modbus requests
+var modbusTransaction atomic.Uint32
+
+// modbusMeter reads the power from the registers of a Modbus TCP energy
+// meter, such as the site meter of a building
+type modbusMeter struct {
+	addr     string
+	unitID   byte
+	register uint16
+	input    bool    // Input registers (function 4) instead of holding registers
+	format   string  // int16, uint16, int32, uint32 or float32
+	swap     bool    // Low word first for 32-bit values
+	scale    float64 // Multiplier from the raw value to W
+}
+
+func newModbus(u *url.URL, path string) (*modbusMeter, error) {
+	register, err := strconv.ParseUint(path, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Modbus register %q in meter address", path)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "502"
+	}
+	m := &modbusMeter{addr: net.JoinHostPort(u.Hostname(), port), unitID: 1, register: uint16(register), format: "int32", scale: 1}
+
+	query := u.Query()
+	if value := query.Get("unit"); value != "" {
+		unit, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Modbus unit %q in meter address", value)
+		}
+		m.unitID = byte(unit)
+	}
+	if value := query.Get("type"); value != "" {
+		switch value {
+		case "int16", "uint16", "int32", "uint32", "float32":
+			m.format = value
+		default:
+			return nil, fmt.Errorf("invalid Modbus type %q, expected int16, uint16, int32, uint32 or float32", value)
+		}
+	}
+	if value := query.Get("scale"); value != "" {
+		if m.scale, err = strconv.ParseFloat(value, 64); err != nil || m.scale <= 0 {
+			return nil, fmt.Errorf("invalid scale %q in meter address", value)
+		}
+	}
+	m.input = query.Get("input") == "true"
+	m.swap = query.Get("swap") == "true"
+	return m, nil
+}
+
+func (m *modbusMeter) Name() string {
+	return fmt.Sprintf("Modbus %s unit %d register %d", m.addr, m.unitID, m.register)
+}
+
+func (m *modbusMeter) ReadPower(ctx context.Context) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	count := uint16(2)
+	if m.format == "int16" || m.format == "uint16" {
+		count = 1
+	}
+	registers, err := m.readRegisters(ctx, count)
+	if err != nil {
+		return 0, err
+	}
+
+	var raw float64
+	switch m.format {
+	case "int16":
+		raw = float64(int16(registers[0]))
+	case "uint16":
+		raw = float64(registers[0])
+	default:
+		high, low := registers[0], registers[1]
+		if m.swap {
+			high, low = low, high
+		}
+		bits := uint32(high)<<16 | uint32(low)
+		switch m.format {
+		case "int32":
+			raw = float64(int32(bits))
+		case "uint32":
+			raw = float64(bits)
+		case "float32":
+			raw = float64(math.Float32frombits(bits))
+		}
+	}
+	return watts(raw * m.scale), nil
+}
+
+// readRegisters reads count registers starting at the meter register over
+// Modbus TCP
+func (m *modbusMeter) readRegisters(ctx context.Context, count uint16) ([]uint16, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", m.Name(), err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	function := byte(modbusReadHoldingRegisters)
+	if m.input {
+		function = modbusReadInputRegisters
+	}
+	// MBAP header (transaction, protocol 0, length, unit) and the request PDU
+	id := uint16(modbusTransaction.Add(1))
+	request := make([]byte, 12)
+	binary.BigEndian.PutUint16(request[0:2], id)
+	binary.BigEndian.PutUint16(request[4:6], 6)
+	request[6] = m.unitID
+	request[7] = function
+	binary.BigEndian.PutUint16(request[8:10], m.register)
+	binary.BigEndian.PutUint16(request[10:12], count)
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("failed to send to %s: %w", m.Name(), err)
+	}
+
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, fmt.Errorf("failed to read from %s: %w", m.Name(), err)
+	}
+	length := binary.BigEndian.Uint16(header[4:6])
+	if binary.BigEndian.Uint16(header[0:2]) != id || length < 2 || length > 254 {
+		return nil, fmt.Errorf("invalid response from %s", m.Name())
+	}
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(conn, pdu); err != nil {
+		return nil, fmt.Errorf("failed to read from %s: %w", m.Name(), err)
+	}
+
+	switch {
+	case pdu[0] == function|0x80 && len(pdu) == 2:
+		return nil, fmt.Errorf("%s returned exception %d", m.Name(), pdu[1])
+	case pdu[0] != function || len(pdu) != 2+2*int(count) || int(pdu[1]) != 2*int(count):
+		return nil, fmt.Errorf("unexpected response from %s", m.Name())
+	}
+	values := make([]uint16, count)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(pdu[2+2*i:])
+	}
+	return values, nil
+}
@@ -0,0 +1,51 @@
+package meter
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Monitor polls a meter and passes every reading on
+type Monitor struct {
+	meter     Meter
+	interval  time.Duration
+	onReading func(power int64)
+	logger    *log.Logger
+}
+
+// NewMonitor creates a monitor calling onReading with every power read, in µW
+func NewMonitor(m Meter, interval time.Duration, onReading func(power int64), logger *log.Logger) *Monitor {
+	return &Monitor{meter: m, interval: interval, onReading: onReading, logger: logger}
+}
+
+// Run polls the meter until the context is cancelled. Failed reads are
+// logged once until the meter answers again.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failing := false
+	for {
+		power, err := m.meter.ReadPower(ctx)
+		switch {
+		case err != nil:
+			if !failing && ctx.Err() == nil {
+				m.logger.Printf("⚠️  Failed to read %s: %v", m.meter.Name(), err)
+				failing = true
+			}
+		default:
+			if failing {
+				m.logger.Printf("✅ %s readable again", m.meter.Name())
+				failing = false
+			}
+			m.onReading(power)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
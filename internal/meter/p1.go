@@ -0,0 +1,150 @@
+package meter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultP1Port is the port of most P1 to TCP bridges
+const defaultP1Port = "23"
+
+// p1ReadTimeout bounds a reading when the context has no deadline. Older
+// meters send a telegram every 10 seconds, and the first one received may
+// be cut.
+const p1ReadTimeout = 25 * time.Second
+
+// OBIS codes of the power delivered to and returned by the site, in kW
+const (
+	p1Delivered = "1-0:1.7.0"
+	p1Returned  = "1-0:2.7.0"
+)
+
+// p1Meter reads the power of the site from the DSMR telegrams of the P1
+// port of a Dutch, Belgian or Luxembourgish smart meter, through a P1 to
+// TCP bridge such as ser2net or a P1 Wi-Fi dongle. The power returned by
+// solar panels is subtracted, so the reading is the net demand.
+type p1Meter struct {
+	addr string
+}
+
+func newP1(u *url.URL) *p1Meter {
+	port := u.Port()
+	if port == "" {
+		port = defaultP1Port
+	}
+	return &p1Meter{addr: net.JoinHostPort(u.Hostname(), port)}
+}
+
+func (p *p1Meter) Name() string {
+	return "P1 " + p.addr
+}
+
+func (p *p1Meter) ReadPower(ctx context.Context) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p1ReadTimeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to %s: %w", p.Name(), err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	telegram, err := readTelegram(bufio.NewReader(conn))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	return parseTelegram(telegram)
+}
+
+// readTelegram returns the first complete telegram read, from its /
+// header to its ! trailer, whose CRC is checked when it has one (DSMR 4 and
+// later)
+func readTelegram(r *bufio.Reader) (string, error) {
+	var telegram strings.Builder
+	started := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("no complete telegram: %w", err)
+		}
+		if strings.HasPrefix(line, "/") {
+			// A new telegram, the one read so far may have been cut
+			telegram.Reset()
+			started = true
+		}
+		if !started {
+			continue
+		}
+		trailer, ok := strings.CutPrefix(strings.TrimSpace(line), "!")
+		if !ok {
+			telegram.WriteString(line)
+			continue
+		}
+		telegram.WriteString("!")
+		if trailer != "" {
+			expected, err := strconv.ParseUint(trailer, 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid telegram CRC %q", trailer)
+			}
+			if crc := crc16([]byte(telegram.String())); crc != uint16(expected) {
+				return "", fmt.Errorf("telegram CRC %04X does not match %04X", crc, expected)
+			}
+		}
+		return telegram.String(), nil
+	}
+}
+
+// parseTelegram returns the net power of a telegram, delivered minus
+// returned, in µW
+func parseTelegram(telegram string) (int64, error) {
+	var delivered, returned float64
+	found := false
+	for _, line := range strings.Split(telegram, "\n") {
+		code, rest, ok := strings.Cut(strings.TrimSpace(line), "(")
+		if !ok || (code != p1Delivered && code != p1Returned) {
+			continue
+		}
+		value, unit, _ := strings.Cut(strings.TrimSuffix(rest, ")"), "*")
+		kw, err := strconv.ParseFloat(value, 64)
+		if err != nil || (unit != "" && unit != "kW") {
+			return 0, fmt.Errorf("invalid power %q of %s", rest, code)
+		}
+		if code == p1Delivered {
+			delivered, found = kw, true
+		} else {
+			returned = kw
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("telegram has no delivered power (%s)", p1Delivered)
+	}
+	return watts((delivered - returned) * 1000), nil
+}
+
+// crc16 is the CRC-16/ARC of DSMR telegrams
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b)
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,62 @@
+package meter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"kcas/new/internal/snmp"
+)
+
+// pdu reads the power of a smart PDU outlet over SNMPv2c
+type pdu struct {
+	addr      string
+	community string
+	oid       snmp.OID
+	scale     float64 // Multiplier from the raw value to W
+}
+
+func newSNMP(u *url.URL, path string) (*pdu, error) {
+	oid, err := snmp.ParseOID(path)
+	if err != nil || len(oid) == 0 {
+		return nil, fmt.Errorf("invalid power OID %q in meter address", path)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "161"
+	}
+	community := "public"
+	if u.User != nil {
+		community = u.User.Username()
+	}
+
+	scale := 1.0
+	if value := u.Query().Get("scale"); value != "" {
+		if scale, err = strconv.ParseFloat(value, 64); err != nil || scale <= 0 {
+			return nil, fmt.Errorf("invalid scale %q in meter address", value)
+		}
+	}
+	return &pdu{addr: net.JoinHostPort(u.Hostname(), port), community: community, oid: oid, scale: scale}, nil
+}
+
+func (p *pdu) Name() string {
+	return fmt.Sprintf("PDU %s OID %s", p.addr, p.oid)
+}
+
+func (p *pdu) ReadPower(ctx context.Context) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	value, err := snmp.Get(ctx, p.addr, p.community, p.oid)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := value.Int()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	return watts(float64(raw) * p.scale), nil
+}
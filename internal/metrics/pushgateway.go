@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// pushTimeout bounds a push to the Pushgateway
+const pushTimeout = 10 * time.Second
+
+// Pusher pushes the registry to a Prometheus Pushgateway, for nodes that
+// cannot be scraped
+type Pusher struct {
+	registry  *Registry
+	endpoint  string // Grouping key URL: <gateway>/metrics/job/<job>/instance/<instance>
+	gateway   string // Gateway URL without credentials, for logs
+	namespace string
+	client    *http.Client
+	logger    *log.Logger
+	trigger   chan struct{}
+}
+
+// NewPusher creates a pusher to the Pushgateway at gatewayURL, grouping the
+// metrics under job and instance
+func NewPusher(registry *Registry, gatewayURL, job, instance, namespace string, logger *log.Logger) (*Pusher, error) {
+	u, err := url.Parse(gatewayURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Pushgateway URL %q", gatewayURL)
+	}
+
+	return &Pusher{
+		registry: registry,
+		endpoint: strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job) +
+			"/instance/" + url.PathEscape(instance),
+		gateway:   u.Redacted(),
+		namespace: namespace,
+		client:    &http.Client{Timeout: pushTimeout},
+		logger:    logger,
+		trigger:   make(chan struct{}, 1),
+	}, nil
+}
+
+// Gateway returns the Pushgateway URL with any password redacted
+func (p *Pusher) Gateway() string {
+	return p.gateway
+}
+
+// Trigger requests a push without blocking the caller
+func (p *Pusher) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+		// A push is already pending
+	}
+}
+
+// Run pushes whenever triggered until the context is cancelled, then pushes
+// the final values once more
+func (p *Pusher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-p.trigger:
+			if err := p.Push(ctx); err != nil {
+				p.logger.Printf("⚠️  Failed to push metrics: %v", err)
+			}
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.Background(), pushTimeout)
+			defer cancel()
+			if err := p.Push(final); err != nil {
+				p.logger.Printf("⚠️  Failed to push final metrics: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// Push replaces the metrics of the grouping key with the current registry
+func (p *Pusher) Push(ctx context.Context) error {
+	var body bytes.Buffer
+	if err := WritePrometheus(&body, p.registry.Snapshot(), p.namespace); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushgateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
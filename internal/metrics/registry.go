@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is the kind of a metric
+type Type string
+
+const (
+	// Gauge is a value that can go up and down
+	Gauge Type = "gauge"
+	// Counter is a monotonically increasing value
+	Counter Type = "counter"
+)
+
+// Labels are key/value dimensions attached to a sample
+type Labels map[string]string
+
+// Sample is a point-in-time value of one metric series
+type Sample struct {
+	Name   string
+	Help   string
+	Type   Type
+	Labels Labels
+	Value  float64
+}
+
+// EventSink receives discrete events such as cap changes
+type EventSink interface {
+	// Event records an event with a title, body text and tags
+	Event(title, text string, tags map[string]string)
+}
+
+// Registry holds the current value of every metric series
+type Registry struct {
+	mu     sync.RWMutex
+	series map[string]*Sample
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		series: make(map[string]*Sample),
+	}
+}
+
+// SetGauge sets a gauge series to value
+func (r *Registry) SetGauge(name, help string, value float64, labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.getOrCreate(name, help, Gauge, labels)
+	s.Value = value
+}
+
+// AddCounter increments a counter series by delta
+func (r *Registry) AddCounter(name, help string, delta float64, labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.getOrCreate(name, help, Counter, labels)
+	s.Value += delta
+}
+
+// SetCounter sets a counter series to an externally maintained total
+func (r *Registry) SetCounter(name, help string, value float64, labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.getOrCreate(name, help, Counter, labels)
+	s.Value = value
+}
+
+// Get returns the current value of a series and whether it exists
+func (r *Registry) Get(name string, labels Labels) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.series[seriesKey(name, labels)]
+	if !ok {
+		return 0, false
+	}
+	return s.Value, true
+}
+
+// Snapshot returns a copy of all series sorted by name and labels
+func (r *Registry) Snapshot() []Sample {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.series))
+	for key := range r.series {
+		keys = append(keys, key)
+	}
+	// Group series of the same metric together, then order by labels
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := r.series[keys[i]], r.series[keys[j]]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return keys[i] < keys[j]
+	})
+
+	samples := make([]Sample, 0, len(keys))
+	for _, key := range keys {
+		s := r.series[key]
+		labels := make(Labels, len(s.Labels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		samples = append(samples, Sample{
+			Name:   s.Name,
+			Help:   s.Help,
+			Type:   s.Type,
+			Labels: labels,
+			Value:  s.Value,
+		})
+	}
+	r.mu.RUnlock()
+
+	return samples
+}
+
+func (r *Registry) getOrCreate(name, help string, typ Type, labels Labels) *Sample {
+	key := seriesKey(name, labels)
+	s, ok := r.series[key]
+	if !ok {
+		copied := make(Labels, len(labels))
+		for k, v := range labels {
+			copied[k] = v
+		}
+		s = &Sample{Name: name, Help: help, Type: typ, Labels: copied}
+		r.series[key] = s
+	}
+	return s
+}
+
+// seriesKey builds a stable identifier from a name and its labels
+func seriesKey(name string, labels Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range names {
+		b.WriteString("|")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(labels[k])
+	}
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDatagramSize keeps StatsD packets below common UDP MTU limits
+const maxDatagramSize = 1432
+
+// StatsDExporter periodically pushes registry values to a DogStatsD agent
+type StatsDExporter struct {
+	registry *Registry
+	conn     net.Conn
+	prefix   string
+	tags     []string
+	interval time.Duration
+	logger   *log.Logger
+
+	mu       sync.Mutex
+	counters map[string]float64 // Last flushed counter values, used to send deltas
+}
+
+// NewStatsDExporter connects to the DogStatsD agent at addr
+func NewStatsDExporter(registry *Registry, addr, prefix string, tags []string, interval time.Duration, logger *log.Logger) (*StatsDExporter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd agent %s: %w", addr, err)
+	}
+
+	return &StatsDExporter{
+		registry: registry,
+		conn:     conn,
+		prefix:   prefix,
+		tags:     tags,
+		interval: interval,
+		logger:   logger,
+		counters: make(map[string]float64),
+	}, nil
+}
+
+// Run flushes metrics every interval until the context is cancelled
+func (e *StatsDExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	defer e.conn.Close()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				e.logger.Printf("⚠️  Failed to flush statsd metrics: %v", err)
+			}
+		case <-ctx.Done():
+			e.Flush()
+			return
+		}
+	}
+}
+
+// Flush sends the current value of every gauge and the delta of every counter
+func (e *StatsDExporter) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var lines []string
+	for _, s := range e.registry.Snapshot() {
+		switch s.Type {
+		case Gauge:
+			lines = append(lines, e.formatMetric(s.Name, s.Value, "g", s.Labels))
+		case Counter:
+			key := seriesKey(s.Name, s.Labels)
+			delta := s.Value - e.counters[key]
+			e.counters[key] = s.Value
+			if delta > 0 {
+				lines = append(lines, e.formatMetric(s.Name, delta, "c", s.Labels))
+			}
+		}
+	}
+
+	return e.send(lines)
+}
+
+// Event submits a DogStatsD event
+func (e *StatsDExporter) Event(title, text string, tags map[string]string) {
+	text = strings.ReplaceAll(text, "\n", "\\n")
+	line := fmt.Sprintf("_e{%d,%d}:%s|%s", len(title), len(text), title, text)
+	if tagStr := e.formatTags(tags); tagStr != "" {
+		line += "|#" + tagStr
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.send([]string{line}); err != nil {
+		e.logger.Printf("⚠️  Failed to send statsd event: %v", err)
+	}
+}
+
+func (e *StatsDExporter) formatMetric(name string, value float64, kind string, labels Labels) string {
+	line := fmt.Sprintf("%s%s:%g|%s", e.prefix, name, value, kind)
+	if tagStr := e.formatTags(labels); tagStr != "" {
+		line += "|#" + tagStr
+	}
+	return line
+}
+
+// formatTags merges constant tags with series labels as "key:value" pairs
+func (e *StatsDExporter) formatTags(labels map[string]string) string {
+	tags := append([]string(nil), e.tags...)
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tags = append(tags, k+":"+labels[k])
+	}
+
+	return strings.Join(tags, ",")
+}
+
+// send packs lines into datagrams no larger than maxDatagramSize
+func (e *StatsDExporter) send(lines []string) error {
+	var packet strings.Builder
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxDatagramSize {
+			if _, err := e.conn.Write([]byte(packet.String())); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteString("\n")
+		}
+		packet.WriteString(line)
+	}
+
+	if packet.Len() > 0 {
+		if _, err := e.conn.Write([]byte(packet.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package modbus
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/power"
+)
+
+// Holding register addresses (see the README register map). Power values are
+// unsigned 32-bit milliwatts, high word first.
+const (
+	regAppliedCap       = 0  // 0-1
+	regHardwareMax      = 2  // 2-3
+	regMeasuredPower    = 4  // 4-5
+	regMinPower         = 6  // 6-7
+	regAdminMax         = 8  // 8-9, 0 when no ceiling is set
+	regOverrideActive   = 10 // 1 while an override is active
+	regOverrideRemain   = 11 // Minutes left of the active override
+	regOverridePower    = 12 // 12-13, writable: override power, 0 disables capping
+	regOverrideDuration = 14 // Writable: minutes; writing commits the override, 0 clears it
+	registerCount       = 15
+)
+
+// overrideSetBy is recorded as the author of Modbus overrides
+const overrideSetBy = "modbus"
+
+// Controller applies the overrides written by Modbus clients
+type Controller interface {
+	// SetOverride pins the cap until the override expires
+	SetOverride(override power.Override) error
+
+	// ClearOverride removes the active override
+	ClearOverride(clearedBy string) (power.Override, bool)
+
+	// ActiveOverride returns the override in effect, if any
+	ActiveOverride() (power.Override, bool)
+}
+
+// PowerRegisters maps the manager state to holding registers
+type PowerRegisters struct {
+	registry    *metrics.Registry
+	ctrl        Controller
+	allowWrites bool // Accept writes to the override registers
+	logger      *log.Logger
+
+	mu           sync.Mutex
+	pendingPower uint32 // Override power written to regOverridePower (mW)
+}
+
+// NewPowerRegisters creates the register map. Writes are rejected unless
+// allowWrites is set.
+func NewPowerRegisters(registry *metrics.Registry, ctrl Controller, allowWrites bool, logger *log.Logger) *PowerRegisters {
+	return &PowerRegisters{
+		registry:    registry,
+		ctrl:        ctrl,
+		allowWrites: allowWrites,
+		logger:      logger,
+	}
+}
+
+// Read returns count registers starting at address
+func (r *PowerRegisters) Read(address, count uint16) ([]uint16, error) {
+	if int(address)+int(count) > registerCount {
+		return nil, exception(exceptionIllegalDataAddress)
+	}
+
+	milliwatts := func(metric string) uint32 {
+		microwatts, _ := r.registry.Get(metric, nil)
+		return uint32(microwatts / 1000)
+	}
+
+	values := make([]uint16, registerCount)
+	put32(values, regAppliedCap, milliwatts("applied_cap_uw"))
+	put32(values, regHardwareMax, milliwatts("hardware_max_uw"))
+	put32(values, regMeasuredPower, milliwatts("measured_power_uw"))
+	put32(values, regMinPower, milliwatts("min_power_uw"))
+	put32(values, regAdminMax, milliwatts("admin_max_uw"))
+
+	if override, ok := r.ctrl.ActiveOverride(); ok {
+		values[regOverrideActive] = 1
+		values[regOverrideRemain] = uint16(min(time.Until(override.ExpiresAt).Minutes()+0.5, 0xffff))
+		if !override.Disable {
+			put32(values, regOverridePower, uint32(override.PowerUW/1000))
+		}
+	}
+	r.mu.Lock()
+	if values[regOverrideActive] == 0 {
+		put32(values, regOverridePower, r.pendingPower)
+	}
+	r.mu.Unlock()
+
+	return values[address : address+count], nil
+}
+
+// Write stores the override registers; a write to the duration register
+// applies the override
+func (r *PowerRegisters) Write(address uint16, values []uint16) error {
+	if !r.allowWrites {
+		return exception(exceptionIllegalFunction)
+	}
+	end := int(address) + len(values)
+	if address < regOverridePower || end > registerCount {
+		return exception(exceptionIllegalDataAddress)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	words := [2]uint16{uint16(r.pendingPower >> 16), uint16(r.pendingPower)}
+	for i, value := range values {
+		switch reg := int(address) + i; reg {
+		case regOverridePower, regOverridePower + 1:
+			words[reg-regOverridePower] = value
+		}
+	}
+	r.pendingPower = uint32(words[0])<<16 | uint32(words[1])
+
+	if end <= regOverrideDuration {
+		return nil
+	}
+	minutes := values[regOverrideDuration-int(address)]
+	return r.commit(minutes)
+}
+
+// commit applies the pending override for the given minutes, or clears the
+// active one when minutes is 0. Callers must hold r.mu.
+func (r *PowerRegisters) commit(minutes uint16) error {
+	if minutes == 0 {
+		if _, ok := r.ctrl.ClearOverride(overrideSetBy); ok {
+			r.logger.Printf("🏢 Override cleared over Modbus")
+		}
+		return nil
+	}
+
+	now := time.Now()
+	override := power.Override{
+		PowerUW:   int64(r.pendingPower) * 1000,
+		Disable:   r.pendingPower == 0,
+		Reason:    fmt.Sprintf("set over Modbus for %d min", minutes),
+		SetBy:     overrideSetBy,
+		SetAt:     now,
+		ExpiresAt: now.Add(time.Duration(minutes) * time.Minute),
+	}
+	if err := r.ctrl.SetOverride(override); err != nil {
+		r.logger.Printf("⚠️  Rejected Modbus override: %v", err)
+		return exception(exceptionIllegalDataValue)
+	}
+	return nil
+}
+
+// put32 stores a 32-bit value in two registers, high word first
+func put32(values []uint16, address int, value uint32) {
+	values[address] = uint16(value >> 16)
+	values[address+1] = uint16(value)
+}
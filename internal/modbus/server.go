@@ -0,0 +1,232 @@
+// Package modbus exposes the power manager state as Modbus TCP holding
+// registers for building-management systems.
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Function codes
+const (
+	funcReadHoldingRegisters   = 0x03
+	funcReadInputRegisters     = 0x04
+	funcWriteSingleRegister    = 0x06
+	funcWriteMultipleRegisters = 0x10
+)
+
+// Exception codes
+const (
+	exceptionIllegalFunction    = 0x01
+	exceptionIllegalDataAddress = 0x02
+	exceptionIllegalDataValue   = 0x03
+	exceptionServerFailure      = 0x04
+)
+
+// Protocol limits
+const (
+	maxReadRegisters  = 125
+	maxWriteRegisters = 123
+	mbapHeaderSize    = 7
+	maxPDUSize        = 253
+)
+
+// idleTimeout closes connections without requests
+const idleTimeout = 5 * time.Minute
+
+// exception is a Modbus exception response
+type exception byte
+
+func (e exception) Error() string {
+	return fmt.Sprintf("modbus exception %d", byte(e))
+}
+
+// Registers is the register map served by the server
+type Registers interface {
+	// Read returns count registers starting at address
+	Read(address, count uint16) ([]uint16, error)
+
+	// Write stores values starting at address
+	Write(address uint16, values []uint16) error
+}
+
+// Server is a Modbus TCP server
+type Server struct {
+	addr      string
+	unitID    byte // Unit identifier answered, 0 for any
+	registers Registers
+	logger    *log.Logger
+
+	wg sync.WaitGroup
+}
+
+// NewServer creates a server listening on addr (e.g. ":502") for unitID
+func NewServer(addr string, unitID byte, registers Registers, logger *log.Logger) *Server {
+	return &Server{
+		addr:      addr,
+		unitID:    unitID,
+		registers: registers,
+		logger:    logger,
+	}
+}
+
+// Run serves requests until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	var mu sync.Mutex
+	conns := make(map[net.Conn]struct{})
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		mu.Lock()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+	defer s.wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept Modbus connection: %w", err)
+		}
+
+		mu.Lock()
+		conns[conn] = struct{}{}
+		mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// serve answers the requests of one connection
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	header := make([]byte, mbapHeaderSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if _, err := io.ReadFull(conn, header); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.logger.Printf("⚠️  Modbus connection from %s closed: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		protocol := binary.BigEndian.Uint16(header[2:4])
+		length := binary.BigEndian.Uint16(header[4:6])
+		if protocol != 0 || length < 2 || length > maxPDUSize+1 {
+			s.logger.Printf("⚠️  Invalid Modbus frame from %s, closing connection", conn.RemoteAddr())
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+
+		unitID := header[6]
+		if s.unitID != 0 && unitID != s.unitID {
+			// Requests for other units get no response, like a gateway without the unit
+			continue
+		}
+
+		response := s.handle(pdu)
+		frame := make([]byte, mbapHeaderSize, mbapHeaderSize+len(response))
+		copy(frame, header[:4])
+		binary.BigEndian.PutUint16(frame[4:6], uint16(len(response)+1))
+		frame[6] = unitID
+		if _, err := conn.Write(append(frame, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// handle executes a request PDU and returns the response PDU
+func (s *Server) handle(pdu []byte) []byte {
+	function := pdu[0]
+	response, err := s.execute(function, pdu[1:])
+	if err != nil {
+		code := exception(exceptionServerFailure)
+		if !errors.As(err, &code) {
+			s.logger.Printf("⚠️  Modbus function 0x%02x failed: %v", function, err)
+		}
+		return []byte{function | 0x80, byte(code)}
+	}
+	return append([]byte{function}, response...)
+}
+
+// execute runs a function on the register map
+func (s *Server) execute(function byte, data []byte) ([]byte, error) {
+	switch function {
+	case funcReadHoldingRegisters, funcReadInputRegisters:
+		if len(data) != 4 {
+			return nil, exception(exceptionIllegalDataValue)
+		}
+		address := binary.BigEndian.Uint16(data[0:2])
+		count := binary.BigEndian.Uint16(data[2:4])
+		if count == 0 || count > maxReadRegisters {
+			return nil, exception(exceptionIllegalDataValue)
+		}
+		values, err := s.registers.Read(address, count)
+		if err != nil {
+			return nil, err
+		}
+		response := []byte{byte(2 * len(values))}
+		for _, value := range values {
+			response = binary.BigEndian.AppendUint16(response, value)
+		}
+		return response, nil
+
+	case funcWriteSingleRegister:
+		if len(data) != 4 {
+			return nil, exception(exceptionIllegalDataValue)
+		}
+		address := binary.BigEndian.Uint16(data[0:2])
+		if err := s.registers.Write(address, []uint16{binary.BigEndian.Uint16(data[2:4])}); err != nil {
+			return nil, err
+		}
+		return data, nil
+
+	case funcWriteMultipleRegisters:
+		if len(data) < 5 {
+			return nil, exception(exceptionIllegalDataValue)
+		}
+		address := binary.BigEndian.Uint16(data[0:2])
+		count := binary.BigEndian.Uint16(data[2:4])
+		if count == 0 || count > maxWriteRegisters || int(data[4]) != 2*int(count) || len(data) != 5+2*int(count) {
+			return nil, exception(exceptionIllegalDataValue)
+		}
+		values := make([]uint16, count)
+		for i := range values {
+			values[i] = binary.BigEndian.Uint16(data[5+2*i:])
+		}
+		if err := s.registers.Write(address, values); err != nil {
+			return nil, err
+		}
+		return data[0:4], nil
+
+	default:
+		return nil, exception(exceptionIllegalFunction)
+	}
+}
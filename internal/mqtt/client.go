@@ -0,0 +1,388 @@
+// Package mqtt implements a minimal MQTT 3.1.1 client publishing and
+// receiving QoS 0 messages.
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetSubscribe  = 8
+	packetPingReq    = 12
+	packetDisconnect = 14
+)
+
+// Connect flags
+const (
+	flagCleanSession = 0x02
+	flagWill         = 0x04
+	flagWillRetain   = 0x20
+	flagPassword     = 0x40
+	flagUsername     = 0x80
+)
+
+// dialTimeout bounds the connection to the broker
+const dialTimeout = 10 * time.Second
+
+// Options configures a connection to the broker
+type Options struct {
+	Broker    string // tcp://host:port, ssl://host:port or host:port
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+
+	// Will is published by the broker, retained, when the connection is lost
+	WillTopic   string
+	WillPayload string
+}
+
+// Client is a connection to an MQTT broker publishing and receiving QoS 0
+// messages
+type Client struct {
+	conn net.Conn
+	done chan struct{} // Closed when the connection is lost
+
+	mu  sync.Mutex // Serialises writes
+	err error      // Error that closed the connection
+
+	handlersMu sync.RWMutex
+	handlers   map[string]func(topic string, payload []byte) // Topic filter → handler
+	packetID   uint16                                        // Identifier of the last SUBSCRIBE
+}
+
+// Dial connects to the broker and completes the MQTT handshake
+func Dial(opts Options) (*Client, error) {
+	network, address, useTLS, err := parseBroker(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if useTLS {
+		host, _, _ := net.SplitHostPort(address)
+		conn, err = tls.DialWithDialer(dialer, network, address, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial(network, address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", opts.Broker, err)
+	}
+
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Write(encodeConnect(opts)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	typ, body, err := readPacket(reader)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if typ != packetConnAck || len(body) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected packet type %d instead of CONNACK", typ)
+	}
+	if code := body[1]; code != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("broker refused connection: %s", connAckReason(code))
+	}
+	conn.SetDeadline(time.Time{})
+
+	c := &Client{conn: conn, done: make(chan struct{}), handlers: make(map[string]func(string, []byte))}
+	go c.readLoop(reader)
+	if opts.KeepAlive > 0 {
+		go c.pingLoop(opts.KeepAlive)
+	}
+	return c, nil
+}
+
+// parseBroker returns the network address of a broker URL and whether it uses TLS
+func parseBroker(broker string) (string, string, bool, error) {
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", "", false, fmt.Errorf("invalid MQTT broker %q: %w", broker, err)
+	}
+
+	useTLS := false
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS, port = true, "8883"
+	default:
+		return "", "", false, fmt.Errorf("unsupported MQTT broker scheme %q (expected tcp or ssl)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", "", false, fmt.Errorf("invalid MQTT broker %q: missing host", broker)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return "tcp", net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// Publish sends a QoS 0 message
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	return c.write(encodePublish(topic, payload, retain))
+}
+
+// Subscribe asks the broker for the QoS 0 messages of a topic filter, which
+// may contain + and # wildcards, and calls handler with each of them from the
+// read loop. Retained messages are delivered right away.
+func (c *Client) Subscribe(filter string, handler func(topic string, payload []byte)) error {
+	c.handlersMu.Lock()
+	c.handlers[filter] = handler
+	c.packetID++
+	id := c.packetID
+	c.handlersMu.Unlock()
+
+	body := []byte{byte(id >> 8), byte(id)}
+	body = appendString(body, filter)
+	body = append(body, 0) // Requested QoS
+	return c.write(appendPacket(packetSubscribe<<4|0x02, body))
+}
+
+// Done is closed when the connection is lost
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that closed the connection
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects cleanly, so the broker does not publish the will
+func (c *Client) Close() error {
+	c.write([]byte{packetDisconnect << 4, 0})
+	return c.conn.Close()
+}
+
+// write sends a packet, closing the connection on failure
+func (c *Client) write(packet []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	if _, err := c.conn.Write(packet); err != nil {
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+// fail records the error closing the connection. Callers must hold c.mu.
+func (c *Client) fail(err error) {
+	if c.err == nil {
+		c.err = err
+		c.conn.Close()
+		close(c.done)
+	}
+}
+
+// readLoop dispatches incoming messages to the subscription handlers and
+// discards other packets (PINGRESP, SUBACK) until the connection closes
+func (c *Client) readLoop(reader *bufio.Reader) {
+	for {
+		typ, body, err := readPacket(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("connection closed by broker")
+			}
+			c.mu.Lock()
+			c.fail(err)
+			c.mu.Unlock()
+			return
+		}
+		if typ == packetPublish {
+			c.dispatch(body)
+		}
+	}
+}
+
+// dispatch passes a QoS 0 PUBLISH body to the handlers of matching filters
+func (c *Client) dispatch(body []byte) {
+	if len(body) < 2 {
+		return
+	}
+	length := int(body[0])<<8 | int(body[1])
+	if len(body) < 2+length {
+		return
+	}
+	topic, payload := string(body[2:2+length]), body[2+length:]
+
+	c.handlersMu.RLock()
+	defer c.handlersMu.RUnlock()
+	for filter, handler := range c.handlers {
+		if topicMatches(filter, topic) {
+			handler(topic, payload)
+		}
+	}
+}
+
+// topicMatches reports whether a topic matches a filter with + (one level)
+// and # (all remaining levels) wildcards
+func topicMatches(filter, topic string) bool {
+	filterLevels, topicLevels := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(topicLevels):
+			return false
+		case level != "+" && level != topicLevels[i]:
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+// pingLoop keeps the connection alive while no message is published
+func (c *Client) pingLoop(keepAlive time.Duration) {
+	ticker := time.NewTicker(keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write([]byte{packetPingReq << 4, 0}); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// encodeConnect builds a CONNECT packet with a clean session
+func encodeConnect(opts Options) []byte {
+	flags := byte(flagCleanSession)
+	var payload []byte
+	payload = appendString(payload, opts.ClientID)
+	if opts.WillTopic != "" {
+		flags |= flagWill | flagWillRetain
+		payload = appendString(payload, opts.WillTopic)
+		payload = appendString(payload, opts.WillPayload)
+	}
+	if opts.Username != "" {
+		flags |= flagUsername
+		payload = appendString(payload, opts.Username)
+		if opts.Password != "" {
+			flags |= flagPassword
+			payload = appendString(payload, opts.Password)
+		}
+	}
+
+	keepAlive := uint16(opts.KeepAlive / time.Second)
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, 4, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = append(body, payload...)
+	return appendPacket(packetConnect<<4, body)
+}
+
+// encodePublish builds a QoS 0 PUBLISH packet
+func encodePublish(topic string, payload []byte, retain bool) []byte {
+	header := byte(packetPublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return appendPacket(header, body)
+}
+
+// appendPacket prefixes a packet body with its fixed header
+func appendPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
+
+// readPacket reads one control packet and returns its type and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, fmt.Errorf("malformed remaining length")
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+// connAckReason describes a CONNACK return code
+func connAckReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
@@ -0,0 +1,251 @@
+// Package publisher publishes the power manager state over MQTT, with
+// optional Home Assistant discovery.
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/mqtt"
+	"kcas/new/internal/power"
+	"kcas/new/internal/version"
+)
+
+// Reconnection delays after the broker is lost
+const (
+	minReconnectDelay = 5 * time.Second
+	maxReconnectDelay = 2 * time.Minute
+)
+
+// Availability payloads
+const (
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+// Source provides the decisions and measurements published over MQTT
+type Source interface {
+	// SubscribeDecisions streams new decisions until the returned function is called
+	SubscribeDecisions() (<-chan power.PowerDecision, func())
+
+	// Metrics returns the registry holding the measured power
+	Metrics() *metrics.Registry
+
+	// Currency returns the currency of the market prices
+	Currency() string
+}
+
+// State is the retained message published on <prefix>/<node>/state
+type State struct {
+	AppliedCapW    float64 `json:"applied_cap_w"`
+	MeasuredPowerW float64 `json:"measured_power_w"`
+	HardwareMaxW   float64 `json:"hardware_max_w"`
+	Period         string  `json:"period"`
+	Volume         float64 `json:"volume_mwh"`
+	Price          float64 `json:"price_eur_mwh"` // In Currency
+	Currency       string  `json:"currency"`
+	Override       bool    `json:"override"`
+	Timestamp      string  `json:"timestamp"`
+}
+
+// Publisher publishes the manager state after every decision
+type Publisher struct {
+	opts      mqtt.Options
+	base      string // Topic prefix of the node
+	discovery string // Home Assistant discovery prefix, empty disables
+	node      string
+	source    Source
+	logger    *log.Logger
+
+	last *State // Latest state, republished after reconnecting
+}
+
+// New creates a publisher sending to <topicPrefix>/<node>/...
+// discoveryPrefix enables Home Assistant discovery when not empty.
+func New(opts mqtt.Options, topicPrefix, node, discoveryPrefix string, source Source, logger *log.Logger) *Publisher {
+	base := strings.TrimSuffix(topicPrefix, "/") + "/" + node
+	opts.WillTopic = base + "/availability"
+	opts.WillPayload = payloadOffline
+	return &Publisher{
+		opts:      opts,
+		base:      base,
+		discovery: strings.TrimSuffix(discoveryPrefix, "/"),
+		node:      node,
+		source:    source,
+		logger:    logger,
+	}
+}
+
+// Run publishes until the context is cancelled, reconnecting to the broker
+// when the connection is lost
+func (p *Publisher) Run(ctx context.Context) {
+	decisions, unsubscribe := p.source.SubscribeDecisions()
+	defer unsubscribe()
+
+	delay := minReconnectDelay
+	for {
+		client, err := p.connect()
+		if err != nil {
+			p.logger.Printf("⚠️  %v, retrying in %v", err, delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			delay = min(delay*2, maxReconnectDelay)
+			continue
+		}
+		delay = minReconnectDelay
+		p.logger.Printf("📨 Connected to MQTT broker %s, publishing to %s", p.opts.Broker, p.base)
+
+		if !p.serve(ctx, client, decisions) {
+			return
+		}
+		p.logger.Printf("⚠️  Lost connection to MQTT broker %s: %v", p.opts.Broker, client.Err())
+	}
+}
+
+// connect dials the broker and publishes availability, discovery and the
+// latest state
+func (p *Publisher) connect() (*mqtt.Client, error) {
+	client, err := mqtt.Dial(p.opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.announce(client); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to publish to MQTT broker %s: %w", p.opts.Broker, err)
+	}
+	return client, nil
+}
+
+// announce publishes availability, discovery and the latest state
+func (p *Publisher) announce(client *mqtt.Client) error {
+	if err := client.Publish(p.base+"/availability", []byte(payloadOnline), true); err != nil {
+		return err
+	}
+	if p.discovery != "" {
+		for _, config := range p.discoveryConfigs() {
+			if err := client.Publish(config.topic, config.payload, true); err != nil {
+				return err
+			}
+		}
+	}
+	if p.last != nil {
+		return p.publishState(client, *p.last)
+	}
+	return nil
+}
+
+// serve publishes decisions until the connection is lost or the context is
+// cancelled, and reports whether to reconnect
+func (p *Publisher) serve(ctx context.Context, client *mqtt.Client, decisions <-chan power.PowerDecision) bool {
+	for {
+		select {
+		case decision, ok := <-decisions:
+			if !ok {
+				client.Close()
+				return false
+			}
+			state := p.state(decision)
+			p.last = &state
+			if err := p.publishState(client, state); err != nil {
+				return true
+			}
+		case <-client.Done():
+			return true
+		case <-ctx.Done():
+			client.Publish(p.base+"/availability", []byte(payloadOffline), true)
+			client.Close()
+			return false
+		}
+	}
+}
+
+// state builds the published state of a decision
+func (p *Publisher) state(decision power.PowerDecision) State {
+	measured, _ := p.source.Metrics().Get("measured_power_uw", nil)
+	return State{
+		AppliedCapW:    float64(decision.AppliedPower) / 1000000,
+		MeasuredPowerW: measured / 1000000,
+		HardwareMaxW:   float64(decision.HardwareMax) / 1000000,
+		Period:         decision.Period,
+		Volume:         decision.Volume,
+		Price:          decision.Price,
+		Currency:       decision.Currency,
+		Override:       decision.Override != nil,
+		Timestamp:      decision.Timestamp.Format(time.RFC3339),
+	}
+}
+
+// publishState sends the retained state message
+func (p *Publisher) publishState(client *mqtt.Client, state State) error {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return client.Publish(p.base+"/state", payload, true)
+}
+
+// discoveryConfig is a Home Assistant discovery message
+type discoveryConfig struct {
+	topic   string
+	payload []byte
+}
+
+// discoveryConfigs returns the Home Assistant sensors of the node state
+func (p *Publisher) discoveryConfigs() []discoveryConfig {
+	sensors := []struct {
+		key, name, unit, deviceClass string
+	}{
+		{"applied_cap_w", "Power cap", "W", "power"},
+		{"measured_power_w", "Measured power", "W", "power"},
+		{"hardware_max_w", "Hardware maximum", "W", "power"},
+		{"price_eur_mwh", "Market price", datastore.PriceUnit(p.source.Currency()), ""},
+		{"volume_mwh", "Market volume", "MWh", ""},
+		{"period", "Market period", "", ""},
+	}
+
+	objectID := "powercap_" + strings.NewReplacer(".", "_", "-", "_").Replace(p.node)
+	device := map[string]interface{}{
+		"identifiers":  []string{objectID},
+		"name":         "PowerCap " + p.node,
+		"manufacturer": "powercap",
+		"sw_version":   version.Get().Version,
+	}
+
+	configs := make([]discoveryConfig, 0, len(sensors))
+	for _, sensor := range sensors {
+		config := map[string]interface{}{
+			"name":               sensor.name,
+			"unique_id":          objectID + "_" + sensor.key,
+			"state_topic":        p.base + "/state",
+			"value_template":     "{{ value_json." + sensor.key + " }}",
+			"availability_topic": p.base + "/availability",
+			"device":             device,
+		}
+		if sensor.unit != "" {
+			config["unit_of_measurement"] = sensor.unit
+			config["state_class"] = "measurement"
+		}
+		if sensor.deviceClass != "" {
+			config["device_class"] = sensor.deviceClass
+		}
+
+		payload, err := json.Marshal(config)
+		if err != nil {
+			continue
+		}
+		configs = append(configs, discoveryConfig{
+			topic:   p.discovery + "/sensor/" + objectID + "/" + sensor.key + "/config",
+			payload: payload,
+		})
+	}
+	return configs
+}
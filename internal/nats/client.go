@@ -0,0 +1,311 @@
+// Package nats implements a minimal NATS client publishing decisions and
+// health events, with optional JetStream acknowledgements.
+package nats
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/version"
+)
+
+// dialTimeout bounds the connection and handshake with the server
+const dialTimeout = 10 * time.Second
+
+// serverInfo is the INFO message sent by the server on connect
+type serverInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// connectOptions is the CONNECT message sent by the client
+type connectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name,omitempty"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Protocol int    `json:"protocol"`
+	Token    string `json:"auth_token,omitempty"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+// PubAck is the JetStream acknowledgement of a published message
+type PubAck struct {
+	Stream    string `json:"stream"`
+	Sequence  uint64 `json:"seq"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Error     *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error,omitempty"`
+}
+
+// Client is a connection to a NATS server
+type Client struct {
+	conn  net.Conn
+	pong  chan struct{}
+	done  chan struct{} // Closed when the connection is lost
+	inbox string        // Prefix of the reply subjects of acknowledged publishes
+
+	mu         sync.Mutex // Serialises writes and guards the fields below
+	err        error
+	subscribed bool
+	nextReply  int
+	replies    map[string]chan []byte
+}
+
+// Dial connects to the server at rawURL (nats://[user:pass@]host:port or
+// tls://host:port) and completes the handshake
+func Dial(rawURL, token, name string) (*Client, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "nats://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	useTLS := u.Scheme == "tls"
+	if u.Scheme != "nats" && !useTLS {
+		return nil, fmt.Errorf("unsupported NATS URL scheme %q (expected nats or tls)", u.Scheme)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS server %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	// The server sends INFO in plain text, then TLS starts if either side requires it
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read NATS server INFO: %v", err)
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("invalid NATS server INFO: %w", err)
+	}
+	if useTLS || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("NATS TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	opts := connectOptions{
+		Name:     name,
+		Lang:     "go",
+		Version:  version.Get().Version,
+		Protocol: 1,
+		Token:    token,
+	}
+	if u.User != nil {
+		opts.User = u.User.Username()
+		opts.Pass, _ = u.User.Password()
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &Client{
+		conn:    conn,
+		pong:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		inbox:   "_INBOX." + randomID(),
+		replies: make(map[string]chan []byte),
+	}
+	if err := c.write("CONNECT " + string(connect) + "\r\nPING\r\n"); err != nil {
+		return nil, fmt.Errorf("failed to send NATS CONNECT: %w", err)
+	}
+	go c.readLoop(reader)
+
+	select {
+	case <-c.pong:
+	case <-c.done:
+		return nil, fmt.Errorf("NATS server rejected connection: %w", c.Err())
+	case <-time.After(dialTimeout):
+		c.Close()
+		return nil, fmt.Errorf("timed out waiting for NATS server")
+	}
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+// Publish sends a message without waiting for an acknowledgement
+func (c *Client) Publish(subject string, payload []byte) error {
+	return c.write(fmt.Sprintf("PUB %s %d\r\n%s\r\n", subject, len(payload), payload))
+}
+
+// PublishAck sends a message to a JetStream stream and waits for its
+// acknowledgement
+func (c *Client) PublishAck(subject string, payload []byte, timeout time.Duration) (PubAck, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return PubAck{}, c.err
+	}
+	var sub string
+	if !c.subscribed {
+		sub = fmt.Sprintf("SUB %s.* 1\r\n", c.inbox)
+		c.subscribed = true
+	}
+	c.nextReply++
+	reply := c.inbox + "." + strconv.Itoa(c.nextReply)
+	ch := make(chan []byte, 1)
+	c.replies[reply] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.replies, reply)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(fmt.Sprintf("%sPUB %s %s %d\r\n%s\r\n", sub, subject, reply, len(payload), payload)); err != nil {
+		return PubAck{}, err
+	}
+
+	select {
+	case data := <-ch:
+		var ack PubAck
+		if err := json.Unmarshal(data, &ack); err != nil {
+			return PubAck{}, fmt.Errorf("invalid JetStream acknowledgement: %w", err)
+		}
+		if ack.Error != nil {
+			return ack, fmt.Errorf("JetStream rejected message: %s (%d)", ack.Error.Description, ack.Error.Code)
+		}
+		return ack, nil
+	case <-c.done:
+		return PubAck{}, c.Err()
+	case <-time.After(timeout):
+		return PubAck{}, fmt.Errorf("no JetStream acknowledgement within %v (is a stream bound to %s?)", timeout, subject)
+	}
+}
+
+// Done is closed when the connection is lost
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that closed the connection
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fail(fmt.Errorf("connection closed"))
+	return nil
+}
+
+// write sends protocol lines, closing the connection on failure
+func (c *Client) write(data string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	if _, err := io.WriteString(c.conn, data); err != nil {
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+// fail records the error closing the connection. Callers must hold c.mu.
+func (c *Client) fail(err error) {
+	if c.err == nil {
+		c.err = err
+		c.conn.Close()
+		close(c.done)
+	}
+}
+
+// readLoop handles server messages until the connection closes
+func (c *Client) readLoop(reader *bufio.Reader) {
+	err := c.read(reader)
+	if errors.Is(err, io.EOF) {
+		err = fmt.Errorf("connection closed by server")
+	}
+	c.mu.Lock()
+	c.fail(err)
+	c.mu.Unlock()
+}
+
+// read processes server protocol lines
+func (c *Client) read(reader *bufio.Reader) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "PING":
+			if err := c.write("PONG\r\n"); err != nil {
+				return err
+			}
+		case line == "PONG":
+			select {
+			case c.pong <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <size>
+			fields := strings.Fields(line)
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || len(fields) < 4 {
+				return fmt.Errorf("malformed MSG line %q", line)
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return err
+			}
+			c.mu.Lock()
+			if ch, ok := c.replies[fields[1]]; ok {
+				ch <- payload[:size]
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// randomID returns a random identifier for inbox subjects
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
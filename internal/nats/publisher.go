@@ -0,0 +1,164 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"kcas/new/internal/power"
+)
+
+// Reconnection delays after the server is lost
+const (
+	minReconnectDelay = 5 * time.Second
+	maxReconnectDelay = 2 * time.Minute
+)
+
+// healthCheckInterval is how often health is evaluated between decisions,
+// so a stalled loop is still reported
+const healthCheckInterval = 30 * time.Second
+
+// ackTimeout bounds the wait for a JetStream acknowledgement
+const ackTimeout = 5 * time.Second
+
+// Source provides the decisions and health published over NATS
+type Source interface {
+	// SubscribeDecisions streams new decisions until the returned function is called
+	SubscribeDecisions() (<-chan power.PowerDecision, func())
+
+	// Health evaluates whether the control loop is making progress
+	Health() power.Health
+}
+
+// HealthEvent is published when the health status of the node changes
+type HealthEvent struct {
+	Node      string       `json:"node"`
+	Previous  string       `json:"previous,omitempty"`
+	Health    power.Health `json:"health"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// Publisher sends every decision and each health change to NATS subjects
+// <prefix>.<node>.decision and <prefix>.<node>.health
+type Publisher struct {
+	url       string
+	token     string
+	jetStream bool // Wait for JetStream acknowledgements
+	node      string
+	subjects  struct{ decision, health string }
+	source    Source
+	logger    *log.Logger
+
+	lastHealth string // Last published health status
+}
+
+// NewPublisher creates a publisher for the server at url
+func NewPublisher(url, token, prefix, node string, jetStream bool, source Source, logger *log.Logger) *Publisher {
+	// Dots separate subject tokens, so they cannot appear in the node token
+	base := strings.TrimSuffix(prefix, ".") + "." + strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_").Replace(node)
+	p := &Publisher{
+		url:       url,
+		token:     token,
+		jetStream: jetStream,
+		node:      node,
+		source:    source,
+		logger:    logger,
+	}
+	p.subjects.decision = base + ".decision"
+	p.subjects.health = base + ".health"
+	return p
+}
+
+// Run publishes until the context is cancelled, reconnecting to the server
+// when the connection is lost. Decisions made while disconnected are dropped.
+func (p *Publisher) Run(ctx context.Context) {
+	decisions, unsubscribe := p.source.SubscribeDecisions()
+	defer unsubscribe()
+
+	delay := minReconnectDelay
+	for {
+		client, err := Dial(p.url, p.token, "powercap-"+p.node)
+		if err != nil {
+			p.logger.Printf("⚠️  %v, retrying in %v", err, delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			delay = min(delay*2, maxReconnectDelay)
+			continue
+		}
+		delay = minReconnectDelay
+		p.logger.Printf("📬 Connected to NATS server, publishing to %s and %s", p.subjects.decision, p.subjects.health)
+
+		if !p.serve(ctx, client, decisions) {
+			client.Close()
+			return
+		}
+		p.logger.Printf("⚠️  Lost connection to NATS server: %v", client.Err())
+	}
+}
+
+// serve publishes until the connection is lost or the context is cancelled,
+// and reports whether to reconnect
+func (p *Publisher) serve(ctx context.Context, client *Client, decisions <-chan power.PowerDecision) bool {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	p.lastHealth = ""
+	p.checkHealth(client)
+	for {
+		select {
+		case decision, ok := <-decisions:
+			if !ok {
+				return false
+			}
+			if err := p.publish(client, p.subjects.decision, decision); err != nil {
+				p.logger.Printf("⚠️  Failed to publish decision to NATS: %v", err)
+			}
+			p.checkHealth(client)
+		case <-ticker.C:
+			p.checkHealth(client)
+		case <-client.Done():
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// checkHealth publishes the health when its status changed
+func (p *Publisher) checkHealth(client *Client) {
+	health := p.source.Health()
+	if health.Status == p.lastHealth {
+		return
+	}
+
+	event := HealthEvent{
+		Node:      p.node,
+		Previous:  p.lastHealth,
+		Health:    health,
+		Timestamp: time.Now(),
+	}
+	if err := p.publish(client, p.subjects.health, event); err != nil {
+		p.logger.Printf("⚠️  Failed to publish health to NATS: %v", err)
+		return
+	}
+	p.lastHealth = health.Status
+}
+
+// publish sends a JSON message, acknowledged by JetStream when enabled
+func (p *Publisher) publish(client *Client, subject string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if !p.jetStream {
+		return client.Publish(subject, payload)
+	}
+	_, err = client.PublishAck(subject, payload, ackTimeout)
+	return err
+}
@@ -0,0 +1,173 @@
+// Package nomad is a minimal client of the HashiCorp Nomad HTTP API, limited
+// to reading and writing the dynamic metadata of a node.
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds a request to the Nomad API
+const requestTimeout = 15 * time.Second
+
+// TLSOptions holds the files of a TLS-enabled Nomad HTTP API
+type TLSOptions struct {
+	CACert     string // CA certificate verifying the agent (empty for the system roots)
+	ClientCert string // Client certificate for mutual TLS (empty if none)
+	ClientKey  string // Key of the client certificate
+}
+
+// Client calls the Nomad HTTP API of an agent
+type Client struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a client of the agent at addr, e.g.
+// http://127.0.0.1:4646, authenticated with an ACL token when not empty
+func NewClient(addr, token string, tlsOpts TLSOptions) (*Client, error) {
+	u, err := url.Parse(addr)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Nomad address %q", addr)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if u.Scheme == "https" {
+		tlsConfig, err := loadTLS(tlsOpts)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &Client{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout, Transport: transport},
+	}, nil
+}
+
+// loadTLS builds the TLS configuration from the certificate files
+func loadTLS(opts TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Nomad CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", opts.CACert)
+		}
+		config.RootCAs = pool
+	}
+	if opts.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Nomad client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// nodeStub is the part of a node listing entry used by the client
+type nodeStub struct {
+	ID   string
+	Name string
+}
+
+// NodeID returns the ID of the node with the given name or ID
+func (c *Client) NodeID(ctx context.Context, name string) (string, error) {
+	var nodes []nodeStub
+	if err := c.do(ctx, http.MethodGet, "/v1/nodes", nil, &nodes); err != nil {
+		return "", err
+	}
+	for _, node := range nodes {
+		if node.Name == name || node.ID == name {
+			return node.ID, nil
+		}
+	}
+	return "", fmt.Errorf("nomad node %q not found", name)
+}
+
+// metadata is the body of the client metadata endpoint
+type metadata struct {
+	Meta map[string]*string
+}
+
+// Metadata returns the metadata of a node, static and dynamic, as seen by
+// its client agent
+func (c *Client) Metadata(ctx context.Context, nodeID string) (map[string]string, error) {
+	var response metadata
+	if err := c.do(ctx, http.MethodGet, "/v1/client/metadata?node_id="+url.QueryEscape(nodeID), nil, &response); err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string, len(response.Meta))
+	for key, value := range response.Meta {
+		if value != nil {
+			meta[key] = *value
+		}
+	}
+	return meta, nil
+}
+
+// ApplyMetadata sets the dynamic metadata of a node: every key is set to
+// its value, or removed when the value is nil
+func (c *Client) ApplyMetadata(ctx context.Context, nodeID string, meta map[string]*string) error {
+	return c.do(ctx, http.MethodPost, "/v1/client/metadata?node_id="+url.QueryEscape(nodeID), metadata{Meta: meta}, nil)
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out when not nil
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("nomad request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("nomad %s %s returned %s: %s", method, strings.SplitN(path, "?", 2)[0], resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid nomad response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package number parses the decimal numbers published by market data
+// sources, in English or European formats.
+package number
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMissing reports a value marked as not available, such as "–" or "n/a"
+var ErrMissing = errors.New("missing value")
+
+// missing holds the placeholders of values that are not available
+var missing = map[string]bool{
+	"": true, "-": true, "–": true, "—": true, "n/a": true, "na": true, "n.a.": true, "nan": true,
+}
+
+// Parse parses a decimal number such as "1,234.5", "1.234,5", "1 234,5"
+// (with a regular, non-breaking or thin space), "1'234.5" or "−12,3".
+// When both '.' and ',' appear, the last one is the decimal separator, and a
+// separator used several times groups thousands. Otherwise a single '.' is the
+// decimal point, and a single ',' groups thousands when it follows one to
+// three digits, not all zero, and precedes exactly three ("1,234"), and is the
+// decimal comma otherwise ("45,67"). Placeholders of missing values return
+// ErrMissing.
+func Parse(s string) (float64, error) {
+	return parse(s, false)
+}
+
+// ParseDecimalComma parses a decimal number like Parse, for sources known to
+// write decimal commas, such as CSV files separated by ';': a single ','
+// is always the decimal separator, "1,234" reading as 1.234.
+func ParseDecimalComma(s string) (float64, error) {
+	return parse(s, true)
+}
+
+// parse parses a decimal number, a single ',' being the decimal separator
+// when decimalComma is set
+func parse(s string, decimalComma bool) (float64, error) {
+	value := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\u2009', '\'', '\u2019':
+			return -1 // Thousands separators and padding
+		case '\u2212':
+			return '-' // Unicode minus sign
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	if missing[strings.ToLower(value)] {
+		return 0, ErrMissing
+	}
+
+	sign := ""
+	if value[0] == '-' || value[0] == '+' {
+		sign, value = value[:1], value[1:]
+	}
+	for _, r := range value {
+		if (r < '0' || r > '9') && r != '.' && r != ',' {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+	}
+
+	value = normalize(value, decimalComma)
+	if value == "" || value == "." {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	f, err := strconv.ParseFloat(sign+value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return f, nil
+}
+
+// normalize rewrites digits with '.' and ',' separators to digits with an
+// optional '.' decimal point, or returns "" when the separators are not
+// consistent
+func normalize(value string, decimalComma bool) string {
+	dot, comma := strings.LastIndexByte(value, '.'), strings.LastIndexByte(value, ',')
+	switch {
+	case dot >= 0 && comma >= 0:
+		decimal, thousands := ".", ","
+		if comma > dot {
+			decimal, thousands = ",", "."
+		}
+		whole, fraction := splitLast(value, decimal)
+		if strings.Contains(fraction, thousands) || !grouped(whole, thousands) {
+			return ""
+		}
+		return strings.ReplaceAll(whole, thousands, "") + "." + fraction
+	case dot >= 0:
+		return separator(value, ".", false)
+	case comma >= 0:
+		return separator(value, ",", decimalComma)
+	}
+	return value
+}
+
+// separator resolves the only separator used in a number: thousands when
+// repeated or a comma grouping three digits, unless it is known to be
+// decimal, and decimal otherwise
+func separator(value, sep string, decimal bool) string {
+	if strings.Count(value, sep) > 1 {
+		if !grouped(value, sep) {
+			return ""
+		}
+		return strings.ReplaceAll(value, sep, "")
+	}
+
+	whole, fraction := splitLast(value, sep)
+	if sep == "," && !decimal && len(fraction) == 3 && len(whole) <= 3 && strings.TrimLeft(whole, "0") != "" {
+		return whole + fraction
+	}
+	return whole + "." + fraction
+}
+
+// splitLast splits a number around the last separator
+func splitLast(value, sep string) (string, string) {
+	i := strings.LastIndex(value, sep)
+	return value[:i], value[i+1:]
+}
+
+// grouped reports whether the thousands separators of a whole part split it
+// into a leading group of one to three digits and groups of three
+func grouped(whole, sep string) bool {
+	groups := strings.Split(whole, sep)
+	if len(groups[0]) == 0 || len(groups[0]) > 3 && len(groups) > 1 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
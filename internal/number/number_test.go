@@ -0,0 +1,61 @@
+package number
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"1,234.5", "1.234,5", "1 234,5", "1'234.5", "−12,3", "45,67", "1,234", "–", "n/a", "1e5", "+", ",5"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := Parse(s)
+		if err != nil {
+			if v != 0 {
+				t.Fatalf("Parse(%q) = %v with error %v", s, v, err)
+			}
+			return
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			t.Fatalf("Parse(%q) = %v", s, v)
+		}
+
+		// Numbers written with a decimal point and no grouping read back
+		// the same, in both formats
+		plain := strconv.FormatFloat(v, 'f', 2, 64)
+		want, _ := strconv.ParseFloat(plain, 64)
+		for _, written := range []string{plain, strings.Replace(plain, ".", ",", 1)} {
+			if got, err := Parse(written); err != nil || got != want {
+				t.Fatalf("Parse(%q) = %v, %v, want %v", written, got, err, want)
+			}
+		}
+	})
+}
+
+func TestParseMissing(t *testing.T) {
+	for _, s := range []string{"", " ", "-", "–", "—", "N/A", "n.a."} {
+		if _, err := Parse(s); !errors.Is(err, ErrMissing) {
+			t.Errorf("Parse(%q) error = %v, want ErrMissing", s, err)
+		}
+	}
+}
+
+func TestParseDecimalComma(t *testing.T) {
+	tests := map[string]float64{
+		"1,234":     1.234,
+		"45,67":     45.67,
+		"1.234,5":   1234.5,
+		"1 234,567": 1234.567,
+		"12.5":      12.5,
+	}
+	for s, want := range tests {
+		if got, err := ParseDecimalComma(s); err != nil || got != want {
+			t.Errorf("ParseDecimalComma(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// degradedFailures is the number of consecutive failed cycles after which
+	// the manager signals a degraded state
+	degradedFailures = 3
+
+	// maxRefreshSkips is the largest number of DATA_REFRESH_RETRY_CRON ticks
+	// skipped between two retries of a failing refresh
+	maxRefreshSkips = 7
+)
+
+// backoff returns the wait before the next cycle after a number of consecutive
+// failed cycles: the interval after the first failure, then doubled after
+// each further one up to FAILURE_BACKOFF_MAX
+func (pm *Manager) backoff(interval time.Duration, failures int) time.Duration {
+	limit := pm.config.BackoffMax
+	if limit <= 0 || interval >= limit {
+		return interval
+	}
+	for i := 1; i < failures && interval < limit; i++ {
+		interval *= 2
+	}
+	return min(interval, limit)
+}
+
+// skipRetry reports whether a retry tick is skipped so the retries of a
+// failing refresh space out: after n consecutive failed fetches, the next
+// 2^(n-1)-1 ticks are skipped, up to maxRefreshSkips. Called with refreshMu
+// held.
+func (pm *Manager) skipRetry() bool {
+	failures := pm.dataStore.GetFetchStatus().ConsecutiveFailures
+	skips := 0
+	for i := 1; i < failures && skips < maxRefreshSkips; i++ {
+		skips = min(2*skips+1, maxRefreshSkips)
+	}
+
+	if pm.refreshSkips >= skips {
+		pm.refreshSkips = 0
+		return false
+	}
+	pm.refreshSkips++
+	if pm.refreshSkips == 1 {
+		pm.logger.Printf("⏳ Data refresh failed %d times in a row, skipping the next %d retries", failures, skips)
+	}
+	return true
+}
+
+// degradedReason returns why the manager runs degraded, empty if it does not
+func (pm *Manager) degradedReason() string {
+	pm.mu.RLock()
+	stats := pm.loopStats
+	active := pm.activeIndex
+	stale := pm.staleData
+	pm.mu.RUnlock()
+
+	switch {
+	case stats.ConsecutiveFailures >= degradedFailures:
+		return fmt.Sprintf("%d consecutive failed cycles: %s", stats.ConsecutiveFailures, stats.LastError)
+	case active > 0:
+		return fmt.Sprintf("data from failover provider %s", pm.chainName(active))
+	case stale:
+		return "following a previous day's market data"
+	}
+	return ""
+}
+
+// updateDegraded sets the degraded gauge, logging and sending an event when
+// the manager enters or leaves the degraded state
+func (pm *Manager) updateDegraded() {
+	reason := pm.degradedReason()
+	degraded := reason != ""
+
+	value := 0.0
+	if degraded {
+		value = 1
+	}
+	pm.metrics.SetGauge("degraded", "Whether the manager runs degraded after repeated failures, on the failover provider or on stale data", value, nil)
+
+	pm.mu.Lock()
+	changed := degraded != pm.degraded
+	pm.degraded = degraded
+	pm.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	title := "Power manager recovered"
+	text := fmt.Sprintf("Node %s: power manager recovered", pm.config.NodeName)
+	if degraded {
+		title = "Power manager degraded"
+		text = fmt.Sprintf("Node %s: power manager degraded (%s)", pm.config.NodeName, reason)
+	}
+	pm.logger.Printf("🩺 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+}
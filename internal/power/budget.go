@@ -0,0 +1,103 @@
+package power
+
+import (
+	"fmt"
+
+	"kcas/new/internal/budget"
+	"kcas/new/internal/config"
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/rapl"
+)
+
+// discoverBudget finds the actuators of BUDGET_SPLIT, in squeeze order. The
+// CPU actuator is a placeholder, its range following the hardware maximum
+// of each cycle.
+func discoverBudget(cfg *config.Config, packages int) ([]budget.Actuator, error) {
+	var actuators []budget.Actuator
+	for _, name := range cfg.BudgetSplit {
+		var actuator budget.Actuator
+		var err error
+		switch name {
+		case budget.CPU:
+			actuator = budget.NewCPU(packages, cfg.RaplLimit, cfg.RaplLimit)
+		case budget.DRAM:
+			actuator, err = budget.DiscoverDRAM(cfg.SysfsRoot, rapl.RaplBasePath, cfg.BudgetFloor)
+		case budget.GPU:
+			actuator, err = budget.DiscoverGPU(cfg.SysfsRoot, cfg.BudgetFloor)
+		default:
+			err = fmt.Errorf("unknown actuator %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		actuators = append(actuators, actuator)
+	}
+	return actuators, nil
+}
+
+// splitBudget divides the node cap among the BUDGET_SPLIT actuators: the
+// power shed below the hardware maximum is taken from the first actuators
+// down to their floor before the next ones. It returns the cap of each RAPL
+// package, the CPU's share, and the limit of every actuator by name, nil
+// when the budget is not split.
+func (pm *Manager) splitBudget(pmax, maxPower int64) (int64, map[string]int64) {
+	if len(pm.budget) == 0 {
+		return pmax, nil
+	}
+	fraction := 1.0
+	if maxPower > pm.config.RaplLimit {
+		fraction = float64(pmax-pm.config.RaplLimit) / float64(maxPower-pm.config.RaplLimit)
+	}
+
+	actuators := make([]budget.Actuator, len(pm.budget))
+	copy(actuators, pm.budget)
+	for i, actuator := range actuators {
+		if actuator.Name == budget.CPU {
+			actuators[i] = budget.NewCPU(actuator.Zones, pm.config.RaplLimit, maxPower)
+		}
+	}
+	limits := make(map[string]int64, len(actuators))
+	cpuCap := pmax
+	for i, limit := range budget.Split(fraction, actuators) {
+		limits[actuators[i].Name] = limit
+		if actuators[i].Name == budget.CPU {
+			cpuCap = actuators[i].ZoneLimit(limit)
+		}
+	}
+	return cpuCap, limits
+}
+
+// writeBudget writes the limits of the actuators other than the CPU, whose
+// share is written to RAPL with the cap
+func (pm *Manager) writeBudget(limits map[string]int64) {
+	for _, actuator := range pm.budget {
+		limit, ok := limits[actuator.Name]
+		if !ok {
+			continue
+		}
+		pm.metrics.SetGauge("budget_limit_uw", "Share of the node budget given to each actuator of BUDGET_SPLIT (µW)",
+			float64(limit), metrics.Labels{"actuator": actuator.Name})
+		if actuator.Name == budget.CPU {
+			continue
+		}
+		for _, err := range actuator.Apply(limit) {
+			pm.logger.Printf("❌ Failed to apply the %s budget: %v", actuator.Name, err)
+		}
+	}
+}
+
+// restoreBudget writes the maximum of the actuators other than the CPU
+func (pm *Manager) restoreBudget() {
+	for _, actuator := range pm.budget {
+		if actuator.Name == budget.CPU {
+			continue
+		}
+		if errs := actuator.Apply(actuator.MaxPower); len(errs) > 0 {
+			for _, err := range errs {
+				pm.logger.Printf("❌ Failed to restore the %s limit: %v", actuator.Name, err)
+			}
+			continue
+		}
+		pm.logger.Printf("✅ Restored the %s limit to %.1f W", actuator.Name, float64(actuator.MaxPower)/1000000)
+	}
+}
@@ -0,0 +1,58 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+// marketClosed returns why the market is closed on the day of t (the holiday
+// name, "weekend" or "closed day"), empty when open or without a calendar.
+// Closing and reopening are logged and sent as an event.
+func (pm *Manager) marketClosed(t time.Time) string {
+	if pm.calendar == nil {
+		return ""
+	}
+	closed, reason := pm.calendar.Closed(t.In(pm.location))
+
+	value := 0.0
+	if closed {
+		value = 1
+	}
+	pm.metrics.SetGauge("market_closed", "Whether the market is closed today (weekend or holiday)", value, nil)
+
+	if reason == pm.closedReason {
+		return reason
+	}
+	pm.closedReason = reason
+
+	title := "Market reopened"
+	text := fmt.Sprintf("Node %s: market open again, restoring the market-based cap", pm.config.NodeName)
+	if closed {
+		title = "Market closed"
+		text = fmt.Sprintf("Node %s: market closed (%s)", pm.config.NodeName, reason)
+		if pm.config.ClosedDayCap.IsSet() {
+			text += fmt.Sprintf(", capping to %s", pm.config.ClosedDayCap)
+		}
+	}
+	pm.logger.Printf("📅 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+	return reason
+}
+
+// closedDayPower returns the cap of closed days for a hardware maximum,
+// within the minimum power and the administrative ceiling
+func (pm *Manager) closedDayPower(maxPower int64) int64 {
+	limit, _, _ := pm.limitPower(pm.config.ClosedDayCap.Resolve(maxPower), maxPower)
+	return limit
+}
+
+// closedDayCap reports whether CLOSED_DAY_CAP pins the cap on the day of t
+func (pm *Manager) closedDayCap(t time.Time) bool {
+	if pm.calendar == nil || !pm.config.ClosedDayCap.IsSet() {
+		return false
+	}
+	closed, _ := pm.calendar.Closed(t.In(pm.location))
+	return closed
+}
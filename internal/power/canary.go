@@ -0,0 +1,235 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"kcas/new/internal/metrics"
+	"kcas/new/internal/rapl"
+)
+
+// canaryTolerance is how far above the canary cap the measured power of the
+// canary domains may be before the cap is deemed not enforced
+const canaryTolerance = 0.1
+
+// raplPowerUnit is the power unit of Intel and AMD RAPL (1/8 W in µW), to
+// which the limits read back are rounded
+const raplPowerUnit = 125000
+
+// canaryRetryDelay is the time before a cap rolled back from the canary
+// domains is tried again
+const canaryRetryDelay = time.Hour
+
+// CanaryStatus describes a raised cap running on the canary domains only
+type CanaryStatus struct {
+	Domains []string  `json:"domains"`
+	CapUW   int64     `json:"cap_uw"` // Raised cap of the canary domains, the others keep the applied power
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"` // End of the verification window
+}
+
+// canaryRollout is a raised cap on trial on the canary domains
+type canaryRollout struct {
+	cap         int64
+	since       time.Time
+	until       time.Time
+	energy      rapl.EnergySample // Energy counters when the trial started
+	writeErrors int               // Failed writes to the canary domains
+}
+
+// resolveCanaryDomains returns the domains receiving raised caps first and
+// the others, or nil when CANARY_WINDOW is not set or the domains do not
+// leave any to compare with
+func resolveCanaryDomains(window time.Duration, configured []string, domains []rapl.Domain) (canary, others []string) {
+	if window <= 0 || len(domains) < 2 {
+		return nil, nil
+	}
+	if len(configured) == 0 {
+		configured = []string{domains[0].ID}
+	}
+	for _, domain := range domains {
+		if slices.Contains(configured, domain.ID) {
+			canary = append(canary, domain.ID)
+		} else {
+			others = append(others, domain.ID)
+		}
+	}
+	if len(canary) == 0 || len(others) == 0 {
+		return nil, nil
+	}
+	return canary, others
+}
+
+// canaryCap returns the cap of the domains outside the canary, and the
+// canary running, if any. A raised cap is applied to the canary domains
+// only for CANARY_WINDOW, and rolled out to the others once the writes, the
+// cycles and the measured power of the canary held up. The first cap,
+// lowered caps and overrides apply everywhere at once: delaying them would
+// keep the node above a ceiling, a UPS or peak shaving limit, or an
+// administrator's decision.
+func (pm *Manager) canaryCap(target int64, immediate bool) (int64, *CanaryStatus) {
+	if pm.canaryIDs == nil {
+		return target, nil
+	}
+	current := pm.lastApplied
+	if immediate || current == 0 || target <= current {
+		if pm.canary != nil {
+			pm.logger.Printf("🐤 Canary of %d µW superseded by a cap of %d µW on every domain", pm.canary.cap, target)
+			pm.endCanary()
+		}
+		return target, nil
+	}
+
+	now := pm.now()
+	if r := pm.canary; r != nil && r.cap == target {
+		if err := pm.verifyCanary(r, !now.Before(r.until)); err != nil {
+			pm.rollbackCanary(r, err, now)
+			return current, nil
+		}
+		if now.Before(r.until) {
+			return current, pm.canaryStatus(r)
+		}
+		pm.promoteCanary(r)
+		return target, nil
+	}
+
+	if target == pm.canaryRejected && now.Before(pm.canaryRetryAt) {
+		pm.logger.Printf("🐤 Cap of %d µW was rolled back from the canary domains, keeping %d µW until %s",
+			target, current, pm.canaryRetryAt.Format("15:04:05"))
+		return current, nil
+	}
+
+	r := &canaryRollout{cap: target, since: now, until: now.Add(pm.config.CanaryWindow), energy: pm.lastEnergy}
+	pm.canary = r
+	pm.metrics.SetGauge("canary_active", "Whether a raised cap runs on the canary domains only", 1, nil)
+	pm.logger.Printf("🐤 Raising the cap of canary domains %v to %d µW (%.1f W) until %s, the others keep %d µW (%.1f W)",
+		pm.canaryIDs, target, float64(target)/1000000, r.until.Format("15:04:05"), current, float64(current)/1000000)
+	return current, pm.canaryStatus(r)
+}
+
+// canaryStatus describes a rollout for the decisions
+func (pm *Manager) canaryStatus(r *canaryRollout) *CanaryStatus {
+	return &CanaryStatus{Domains: pm.canaryIDs, CapUW: r.cap, Since: r.since, Until: r.until}
+}
+
+// verifyCanary checks a rollout: no failed writes to the canary domains and
+// no failed cycle during the window, then at its end the limits read back
+// from the canary domains and, with energy counters, their measured power
+func (pm *Manager) verifyCanary(r *canaryRollout, final bool) error {
+	if r.writeErrors > 0 {
+		return fmt.Errorf("%d failed writes to the canary domains", r.writeErrors)
+	}
+	pm.mu.RLock()
+	failures, lastError := pm.loopStats.ConsecutiveFailures, pm.loopStats.LastError
+	pm.mu.RUnlock()
+	if failures > 0 {
+		return fmt.Errorf("adjustment cycle failed during the canary: %s", lastError)
+	}
+	if !final {
+		return nil
+	}
+
+	if pm.raplMgr.Backend() != rapl.BackendCPUFreq {
+		// RAPL rounds the limits to its power unit, and clamps them to the
+		// maximum of each constraint
+		tolerance := max(raplPowerUnit, int64(float64(r.cap)*canaryTolerance/10))
+		maxima := pm.constraintMaxima()
+		for _, domain := range pm.raplMgr.ReadCurrentLimits() {
+			if !slices.Contains(pm.canaryIDs, domain.ID) {
+				continue
+			}
+			for _, constraint := range domain.Constraints {
+				if limit, ok := maxima[constraint.Path]; ok && limit > 0 && limit < r.cap {
+					continue
+				}
+				value, err := strconv.ParseInt(constraint.Value, 10, 64)
+				if err != nil || value < r.cap-tolerance || value > r.cap+tolerance {
+					return fmt.Errorf("%s reads %q instead of the canary cap %d µW", constraint.Path, constraint.Value, r.cap)
+				}
+			}
+		}
+	}
+
+	if r.energy.Counters != nil && pm.lastEnergy.Time.After(r.energy.Time) {
+		power, err := pm.raplMgr.AverageDomainPower(r.energy, pm.lastEnergy, pm.canaryIDs)
+		if err != nil {
+			return fmt.Errorf("measuring the canary domains: %w", err)
+		}
+		limit := float64(r.cap) * float64(len(pm.canaryIDs)) * (1 + canaryTolerance)
+		if float64(power) > limit {
+			return fmt.Errorf("canary domains drew %.1f W, above their cap of %.1f W each", float64(power)/1000000, float64(r.cap)/1000000)
+		}
+	}
+	return nil
+}
+
+// constraintMaxima returns the maximum power of the constraints of the RAPL
+// domains, by the path of their limit
+func (pm *Manager) constraintMaxima() map[string]int64 {
+	maxima := make(map[string]int64)
+	for _, domain := range pm.raplMgr.GetDomains() {
+		for _, limit := range domain.ConstraintsMax {
+			value, err := strconv.ParseInt(limit.Value, 10, 64)
+			if err != nil {
+				continue
+			}
+			for _, constraint := range domain.Constraints {
+				if constraint.ID == limit.ID {
+					maxima[constraint.Path] = value
+				}
+			}
+		}
+	}
+	return maxima
+}
+
+// promoteCanary ends a verified rollout, the cap now applying everywhere
+func (pm *Manager) promoteCanary(r *canaryRollout) {
+	pm.endCanary()
+	pm.metrics.AddCounter("canary_rollouts_total", "Number of canary rollouts of raised caps by outcome", 1, metrics.Labels{"outcome": "promoted"})
+	text := fmt.Sprintf("Node %s: cap of %.1f W verified on canary domains %v since %s, applying it to every domain",
+		pm.config.NodeName, float64(r.cap)/1000000, pm.canaryIDs, r.since.Format("15:04:05"))
+	pm.logger.Printf("✅ %s", text)
+	if pm.events != nil {
+		pm.events.Event("Canary cap promoted", text, map[string]string{"node": pm.config.NodeName})
+	}
+}
+
+// rollbackCanary ends a failed rollout, the canary domains returning to the
+// applied cap, and holds the cap back for canaryRetryDelay
+func (pm *Manager) rollbackCanary(r *canaryRollout, reason error, now time.Time) {
+	pm.endCanary()
+	pm.canaryRejected, pm.canaryRetryAt = r.cap, now.Add(canaryRetryDelay)
+	pm.metrics.AddCounter("canary_rollouts_total", "Number of canary rollouts of raised caps by outcome", 1, metrics.Labels{"outcome": "rolled_back"})
+	text := fmt.Sprintf("Node %s: cap of %.1f W rolled back from canary domains %v: %v",
+		pm.config.NodeName, float64(r.cap)/1000000, pm.canaryIDs, reason)
+	pm.logger.Printf("❌ %s", text)
+	if pm.events != nil {
+		pm.events.Event("Canary cap rolled back", text, map[string]string{"node": pm.config.NodeName})
+	}
+}
+
+// endCanary drops the running rollout
+func (pm *Manager) endCanary() {
+	pm.canary = nil
+	pm.metrics.SetGauge("canary_active", "Whether a raised cap runs on the canary domains only", 0, nil)
+}
+
+// writeLimits writes the cap to every domain, or to the domains outside the
+// canary while a raised cap runs on it
+func (pm *Manager) writeLimits(ctx context.Context, pmax int64) []error {
+	r := pm.canary
+	if r == nil {
+		return pm.raplMgr.ApplyPowerLimitsContext(ctx, pmax)
+	}
+
+	errs := pm.raplMgr.ApplyDomainLimitsContext(ctx, pmax, pm.canaryOthers)
+	if canaryErrs := pm.raplMgr.ApplyDomainLimitsContext(ctx, r.cap, pm.canaryIDs); len(canaryErrs) > 0 {
+		r.writeErrors += len(canaryErrs)
+		errs = append(errs, canaryErrs...)
+	}
+	return errs
+}
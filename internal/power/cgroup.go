@@ -0,0 +1,38 @@
+package power
+
+import "kcas/new/internal/cgroup"
+
+// throttleCgroups sets the CPU bandwidth of the CGROUP_SLICES for the
+// applied cap, so that their workloads yield CPU time to the others before
+// RAPL slows every core alike
+func (pm *Manager) throttleCgroups(pmax, maxPower int64) {
+	if pm.cgroups == nil {
+		return
+	}
+	cpus, errs := pm.cgroups.Apply(pmax, pm.config.RaplLimit, maxPower)
+	for _, err := range errs {
+		pm.logger.Printf("❌ Failed to throttle cgroup: %v", err)
+	}
+	if len(errs) == len(pm.cgroups.Slices()) {
+		return
+	}
+	if cpus != pm.cgroupCPUs {
+		pm.logger.Printf("🧮 CPU bandwidth of %v set to %.2f CPUs", pm.cgroups.Slices(), cpus)
+	}
+	pm.cgroupCPUs = cpus
+	pm.metrics.SetGauge("cgroup_cpu_limit_cpus", "CPU bandwidth left to each of the CGROUP_SLICES (CPUs)", cpus, nil)
+}
+
+// restoreCgroups lifts the CPU bandwidth limit of the CGROUP_SLICES
+func (pm *Manager) restoreCgroups() {
+	if pm.cgroups == nil {
+		return
+	}
+	if errs := pm.cgroups.Restore(); len(errs) > 0 {
+		for _, err := range errs {
+			pm.logger.Printf("❌ Failed to restore cgroup: %v", err)
+		}
+		return
+	}
+	pm.logger.Printf("✅ Restored the CPU bandwidth of %v to %s", pm.cgroups.Slices(), cgroup.Unlimited)
+}
@@ -0,0 +1,23 @@
+package power
+
+// withinDeadband reports whether a cap is closer to the applied one than
+// CAP_DEADBAND, so that the cycle keeps the applied cap without writing RAPL
+// or the node. The first cap, overrides, canaries and the cycle after a
+// failed one are always written.
+func (pm *Manager) withinDeadband(pmax, hardwareMax int64, override bool) bool {
+	if !pm.config.CapDeadband.IsSet() || pm.lastApplied == 0 || override || pm.canary != nil {
+		return false
+	}
+	pm.mu.RLock()
+	failures := pm.loopStats.ConsecutiveFailures
+	pm.mu.RUnlock()
+	if failures > 0 {
+		return false
+	}
+
+	change := pmax - pm.lastApplied
+	if change < 0 {
+		change = -change
+	}
+	return change < pm.config.CapDeadband.Resolve(hardwareMax)
+}
@@ -0,0 +1,142 @@
+package power
+
+import (
+	"sync"
+	"time"
+)
+
+// Clamp reasons recorded in a PowerDecision
+const (
+	ClampNone        = "none"
+	ClampHardwareMax = "hardware_max"
+	ClampMinPower    = "min_power"
+	ClampAdminMax    = "admin_max"
+	ClampOverride    = "override"
+	ClampBattery     = "ups_battery"
+	ClampPeakShaving = "peak_shaving"
+	ClampBatteryLow  = "battery_low"
+	ClampBatteryFull = "battery_full"
+	ClampBatterySoC  = "battery_soc"
+	ClampPriceSpike  = "price_spike"
+	ClampClosedDay   = "closed_day"
+)
+
+// decisionHistorySize is the number of decisions kept, a day at the default
+// five-minute interval
+const decisionHistorySize = 288
+
+// PowerDecision records the inputs and outcome of one adjustment cycle
+type PowerDecision struct {
+	Timestamp        time.Time        `json:"timestamp"`
+	Node             string           `json:"node"`
+	Provider         string           `json:"provider"`
+	Period           string           `json:"period"`
+	PeriodFound      bool             `json:"period_found"`
+	Volume           float64          `json:"volume_mwh"`
+	Price            float64          `json:"price_eur_mwh"` // Per MWh in Currency, despite the historical name
+	Currency         string           `json:"currency"`
+	ReferenceVolume  float64          `json:"reference_volume_mwh"`
+	DataPoints       int              `json:"data_points"`
+	DataUpdatedAt    time.Time        `json:"data_updated_at"`
+	DataAge          string           `json:"data_age"`
+	DataDate         string           `json:"data_date,omitempty"`  // Market day of the data, a previous one when stale
+	DataStale        bool             `json:"data_stale,omitempty"` // The data of a previous day stands in for today's
+	Confidence       float64          `json:"confidence,omitempty"` // Derating of the cap above the minimum for stale data, 0 when not derated
+	HardwareMax      int64            `json:"hardware_max_uw"`
+	AdminMax         int64            `json:"admin_max_uw,omitempty"`
+	MinPower         int64            `json:"min_power_uw"`
+	SourcePower      int64            `json:"source_power_uw"`
+	AppliedPower     int64            `json:"applied_power_uw"`
+	Formula          string           `json:"formula"`
+	Clamp            string           `json:"clamp"`
+	WallPower        int64            `json:"wall_power_uw,omitempty"`        // Measured by the external meter
+	EnergyWh         float64          `json:"energy_wh,omitempty"`            // Drawn since the previous cycle, under its cap
+	Cost             float64          `json:"cost,omitempty"`                 // Of EnergyWh at the price of the previous cycle's period, in Currency
+	EnergyEstimated  bool             `json:"energy_estimated,omitempty"`     // EnergyWh is drawn at the applied cap, nothing being measured
+	DailyEnergyWh    float64          `json:"daily_energy_wh,omitempty"`      // Drawn since the market midnight
+	DailyCost        float64          `json:"daily_cost,omitempty"`           // Of DailyEnergyWh, in Currency
+	PlatformOverhead int64            `json:"platform_overhead_uw,omitempty"` // Subtracted from the limit by the closed loop
+	SiteDemand       int64            `json:"site_demand_uw,omitempty"`       // Site meter reading during peak shaving
+	PeakShaving      float64          `json:"peak_shaving,omitempty"`         // Share of the cap above the minimum shed
+	BatterySoC       *float64         `json:"battery_soc,omitempty"`          // State of charge of the site battery (%)
+	PVPower          int64            `json:"pv_power_uw,omitempty"`          // Solar production of the site
+	MarketClosed     string           `json:"market_closed,omitempty"`        // Why the market is closed today: holiday, weekend or closed day
+	Fallbacks        []string         `json:"fallbacks,omitempty"`
+	Shadows          []ShadowDecision `json:"shadows,omitempty"` // Caps of the SHADOW_CALC_MODES, not applied
+	Override         *Override        `json:"override,omitempty"`
+	Canary           *CanaryStatus    `json:"canary,omitempty"`         // Raised cap running on the canary domains only
+	Deadband         bool             `json:"deadband,omitempty"`       // The change was within CAP_DEADBAND: nothing was written
+	Budget           map[string]int64 `json:"budget_uw,omitempty"`      // Limit of each BUDGET_SPLIT actuator, over all its zones
+	CgroupCPUs       float64          `json:"cgroup_cpus,omitempty"`    // CPU bandwidth left to each of the CGROUP_SLICES
+	OfflineCPUs      int              `json:"offline_cpus,omitempty"`   // CPUs taken offline for a shed request the minimum cap cannot meet
+	IdlePolicy       string           `json:"idle_policy,omitempty"`    // Idle policy of the CPUs: tight or relaxed (IDLE_RELAX_BELOW)
+	ExperimentArm    string           `json:"experiment_arm,omitempty"` // Arm of the node in a policy experiment (EXPERIMENT_LABEL)
+	Reinitialized    string           `json:"reinitialized,omitempty"`  // Why the node was initialized again, its annotations being lost
+}
+
+// LastDecision returns the most recent decision, if any cycle has completed
+func (pm *Manager) LastDecision() (PowerDecision, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.lastDecision == nil {
+		return PowerDecision{}, false
+	}
+	return *pm.lastDecision, true
+}
+
+// setLastDecision stores the decision of the current cycle
+func (pm *Manager) setLastDecision(decision PowerDecision) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.lastDecision = &decision
+	pm.history = append(pm.history, decision)
+	if len(pm.history) > decisionHistorySize {
+		pm.history = pm.history[len(pm.history)-decisionHistorySize:]
+	}
+
+	for subscriber := range pm.subscribers {
+		select {
+		case subscriber <- decision:
+		default:
+			// A slow subscriber misses decisions rather than stalling the cycle
+		}
+	}
+}
+
+// SubscribeDecisions returns a channel receiving every new decision and a
+// function ending the subscription, which closes the channel
+func (pm *Manager) SubscribeDecisions() (<-chan PowerDecision, func()) {
+	ch := make(chan PowerDecision, 16)
+
+	pm.mu.Lock()
+	if pm.subscribers == nil {
+		pm.subscribers = make(map[chan PowerDecision]struct{})
+	}
+	pm.subscribers[ch] = struct{}{}
+	pm.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			pm.mu.Lock()
+			delete(pm.subscribers, ch)
+			pm.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// DecisionHistory returns up to limit of the most recent decisions, oldest
+// first; limit <= 0 returns all kept decisions
+func (pm *Manager) DecisionHistory(limit int) []PowerDecision {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	history := pm.history
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return append([]PowerDecision(nil), history...)
+}
@@ -0,0 +1,226 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+// demandEventHistorySize is the number of demand-response events kept for
+// compliance reports
+const demandEventHistorySize = 50
+
+// demandResponseSetBy is recorded as the author of demand-response overrides
+const demandResponseSetBy = "demand-response"
+
+// ShedRequest asks the node to reduce its power for a bounded duration
+type ShedRequest struct {
+	ID       string        // Event identifier of the aggregator
+	ReduceUW int64         // Requested power reduction (µW)
+	Duration time.Duration // Length of the shed
+}
+
+// DemandEvent is an accepted shed request and its compliance so far
+type DemandEvent struct {
+	ID         string    `json:"id"`
+	ReduceUW   int64     `json:"reduce_uw"`
+	BaselineUW int64     `json:"baseline_uw"` // Market-based cap when the request was received
+	TargetUW   int64     `json:"target_uw"`   // Temporary cap applied for the shed
+	Limited    bool      `json:"limited"`     // The target was raised to the minimum power or lowered to a ceiling
+	ReceivedAt time.Time `json:"received_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Status     string    `json:"status"` // active, completed or superseded
+
+	OfflineCPUs int `json:"offline_cpus,omitempty"` // CPUs taken offline, the minimum cap falling short of the reduction
+
+	// Compliance measured from the RAPL energy counters
+	MeasuredBaselineUW int64   `json:"measured_baseline_uw,omitempty"` // Measured power before the shed
+	Samples            int     `json:"samples"`
+	AvgMeasuredUW      int64   `json:"avg_measured_uw,omitempty"`
+	MaxMeasuredUW      int64   `json:"max_measured_uw,omitempty"`
+	DeliveredReduceUW  int64   `json:"delivered_reduce_uw,omitempty"`
+	ComplianceRatio    float64 `json:"compliance_ratio"` // Delivered over requested reduction
+
+	measuredTotalUW     int64 // Sum of the samples
+	measuredBaselineSet bool  // MeasuredBaselineUW is known
+}
+
+// Demand-response event states
+const (
+	DemandActive     = "active"
+	DemandCompleted  = "completed"
+	DemandSuperseded = "superseded"
+)
+
+// ShedLoad converts a shed request into a temporary cap below the
+// market-based cap and returns the accepted event. A new request supersedes
+// the active one rather than stacking on it, and requests are rejected while
+// a manual override is in effect.
+func (pm *Manager) ShedLoad(req ShedRequest) (DemandEvent, error) {
+	if req.ID == "" {
+		return DemandEvent{}, fmt.Errorf("event id is required")
+	}
+	if req.ReduceUW <= 0 {
+		return DemandEvent{}, fmt.Errorf("reduction must be positive, got %d µW", req.ReduceUW)
+	}
+	if req.Duration <= 0 || req.Duration > pm.config.DRMaxDuration {
+		return DemandEvent{}, fmt.Errorf("duration must be between 0 and %v, got %v", pm.config.DRMaxDuration, req.Duration)
+	}
+
+	last, ok := pm.LastDecision()
+	if !ok {
+		return DemandEvent{}, fmt.Errorf("no power cap applied yet")
+	}
+
+	baseline, _, _ := pm.limitPower(last.SourcePower, last.HardwareMax)
+	now := time.Now()
+	event := DemandEvent{
+		ID:         req.ID,
+		ReduceUW:   req.ReduceUW,
+		BaselineUW: baseline,
+		TargetUW:   baseline - req.ReduceUW,
+		ReceivedAt: now,
+		EndsAt:     now.Add(req.Duration),
+		Status:     DemandActive,
+	}
+	if event.TargetUW < pm.config.RaplLimit {
+		event.TargetUW = pm.config.RaplLimit
+		event.Limited = true
+	}
+	if power, clamp := pm.overridePower(Override{PowerUW: event.TargetUW}, last.HardwareMax); clamp != ClampOverride {
+		event.TargetUW = power
+		event.Limited = true
+	}
+
+	pm.mu.Lock()
+	for i := range pm.demandEvents {
+		if pm.demandEvents[i].ID == req.ID {
+			pm.mu.Unlock()
+			return DemandEvent{}, fmt.Errorf("event %s was already received", req.ID)
+		}
+	}
+	if o := pm.override; o != nil && o.SetBy != demandResponseSetBy && o.ExpiresAt.After(now) {
+		pm.mu.Unlock()
+		return DemandEvent{}, fmt.Errorf("a manual override by %s is in effect until %s", o.SetBy, o.ExpiresAt.Format(time.RFC3339))
+	}
+	pm.mu.Unlock()
+
+	err := pm.setOverride(Override{
+		PowerUW:   event.TargetUW,
+		Reason:    fmt.Sprintf("demand response %s: reduce %.1f W", req.ID, float64(req.ReduceUW)/1000000),
+		SetBy:     demandResponseSetBy,
+		SetAt:     now,
+		ExpiresAt: event.EndsAt,
+	}, false)
+	if err != nil {
+		return DemandEvent{}, err
+	}
+
+	pm.mu.Lock()
+	if pm.lastMeasured > 0 {
+		event.MeasuredBaselineUW = pm.lastMeasured
+		event.measuredBaselineSet = true
+	}
+	for i := range pm.demandEvents {
+		if previous := &pm.demandEvents[i]; previous.Status == DemandActive {
+			previous.Status = DemandSuperseded
+			previous.EndsAt = now
+			// Power is already reduced, measure against the original baseline
+			event.MeasuredBaselineUW = previous.MeasuredBaselineUW
+			event.measuredBaselineSet = previous.measuredBaselineSet
+		}
+	}
+	pm.demandEvents = append(pm.demandEvents, event)
+	if len(pm.demandEvents) > demandEventHistorySize {
+		pm.demandEvents = pm.demandEvents[len(pm.demandEvents)-demandEventHistorySize:]
+	}
+	pm.mu.Unlock()
+
+	pm.logger.Printf("📉 Demand-response event %s: reduce %.1f W for %v, cap %.1f W → %.1f W",
+		req.ID, float64(req.ReduceUW)/1000000, req.Duration, float64(event.BaselineUW)/1000000, float64(event.TargetUW)/1000000)
+	pm.metrics.AddCounter("demand_response_events_total", "Number of accepted demand-response shed requests", 1, nil)
+	pm.TriggerAdjust()
+	return event, nil
+}
+
+// DemandEvents returns the recent demand-response events, oldest first
+func (pm *Manager) DemandEvents() []DemandEvent {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.completeDemandEvents(time.Now())
+	events := make([]DemandEvent, len(pm.demandEvents))
+	copy(events, pm.demandEvents)
+	return events
+}
+
+// DemandEvent returns the demand-response event with the given id
+func (pm *Manager) DemandEvent(id string) (DemandEvent, bool) {
+	for _, event := range pm.DemandEvents() {
+		if event.ID == id {
+			return event, true
+		}
+	}
+	return DemandEvent{}, false
+}
+
+// recordDemandSample adds a measured power sample, averaged over the
+// previous cycle, to the compliance of the active demand-response event.
+// Callers must not hold pm.mu.
+func (pm *Manager) recordDemandSample(measured int64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	now := time.Now()
+	pm.lastMeasured = measured
+	for i := range pm.demandEvents {
+		event := &pm.demandEvents[i]
+		if event.Status != DemandActive || now.Sub(event.ReceivedAt) < pm.config.StabilisationTime {
+			// Samples spanning the request time mostly measure the baseline
+			continue
+		}
+		event.Samples++
+		event.measuredTotalUW += measured
+		event.AvgMeasuredUW = event.measuredTotalUW / int64(event.Samples)
+		if measured > event.MaxMeasuredUW {
+			event.MaxMeasuredUW = measured
+		}
+		if event.measuredBaselineSet {
+			event.DeliveredReduceUW = event.MeasuredBaselineUW - event.AvgMeasuredUW
+			event.ComplianceRatio = float64(event.DeliveredReduceUW) / float64(event.ReduceUW)
+		}
+		pm.metrics.SetGauge("demand_response_compliance_ratio",
+			"Delivered over requested reduction of the active demand-response event", event.ComplianceRatio, nil)
+	}
+	pm.completeDemandEvents(now)
+}
+
+// supersedeDemandEvents ends the active event when its override is replaced
+// or cleared, the cap no longer following the shed. Callers must hold pm.mu.
+func (pm *Manager) supersedeDemandEvents(now time.Time) {
+	for i := range pm.demandEvents {
+		if event := &pm.demandEvents[i]; event.Status == DemandActive {
+			event.Status = DemandSuperseded
+			event.EndsAt = now
+			pm.logger.Printf("📉 Demand-response event %s superseded by a manual override change", event.ID)
+		}
+	}
+}
+
+// completeDemandEvents marks events past their end as completed. Callers
+// must hold pm.mu.
+func (pm *Manager) completeDemandEvents(now time.Time) {
+	for i := range pm.demandEvents {
+		event := &pm.demandEvents[i]
+		if event.Status == DemandActive && !now.Before(event.EndsAt) {
+			event.Status = DemandCompleted
+			pm.logger.Printf("📉 Demand-response event %s completed: delivered %.1f W of %.1f W (%.0f%%)", event.ID,
+				float64(event.DeliveredReduceUW)/1000000, float64(event.ReduceUW)/1000000, event.ComplianceRatio*100)
+			if pm.events != nil {
+				pm.events.Event("Demand-response event completed",
+					fmt.Sprintf("Node %s: event %s delivered %.1f W of the requested %.1f W reduction",
+						pm.config.NodeName, event.ID, float64(event.DeliveredReduceUW)/1000000, float64(event.ReduceUW)/1000000),
+					map[string]string{"node": pm.config.NodeName, "event": event.ID})
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package power
+
+import (
+	"kcas/new/internal/config"
+)
+
+// SetConfigLoader sets the function re-reading the declared configuration.
+// Each cycle the declared configuration is compared with the running one and
+// the config_drift metric reports whether they differ.
+func (pm *Manager) SetConfigLoader(loader func() (*config.Config, error)) {
+	pm.configLoader = loader
+}
+
+// checkConfigDrift compares the running configuration with the declared one
+func (pm *Manager) checkConfigDrift() {
+	if pm.configLoader == nil {
+		return
+	}
+
+	declared, err := pm.configLoader()
+	if err != nil {
+		pm.logger.Printf("⚠️  Failed to reload the declared configuration: %v", err)
+		return
+	}
+
+	declaredHash := config.Hash(declared)
+	drift := declaredHash != pm.configHash
+	if drift && declaredHash != pm.declaredHash {
+		pm.logger.Printf("⚠️  Configuration drift: running %s, declared %s (restart to apply)", pm.configHash, declaredHash)
+	} else if !drift && pm.declaredHash != "" && pm.declaredHash != pm.configHash {
+		pm.logger.Printf("✅ Declared configuration matches the running one again (%s)", pm.configHash)
+	}
+	pm.declaredHash = declaredHash
+
+	value := 0.0
+	if drift {
+		value = 1
+	}
+	pm.metrics.SetGauge("config_drift", "Whether the declared configuration differs from the running one (1) or not (0)", value, nil)
+}
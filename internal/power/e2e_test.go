@@ -0,0 +1,716 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var e2eDay = time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+// peakAtNoon is a day whose volume peaks at 12:00 and is a quarter of the
+// peak at night
+func peakAtNoon(hour, minute int) float64 {
+	if hour == 12 && minute == 0 {
+		return 400
+	}
+	return 100
+}
+
+func TestE2ECapFollowsVolume(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMaxPower {
+		t.Errorf("cap at the peak = %d µW, want the hardware maximum %d µW", got, testMaxPower)
+	}
+
+	h.setTime(e2eDay.Add(3 * time.Hour))
+	h.cycle()
+	want := int64(testMaxPower / 4)
+	for _, zone := range []string{"intel-rapl:0", "intel-rapl:1"} {
+		if got := h.limit(zone); got != want {
+			t.Errorf("%s cap at night = %d µW, want %d µW", zone, got, want)
+		}
+	}
+	if got := h.annotation(AnnotationPmax); got != strconv.FormatInt(want, 10) {
+		t.Errorf("pmax annotation = %q, want %d", got, want)
+	}
+	if got := h.annotation(AnnotationState); got != StateRunning {
+		t.Errorf("state annotation = %q, want %q", got, StateRunning)
+	}
+}
+
+func TestE2EMissingData(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), nil)
+	if err := h.pm.LoadData(e2eDay); err == nil {
+		t.Fatal("load data succeeded without data for the day or the day before")
+	}
+
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMinPower {
+		t.Errorf("cap without data = %d µW, want the minimum %d µW", got, testMinPower)
+	}
+	if health := h.pm.Health(); !health.Healthy {
+		t.Errorf("health without data = %+v, want healthy on the minimum power", health)
+	}
+}
+
+func TestE2EMidnightRollover(t *testing.T) {
+	next := e2eDay.AddDate(0, 0, 1)
+	h := newHarness(t, e2eDay.Add(23*time.Hour+50*time.Minute), nil)
+	h.provider.setDay(e2eDay, func(hour, minute int) float64 { return 100 })
+	h.provider.setDay(next, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMaxPower {
+		t.Errorf("cap on a flat day = %d µW, want %d µW", got, testMaxPower)
+	}
+
+	h.setTime(next.Add(5 * time.Minute))
+	h.pm.rolloverData()
+	h.cycle()
+	if got, want := h.limit("intel-rapl:0"), int64(testMaxPower/4); got != want {
+		t.Errorf("cap after midnight = %d µW, want %d µW from the new day's data", got, want)
+	}
+}
+
+func TestE2ERAPLWriteFailure(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	// A directory in place of the limit file makes every write fail
+	broken := filepath.Join(h.sysfs, "sys/devices/virtual/powercap/intel-rapl/intel-rapl:1/constraint_1_power_limit_uw")
+	if err := os.Remove(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(broken, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	h.cycle()
+	want := int64(testMaxPower / 4)
+	for _, zone := range []string{"intel-rapl:0", "intel-rapl:1"} {
+		if got := h.limit(zone); got != want {
+			t.Errorf("%s cap = %d µW, want %d µW despite the failed write", zone, got, want)
+		}
+	}
+}
+
+func TestE2ENodeUpdateFailure(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	restore := h.failNodeUpdates()
+	for i := 0; i < degradedFailures; i++ {
+		h.cycle()
+	}
+	health := h.pm.Health()
+	if health.Loop.ConsecutiveFailures != degradedFailures || health.Status != HealthDegraded {
+		t.Errorf("health after %d failed updates = %s with %d failures", degradedFailures, health.Status, health.Loop.ConsecutiveFailures)
+	}
+	if wait := h.pm.backoff(h.pm.config.StabilisationTime, degradedFailures); wait <= h.pm.config.StabilisationTime {
+		t.Errorf("wait after %d failures = %v, want more than %v", degradedFailures, wait, h.pm.config.StabilisationTime)
+	}
+
+	restore()
+	h.cycle()
+	if health := h.pm.Health(); health.Status != HealthOK {
+		t.Errorf("health after recovery = %s (%s), want %s", health.Status, health.Reason, HealthOK)
+	}
+	if got := h.annotation(AnnotationPmax); got != strconv.Itoa(testMaxPower/4) {
+		t.Errorf("pmax annotation after recovery = %q, want %d", got, testMaxPower/4)
+	}
+}
+
+func TestE2ECanaryRollout(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), map[string]string{"CANARY_WINDOW": "10m"})
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	h.cycle()
+
+	// The raised cap runs on the first zone only until the window ends
+	night := int64(testMaxPower / 4)
+	h.setTime(e2eDay.Add(12 * time.Hour))
+	h.cycle()
+	if got0, got1 := h.limit("intel-rapl:0"), h.limit("intel-rapl:1"); got0 != testMaxPower || got1 != night {
+		t.Errorf("caps during the canary = %d, %d µW, want %d, %d µW", got0, got1, int64(testMaxPower), night)
+	}
+	h.setTime(e2eDay.Add(12*time.Hour + 10*time.Minute))
+	h.cycle()
+	if got := h.limit("intel-rapl:1"); got != testMaxPower {
+		t.Errorf("cap of the other zone after the window = %d µW, want %d µW", got, testMaxPower)
+	}
+
+	// A failed write to the canary zone rolls the raised cap back
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	broken := filepath.Join(h.sysfs, "sys/devices/virtual/powercap/intel-rapl/intel-rapl:0/constraint_1_power_limit_uw")
+	if err := os.Remove(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(broken, 0755); err != nil {
+		t.Fatal(err)
+	}
+	h.setTime(e2eDay.Add(12 * time.Hour))
+	h.cycle()
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != night {
+		t.Errorf("canary cap after a failed write = %d µW, want the previous %d µW", got, night)
+	}
+}
+
+func TestE2EPreviousDayFallback(t *testing.T) {
+	yesterday := e2eDay.AddDate(0, 0, -1)
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	h.provider.setDay(yesterday, peakAtNoon)
+	if err := h.pm.dataStore.PrefetchData(context.Background(), yesterday); err != nil {
+		t.Fatalf("store yesterday's data: %v", err)
+	}
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	// A day old, the cap above the minimum is derated by FALLBACK_DECAY
+	h.cycle()
+	if got, want := h.limit("intel-rapl:0"), int64(testMinPower+0.9*(testMaxPower/4-testMinPower)); got != want {
+		t.Errorf("cap on yesterday's data = %d µW, want %d µW", got, want)
+	}
+	if got := h.annotation(AnnotationDataStale); got != "true" {
+		t.Errorf("data-stale annotation = %q, want true", got)
+	}
+	if decision, _ := h.pm.LastDecision(); !decision.DataStale || decision.DataDate != yesterday.Format("2006-01-02") {
+		t.Errorf("decision data = %s (stale %v), want yesterday's marked stale", decision.DataDate, decision.DataStale)
+	}
+	if health := h.pm.Health(); health.Status != HealthDegraded {
+		t.Errorf("health on stale data = %s, want %s", health.Status, HealthDegraded)
+	}
+
+	// The pending refresh fetches today's data once published
+	h.provider.setDay(e2eDay, func(hour, minute int) float64 { return 100 })
+	h.pm.refreshData()
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMaxPower {
+		t.Errorf("cap on today's data = %d µW, want %d µW", got, testMaxPower)
+	}
+	if got := h.annotation(AnnotationDataStale); got != "false" {
+		t.Errorf("data-stale annotation after the refresh = %q, want false", got)
+	}
+	if health := h.pm.Health(); health.Status != HealthOK {
+		t.Errorf("health after the refresh = %s (%s), want %s", health.Status, health.Reason, HealthOK)
+	}
+}
+
+func TestE2EMultiDayFallback(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	for _, age := range []int{2, 7} {
+		day := e2eDay.AddDate(0, 0, -age)
+		h.provider.setDay(day, func(hour, minute int) float64 { return float64(age) })
+		if err := h.pm.dataStore.PrefetchData(context.Background(), day); err != nil {
+			t.Fatalf("store the data of %d days ago: %v", age, err)
+		}
+	}
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	// The same weekday of the previous week wins over a more recent day
+	h.cycle()
+	decision, _ := h.pm.LastDecision()
+	if want := e2eDay.AddDate(0, 0, -7).Format("2006-01-02"); decision.DataDate != want {
+		t.Errorf("fallback data of %s, want the same weekday %s", decision.DataDate, want)
+	}
+	confidence := math.Pow(0.9, 7)
+	if got, want := h.limit("intel-rapl:0"), int64(testMinPower+confidence*(testMaxPower-testMinPower)); got != want {
+		t.Errorf("cap on week-old data = %d µW, want %d µW", got, want)
+	}
+}
+
+func TestE2ESavings(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	h.cycle()
+	if _, ok := h.pm.Savings(); ok {
+		t.Fatal("savings reported after a single cycle")
+	}
+
+	// An hour at a quarter of the hardware maximum, nothing measured
+	h.setTime(e2eDay.Add(4 * time.Hour))
+	h.cycle()
+	savings, ok := h.pm.Savings()
+	if !ok {
+		t.Fatal("no savings after two cycles")
+	}
+	wantWh := float64(testMaxPower-testMaxPower/4) / 1000000
+	if math.Abs(savings.Today.EnergyWh-wantWh) > 1e-6 || math.Abs(savings.Total.EnergyWh-wantWh) > 1e-6 {
+		t.Errorf("energy saved = %v Wh today, %v Wh in total, want %v Wh", savings.Today.EnergyWh, savings.Total.EnergyWh, wantWh)
+	}
+	if want := wantWh / 1000000 * 50; math.Abs(savings.Today.Cost-want) > 1e-9 {
+		t.Errorf("cost saved = %v, want %v at 50 per MWh", savings.Today.Cost, want)
+	}
+
+	// The next market day starts a new daily total
+	h.setTime(e2eDay.AddDate(0, 0, 1).Add(5 * time.Minute))
+	h.cycle()
+	savings, _ = h.pm.Savings()
+	if !savings.Today.Since.Equal(e2eDay.AddDate(0, 0, 1)) || savings.Total.EnergyWh <= wantWh {
+		t.Errorf("savings after midnight = %+v, want a new day and a growing total", savings)
+	}
+}
+
+func TestE2EDecisionCost(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(3*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	h.cycle()
+	h.setTime(e2eDay.Add(3*time.Hour + 30*time.Minute))
+	h.cycle()
+	h.setTime(e2eDay.Add(4 * time.Hour))
+	h.cycle()
+
+	decision, ok := h.pm.LastDecision()
+	if !ok {
+		t.Fatal("no decision")
+	}
+	// Half an hour at a quarter of the hardware maximum, at 50 per MWh
+	wantWh := float64(testMaxPower/4) / 1000000 / 2
+	if math.Abs(decision.EnergyWh-wantWh) > 1e-6 || !decision.EnergyEstimated {
+		t.Errorf("decision energy = %v Wh (estimated %v), want %v Wh estimated from the cap", decision.EnergyWh, decision.EnergyEstimated, wantWh)
+	}
+	if want := wantWh / 1000000 * 50; math.Abs(decision.Cost-want) > 1e-9 {
+		t.Errorf("decision cost = %v, want %v", decision.Cost, want)
+	}
+	if want := 2 * wantWh / 1000000 * 50; math.Abs(decision.DailyCost-want) > 1e-9 {
+		t.Errorf("daily cost = %v, want %v", decision.DailyCost, want)
+	}
+	if got := h.annotation(AnnotationDailyCost); got != strconv.FormatFloat(decision.DailyCost, 'f', 4, 64) {
+		t.Errorf("daily cost annotation = %q, want %.4f", got, decision.DailyCost)
+	}
+}
+
+func TestE2EExperimentArm(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{"EXPERIMENT_LABEL": "powercap/arm"})
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	h.pm.SetExperimentArm("aggressive")
+
+	h.cycle()
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+
+	decision, _ := h.pm.LastDecision()
+	if decision.ExperimentArm != "aggressive" {
+		t.Errorf("decision arm = %q, want aggressive", decision.ExperimentArm)
+	}
+	if got, _ := h.pm.Metrics().Get("experiment_arm", map[string]string{"label": "powercap/arm", "arm": "aggressive"}); got != 1 {
+		t.Errorf("experiment_arm = %v, want 1", got)
+	}
+	if got, _ := h.pm.Metrics().Get("throttle_events_total", nil); got != 1 {
+		t.Errorf("throttle_events_total = %v after lowering the cap once, want 1", got)
+	}
+}
+
+func TestE2ECgroupThrottling(t *testing.T) {
+	const slice = "kubepods.slice/kubepods-besteffort.slice"
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"CGROUP_SLICES":          slice,
+		"RESTORE_LIMITS_ON_EXIT": "true",
+	})
+	cpuMax := filepath.Join(h.sysfs, "sys/fs/cgroup", slice, "cpu.max")
+	if err := os.MkdirAll(filepath.Dir(cpuMax), 0755); err != nil {
+		t.Fatal(err)
+	}
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	readCPUMax := func() string {
+		data, err := os.ReadFile(cpuMax)
+		if err != nil {
+			t.Fatalf("read cpu.max: %v", err)
+		}
+		return string(data)
+	}
+
+	h.cycle()
+	if got := readCPUMax(); got != "max 100000" {
+		t.Errorf("cpu.max at the hardware maximum = %q, want max 100000", got)
+	}
+
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	decision, _ := h.pm.LastDecision()
+	if decision.CgroupCPUs <= 0 || decision.CgroupCPUs >= float64(runtime.NumCPU()) {
+		t.Fatalf("decision cgroup CPUs = %v, want between 0 and %d below the maximum", decision.CgroupCPUs, runtime.NumCPU())
+	}
+	if got, want := readCPUMax(), fmt.Sprintf("%d 100000", int64(decision.CgroupCPUs*100000)); got != want {
+		t.Errorf("cpu.max = %q, want %q", got, want)
+	}
+
+	if err := h.pm.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if got := readCPUMax(); got != "max 100000" {
+		t.Errorf("cpu.max after shutdown = %q, want max 100000", got)
+	}
+}
+
+func TestE2EOfflineCPUs(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"DR_OFFLINE_CPUS":   "2-3",
+		"DR_WEBHOOK_SECRET": "secret",
+		"HTTP_ADDR":         "127.0.0.1:0",
+	})
+	online := func(cpu int) string {
+		return filepath.Join(h.sysfs, "sys/devices/system/cpu", fmt.Sprintf("cpu%d", cpu), "online")
+	}
+	for _, cpu := range []int{2, 3} {
+		if err := os.MkdirAll(filepath.Dir(online(cpu)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(online(cpu), []byte("1"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readOnline := func() string {
+		var states string
+		for _, cpu := range []int{2, 3} {
+			data, err := os.ReadFile(online(cpu))
+			if err != nil {
+				t.Fatalf("read online state: %v", err)
+			}
+			states += string(data)
+		}
+		return states
+	}
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	h.cycle()
+
+	// A reduction the minimum cap can meet keeps every CPU online
+	if _, err := h.pm.ShedLoad(ShedRequest{ID: "evt-1", ReduceUW: 50000000, Duration: time.Hour}); err != nil {
+		t.Fatalf("shed load: %v", err)
+	}
+	h.cycle()
+	if got := readOnline(); got != "11" {
+		t.Errorf("online states = %q for a reachable reduction, want 11", got)
+	}
+
+	if _, err := h.pm.ShedLoad(ShedRequest{ID: "evt-2", ReduceUW: 1000000000, Duration: time.Hour}); err != nil {
+		t.Fatalf("shed load: %v", err)
+	}
+	h.cycle()
+	if got := readOnline(); got != "00" {
+		t.Errorf("online states = %q beyond the minimum cap, want 00", got)
+	}
+	if decision, _ := h.pm.LastDecision(); decision.OfflineCPUs != 2 {
+		t.Errorf("decision offline CPUs = %d, want 2", decision.OfflineCPUs)
+	}
+	if event, _ := h.pm.DemandEvent("evt-2"); event.OfflineCPUs != 2 {
+		t.Errorf("event offline CPUs = %d, want 2", event.OfflineCPUs)
+	}
+
+	h.pm.mu.Lock()
+	h.pm.demandEvents[len(h.pm.demandEvents)-1].EndsAt = time.Now()
+	h.pm.mu.Unlock()
+	h.cycle()
+	if got := readOnline(); got != "11" {
+		t.Errorf("online states = %q after the event, want 11", got)
+	}
+}
+
+func TestE2EShedDuringOverride(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"DR_WEBHOOK_SECRET": "secret",
+		"HTTP_ADDR":         "127.0.0.1:0",
+	})
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	h.cycle()
+
+	if _, err := h.pm.ShedLoad(ShedRequest{ID: "evt-1", ReduceUW: 50000000, Duration: time.Hour}); err != nil {
+		t.Fatalf("shed load: %v", err)
+	}
+	now := time.Now()
+	if err := h.pm.SetOverride(Override{PowerUW: 100000000, SetBy: "operator", SetAt: now, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("set override: %v", err)
+	}
+	if event, _ := h.pm.DemandEvent("evt-1"); event.Status != DemandSuperseded {
+		t.Errorf("event status = %s after a manual override, want %s", event.Status, DemandSuperseded)
+	}
+
+	// A shed would silently replace the operator's override
+	if _, err := h.pm.ShedLoad(ShedRequest{ID: "evt-2", ReduceUW: 50000000, Duration: time.Hour}); err == nil {
+		t.Error("shed accepted during a manual override")
+	}
+
+	h.pm.ClearOverride("operator")
+	if _, err := h.pm.ShedLoad(ShedRequest{ID: "evt-3", ReduceUW: 50000000, Duration: time.Hour}); err != nil {
+		t.Fatalf("shed load: %v", err)
+	}
+	h.pm.ClearOverride("operator")
+	if event, _ := h.pm.DemandEvent("evt-3"); event.Status != DemandSuperseded {
+		t.Errorf("event status = %s after the override was cleared, want %s", event.Status, DemandSuperseded)
+	}
+}
+
+func TestE2EIdlePolicy(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"IDLE_RELAX_BELOW":       "50%",
+		"IDLE_LATENCY_US":        "20",
+		"RESTORE_LIMITS_ON_EXIT": "true",
+	})
+	cpu := filepath.Join(h.sysfs, "sys/devices/system/cpu/cpu0")
+	files := map[string]string{
+		"power/pm_qos_resume_latency_us": "5",
+		"cpuidle/state1/latency":         "2",
+		"cpuidle/state1/disable":         "0",
+		"cpuidle/state2/latency":         "100",
+		"cpuidle/state2/disable":         "0",
+	}
+	for name, value := range files {
+		path := filepath.Join(cpu, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(when, latency, deepDisabled string) {
+		t.Helper()
+		for name, want := range map[string]string{
+			"power/pm_qos_resume_latency_us": latency,
+			"cpuidle/state1/disable":         "0",
+			"cpuidle/state2/disable":         deepDisabled,
+		} {
+			data, err := os.ReadFile(filepath.Join(cpu, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != want {
+				t.Errorf("%s %s = %q, want %q", when, name, data, want)
+			}
+		}
+	}
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	h.cycle()
+	check("at the hardware maximum", "20", "1")
+	if decision, _ := h.pm.LastDecision(); decision.IdlePolicy != IdleTight {
+		t.Errorf("decision idle policy = %q, want %s", decision.IdlePolicy, IdleTight)
+	}
+
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	check("under a quarter of the maximum", "0", "0")
+	if decision, _ := h.pm.LastDecision(); decision.IdlePolicy != IdleRelaxed {
+		t.Errorf("decision idle policy = %q, want %s", decision.IdlePolicy, IdleRelaxed)
+	}
+
+	if err := h.pm.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	check("after shutdown", "5", "0")
+}
+
+func TestE2EBudgetSplit(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"BUDGET_SPLIT": "dram,cpu",
+		"BUDGET_FLOOR": "0.5",
+	})
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	dramLimit := func(zone string) int64 {
+		return h.limit(zone + "/" + zone + ":0")
+	}
+
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMaxPower {
+		t.Errorf("package cap at the peak = %d, want the hardware maximum %d", got, testMaxPower)
+	}
+	if got := dramLimit("intel-rapl:0"); got != testDRAMPower {
+		t.Errorf("dram limit at the peak = %d, want its maximum %d", got, testDRAMPower)
+	}
+
+	// A quarter of the peak volume sheds 5/6 of the node range, taken from
+	// the DRAM down to its floor first, then from the packages
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	for _, zone := range []string{"intel-rapl:0", "intel-rapl:1"} {
+		if got := dramLimit(zone); got != testDRAMPower/2 {
+			t.Errorf("%s dram limit = %d, want its floor %d", zone, got, testDRAMPower/2)
+		}
+	}
+	uniform := int64(testMinPower + (testMaxPower-testMinPower)/6)
+	if got := h.limit("intel-rapl:0"); got <= uniform {
+		t.Errorf("package cap = %d, want above the uniform cap %d with the DRAM squeezed first", got, uniform)
+	}
+	decision, _ := h.pm.LastDecision()
+	if decision.Budget["dram"] != testDRAMPower || decision.AppliedPower != h.limit("intel-rapl:0") {
+		t.Errorf("decision budget = %v applied %d, want dram at %d and the package cap applied", decision.Budget, decision.AppliedPower, testDRAMPower)
+	}
+}
+
+func TestE2EProviderFailoverChain(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"FAILOVER_PROVIDER": "energy-charts,static",
+		"FAILOVER_AFTER":    "2",
+	})
+	backup := newScriptedProvider()
+	backup.setDay(e2eDay, peakAtNoon)
+	h.pm.failovers[0] = newScriptedProvider() // Fails like the primary
+	h.pm.failovers[1] = backup
+
+	h.pm.refreshData()
+	if got := h.pm.providerName(); got != "mock" {
+		t.Fatalf("provider after one failed fetch = %s, want mock", got)
+	}
+	h.pm.refreshData()
+	if got := h.pm.providerName(); got != "energy-charts" {
+		t.Fatalf("provider after FAILOVER_AFTER failed fetches = %s, want the next in the chain", got)
+	}
+	h.pm.refreshData()
+	h.pm.refreshData()
+	if got := h.pm.providerName(); got != "static" {
+		t.Fatalf("provider after the failover failed too = %s, want static", got)
+	}
+	if got, _ := h.pm.Metrics().Get("provider_active", map[string]string{"provider": "static"}); got != 1 {
+		t.Errorf("provider_active{static} = %v, want 1", got)
+	}
+	health := h.pm.ProviderHealth()
+	if health[0].ConsecutiveFailures == 0 || health[0].Score >= health[2].Score {
+		t.Errorf("health = %+v, want the failing primary scored below the answering backup", health)
+	}
+
+	// The primary answers again and is promoted back
+	h.provider.setDay(e2eDay, peakAtNoon)
+	h.pm.refreshData()
+	if got := h.pm.providerName(); got != "mock" {
+		t.Errorf("provider once the primary answers = %s, want mock", got)
+	}
+}
+
+func TestE2EFailoverOnIncompleteData(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), map[string]string{
+		"FAILOVER_PROVIDER": "static",
+		"FAILOVER_AFTER":    "1",
+		"FETCH_MIN_PERIODS": "90",
+	})
+	// The primary only publishes the afternoon
+	h.provider.setDay(e2eDay, func(hour, minute int) float64 {
+		if hour < 12 {
+			return 0
+		}
+		return peakAtNoon(hour, minute)
+	})
+	backup := newScriptedProvider()
+	backup.setDay(e2eDay, peakAtNoon)
+	h.pm.failovers[0] = backup
+
+	h.pm.refreshData()
+	if got := h.pm.providerName(); got != "static" {
+		t.Fatalf("provider after an incomplete fetch = %s, want static", got)
+	}
+	if got := h.pm.dataPeriods(); got != 96 {
+		t.Errorf("periods after the failover = %d, want the backup's 96", got)
+	}
+	h.cycle()
+	if got := h.annotation(AnnotationProvider); got != "static" {
+		t.Errorf("provider annotation = %q, want static", got)
+	}
+
+	// An incomplete answer with nothing better is used and retried
+	backup.setDay(e2eDay, func(hour, minute int) float64 { return 0 })
+	h.pm.refreshData()
+	if got := h.pm.dataPeriods(); got != 48 {
+		t.Errorf("periods = %d, want the primary's 48 kept", got)
+	}
+	if !h.pm.refreshPending {
+		t.Error("refresh not pending after an incomplete fetch")
+	}
+}
+
+func TestE2ENodeStateRestored(t *testing.T) {
+	h := newHarness(t, e2eDay.Add(12*time.Hour), nil)
+	h.provider.setDay(e2eDay, peakAtNoon)
+	if err := h.pm.LoadData(e2eDay); err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+	h.cycle()
+
+	// The node object is recreated without any of the annotations
+	h.replaceAnnotations(nil)
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != 50000000 {
+		t.Errorf("cap after the annotations were lost = %d, want 50000000", got)
+	}
+	if got := h.annotation(AnnotationMaxPower); got != strconv.FormatInt(testMaxPower, 10) {
+		t.Errorf("max power annotation = %q, want it initialized again to %d", got, testMaxPower)
+	}
+	decision, _ := h.pm.LastDecision()
+	if decision.Reinitialized == "" {
+		t.Error("decision does not record the reinitialization")
+	}
+
+	// A corrupted max power is initialized again too
+	h.replaceAnnotations(map[string]string{
+		h.pm.config.InitAnnotation:          "kcas-power-manager",
+		h.pm.annotation(AnnotationMaxPower): "garbage",
+	})
+	h.setTime(e2eDay.Add(12 * time.Hour))
+	h.cycle()
+	if got := h.limit("intel-rapl:0"); got != testMaxPower {
+		t.Errorf("cap after the max power was corrupted = %d, want %d", got, testMaxPower)
+	}
+	if got := h.annotation(AnnotationMaxPower); got != strconv.FormatInt(testMaxPower, 10) {
+		t.Errorf("max power annotation = %q, want it initialized again to %d", got, testMaxPower)
+	}
+
+	// Intact annotations are left alone
+	h.setTime(e2eDay.Add(13 * time.Hour))
+	h.cycle()
+	if decision, _ := h.pm.LastDecision(); decision.Reinitialized != "" {
+		t.Errorf("decision reinitialized = %q with the annotations intact", decision.Reinitialized)
+	}
+}
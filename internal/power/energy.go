@@ -0,0 +1,82 @@
+package power
+
+import "time"
+
+// AnnotationDailyCost is the cost of the energy drawn by the node since the
+// market midnight, in the configured currency
+const AnnotationDailyCost = "daily-cost"
+
+// intervalEnergy is the energy drawn by the node between two cycles and its
+// cost, with the running totals of the market day
+type intervalEnergy struct {
+	Wh            float64
+	Cost          float64
+	Estimated     bool // Drawn at the applied cap, nothing being measured
+	DailyEnergyWh float64
+	DailyCost     float64
+}
+
+// accountEnergy estimates the energy drawn since the previous accounting,
+// during which the cap of the last decision applied: at the power measured,
+// or at the cap when nothing is measured, priced at the period of that
+// decision. The interval is measured on the system time wall, as a replay
+// clock runs faster and loops back, while now, the market time, picks the
+// day. It adds the interval to the day's cost and to the savings, and
+// returns false when there is no interval yet, on the first cycle.
+func (pm *Manager) accountEnergy(wall, now time.Time) (intervalEnergy, bool) {
+	since := pm.energyAt
+	pm.energyAt = wall
+	previous, ok := pm.LastDecision()
+	if since.IsZero() || !ok || previous.HardwareMax <= 0 || !wall.After(since) {
+		return intervalEnergy{}, false
+	}
+	elapsed := wall.Sub(since)
+
+	energy := intervalEnergy{}
+	drawn := pm.lastPackage
+	if drawn <= 0 {
+		drawn = pm.lastWall
+	}
+	if drawn > 0 && previous.AppliedPower >= previous.HardwareMax {
+		pm.uncappedDraw = drawn
+	}
+	if drawn <= 0 {
+		drawn, energy.Estimated = previous.AppliedPower, true
+	}
+	if pm.lastPackage > 0 && previous.AppliedPower < previous.HardwareMax &&
+		float64(pm.lastPackage) >= capBoundShare*float64(previous.AppliedPower) {
+		pm.metrics.AddCounter("cap_bound_seconds_total", "Time the measured package power was held at the applied cap below the hardware maximum, a proxy of workload slowdown (s)",
+			elapsed.Seconds(), nil)
+	}
+	energy.Wh = float64(drawn) / 1000000 * elapsed.Hours()
+	energy.Cost = energy.Wh / 1000000 * previous.Price
+
+	pm.mu.Lock()
+	if day := marketDay(now, pm.location); !day.Equal(pm.costDay) {
+		pm.costDay, pm.dailyEnergyWh, pm.dailyCost = day, 0, 0
+	}
+	pm.dailyEnergyWh += energy.Wh
+	pm.dailyCost += energy.Cost
+	energy.DailyEnergyWh, energy.DailyCost = pm.dailyEnergyWh, pm.dailyCost
+	pm.mu.Unlock()
+
+	currency := pm.config.Currency
+	pm.metrics.AddCounter("energy_drawn_wh_total", "Energy drawn by the node, measured or at the applied cap (Wh)", energy.Wh, nil)
+	pm.metrics.SetGauge("daily_energy_wh", "Energy drawn by the node since the market midnight (Wh)", energy.DailyEnergyWh, nil)
+	pm.metrics.SetGauge("daily_cost", "Cost of the energy drawn since the market midnight at the period prices (in "+currency+")", energy.DailyCost, nil)
+
+	pm.accountSavings(elapsed, now, previous, drawn)
+	return energy, true
+}
+
+// DailyCost returns the energy drawn since the market midnight (Wh) and its
+// cost, zero before the first interval of the day
+func (pm *Manager) DailyCost() (float64, float64) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if !pm.costDay.Equal(marketDay(pm.now(), pm.location)) {
+		return 0, 0
+	}
+	return pm.dailyEnergyWh, pm.dailyCost
+}
@@ -0,0 +1,61 @@
+package power
+
+import (
+	"context"
+	"fmt"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/metrics"
+)
+
+// ExperimentDefault is the arm of the nodes without the experiment label,
+// which run the declared configuration
+const ExperimentDefault = "default"
+
+// capBoundShare is the share of the applied cap above which the measured
+// package power counts as held back by the cap
+const capBoundShare = 0.95
+
+// ExperimentArm returns the experiment arm of the node: the value of its
+// EXPERIMENT_LABEL label, or of the metadata key of that name on
+// orchestrators without labels, empty when the node has neither
+func ExperimentArm(ctx context.Context, cfg *config.Config) (string, error) {
+	nodes, err := NewNodeClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	node, err := nodes.GetNode(ctx, cfg.NodeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node: %w", err)
+	}
+	if arm := node.Labels[cfg.ExperimentLabel]; arm != "" {
+		return arm, nil
+	}
+	return node.Annotations[cfg.ExperimentLabel], nil
+}
+
+// SetExperimentArm records the experiment arm of the node in the decisions
+// and the experiment_arm metric, which outcome metrics are joined with to
+// compare the arms. The throttle and cap-bound counters start at zero so
+// that every arm exports them.
+func (pm *Manager) SetExperimentArm(arm string) {
+	if arm == "" {
+		arm = ExperimentDefault
+	}
+	pm.mu.Lock()
+	pm.experimentArm = arm
+	pm.mu.Unlock()
+
+	pm.metrics.SetGauge("experiment_arm", "Experiment arm of the node, from the EXPERIMENT_LABEL label (always 1)", 1,
+		metrics.Labels{"label": pm.config.ExperimentLabel, "arm": arm})
+	pm.metrics.AddCounter("throttle_events_total", "Number of times the applied cap was lowered", 0, nil)
+	pm.metrics.AddCounter("cap_bound_seconds_total", "Time the measured package power was held at the applied cap below the hardware maximum, a proxy of workload slowdown (s)", 0, nil)
+}
+
+// ExperimentArm returns the experiment arm of the node, empty when
+// experiments are disabled
+func (pm *Manager) ExperimentArm() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.experimentArm
+}
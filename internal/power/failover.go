@@ -0,0 +1,312 @@
+package power
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/metrics"
+)
+
+// providerHealthAlpha is the weight of the latest fetch in the success rate
+// and latency of a provider
+const providerHealthAlpha = 0.2
+
+// dayPeriods is the number of 15-minute market periods of a day
+const dayPeriods = 96
+
+// ProviderHealth scores a provider of the failover chain from its recent
+// fetches
+type ProviderHealth struct {
+	Provider            string    `json:"provider"`
+	Active              bool      `json:"active"`
+	Score               float64   `json:"score"`        // Success rate weighted by completeness and latency, 0 to 1
+	SuccessRate         float64   `json:"success_rate"` // Weighted share of successful fetches
+	LatencyS            float64   `json:"latency_s"`    // Weighted fetch duration
+	Completeness        float64   `json:"completeness"` // Share of the day's periods in the last data fetched
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Fetches             int       `json:"fetches"`
+	LastFetch           time.Time `json:"last_fetch,omitempty"`
+}
+
+// newProviderHealth returns the health of a provider not tried yet, trusted
+// until its first fetch
+func newProviderHealth(name string) ProviderHealth {
+	return ProviderHealth{Provider: name, Score: 1, SuccessRate: 1, Completeness: 1}
+}
+
+// score combines the success rate with the completeness of the data and the
+// latency of the fetches, a fetch taking the whole PROVIDER_TIMEOUT counting
+// as slow as a provider may be
+func (h *ProviderHealth) score(timeout time.Duration) {
+	speed := 1.0
+	if timeout > 0 {
+		speed = 1 - min(1, h.LatencyS/timeout.Seconds())
+	}
+	h.Score = h.SuccessRate * (0.7*h.Completeness + 0.3*speed)
+}
+
+// usingFailover reports whether a failover provider fetches the data
+func (pm *Manager) usingFailover() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.activeIndex > 0
+}
+
+// chainProvider returns the provider at an index of the failover chain, the
+// configured one first
+func (pm *Manager) chainProvider(i int) datastore.MarketDataProvider {
+	if i == 0 {
+		return pm.provider
+	}
+	return pm.failovers[i-1]
+}
+
+// chainName returns the type of the provider at an index of the failover
+// chain
+func (pm *Manager) chainName(i int) string {
+	if i == 0 {
+		return pm.config.DataProvider
+	}
+	return pm.config.FailoverProviders[i-1]
+}
+
+// activeProvider returns the provider currently fetching the data
+func (pm *Manager) activeProvider() datastore.MarketDataProvider {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.chainProvider(pm.activeIndex)
+}
+
+// providerName returns the type of the provider currently fetching the data
+func (pm *Manager) providerName() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.chainName(pm.activeIndex)
+}
+
+// ProviderHealth returns the health of every provider of the failover
+// chain, the configured one first
+func (pm *Manager) ProviderHealth() []ProviderHealth {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	health := make([]ProviderHealth, len(pm.providerHealth))
+	copy(health, pm.providerHealth)
+	return health
+}
+
+// refreshToday fetches today's data. Providers ahead of the active one in
+// the failover chain are tried first and promoted once they answer with a
+// score of at least PROVIDER_MIN_SCORE. The active provider is demoted for
+// the healthiest other one after FAILOVER_AFTER consecutive failed fetches,
+// or when its score falls below PROVIDER_MIN_SCORE and another scores
+// higher. Called with refreshMu held.
+func (pm *Manager) refreshToday(ctx context.Context, today time.Time) error {
+	if len(pm.failovers) == 0 {
+		data, _, err := pm.fetchFrom(ctx, 0, today)
+		return pm.keepIncomplete(0, pm.storeData(today, data, err))
+	}
+
+	pm.mu.RLock()
+	active := pm.activeIndex
+	pm.mu.RUnlock()
+	for i := 0; i < active; i++ {
+		// The data of a probe is only used once its provider is promoted
+		data, health, err := pm.fetchFrom(ctx, i, today)
+		if err == nil && health.Score >= pm.config.ProviderMinScore {
+			pm.switchProvider(i, fmt.Sprintf("answers again with a health score of %.2f", health.Score))
+			return pm.storeData(today, data, nil)
+		}
+		if err != nil {
+			pm.logger.Printf("🔀 Provider %s still failing, staying on %s: %v", pm.chainName(i), pm.chainName(active), err)
+		}
+	}
+
+	data, health, err := pm.fetchFrom(ctx, active, today)
+	err = pm.storeData(today, data, err)
+	failed := err != nil && health.ConsecutiveFailures >= pm.config.FailoverAfter
+	unhealthy := health.Score < pm.config.ProviderMinScore
+	if !failed && !unhealthy {
+		return pm.keepIncomplete(active, err)
+	}
+
+	next := pm.healthiestProvider(active)
+	if next < 0 || (!failed && pm.ProviderHealth()[next].Score <= health.Score) {
+		return pm.keepIncomplete(active, err)
+	}
+	reason := fmt.Sprintf("health score %.2f below %.2f", health.Score, pm.config.ProviderMinScore)
+	if failed {
+		reason = fmt.Sprintf("failed %d times in a row (%v)", health.ConsecutiveFailures, err)
+	}
+	pm.switchProvider(next, reason)
+	nextData, _, nextErr := pm.fetchFrom(ctx, next, today)
+	nextErr = pm.storeData(today, nextData, nextErr)
+	if err == nil {
+		return nil
+	}
+	return pm.keepIncomplete(next, nextErr)
+}
+
+// errIncompleteData reports a fetch answering with fewer periods than
+// FETCH_MIN_PERIODS
+var errIncompleteData = errors.New("too few periods")
+
+// fetchFrom fetches today's data from the provider at an index of the chain
+// and records its health, leaving the current data unchanged. A fetch with
+// fewer periods than FETCH_MIN_PERIODS counts as failed, though its data is
+// returned.
+func (pm *Manager) fetchFrom(ctx context.Context, i int, today time.Time) ([]datastore.MarketDataPoint, ProviderHealth, error) {
+	data, err := pm.dataStore.FetchData(ctx, pm.chainProvider(i), today)
+	if err == nil && pm.config.FetchMinPeriods > 0 {
+		if periods := volumePeriods(data); periods < pm.config.FetchMinPeriods {
+			err = fmt.Errorf("%w: %d of at least %d", errIncompleteData, periods, pm.config.FetchMinPeriods)
+		}
+	}
+	return data, pm.recordFetch(i, data, err), err
+}
+
+// storeData stores the data fetched from the active provider and makes it
+// current, unless the fetch failed for another reason than too few periods
+func (pm *Manager) storeData(today time.Time, data []datastore.MarketDataPoint, err error) error {
+	if err != nil && !errors.Is(err, errIncompleteData) {
+		return err
+	}
+	if saveErr := pm.dataStore.SaveData(today, data); saveErr != nil {
+		return fmt.Errorf("failed to save data: %w", saveErr)
+	}
+	return err
+}
+
+// keepIncomplete uses the data of a fetch that only failed for having too few
+// periods when no other provider replaces it, keeping the refresh pending so
+// it is retried
+func (pm *Manager) keepIncomplete(i int, err error) error {
+	if !errors.Is(err, errIncompleteData) {
+		return err
+	}
+	pm.logger.Printf("⚠️  Provider %s answered with %v, using its data until a retry completes it", pm.chainName(i), err)
+	pm.refreshPending = true
+	return nil
+}
+
+// dataPeriods returns the number of periods with a volume in the current data
+func (pm *Manager) dataPeriods() int {
+	return volumePeriods(pm.dataStore.GetCurrentData())
+}
+
+// volumePeriods returns the number of periods with a volume in some data
+func volumePeriods(data []datastore.MarketDataPoint) int {
+	var periods int
+	for _, point := range data {
+		if point.Volume > 0 {
+			periods++
+		}
+	}
+	return periods
+}
+
+// healthiestProvider returns the index of the provider of the chain with the
+// highest score other than the given one, the earliest in the chain on a
+// tie, or -1 when the chain has no other provider
+func (pm *Manager) healthiestProvider(except int) int {
+	health := pm.ProviderHealth()
+	best := -1
+	for i := range health {
+		if i != except && (best < 0 || health[i].Score > health[best].Score) {
+			best = i
+		}
+	}
+	return best
+}
+
+// recordFetch updates the health of the provider at an index of the chain
+// after a fetch of some data, and returns it
+func (pm *Manager) recordFetch(i int, data []datastore.MarketDataPoint, err error) ProviderHealth {
+	status := pm.dataStore.GetFetchStatus()
+	fetched := err == nil || errors.Is(err, errIncompleteData)
+	var completeness float64
+	if fetched {
+		completeness = min(1, float64(volumePeriods(data))/dayPeriods)
+	}
+
+	pm.mu.Lock()
+	health := &pm.providerHealth[i]
+	success := 0.0
+	if err == nil {
+		success = 1
+		health.ConsecutiveFailures = 0
+	} else {
+		health.ConsecutiveFailures++
+	}
+	if fetched {
+		health.Completeness = completeness
+	}
+	health.SuccessRate += providerHealthAlpha * (success - health.SuccessRate)
+	if health.Fetches == 0 {
+		health.LatencyS = status.LastDuration.Seconds()
+	} else {
+		health.LatencyS += providerHealthAlpha * (status.LastDuration.Seconds() - health.LatencyS)
+	}
+	health.Fetches++
+	health.LastFetch = status.LastAttempt
+	health.score(pm.config.ProviderTimeout)
+	result := *health
+	pm.mu.Unlock()
+
+	pm.metrics.SetGauge("provider_health_score", "Health score of each provider of the failover chain, from success rate, completeness and latency",
+		result.Score, metrics.Labels{"provider": result.Provider})
+	return result
+}
+
+// switchProvider makes the provider at an index of the chain fetch the
+// data, logging and sending an event
+func (pm *Manager) switchProvider(next int, reason string) {
+	pm.mu.Lock()
+	previous := pm.activeIndex
+	pm.activeIndex = next
+	for i := range pm.providerHealth {
+		pm.providerHealth[i].Active = i == next
+	}
+	pm.mu.Unlock()
+
+	pm.dataStore.SetProvider(pm.chainProvider(next))
+	pm.recordActiveProvider()
+
+	title := "Data provider failover"
+	text := fmt.Sprintf("Node %s: provider %s %s, switching to %s",
+		pm.config.NodeName, pm.chainName(previous), reason, pm.chainName(next))
+	if next < previous {
+		title = "Data provider restored"
+		text = fmt.Sprintf("Node %s: provider %s %s, leaving %s",
+			pm.config.NodeName, pm.chainName(next), reason, pm.chainName(previous))
+	}
+	pm.logger.Printf("🔀 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+	pm.updateDegraded()
+}
+
+// recordActiveProvider exports which provider of the chain fetches the data
+func (pm *Manager) recordActiveProvider() {
+	pm.mu.RLock()
+	active := pm.activeIndex
+	pm.mu.RUnlock()
+
+	failover := 0.0
+	if active > 0 {
+		failover = 1
+	}
+	pm.metrics.SetGauge("provider_failover", "Whether a failover provider fetches the data", failover, nil)
+	for i := 0; i <= len(pm.failovers); i++ {
+		value := 0.0
+		if i == active {
+			value = 1
+		}
+		pm.metrics.SetGauge("provider_active", "Whether each provider of the failover chain fetches the data", value,
+			metrics.Labels{"provider": pm.chainName(i)})
+	}
+}
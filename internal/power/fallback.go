@@ -0,0 +1,35 @@
+package power
+
+import (
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// periodFallback returns the cap planned for another period when the current
+// one has no data or a zero volume: the same period in the previous refresh
+// of the day, otherwise the nearest period of the day with data, searching
+// PERIOD_FALLBACK periods on each side, earlier first. It also describes the
+// substitute for the decision.
+func (pm *Manager) periodFallback(plan *dayPlan, currentTime time.Time, period string) (PlannedCap, string, bool) {
+	if point, ok := plan.previous[period]; ok && len(plan.caps) > 0 {
+		data := pm.dataStore.GetCurrentData()
+		planned := pm.plannedCap(point, plan.hardwareMax, pm.referenceVolume(), datastore.MedianPrice(data), plan.closed)
+		return planned, "period " + period + " of the previous refresh", true
+	}
+
+	day := currentTime.Format("2006-01-02")
+	for k := 1; k <= pm.config.PeriodFallback; k++ {
+		for _, offset := range []time.Duration{-1, 1} {
+			t := currentTime.Add(offset * time.Duration(k) * 15 * time.Minute)
+			if t.Format("2006-01-02") != day {
+				continue // Another day's periods are not in the plan
+			}
+			neighbour := pm.calculator.GetCurrentPeriod(t)
+			if planned, ok := plan.lookup(neighbour); ok && planned.Volume != 0 {
+				return planned, "nearest period " + neighbour, true
+			}
+		}
+	}
+	return PlannedCap{}, "", false
+}
@@ -0,0 +1,242 @@
+package power
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// Sources of a forecast period
+const (
+	ForecastMarket = "market" // Published market data
+	ForecastModel  = "model"  // Same period over recent days, per FORECAST_MODEL
+)
+
+// forecastModelDays is the number of past days modelled when FORECAST_DAYS is
+// not set
+const forecastModelDays = 7
+
+// ForecastCap is the cap expected in one upcoming market period
+type ForecastCap struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Period string    `json:"period"`
+	Volume float64   `json:"volume_mwh"`
+	Price  float64   `json:"price_eur_mwh"`
+	CapUW  int64     `json:"cap_uw"`
+	Clamp  string    `json:"clamp"`
+	Source string    `json:"source"` // market or model
+}
+
+// Forecast returns the caps expected over the next hours with the hardware
+// maximum of the last decision: from the published data of today and
+// tomorrow when fetched, otherwise from a model of the last days stored.
+// Overrides, batteries and peak shaving are not forecast.
+func (pm *Manager) Forecast(hours int) ([]ForecastCap, error) {
+	decision, ok := pm.LastDecision()
+	if !ok || decision.HardwareMax <= 0 {
+		return nil, errors.New("the hardware maximum is not known until the first cycle completes")
+	}
+
+	now := pm.now().In(pm.location)
+	until := now.Add(time.Duration(hours) * time.Hour)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, pm.location)
+
+	var forecast []ForecastCap
+	for date := today; date.Before(until); date = date.AddDate(0, 0, 1) {
+		data, origin := pm.forecastData(date, today)
+		if len(data) == 0 {
+			continue
+		}
+
+		referenceVolume := forecastReference(data, pm.config.PowerCalcMode)
+		if date.Equal(today) {
+			referenceVolume = pm.referenceVolume()
+		}
+		if referenceVolume <= 0 {
+			continue
+		}
+
+		closed := pm.closedDayCap(date)
+		median := datastore.MedianPrice(data)
+		for _, point := range data {
+			start, end, err := periodBounds(date, point.Period)
+			if err != nil || !end.After(now) || !start.Before(until) {
+				continue
+			}
+
+			sourcePower := int64(math.Round(point.Volume / referenceVolume * float64(decision.HardwareMax)))
+			if sourcePower == 0 {
+				sourcePower = pm.config.RaplLimit
+			}
+			capPower, clamp, _ := pm.limitPower(sourcePower, decision.HardwareMax)
+			if closed {
+				capPower, clamp = pm.closedDayPower(decision.HardwareMax), ClampClosedDay
+			} else if capPower > pm.config.RaplLimit &&
+				datastore.IsPriceSpike(point.Price, median, pm.config.PriceSpikeThreshold, pm.config.PriceSpikeFactor) {
+				capPower, clamp = pm.config.RaplLimit, ClampPriceSpike
+			}
+
+			forecast = append(forecast, ForecastCap{
+				Start:  start,
+				End:    end,
+				Period: point.Period,
+				Volume: point.Volume,
+				Price:  point.Price,
+				CapUW:  capPower,
+				Clamp:  clamp,
+				Source: origin,
+			})
+		}
+	}
+
+	if len(forecast) == 0 {
+		return nil, errors.New("no market data loaded or stored to forecast from")
+	}
+	return forecast, nil
+}
+
+// forecastData returns the data of a day and its source: the current data
+// for today, the stored data of a later day when already fetched, otherwise
+// a model of recent days
+func (pm *Manager) forecastData(date, today time.Time) ([]datastore.MarketDataPoint, string) {
+	if date.Equal(today) {
+		if data := pm.dataStore.GetCurrentData(); len(data) > 0 {
+			return data, ForecastMarket
+		}
+	} else if pm.dataStore.HasData(date) {
+		if data, err := pm.dataStore.ReadData(date); err == nil && len(data) > 0 {
+			return data, ForecastMarket
+		}
+	}
+	return pm.modelData(date), ForecastModel
+}
+
+// modelData synthesizes the data of a day not published yet from the days
+// stored before it, per FORECAST_MODEL: the average of each period over the
+// last FORECAST_DAYS days, an exponentially weighted average favouring the
+// most recent ones, or the average of the same weekday over the last
+// FORECAST_DAYS weeks, falling back to the plain average when no such day is
+// stored. The period order of the most recent day is kept.
+func (pm *Manager) modelData(date time.Time) []datastore.MarketDataPoint {
+	if pm.config.ForecastModel == "weekday" {
+		if model := modelPeriods(pm.modelHistory(date, 7), "average", 0); len(model) > 0 {
+			return model
+		}
+		return modelPeriods(pm.modelHistory(date, 1), "average", 0)
+	}
+	return modelPeriods(pm.modelHistory(date, 1), pm.config.ForecastModel, pm.config.ForecastAlpha)
+}
+
+// modelHistory returns the data of up to FORECAST_DAYS stored days before
+// date, every step days, the most recent first
+func (pm *Manager) modelHistory(date time.Time, step int) [][]datastore.MarketDataPoint {
+	days := pm.config.ForecastDays
+	if days <= 0 {
+		days = forecastModelDays
+	}
+	var history [][]datastore.MarketDataPoint
+	for back := 1; back <= days; back++ {
+		day := date.AddDate(0, 0, -back*step)
+		if !pm.dataStore.HasData(day) {
+			continue
+		}
+		if data, err := pm.dataStore.ReadData(day); err == nil && len(data) > 0 {
+			history = append(history, data)
+		}
+	}
+	return history
+}
+
+// modelPeriods combines the volume and price of each period over days, the
+// most recent first: their average, or with "ewma" their exponentially
+// weighted average, alpha being the weight of the most recent day
+func modelPeriods(history [][]datastore.MarketDataPoint, method string, alpha float64) []datastore.MarketDataPoint {
+	type sum struct {
+		volume, price, weight float64
+	}
+	sums := make(map[string]*sum)
+	var periods []string
+
+	weight := 1.0
+	for _, data := range history {
+		for _, point := range data {
+			s, ok := sums[point.Period]
+			if !ok {
+				s = &sum{}
+				sums[point.Period] = s
+				periods = append(periods, point.Period)
+			}
+			s.volume += weight * point.Volume
+			s.price += weight * point.Price
+			s.weight += weight
+		}
+		if method == "ewma" {
+			weight *= 1 - alpha
+		}
+	}
+
+	model := make([]datastore.MarketDataPoint, 0, len(periods))
+	for _, period := range periods {
+		s := sums[period]
+		if s.weight <= 0 {
+			continue
+		}
+		model = append(model, datastore.MarketDataPoint{
+			Period: period,
+			Volume: s.volume / s.weight,
+			Price:  s.price / s.weight,
+		})
+	}
+	return model
+}
+
+// forecastReference returns the reference volume of a day other than today:
+// its maximum volume, or 100 for percentages
+func forecastReference(data []datastore.MarketDataPoint, mode string) float64 {
+	if mode == "percent" {
+		return datastore.PercentReference
+	}
+	var maxVolume float64
+	for _, point := range data {
+		maxVolume = math.Max(maxVolume, point.Volume)
+	}
+	return maxVolume
+}
+
+// periodBounds returns the start and end of a period such as "13:45-14:00"
+// on the given day; an end of 24:00 is the next midnight
+func periodBounds(date time.Time, period string) (time.Time, time.Time, error) {
+	from, to, ok := strings.Cut(period, "-")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+	start, err := clockTime(date, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := clockTime(date, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// clockTime returns the time of a "HH:MM" clock reading on the given day
+func clockTime(date time.Time, clock string) (time.Time, error) {
+	hour, minute, ok := strings.Cut(strings.TrimSpace(clock), ":")
+	h, errH := strconv.Atoi(hour)
+	m, errM := strconv.Atoi(minute)
+	if !ok || errH != nil || errM != nil || h < 0 || h > 24 || m < 0 || m > 59 {
+		return time.Time{}, fmt.Errorf("invalid time %q", clock)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, date.Location()), nil
+}
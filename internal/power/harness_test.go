@@ -0,0 +1,260 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/datastore"
+)
+
+// Power of the fake RAPL zones (µW)
+const (
+	testMaxPower  = 200000000
+	testMinPower  = 20000000
+	testDRAMPower = 20000000 // Maximum of the dram subzone of each zone
+)
+
+// harness drives full adjustment cycles against a fake powercap sysfs tree, a
+// fake Kubernetes API and a scripted market data provider, with a settable
+// clock
+type harness struct {
+	t         *testing.T
+	pm        *Manager
+	sysfs     string
+	clientset *fake.Clientset
+	provider  *scriptedProvider
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+// newHarness starts a manager on a fake node with two RAPL zones, each with
+// a dram subzone, at the given market time. Settings are passed as environment variables.
+func newHarness(t *testing.T, now time.Time, env map[string]string) *harness {
+	t.Helper()
+
+	sysfs := t.TempDir()
+	for _, zone := range []string{"intel-rapl:0", "intel-rapl:1"} {
+		writeZone(t, sysfs, zone, testMaxPower)
+		dram := zone + "/" + zone + ":0"
+		writeZone(t, sysfs, dram, testDRAMPower)
+		path := filepath.Join(sysfs, "sys/devices/virtual/powercap/intel-rapl", dram, "name")
+		if err := os.WriteFile(path, []byte("dram"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settings := map[string]string{
+		config.EnvNodeName:       "e2e-node",
+		config.EnvSysfsRoot:      sysfs,
+		config.EnvOrchestrator:   "standalone",
+		config.EnvStateFile:      filepath.Join(t.TempDir(), "state.json"),
+		config.EnvDataDir:        t.TempDir(),
+		config.EnvDataProvider:   "mock",
+		config.EnvRaplLimit:      strconv.Itoa(testMinPower),
+		config.EnvRestoreLastCap: "false",
+		config.EnvPowerBackend:   "intel-rapl",
+		config.EnvTimezone:       "UTC",
+	}
+	for key, value := range env {
+		settings[key] = value
+	}
+	for key, value := range settings {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	pm, err := NewManager(ctx, cfg, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+
+	h := &harness{
+		t:         t,
+		pm:        pm,
+		sysfs:     sysfs,
+		clientset: fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: cfg.NodeName}}),
+		provider:  newScriptedProvider(),
+		now:       now,
+	}
+	pm.nodes = &kubernetesNodes{clientset: h.clientset}
+	pm.provider = h.provider
+	pm.dataStore.SetProvider(h.provider)
+	pm.clock = h.clock
+	pm.wallClock = h.clock
+
+	if err := pm.InitializeNode(); err != nil {
+		t.Fatalf("initialize node: %v", err)
+	}
+	return h
+}
+
+// clock returns the harness time
+func (h *harness) clock() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.now
+}
+
+// setTime moves the harness clock
+func (h *harness) setTime(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.now = now
+}
+
+// cycle runs one adjustment cycle as the control loop does
+func (h *harness) cycle() {
+	h.t.Helper()
+	h.pm.runCycle()
+}
+
+// limit returns the power limit written to a constraint of a zone
+func (h *harness) limit(zone string) int64 {
+	h.t.Helper()
+	path := filepath.Join(h.sysfs, "sys/devices/virtual/powercap/intel-rapl", zone, "constraint_0_power_limit_uw")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("read %s: %v", path, err)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		h.t.Fatalf("parse %s: %v", path, err)
+	}
+	return value
+}
+
+// annotation returns a node annotation from the fake API
+func (h *harness) annotation(name string) string {
+	h.t.Helper()
+	node, err := h.clientset.CoreV1().Nodes().Get(context.Background(), "e2e-node", metav1.GetOptions{})
+	if err != nil {
+		h.t.Fatalf("get node: %v", err)
+	}
+	return node.Annotations[h.pm.annotation(name)]
+}
+
+// replaceAnnotations replaces the annotations of the node in the fake API,
+// as when the node object is recreated
+func (h *harness) replaceAnnotations(annotations map[string]string) {
+	h.t.Helper()
+	node, err := h.clientset.CoreV1().Nodes().Get(context.Background(), "e2e-node", metav1.GetOptions{})
+	if err != nil {
+		h.t.Fatalf("get node: %v", err)
+	}
+	node.Annotations = annotations
+	if _, err := h.clientset.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{}); err != nil {
+		h.t.Fatalf("update node: %v", err)
+	}
+}
+
+// failNodeUpdates makes the fake API reject node updates until the returned
+// function is called
+func (h *harness) failNodeUpdates() (restore func()) {
+	var mu sync.Mutex
+	failing := true
+	h.clientset.PrependReactor("update", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			return true, nil, fmt.Errorf("apiserver unavailable")
+		}
+		return false, nil, nil
+	})
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		failing = false
+	}
+}
+
+// writeZone creates a powercap zone with two constraints at the hardware
+// maximum and an energy counter
+func writeZone(t *testing.T, root, zone string, maxPower int64) {
+	t.Helper()
+	dir := filepath.Join(root, "sys/devices/virtual/powercap/intel-rapl", zone)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"name":                        zone,
+		"energy_uj":                   "0",
+		"max_energy_range_uj":         "262143328850",
+		"constraint_0_power_limit_uw": strconv.FormatInt(maxPower, 10),
+		"constraint_0_max_power_uw":   strconv.FormatInt(maxPower, 10),
+		"constraint_1_power_limit_uw": strconv.FormatInt(maxPower, 10),
+		"constraint_1_max_power_uw":   strconv.FormatInt(maxPower, 10),
+	}
+	for name, value := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scriptedProvider serves the market data set for each day, and fails for
+// the others
+type scriptedProvider struct {
+	mu   sync.Mutex
+	days map[string][]datastore.MarketDataPoint
+}
+
+func newScriptedProvider() *scriptedProvider {
+	return &scriptedProvider{days: map[string][]datastore.MarketDataPoint{}}
+}
+
+// setDay sets the volumes of a day's 96 periods from a function of the
+// period start
+func (p *scriptedProvider) setDay(date time.Time, volume func(hour, minute int) float64) {
+	var points []datastore.MarketDataPoint
+	for i := 0; i < 96; i++ {
+		hour, minute := i/4, i%4*15
+		end := fmt.Sprintf("%02d:%02d", (i+1)/4, (i+1)%4*15)
+		points = append(points, datastore.MarketDataPoint{
+			Period: fmt.Sprintf("%02d:%02d-%s", hour, minute, end),
+			Volume: volume(hour, minute),
+			Price:  50,
+		})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.days[date.Format("2006-01-02")] = points
+}
+
+func (p *scriptedProvider) GetName() string { return "scripted" }
+
+func (p *scriptedProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("scripted_%s.csv", date.Format("2006-01-02"))
+}
+
+func (p *scriptedProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	points, ok := p.days[date.Format("2006-01-02")]
+	if !ok {
+		return nil, fmt.Errorf("no data scripted for %s", date.Format("2006-01-02"))
+	}
+	return points, nil
+}
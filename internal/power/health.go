@@ -0,0 +1,126 @@
+package power
+
+import (
+	"time"
+
+	"kcas/new/internal/metrics"
+)
+
+const (
+	// stallFactor is how many adjustment intervals may pass without a completed
+	// cycle before the loop is reported as stalled
+	stallFactor = 3
+
+	// unhealthyFailures is the number of consecutive failed cycles after which
+	// the manager reports itself unhealthy
+	unhealthyFailures = 5
+)
+
+// Health states reported by the health endpoint
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFailing  = "failing"
+	HealthStalled  = "stalled"
+	HealthStarting = "starting"
+)
+
+// LoopStats tracks the behaviour of the control loop
+type LoopStats struct {
+	Cycles              int           `json:"cycles"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	LastCycleStart      time.Time     `json:"last_cycle_start"`
+	LastCycleEnd        time.Time     `json:"last_cycle_end"`
+	LastCycleDuration   time.Duration `json:"last_cycle_duration_ns"`
+	LastSuccess         time.Time     `json:"last_success"`
+	LastError           string        `json:"last_error,omitempty"`
+	RefreshSuccesses    int           `json:"refresh_successes"`
+	RefreshFailures     int           `json:"refresh_failures"`
+}
+
+// Health summarizes whether the control loop is making progress
+type Health struct {
+	Status  string    `json:"status"`
+	Healthy bool      `json:"healthy"`
+	Reason  string    `json:"reason,omitempty"`
+	Loop    LoopStats `json:"loop"`
+}
+
+// Health evaluates the control loop statistics
+func (pm *Manager) Health() Health {
+	pm.mu.RLock()
+	stats := pm.loopStats
+	pm.mu.RUnlock()
+
+	fetch := pm.dataStore.GetFetchStatus()
+	stats.RefreshSuccesses = fetch.TotalSuccesses
+	stats.RefreshFailures = fetch.TotalFailures
+
+	health := Health{Status: HealthOK, Healthy: true, Loop: stats}
+	now := time.Now()
+	stallAfter := stallFactor * pm.maxInterval()
+	if stats.ConsecutiveFailures > 0 && pm.config.BackoffMax > pm.maxInterval() {
+		// Failing cycles back off up to FAILURE_BACKOFF_MAX
+		stallAfter = stallFactor * pm.config.BackoffMax
+	}
+
+	switch {
+	case stats.Cycles == 0:
+		health.Status = HealthStarting
+		if now.Sub(pm.startedAt) > stallAfter {
+			health.Status, health.Healthy = HealthStalled, false
+			health.Reason = "no adjustment cycle has completed since start"
+		}
+	case now.Sub(stats.LastCycleEnd) > stallAfter:
+		health.Status, health.Healthy = HealthStalled, false
+		health.Reason = "no adjustment cycle completed in " + now.Sub(stats.LastCycleEnd).Round(time.Second).String()
+	case stats.ConsecutiveFailures >= unhealthyFailures:
+		health.Status, health.Healthy = HealthFailing, false
+		health.Reason = stats.LastError
+	case stats.ConsecutiveFailures > 0:
+		health.Status = HealthDegraded
+		health.Reason = stats.LastError
+	case pm.usingFailover():
+		health.Status = HealthDegraded
+		health.Reason = "data from failover provider " + pm.providerName()
+	case pm.followsStaleData():
+		health.Status = HealthDegraded
+		health.Reason = "following a previous day's market data"
+	}
+
+	return health
+}
+
+// recordCycle updates loop statistics and metrics after an adjustment cycle
+func (pm *Manager) recordCycle(start time.Time, duration time.Duration, err error) {
+	pm.mu.Lock()
+	pm.loopStats.Cycles++
+	pm.loopStats.LastCycleStart = start
+	pm.loopStats.LastCycleEnd = start.Add(duration)
+	pm.loopStats.LastCycleDuration = duration
+	if err != nil {
+		pm.loopStats.ConsecutiveFailures++
+		pm.loopStats.LastError = err.Error()
+	} else {
+		pm.loopStats.ConsecutiveFailures = 0
+		pm.loopStats.LastError = ""
+		pm.loopStats.LastSuccess = pm.loopStats.LastCycleEnd
+	}
+	stats := pm.loopStats
+	pm.mu.Unlock()
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	pm.metrics.AddCounter("adjustments_total", "Number of power cap adjustment cycles", 1, metrics.Labels{"result": result})
+	pm.metrics.SetGauge("cycle_duration_seconds", "Duration of the last adjustment cycle", duration.Seconds(), nil)
+	pm.metrics.SetGauge("consecutive_failures", "Number of consecutive failed adjustment cycles", float64(stats.ConsecutiveFailures), nil)
+	if !stats.LastSuccess.IsZero() {
+		pm.metrics.SetGauge("last_success_timestamp_seconds", "Unix time of the last successful adjustment", float64(stats.LastSuccess.Unix()), nil)
+	}
+
+	fetch := pm.dataStore.GetFetchStatus()
+	pm.metrics.SetCounter("data_refreshes_total", "Number of market data fetches", float64(fetch.TotalSuccesses), metrics.Labels{"result": "success"})
+	pm.metrics.SetCounter("data_refreshes_total", "Number of market data fetches", float64(fetch.TotalFailures), metrics.Labels{"result": "failure"})
+}
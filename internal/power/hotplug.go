@@ -0,0 +1,73 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+// shedShortfall reports whether an active shed request asks for a reduction
+// the minimum cap cannot deliver, marking it with the CPUs offlined for it
+func (pm *Manager) shedShortfall(offline int) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.completeDemandEvents(time.Now())
+	for i := range pm.demandEvents {
+		event := &pm.demandEvents[i]
+		if event.Status == DemandActive && event.BaselineUW-event.ReduceUW < pm.config.RaplLimit {
+			event.OfflineCPUs = offline
+			return true
+		}
+	}
+	return false
+}
+
+// offlineCPUs takes the DR_OFFLINE_CPUS offline while a shed request cannot
+// be met at the minimum cap, and brings them back online once none is active.
+// It returns the number of CPUs offline.
+func (pm *Manager) offlineCPUs() int {
+	if pm.hotplug == nil {
+		return 0
+	}
+	cpus := pm.hotplug.CPUs()
+	if !pm.shedShortfall(len(cpus)) {
+		pm.onlineCPUs()
+		return 0
+	}
+
+	switched, errs := pm.hotplug.Offline()
+	for _, err := range errs {
+		pm.logger.Printf("❌ Failed to take CPU offline: %v", err)
+	}
+	pm.cpusOffline = true
+	offline := len(cpus) - len(errs)
+	if len(switched) > 0 {
+		text := fmt.Sprintf("Node %s: the minimum cap cannot meet the shed request, CPUs %v taken offline", pm.config.NodeName, switched)
+		pm.logger.Printf("🔻 %s", text)
+		if pm.events != nil {
+			pm.events.Event("CPUs offlined", text, map[string]string{"node": pm.config.NodeName})
+		}
+	}
+	pm.metrics.SetGauge("cpus_offline", "CPUs of DR_OFFLINE_CPUS taken offline for a shed request", float64(offline), nil)
+	return offline
+}
+
+// onlineCPUs brings the DR_OFFLINE_CPUS back online after a shed request,
+// or after a previous run that stopped while they were offline
+func (pm *Manager) onlineCPUs() {
+	if pm.hotplug == nil || !pm.cpusOffline {
+		return
+	}
+	switched, errs := pm.hotplug.Online()
+	for _, err := range errs {
+		pm.logger.Printf("❌ Failed to bring CPU back online: %v", err)
+	}
+	if len(errs) > 0 {
+		return
+	}
+	pm.cpusOffline = false
+	if len(switched) > 0 {
+		pm.logger.Printf("🔺 CPUs %v brought back online", switched)
+	}
+	pm.metrics.SetGauge("cpus_offline", "CPUs of DR_OFFLINE_CPUS taken offline for a shed request", 0, nil)
+}
@@ -0,0 +1,61 @@
+package power
+
+// Idle policies of the CPUs (IDLE_RELAX_BELOW)
+const (
+	IdleTight   = "tight"   // Original settings, or resume latency kept within IDLE_LATENCY_US
+	IdleRelaxed = "relaxed" // Deepest C-states allowed
+)
+
+// applyIdlePolicy relaxes the idle policy of the CPUs while the applied cap
+// is at or below IDLE_RELAX_BELOW, when the node saves more from idle cores
+// than it loses in wake-up latency, and tightens it again above. It returns
+// the policy in force, empty when disabled.
+func (pm *Manager) applyIdlePolicy(pmax, maxPower int64) string {
+	if pm.idlePolicy == nil {
+		return ""
+	}
+	policy := IdleTight
+	if pmax <= pm.config.IdleRelaxBelow.Resolve(maxPower) {
+		policy = IdleRelaxed
+	}
+	if policy == pm.idleState {
+		return policy
+	}
+
+	var errs []error
+	if policy == IdleRelaxed {
+		errs = pm.idlePolicy.Relax()
+	} else {
+		errs = pm.idlePolicy.Tighten()
+	}
+	for _, err := range errs {
+		pm.logger.Printf("❌ Failed to set the %s idle policy: %v", policy, err)
+	}
+	if len(errs) > 0 {
+		// Retried next cycle
+		return pm.idleState
+	}
+
+	pm.logger.Printf("😴 Idle policy %s at %.1f W", policy, float64(pmax)/1000000)
+	pm.idleState = policy
+	value := 0.0
+	if policy == IdleRelaxed {
+		value = 1
+	}
+	pm.metrics.SetGauge("idle_policy_relaxed", "Whether the CPUs may enter their deepest C-states under a low cap", value, nil)
+	return policy
+}
+
+// restoreIdlePolicy writes back the idle settings found at startup
+func (pm *Manager) restoreIdlePolicy() {
+	if pm.idlePolicy == nil || pm.idleState == "" {
+		return
+	}
+	if errs := pm.idlePolicy.Restore(); len(errs) > 0 {
+		for _, err := range errs {
+			pm.logger.Printf("❌ Failed to restore idle policy: %v", err)
+		}
+		return
+	}
+	pm.logger.Println("✅ Restored the idle policy found at startup")
+}
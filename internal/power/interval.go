@@ -0,0 +1,82 @@
+package power
+
+import (
+	"math"
+	"time"
+)
+
+// Volatility bounds of the adaptive interval, as the largest volume change
+// between the current period and its neighbours relative to the reference
+// volume: at or below flatVolatility the interval is the longest, at or above
+// steepVolatility the shortest
+const (
+	flatVolatility  = 0.02
+	steepVolatility = 0.2
+)
+
+// adaptiveInterval reports whether the interval follows the market volatility
+func (pm *Manager) adaptiveInterval() bool {
+	return pm.config.AdjustIntervalMin > 0 && pm.config.AdjustIntervalMax > 0
+}
+
+// maxInterval returns the longest wait between two cycles
+func (pm *Manager) maxInterval() time.Duration {
+	if pm.adaptiveInterval() && pm.config.AdjustIntervalMax > pm.config.StabilisationTime {
+		return pm.config.AdjustIntervalMax
+	}
+	return pm.config.StabilisationTime
+}
+
+// adjustInterval returns the wait before the next cycle: STABILISATION_TIME,
+// or with ADJUST_INTERVAL_MIN and ADJUST_INTERVAL_MAX an interval shortened
+// when the volumes of adjacent periods differ strongly, so the cap follows a
+// steep market closely, and lengthened on flat days to avoid churn
+func (pm *Manager) adjustInterval() time.Duration {
+	if !pm.adaptiveInterval() {
+		return pm.config.StabilisationTime
+	}
+
+	volatility := pm.volatility()
+	shortest, longest := pm.config.AdjustIntervalMin, pm.config.AdjustIntervalMax
+	var interval time.Duration
+	switch {
+	case volatility <= flatVolatility:
+		interval = longest
+	case volatility >= steepVolatility:
+		interval = shortest
+	default:
+		share := (volatility - flatVolatility) / (steepVolatility - flatVolatility)
+		interval = longest - time.Duration(share*float64(longest-shortest))
+	}
+	interval = interval.Round(time.Second)
+
+	pm.metrics.SetGauge("market_volatility", "Largest volume change between the current period and its neighbours, relative to the reference volume", volatility, nil)
+	pm.metrics.SetGauge("adjust_interval_seconds", "Wait before the next adjustment cycle (s)", interval.Seconds(), nil)
+	return interval
+}
+
+// volatility returns the largest volume change between the current period
+// and the previous or next one, relative to the reference volume. Without
+// data for the current period the market is treated as steep, so the cap is
+// corrected as soon as data arrives.
+func (pm *Manager) volatility() float64 {
+	data := pm.dataStore.GetCurrentData()
+	referenceVolume := pm.referenceVolume()
+	period := pm.calculator.GetCurrentPeriod(pm.now())
+	if referenceVolume <= 0 {
+		return steepVolatility
+	}
+
+	i := pm.dataStore.PeriodIndex(period)
+	if i < 0 || i >= len(data) || data[i].Period != period {
+		return steepVolatility
+	}
+	var change float64
+	if i > 0 {
+		change = math.Abs(data[i].Volume - data[i-1].Volume)
+	}
+	if i+1 < len(data) {
+		change = math.Max(change, math.Abs(data[i+1].Volume-data[i].Volume))
+	}
+	return change / referenceVolume
+}
@@ -0,0 +1,117 @@
+package power
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lastStateMaxAge is the age past which a saved state is not restored: the
+// hardware or the market may have changed since
+const lastStateMaxAge = 24 * time.Hour
+
+// lastState is the content of the last state file
+type lastState struct {
+	SavedAt      time.Time       `json:"saved_at"`
+	ConfigHash   string          `json:"config_hash"`
+	AppliedPower int64           `json:"applied_power_uw"`
+	Decisions    []PowerDecision `json:"decisions"` // Recent decisions, oldest first, the last one applied
+}
+
+// saveLastState writes the applied cap and the recent decisions to the last
+// state file, so that a restart resumes from them
+func (pm *Manager) saveLastState(appliedPower int64) {
+	if !pm.config.RestoreLastCap {
+		return
+	}
+
+	pm.mu.RLock()
+	state := lastState{
+		SavedAt:      time.Now(),
+		ConfigHash:   pm.configHash,
+		AppliedPower: appliedPower,
+		Decisions:    append([]PowerDecision(nil), pm.history...),
+	}
+	pm.mu.RUnlock()
+
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = writeFileAtomic(pm.config.LastStatePath(), data)
+	}
+	if err != nil {
+		pm.logger.Printf("⚠️  Failed to save the last state to %s: %v", pm.config.LastStatePath(), err)
+	}
+}
+
+// RestoreLastState applies the cap saved by the previous run and restores its
+// decisions, so that a restart neither leaves the limit found in the kernel
+// until the first cycle completes nor loses the day's decisions. Nothing is
+// restored without a saved state, or when it is older than a day.
+func (pm *Manager) RestoreLastState() error {
+	if !pm.config.RestoreLastCap {
+		return nil
+	}
+
+	path := pm.config.LastStatePath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		pm.logger.Printf("ℹ️  No saved state in %s, waiting for the first cycle", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read last state: %w", err)
+	}
+
+	var state lastState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid last state file %s: %w", path, err)
+	}
+	if len(state.Decisions) == 0 || state.AppliedPower <= 0 {
+		return nil
+	}
+	if age := time.Since(state.SavedAt); age > lastStateMaxAge {
+		pm.logger.Printf("ℹ️  Saved state from %s is %v old, not restoring it", state.SavedAt.Format(time.RFC3339), age.Round(time.Minute))
+		return nil
+	}
+
+	// The configuration may have changed since: keep the cap within the
+	// current minimum and ceiling
+	last := state.Decisions[len(state.Decisions)-1]
+	appliedPower := state.AppliedPower
+	if last.HardwareMax > 0 {
+		appliedPower, _, _ = pm.limitPower(appliedPower, last.HardwareMax)
+	} else if appliedPower < pm.config.RaplLimit {
+		appliedPower = pm.config.RaplLimit
+	}
+
+	ctx, cancel := pm.cycleContext()
+	defer cancel()
+	if errs := pm.raplMgr.ApplyPowerLimitsContext(ctx, appliedPower); len(errs) > 0 {
+		return fmt.Errorf("failed to restore power limit: %w", errors.Join(errs...))
+	}
+	pm.lastApplied = appliedPower
+	pm.metrics.SetGauge("applied_cap_uw", "Power limit currently applied to RAPL domains (µW)", float64(appliedPower), nil)
+
+	cutoff := time.Now().Add(-lastStateMaxAge)
+	pm.mu.Lock()
+	for _, decision := range state.Decisions {
+		if decision.Timestamp.After(cutoff) {
+			pm.history = append(pm.history, decision)
+		}
+	}
+	if len(pm.history) > decisionHistorySize {
+		pm.history = pm.history[len(pm.history)-decisionHistorySize:]
+	}
+	pm.lastDecision = &last
+	pm.mu.Unlock()
+
+	pm.logger.Printf("♻️  Restored cap %d µW (%.1f W) from the decision of %s (period %s), %d decisions",
+		appliedPower, float64(appliedPower)/1000000, last.Timestamp.Format(time.RFC3339), last.Period, len(pm.history))
+	if state.ConfigHash != pm.configHash {
+		pm.logger.Printf("   The configuration changed since the state was saved")
+	}
+	return nil
+}
@@ -7,69 +7,202 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"kcas/new/internal/budget"
+	"kcas/new/internal/calendar"
+	"kcas/new/internal/cgroup"
 	"kcas/new/internal/config"
+	"kcas/new/internal/cpuidle"
 	"kcas/new/internal/datastore"
+	"kcas/new/internal/errreport"
+	"kcas/new/internal/features"
+	"kcas/new/internal/hotplug"
+	"kcas/new/internal/logging"
+	"kcas/new/internal/meter"
+	"kcas/new/internal/metrics"
 	"kcas/new/internal/rapl"
+	"kcas/new/internal/version"
 	"kcas/new/pkg/providers"
 )
 
+// Node annotation names, relative to the configured annotation prefix
 const (
-	InitializationAnnotation = "power-manager/initialized"
+	AnnotationMaxPower     = "max_power_uw"
+	AnnotationPmax         = "pmax"
+	AnnotationProvider     = "provider"
+	AnnotationLastUpdate   = "last-update"
+	AnnotationMarketPeriod = "market-period"
+	AnnotationMarketVolume = "market-volume"
+	AnnotationMarketPrice  = "market-price"
+	AnnotationConfigHash   = "config-hash"
+	AnnotationState        = "state"
+	AnnotationVersion      = "version"
+	AnnotationOverride     = "override"
 )
 
 // Manager handles power management operations
 type Manager struct {
-	clientset  *kubernetes.Clientset
+	nodes      NodeClient
 	config     *config.Config
 	logger     *log.Logger
 	raplMgr    *rapl.Manager
 	dataStore  datastore.DataStore
+	provider   datastore.MarketDataProvider
 	calculator datastore.PowerCalculator
+	reporter   errreport.Reporter
+	metrics    *metrics.Registry
+	events     metrics.EventSink
 	ctx        context.Context
+	location   *time.Location // Market timezone of the periods and daily data
+
+	lastApplied int64             // Last power limit written to RAPL (µW), 0 if none yet
+	lastEnergy  rapl.EnergySample // Previous energy reading used to measure power
+
+	startedAt    time.Time
+	clock        func() time.Time // Current time of the market periods, nil for the system clock
+	wallClock    func() time.Time // Time measuring the energy intervals, nil for the system clock
+	mu           sync.RWMutex
+	lastDecision *PowerDecision
+	loopStats    LoopStats
+
+	refreshMu      sync.Mutex // Serialises scheduled data refreshes
+	refreshPending bool       // A refresh failed and should be retried
+	refreshSkips   int        // Retry ticks skipped since the last failed retry
+
+	failovers      []datastore.MarketDataProvider // Providers used, in order, while the configured one keeps failing
+	activeIndex    int                            // Index in the failover chain of the provider fetching the data, 0 for the configured one
+	providerHealth []ProviderHealth               // Health of every provider of the failover chain, the configured one first
+	degraded       bool                           // A degraded state was signalled
+	staleData      bool                           // The cap follows a previous day's data
+
+	canaryIDs      []string       // Domains receiving raised caps first, nil when canaries are disabled
+	canaryOthers   []string       // Domains keeping the applied cap during a canary
+	canary         *canaryRollout // Raised cap on trial on the canary domains, nil if none
+	canaryRejected int64          // Last cap rolled back from the canary domains
+	canaryRetryAt  time.Time      // Time the rolled back cap may be tried again
+
+	liveMaxPower   int64     // Hardware maximum last read from RAPL (PMAX_SOURCE=live)
+	liveMaxPowerAt time.Time // Time of the last live read
+	pmaxCheckedAt  time.Time // Time of the last check of the annotated maximum (PMAX_RECHECK)
+
+	configHash   string                         // Hash of the running configuration
+	declaredHash string                         // Hash of the declared configuration at the last check
+	configLoader func() (*config.Config, error) // Re-reads the declared configuration
+
+	history   []PowerDecision // Recent decisions, oldest first
+	override  *Override       // Manual override, nil if none
+	adjustNow chan struct{}   // Requests an adjustment before the next tick
+
+	overrideTimer          *time.Timer // Triggers an adjustment when the override expires
+	overrideFromAnnotation bool        // The override was declared in the node annotation
+	annotationOverride     string      // Last seen value of the override annotation
+
+	cycleHook func() // Called after every adjustment cycle, nil if none
+
+	demandEvents []DemandEvent // Recent demand-response events, oldest first
+	lastMeasured int64         // Last measured power (µW), at the wall when metered, 0 if unknown
+
+	wallMeter   meter.Meter // External meter of the node wall power, nil if none
+	lastPackage int64       // Last package power measured from RAPL (µW), 0 if unknown
+	lastWall    int64       // Last wall power read from the meter (µW), 0 if unknown
+
+	energyAt      time.Time      // System time the energy drawn was last accounted
+	costDay       time.Time      // Market midnight of the daily energy and cost
+	dailyEnergyWh float64        // Energy drawn since the market midnight (Wh)
+	dailyCost     float64        // Cost of the energy drawn since the market midnight
+	savings       *SavingsReport // Savings against the baseline, nil until the second cycle
+	uncappedDraw  int64          // Power last measured while the cap was the hardware maximum (µW), 0 if unknown
+
+	onBattery bool // The UPS of the node runs on battery
+
+	cgroups    *cgroup.Throttler // CPU bandwidth of the CGROUP_SLICES, nil when disabled
+	cgroupCPUs float64           // Bandwidth last written to the slices (CPUs), 0 if none
+
+	hotplug     *hotplug.Offliner // CPUs offlined for shed requests, nil when disabled
+	cpusOffline bool              // The CPUs may be offline
+
+	budget []budget.Actuator // Actuators sharing the node budget in squeeze order, nil when not split
+
+	idlePolicy *cpuidle.Policy // Idle policy of the CPUs, nil when IDLE_RELAX_BELOW is unset
+	idleState  string          // Idle policy in force, empty until written
+
+	experimentArm string // Experiment arm of the node, empty when experiments are disabled
+
+	batterySoC   float64   // Last state of charge of the site battery (%)
+	batterySoCAt time.Time // Time of the last state of charge reading
+	pvPower      int64     // Last solar production read (µW)
+	pvPowerAt    time.Time // Time of the last solar production reading
+
+	siteDemand   int64     // Last power read from the site meter (µW)
+	siteDemandAt time.Time // Time of the last site meter reading
+	peakShaving  float64   // Share of the cap above the minimum shed for peak shaving
+
+	inPriceSpike bool // The last cycle enforced the floor for a price spike
+
+	planMu  sync.Mutex
+	dayPlan *dayPlan // Caps planned for the loaded day, nil until first needed
+
+	calendar     *calendar.Calendar // Days the market is closed, nil if none
+	closedReason string             // Why the market was closed in the last cycle, empty if open
+
+	subscribers map[chan PowerDecision]struct{} // Receivers of every new decision
 }
 
 // NewManager creates and initializes a new power Manager
-func NewManager(ctx context.Context, logger *log.Logger) (*Manager, error) {
+func NewManager(ctx context.Context, cfg *config.Config, logger *log.Logger) (*Manager, error) {
 	logger.Println("🚀 Initializing PowerCap Manager...")
-
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
-	}
 	logger.Printf("✅ Configuration loaded successfully")
 	logger.Printf("   - Node Name: %s", cfg.NodeName)
 	logger.Printf("   - Data Provider: %s", cfg.DataProvider)
 	logger.Printf("   - Provider URL: %s", cfg.ProviderURL)
 	logger.Printf("   - Stabilisation Time: %v", cfg.StabilisationTime)
 	logger.Printf("   - RAPL Min Power: %d µW (%.1f W)", cfg.RaplLimit, float64(cfg.RaplLimit)/1000000)
+	logger.Printf("   - Feature Gates: %s", cfg.Features)
+	logger.Printf("   - Config Hash: %s", config.Hash(cfg))
 
-	logger.Println("🔌 Creating Kubernetes client...")
-	clientset, err := createKubernetesClient()
+	logger.Printf("🔌 Creating %s client...", cfg.Orchestrator)
+	nodes, err := NewNodeClient(cfg)
 	if err != nil {
-		logger.Printf("❌ Failed to create Kubernetes client: %v", err)
-		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		logger.Printf("❌ Failed to create %s client: %v", cfg.Orchestrator, err)
+		return nil, fmt.Errorf("failed to create %s client: %w", cfg.Orchestrator, err)
 	}
-	logger.Printf("✅ Kubernetes client created successfully")
+	logger.Printf("✅ %s client created successfully", cfg.Orchestrator)
 
 	logger.Println("⚡ Discovering RAPL domains...")
 	raplMgr := rapl.NewManager(logger)
+	raplMgr.SetBackend(rapl.Backend(cfg.PowerBackend), cfg.CPUFreqMaxPower)
+	raplMgr.SetRoot(cfg.SysfsRoot)
 	if err := raplMgr.DiscoverDomains(); err != nil {
 		logger.Printf("❌ Failed to discover RAPL domains: %v", err)
 		return nil, fmt.Errorf("failed to discover RAPL domains: %w", err)
 	}
-	logger.Printf("✅ Discovered %d RAPL domains", len(raplMgr.GetDomains()))
+	logger.Printf("✅ Discovered %d RAPL domains (%s backend)", len(raplMgr.GetDomains()), raplMgr.Backend())
+
+	canaryIDs, canaryOthers := resolveCanaryDomains(cfg.CanaryWindow, cfg.CanaryDomains, raplMgr.GetDomains())
+	switch {
+	case canaryIDs != nil:
+		logger.Printf("🐤 Raised caps run on %v for %v before the other domains", canaryIDs, cfg.CanaryWindow)
+	case cfg.CanaryWindow > 0:
+		logger.Printf("⚠️  %s=%v ignored: the canary domains must leave other domains to compare with", config.EnvCanaryWindow, cfg.CanaryWindow)
+	}
 
 	// Initialize data store and calculator
 	logger.Println("📊 Initializing data store and calculator...")
 	dataStore := datastore.NewCSVDataStore(logger)
+	dataStore.SetDirectory(cfg.DataDir)
+	location := cfg.MarketLocation()
+	dataStore.SetLocation(location)
+	dataStore.SetCurrency(cfg.Currency)
+	dataStore.SetFallbackDays(cfg.FallbackDays)
+	dataStore.SetSmoothingWindow(cfg.SmoothingWindow)
 	calculator := datastore.NewMarketBasedCalculator()
+	calculator.SetLocation(location)
 
 	// Create and configure provider using factory
 	logger.Println("🏭 Setting up market data provider...")
@@ -88,36 +221,174 @@ func NewManager(ctx context.Context, logger *log.Logger) (*Manager, error) {
 	dataStore.SetProvider(provider)
 	logger.Printf("✅ Configured data provider: %s", provider.GetName())
 
+	failovers, err := factory.CreateFailovers(cfg)
+	if err != nil {
+		logger.Printf("❌ Failed to create failover provider: %v", err)
+		return nil, fmt.Errorf("failed to create failover provider: %w", err)
+	}
+	providerHealth := []ProviderHealth{newProviderHealth(cfg.DataProvider)}
+	providerHealth[0].Active = true
+	for i, failover := range failovers {
+		logger.Printf("✅ Failover data provider %d: %s (after %d failed fetches)", i+1, failover.GetName(), cfg.FailoverAfter)
+		providerHealth = append(providerHealth, newProviderHealth(cfg.FailoverProviders[i]))
+	}
+
+	actuators, err := discoverBudget(cfg, len(raplMgr.GetDomains()))
+	if err != nil {
+		logger.Printf("❌ Failed to discover the %s actuators: %v", config.EnvBudgetSplit, err)
+		return nil, fmt.Errorf("failed to discover the %s actuators: %w", config.EnvBudgetSplit, err)
+	}
+	if len(actuators) > 0 {
+		logger.Printf("🎚️  Node budget split across %v, squeezed first to last", cfg.BudgetSplit)
+	}
+
+	marketCalendar, err := cfg.MarketCalendar()
+	if err != nil {
+		logger.Printf("❌ Invalid market calendar: %v", err)
+		return nil, fmt.Errorf("invalid market calendar: %w", err)
+	}
+
 	logger.Printf("✅ PowerCap Manager initialized successfully with %d RAPL domains", len(raplMgr.GetDomains()))
 
-	return &Manager{
-		clientset:  clientset,
+	pm := &Manager{
+		nodes:      nodes,
 		config:     cfg,
 		logger:     logger,
 		raplMgr:    raplMgr,
 		dataStore:  dataStore,
 		calculator: calculator,
+		provider:   provider,
+		failovers:  failovers,
+		reporter:   errreport.NopReporter{},
+		metrics:    metrics.NewRegistry(),
 		ctx:        ctx,
-	}, nil
+		location:   location,
+		startedAt:  time.Now(),
+		configHash: config.Hash(cfg),
+		adjustNow:  make(chan struct{}, 1),
+		calendar:   marketCalendar,
+
+		canaryIDs:    canaryIDs,
+		canaryOthers: canaryOthers,
+		budget:       actuators,
+
+		providerHealth: providerHealth,
+	}
+	if len(cfg.CgroupSlices) > 0 {
+		pm.cgroups = cgroup.NewThrottler(cfg.SysfsRoot, cfg.CgroupSlices, cfg.CgroupMinCPUs)
+		logger.Printf("🧮 CPU bandwidth of %v follows the cap, down to %g CPUs", cfg.CgroupSlices, cfg.CgroupMinCPUs)
+	}
+	if cfg.IdleRelaxBelow.IsSet() {
+		pm.idlePolicy = cpuidle.NewPolicy(cfg.SysfsRoot, cfg.IdleLatency)
+	}
+	if len(cfg.DROfflineCPUs) > 0 {
+		// A previous run may have stopped with the CPUs offline
+		pm.hotplug, pm.cpusOffline = hotplug.NewOffliner(cfg.SysfsRoot, cfg.DROfflineCPUs), true
+	}
+	if replay, ok := provider.(datastore.ClockProvider); ok && replay.Clock() != nil {
+		pm.clock = replay.Clock()
+		logger.Printf("⏩ Market periods follow the clock of %s, now %s", provider.GetName(), pm.now().Format("2006-01-02 15:04"))
+	}
+	pm.recordFeatureGates()
+	pm.recordActiveProvider()
+	pm.recordBuildInfo()
+	return pm, nil
+}
+
+// recordBuildInfo publishes the version of the running binary
+func (pm *Manager) recordBuildInfo() {
+	info := version.Get()
+	pm.metrics.SetGauge("build_info", "Build information of the running manager (always 1)", 1, metrics.Labels{
+		"version":    info.Version,
+		"commit":     info.Commit,
+		"go_version": info.GoVersion,
+	})
+}
+
+// recordFeatureGates publishes the state of every feature gate
+func (pm *Manager) recordFeatureGates() {
+	for _, feature := range features.Known() {
+		enabled := 0.0
+		if pm.config.Features.Enabled(feature) {
+			enabled = 1
+		}
+		pm.metrics.SetGauge("feature_enabled", "Whether a gated feature is enabled (1) or not (0)", enabled, metrics.Labels{"feature": string(feature)})
+	}
+}
+
+// wallNow returns the time measuring the energy intervals, which a replay
+// clock would stretch or rewind
+func (pm *Manager) wallNow() time.Time {
+	if pm.wallClock != nil {
+		return pm.wallClock()
+	}
+	return time.Now()
+}
+
+// now returns the current time in the market timezone
+func (pm *Manager) now() time.Time {
+	if pm.clock != nil {
+		return pm.clock().In(pm.location)
+	}
+	return time.Now().In(pm.location)
+}
+
+// Metrics returns the registry holding the manager's metrics
+func (pm *Manager) Metrics() *metrics.Registry {
+	return pm.metrics
+}
+
+// SetEventSink sets the sink notified when the applied cap changes
+func (pm *Manager) SetEventSink(sink metrics.EventSink) {
+	pm.events = sink
+}
+
+// SetCycleHook sets a function called after every adjustment cycle, e.g. to
+// push metrics. It must not block.
+func (pm *Manager) SetCycleHook(hook func()) {
+	pm.cycleHook = hook
+}
+
+// SetWallMeter sets the external meter reading the node wall power, which
+// feeds reports and, with the ClosedLoop feature, corrects the cap for the
+// platform draw RAPL does not see
+func (pm *Manager) SetWallMeter(m meter.Meter) {
+	pm.wallMeter = m
+}
+
+// SetErrorReporter sets the reporter receiving cycle errors and panics
+func (pm *Manager) SetErrorReporter(reporter errreport.Reporter) {
+	pm.reporter = reporter
 }
 
 // SetDataProvider sets the market data provider (deprecated - use config instead)
 func (pm *Manager) SetDataProvider(provider datastore.MarketDataProvider) {
 	pm.logger.Printf("Warning: SetDataProvider is deprecated. Use configuration instead.")
 	pm.dataStore.SetProvider(provider)
+	pm.provider = provider
 }
 
 // LoadData loads market data for the given date
 func (pm *Manager) LoadData(date time.Time) error {
 	pm.logger.Printf("📥 Loading market data for %s...", date.Format("2006-01-02"))
 
-	data, err := pm.dataStore.LoadData(date)
+	ctx, cancel := pm.refreshContext()
+	defer cancel()
+	data, err := pm.dataStore.LoadData(ctx, date)
 	if err != nil {
 		pm.logger.Printf("❌ Failed to load market data for %s: %v", date.Format("2006-01-02"), err)
 		return fmt.Errorf("failed to load market data: %w", err)
 	}
 
 	pm.logger.Printf("✅ Successfully loaded %d market data points for %s", len(data), date.Format("2006-01-02"))
+	pm.replan()
+
+	// The previous day's data stands in until the retries fetch the day's
+	if stale, dataDate := pm.dataStale(date); stale {
+		pm.logger.Printf("⚠️  Using the stale data of %s, retrying the fetch of %s on %q",
+			dataDate.Format("2006-01-02"), date.Format("2006-01-02"), pm.config.DataRetryCron)
+		pm.refreshPending = true
+	}
 
 	// Log sample data for debugging
 	if len(data) > 0 {
@@ -127,8 +398,8 @@ func (pm *Manager) LoadData(date time.Time) error {
 			sampleCount = len(data)
 		}
 		for i := 0; i < sampleCount; i++ {
-			pm.logger.Printf("      %s: %.1f MWh @ %.2f €/MWh",
-				data[i].Period, data[i].Volume, data[i].Price)
+			pm.logger.Printf("      %s: %.1f MWh @ %.2f %s",
+				data[i].Period, data[i].Volume, data[i].Price, datastore.PriceUnit(pm.config.Currency))
 		}
 		if len(data) > sampleCount {
 			pm.logger.Printf("      ... and %d more data points", len(data)-sampleCount)
@@ -138,11 +409,14 @@ func (pm *Manager) LoadData(date time.Time) error {
 	return nil
 }
 
-// InitializeNode initializes the Kubernetes node with RAPL information
+// InitializeNode initializes the node annotations (or Nomad metadata) with
+// RAPL information
 func (pm *Manager) InitializeNode() error {
-	pm.logger.Printf("🔧 Initializing Kubernetes node '%s'...", pm.config.NodeName)
+	pm.logger.Printf("🔧 Initializing %s node '%s'...", pm.config.Orchestrator, pm.config.NodeName)
 
-	node, err := pm.getNode()
+	ctx, cancel := pm.cycleContext()
+	defer cancel()
+	node, err := pm.getNode(ctx)
 	if err != nil {
 		pm.logger.Printf("❌ Failed to get node '%s': %v", pm.config.NodeName, err)
 		return fmt.Errorf("failed to get node: %w", err)
@@ -157,6 +431,26 @@ func (pm *Manager) InitializeNode() error {
 
 	pm.logger.Printf("🚀 Node '%s' not initialized, proceeding with initialization...", node.Name)
 
+	maxPower, err := pm.initAnnotations(node)
+	if err != nil {
+		return err
+	}
+
+	// Mark the node as initialized
+	pm.logger.Printf("🏷️  Marking node as initialized...")
+	if err := pm.markNodeAsInitialized(ctx, node); err != nil {
+		pm.logger.Printf("❌ Failed to mark node as initialized: %v", err)
+		return fmt.Errorf("failed to mark node as initialized: %w", err)
+	}
+
+	pm.logger.Printf("✅ Node '%s' initialized successfully with max power: %d µW (%.1f W)",
+		node.Name, maxPower, float64(maxPower)/1000000)
+	return nil
+}
+
+// initAnnotations sets the annotations of a node being initialized from the
+// RAPL maximum power, and returns it
+func (pm *Manager) initAnnotations(node *v1.Node) (int64, error) {
 	if node.Annotations == nil {
 		node.Annotations = make(map[string]string)
 		pm.logger.Printf("📝 Created new annotations map for node '%s'", node.Name)
@@ -167,124 +461,348 @@ func (pm *Manager) InitializeNode() error {
 	maxPower, err := pm.raplMgr.FindMaxPowerValue()
 	if err != nil {
 		pm.logger.Printf("❌ Failed to find max power value: %v", err)
-		return fmt.Errorf("failed to find max power value: %w", err)
+		return 0, fmt.Errorf("failed to find max power value: %w", err)
 	}
 	pm.logger.Printf("✅ Found maximum power value: %d µW (%.1f W)", maxPower, float64(maxPower)/1000000)
 
 	// Store a single value for the node
 	maxPowerValue := strconv.FormatInt(maxPower, 10)
 	pm.logger.Printf("📝 Setting node annotations...")
-	node.Annotations["rapl/max_power_uw"] = maxPowerValue
-	node.Annotations["rapl/pmax"] = maxPowerValue
-	node.Annotations["rapl/provider"] = pm.config.DataProvider
-	pm.logger.Printf("   - rapl/max_power_uw: %s", maxPowerValue)
-	pm.logger.Printf("   - rapl/pmax: %s", maxPowerValue)
-	pm.logger.Printf("   - rapl/provider: %s", pm.config.DataProvider)
-
-	// Mark the node as initialized
-	pm.logger.Printf("🏷️  Marking node as initialized...")
-	if err := pm.markNodeAsInitialized(node); err != nil {
-		pm.logger.Printf("❌ Failed to mark node as initialized: %v", err)
-		return fmt.Errorf("failed to mark node as initialized: %w", err)
-	}
+	node.Annotations[pm.annotation(AnnotationMaxPower)] = maxPowerValue
+	node.Annotations[pm.annotation(AnnotationPmax)] = maxPowerValue
+	node.Annotations[pm.annotation(AnnotationProvider)] = pm.config.DataProvider
+	pm.logger.Printf("   - %s: %s", pm.annotation(AnnotationMaxPower), maxPowerValue)
+	pm.logger.Printf("   - %s: %s", pm.annotation(AnnotationPmax), maxPowerValue)
+	pm.logger.Printf("   - %s: %s", pm.annotation(AnnotationProvider), pm.config.DataProvider)
+	return maxPower, nil
+}
 
-	pm.logger.Printf("✅ Node '%s' initialized successfully with max power: %s µW (%.1f W)",
-		node.Name, maxPowerValue, float64(maxPower)/1000000)
-	return nil
+// referenceVolume returns the volume matching the hardware maximum: the
+// day's maximum or average, or 100 when volumes are percentages
+// (POWER_CALC_MODE=percent)
+func (pm *Manager) referenceVolume() float64 {
+	return pm.dataStore.GetReferenceVolume(pm.config.PowerCalcMode)
 }
 
-// AdjustPowerCap adjusts the power cap based on current market data
+// AdjustPowerCap adjusts the power cap based on current market data, within
+// CYCLE_TIMEOUT
 func (pm *Manager) AdjustPowerCap() error {
+	ctx, cancel := pm.cycleContext()
+	defer cancel()
+	return pm.adjustPowerCap(ctx)
+}
+
+// adjustPowerCap runs an adjustment cycle, ctx bounding the node API calls,
+// the meter reads and the RAPL writes
+func (pm *Manager) adjustPowerCap(ctx context.Context) error {
 	pm.logger.Printf("🔄 Starting power cap adjustment cycle...")
 
-	node, err := pm.getNode()
+	pm.measurePower(ctx)
+	energy, accounted := pm.accountEnergy(pm.wallNow(), pm.now())
+
+	node, err := pm.getNode(ctx)
 	if err != nil {
 		pm.logger.Printf("❌ Failed to get node: %v", err)
 		return fmt.Errorf("failed to get node: %w", err)
 	}
+	reinitialized, err := pm.restoreNodeState(node)
+	if err != nil {
+		pm.logger.Printf("❌ Failed to initialize node again: %v", err)
+		return fmt.Errorf("failed to initialize node again: %w", err)
+	}
+	pm.syncAnnotationOverride(node)
+	pm.expireOverride(node)
 
 	// Calculate source power using market data
-	currentTime := time.Now()
+	currentTime := pm.now()
 	currentPeriod := pm.calculator.GetCurrentPeriod(currentTime)
 	pm.logger.Printf("⏰ Current time: %s (period: %s)", currentTime.Format("15:04:05"), currentPeriod)
 
 	data := pm.dataStore.GetCurrentData()
-	maxVolume := pm.dataStore.GetMaxVolume()
-	pm.logger.Printf("📊 Market data: %d points available, max volume: %.1f MWh", len(data), maxVolume)
+	referenceVolume := pm.referenceVolume()
+	pm.logger.Printf("📊 Market data: %d points available, reference volume: %.1f", len(data), referenceVolume)
+
+	decision := PowerDecision{
+		Timestamp:       currentTime,
+		Node:            pm.config.NodeName,
+		Provider:        pm.providerName(),
+		Currency:        pm.config.Currency,
+		Period:          currentPeriod,
+		ReferenceVolume: referenceVolume,
+		DataPoints:      len(data),
+		DataUpdatedAt:   pm.dataStore.GetLastUpdate(),
+		MinPower:        pm.config.RaplLimit,
+		WallPower:       pm.lastWall,
+		Formula:         "source_power = (volume / reference_volume) × hardware_max",
+		Clamp:           ClampNone,
+		ExperimentArm:   pm.ExperimentArm(),
+		Reinitialized:   reinitialized,
+	}
+	if accounted {
+		decision.EnergyWh, decision.Cost, decision.EnergyEstimated = energy.Wh, energy.Cost, energy.Estimated
+		decision.DailyEnergyWh, decision.DailyCost = energy.DailyEnergyWh, energy.DailyCost
+	}
+	if !decision.DataUpdatedAt.IsZero() {
+		decision.DataAge = currentTime.Sub(decision.DataUpdatedAt).Round(time.Second).String()
+	}
+	stale, dataDate := pm.dataStale(currentTime)
+	if !dataDate.IsZero() {
+		decision.DataDate = dataDate.Format("2006-01-02")
+	}
+	if stale {
+		decision.DataStale = true
+		decision.Fallbacks = append(decision.Fallbacks, "stale market data: using the data of "+decision.DataDate)
+	}
+	pm.updateStaleData(stale, dataDate)
+	if point, ok := pm.dataStore.CurrentPoint(currentPeriod); ok {
+		decision.PeriodFound = true
+		decision.Volume = point.Volume
+		decision.Price = point.Price
+	}
 
 	// Get the maximum hardware power limit from RAPL
 	pm.logger.Printf("⚡ Retrieving RAPL max power...")
-	maxPower, err := pm.getMaxPowerValue(node)
+	maxPower, err := pm.hardwareMax(node)
 	if err != nil {
 		pm.logger.Printf("❌ Failed to get max power value: %v", err)
 		return fmt.Errorf("failed to get max power value: %w", err)
 	}
 	pm.logger.Printf("✅ RAPL max power: %d µW (%.1f W)", maxPower, float64(maxPower)/1000000)
-
-	// Use RAPL max power as the reference for rule of three calculation
-	pm.logger.Printf("🧮 Calculating source power using market data...")
-	sourcePower := pm.calculator.CalculatePower(float64(maxPower), maxVolume, currentTime, data)
-
-	if sourcePower == 0 {
+	decision.HardwareMax = maxPower
+
+	// The market-based limit of the period comes from the day's plan, computed
+	// once per data refresh with RAPL max power as the rule of three reference
+	pm.logger.Printf("🧮 Looking up the planned cap of period %s...", currentPeriod)
+	plan := pm.plan(maxPower)
+	planned, planFound := plan.lookup(currentPeriod)
+	sourcePower := planned.SourcePower
+
+	if !planFound || planned.Volume == 0 {
+		if substitute, from, ok := pm.periodFallback(plan, currentTime, currentPeriod); ok {
+			planned, planFound = substitute, true
+			sourcePower = planned.SourcePower
+			decision.Fallbacks = append(decision.Fallbacks, "no market data for period: using "+from)
+			pm.logger.Printf("⚠️  No market data found for period %s, using %s: %d µW (%.1f W)",
+				currentPeriod, from, sourcePower, float64(sourcePower)/1000000)
+		}
+	}
+	if !planFound || planned.Volume == 0 {
 		pm.logger.Printf("⚠️  No market data found for period %s, using minimum power fallback", currentPeriod)
 		sourcePower = pm.config.RaplLimit
+		decision.Fallbacks = append(decision.Fallbacks, "no market data for period: using minimum power")
 		pm.logger.Printf("   Fallback source power: %d µW (%.1f W)", sourcePower, float64(sourcePower)/1000000)
 	} else {
 		pm.logger.Printf("✅ Calculated source power: %d µW (%.1f W)", sourcePower, float64(sourcePower)/1000000)
 	}
+	decision.SourcePower = sourcePower
 
 	// Determine the power limit to apply
 	pm.logger.Printf("🎯 Determining final power limit to apply...")
-	var pmax int64 = pm.config.RaplLimit
-	pm.logger.Printf("   Starting with minimum: %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	pmax, clamp := planned.CapUW, planned.Clamp
+	if !planFound {
+		pmax, clamp, _ = pm.limitPower(sourcePower, maxPower)
+	}
+	decision.Clamp = clamp
+	decision.AdminMax = plan.adminMax
 
-	if sourcePower > maxPower {
-		pmax = maxPower
-		pm.logger.Printf("   ⬆️  Source power exceeds max hardware limit")
-		pm.logger.Printf("   🔒 Capped to hardware max: %d µW (%.1f W)", pmax, float64(pmax)/1000000)
-	} else if sourcePower > pm.config.RaplLimit {
-		pmax = sourcePower
+	// Shadow modes are compared with the market-based cap, before overrides
+	if planFound && planned.Volume != 0 {
+		point := datastore.MarketDataPoint{Period: planned.Period, Volume: planned.Volume, Price: planned.Price}
+		decision.Shadows = pm.evaluateShadows(point, maxPower, plan.closed, pmax)
+	}
+
+	// A previous day's data is trusted less the older it is: the cap above
+	// the minimum is derated by the confidence in it
+	confidence, age := pm.dataConfidence(currentTime)
+	pm.metrics.SetGauge("market_data_confidence", "Confidence in the market data, derating the cap when a previous day's stands in", confidence, nil)
+	if confidence < 1 && clamp != ClampClosedDay && pmax > pm.config.RaplLimit {
+		pmax = pm.config.RaplLimit + int64(confidence*float64(pmax-pm.config.RaplLimit))
+		decision.Confidence = confidence
+		decision.Fallbacks = append(decision.Fallbacks,
+			fmt.Sprintf("market data %d days old: cap above the minimum derated to %.0f%%", age, confidence*100))
+		pm.logger.Printf("   📉 Market data %d days old, derating the cap to %.0f%% above the minimum: %d µW (%.1f W)",
+			age, confidence*100, pmax, float64(pmax)/1000000)
+	}
+
+	// Closing and spikes are tracked every cycle for their metrics and events
+	decision.MarketClosed = pm.marketClosed(currentTime)
+	if !plan.closed && decision.PeriodFound {
+		pm.priceSpike(decision.Price, data)
+	}
+
+	switch clamp {
+	case ClampHardwareMax:
+		pm.logger.Printf("   🔒 Source power exceeds max hardware limit, capped to %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	case ClampMinPower:
+		pm.logger.Printf("   🔒 Source power below minimum threshold, using minimum limit %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	case ClampAdminMax:
+		pm.logger.Printf("   🔒 Capped to administrative ceiling %s: %d µW (%.1f W)",
+			pm.config.RaplMaxPower, pmax, float64(pmax)/1000000)
+	case ClampClosedDay:
+		pm.logger.Printf("   📅 Market closed (%s), using closed day limit %d µW (%.1f W)",
+			decision.MarketClosed, pmax, float64(pmax)/1000000)
+	case ClampPriceSpike:
+		pm.logger.Printf("   💥 Price spike at %.2f %s, using minimum limit %d µW (%.1f W)",
+			decision.Price, datastore.PriceUnit(pm.config.Currency), pmax, float64(pmax)/1000000)
+	default:
 		pm.logger.Printf("   ✅ Using calculated source power: %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	}
+
+	// With a wall meter and ClosedLoop, the market-based limit applies to the
+	// wall power: the platform draw outside the RAPL domains is subtracted
+	if overhead := pm.platformOverhead(); overhead > 0 {
+		decision.PlatformOverhead = overhead
+		if pmax -= overhead; pmax < pm.config.RaplLimit {
+			pmax = pm.config.RaplLimit
+		}
+		pm.logger.Printf("   🔁 Platform draw %d µW (%.1f W) outside RAPL, package limit %d µW (%.1f W)",
+			overhead, float64(overhead)/1000000, pmax, float64(pmax)/1000000)
+	}
+
+	// The site battery runs the node hot while full and PV produces, and
+	// throttles it as the charge drops
+	if limit, clamp, soc, pv := pm.batteryPower(pmax, maxPower); soc != nil {
+		decision.BatterySoC, decision.PVPower = soc, pv
+		if clamp != "" {
+			pmax, decision.Clamp = limit, clamp
+			pm.logger.Printf("   🔆 Battery at %.0f%% (%s): %d µW (%.1f W)", *soc, clamp, pmax, float64(pmax)/1000000)
+		}
+	}
+
+	// Peak shaving wins over the market signal while the site nears its
+	// demand-charge threshold
+	if factor, demand := pm.PeakShaving(); factor > 0 && pmax > pm.config.RaplLimit {
+		pmax -= int64(factor * float64(pmax-pm.config.RaplLimit))
+		decision.Clamp, decision.SiteDemand, decision.PeakShaving = ClampPeakShaving, demand, factor
+		pm.logger.Printf("   🏢 Site demand %.1f kW near the %.1f kW threshold, shaving %.0f%%: %d µW (%.1f W)",
+			float64(demand)/1e9, float64(pm.config.PeakThreshold)/1e9, factor*100, pmax, float64(pmax)/1000000)
+	}
+
+	// A manual override replaces the market-based limit until it expires
+	if override, ok := pm.ActiveOverride(); ok {
+		pmax, decision.Clamp = pm.overridePower(override, maxPower)
+		decision.Override = &override
+		pm.logger.Printf("   🔧 Manual override by %s until %s (%s): %d µW (%.1f W)",
+			override.SetBy, override.ExpiresAt.Format("15:04:05"), override.describe(), pmax, float64(pmax)/1000000)
+		pm.metrics.SetGauge("override_active", "Whether a manual override pins the cap", 1, nil)
 	} else {
-		pm.logger.Printf("   ⬇️  Source power below minimum threshold")
-		pm.logger.Printf("   🔒 Using minimum limit: %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+		pm.metrics.SetGauge("override_active", "Whether a manual override pins the cap", 0, nil)
 	}
 
+	// Running on battery wins over the market signal and overrides
+	if pm.OnBattery() && pmax > pm.config.RaplLimit {
+		pmax, decision.Clamp = pm.config.RaplLimit, ClampBattery
+		decision.Fallbacks = append(decision.Fallbacks, "UPS on battery: using minimum power")
+		pm.logger.Printf("   🔋 UPS on battery, dropping to the minimum power %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	}
+
+	// The BUDGET_SPLIT actuators share the node cap, the RAPL packages
+	// receiving the CPU's share
+	pmax, decision.Budget = pm.splitBudget(pmax, maxPower)
+
+	// A change within CAP_DEADBAND keeps the applied cap, writing nothing to
+	// RAPL. A larger raise runs on the canary domains first, the others
+	// keeping the applied cap until it is verified.
+	if pm.withinDeadband(pmax, maxPower, decision.Override != nil) {
+		if pmax != pm.lastApplied {
+			pm.logger.Printf("   💤 Change to %d µW within the deadband, keeping %d µW (%.1f W)",
+				pmax, pm.lastApplied, float64(pm.lastApplied)/1000000)
+		}
+		pmax, decision.Deadband = pm.lastApplied, true
+	} else {
+		pmax, decision.Canary = pm.canaryCap(pmax, decision.Override != nil)
+	}
+	decision.AppliedPower = pmax
+
 	// Log the calculation details
 	pm.logger.Printf("📋 Power calculation summary:")
 	pm.logger.Printf("   - Period: %s", currentPeriod)
 	pm.logger.Printf("   - Source Power: %d µW (%.1f W)", sourcePower, float64(sourcePower)/1000000)
 	pm.logger.Printf("   - Max Hardware: %d µW (%.1f W)", maxPower, float64(maxPower)/1000000)
 	pm.logger.Printf("   - Min Threshold: %d µW (%.1f W)", pm.config.RaplLimit, float64(pm.config.RaplLimit)/1000000)
+	if decision.AdminMax > 0 {
+		pm.logger.Printf("   - Admin Ceiling: %d µW (%.1f W)", decision.AdminMax, float64(decision.AdminMax)/1000000)
+	}
 	pm.logger.Printf("   - Applied Limit: %d µW (%.1f W)", pmax, float64(pmax)/1000000)
+	pm.logger.Printf("%s period=%s source=%d µW applied=%d µW (%.1f W)",
+		logging.DecisionPrefix, currentPeriod, sourcePower, pmax, float64(pmax)/1000000)
+
+	if decision.Deadband {
+		// The annotations still follow the period, the data and the provider
+		pm.logger.Printf("💤 Cap unchanged within %s=%s, skipping the RAPL writes", config.EnvCapDeadband, pm.config.CapDeadband)
+		pm.metrics.AddCounter("deadband_skips_total", "Number of cycles whose cap change was within the deadband and not written to RAPL", 1, nil)
+		pm.setAnnotations(node, pmax)
+		if err := pm.updateNode(ctx, node); err != nil {
+			return err
+		}
+	} else {
+		pm.logger.Printf("⚡ Applying power limits to RAPL domains...")
+		if err := pm.applyPowerLimits(ctx, node, pmax); err != nil {
+			return err
+		}
+		pm.writeBudget(decision.Budget)
+		pm.throttleCgroups(pmax, maxPower)
+	}
+	decision.CgroupCPUs = pm.cgroupCPUs
+	decision.OfflineCPUs = pm.offlineCPUs()
+	decision.IdlePolicy = pm.applyIdlePolicy(pmax, maxPower)
 
-	pm.logger.Printf("⚡ Applying power limits to RAPL domains...")
-	return pm.applyPowerLimits(node, pmax)
+	pm.setLastDecision(decision)
+	pm.recordAppliedCap(currentPeriod, sourcePower, maxPower, pmax)
+	pm.saveLastState(pmax)
+	return nil
+}
+
+// limitPower clamps the source power between the minimum power and the
+// hardware maximum, then to the administrative ceiling, which wins over the
+// minimum to protect facility breakers. It returns the limit, the clamp
+// applied and the resolved ceiling (0 when none is set).
+func (pm *Manager) limitPower(sourcePower, maxPower int64) (int64, string, int64) {
+	pmax, clamp := sourcePower, ClampNone
+	if sourcePower > maxPower {
+		pmax, clamp = maxPower, ClampHardwareMax
+	} else if sourcePower <= pm.config.RaplLimit {
+		pmax, clamp = pm.config.RaplLimit, ClampMinPower
+	}
+
+	var adminMax int64
+	if pm.config.RaplMaxPower.IsSet() {
+		adminMax = pm.config.RaplMaxPower.Resolve(maxPower)
+		if pmax > adminMax {
+			pmax, clamp = adminMax, ClampAdminMax
+		}
+	}
+	return pmax, clamp, adminMax
 }
 
 // Run starts the power management cycle
 func (pm *Manager) Run() {
 	pm.logger.Println("Starting power management cycle...")
 
-	ticker := time.NewTicker(pm.config.StabilisationTime)
-	defer ticker.Stop()
+	timer := time.NewTimer(pm.config.StabilisationTime)
+	defer timer.Stop()
 
-	// Schedule daily data refresh at midnight
-	dailyTicker := pm.scheduleDailyDataRefresh()
-	defer dailyTicker.Stop()
+	// Schedule the day rollover and data refreshes
+	scheduler := pm.startScheduler()
+	defer func() {
+		// Let running refreshes finish before returning
+		<-scheduler.Stop().Done()
+	}()
+	pm.consumePushes()
 
 	// Do an initial adjustment
-	if err := pm.AdjustPowerCap(); err != nil {
-		pm.logger.Printf("Initial power cap adjustment failed: %v", err)
-	}
+	pm.runCycle()
+	interval := pm.resetTimer(timer, 0)
 
 	// Main event loop
 	for {
 		select {
-		case <-ticker.C:
-			if err := pm.AdjustPowerCap(); err != nil {
-				pm.logger.Printf("Failed to adjust power cap: %v", err)
-			}
+		case <-timer.C:
+			pm.runCycle()
+			interval = pm.resetTimer(timer, interval)
+		case <-pm.adjustNow:
+			pm.runCycle()
+			interval = pm.resetTimer(timer, interval)
 		case <-pm.ctx.Done():
 			pm.logger.Println("Power manager shutting down...")
 			return
@@ -292,62 +810,230 @@ func (pm *Manager) Run() {
 	}
 }
 
+// resetTimer schedules the next cycle after the current interval, logging
+// when the adaptive interval changes from the previous one
+func (pm *Manager) resetTimer(timer *time.Timer, previous time.Duration) time.Duration {
+	interval := pm.adjustInterval()
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	pm.mu.RLock()
+	failures := pm.loopStats.ConsecutiveFailures
+	pm.mu.RUnlock()
+	if wait := pm.backoff(interval, failures); wait > interval {
+		timer.Reset(wait)
+		pm.logger.Printf("⏳ %d consecutive failed cycles, next attempt in %v", failures, wait)
+		return interval
+	}
+	timer.Reset(interval)
+
+	if previous > 0 && interval != previous {
+		pm.logger.Printf("⏱️  Market volatility %.1f%%, next adjustment in %v (was %v)",
+			pm.volatility()*100, interval, previous)
+	}
+	return interval
+}
+
+// runCycle performs one adjustment and records its outcome
+func (pm *Manager) runCycle() {
+	pm.checkConfigDrift()
+
+	pm.mu.RLock()
+	previous := pm.loopStats.LastError
+	pm.mu.RUnlock()
+
+	start := time.Now()
+	err := pm.AdjustPowerCap()
+	pm.recordCycle(start, time.Since(start), err)
+	if pm.cycleHook != nil {
+		defer pm.cycleHook()
+	}
+
+	if err != nil {
+		// Log a repeated error once, the backoff reports the failures since
+		if err.Error() != previous {
+			pm.logger.Printf("Failed to adjust power cap: %v", err)
+		}
+		pm.reportError(err, "adjust")
+	} else {
+		errreport.ResetRepeats(pm.reporter)
+	}
+	pm.updateDegraded()
+}
+
 // RefreshData manually refreshes market data
 func (pm *Manager) RefreshData(date time.Time) error {
-	return pm.dataStore.RefreshData(context.Background(), date)
+	ctx, cancel := pm.refreshContext()
+	defer cancel()
+	return pm.dataStore.RefreshData(ctx, date)
 }
 
-// scheduleDailyDataRefresh sets up automatic data refresh at midnight
-func (pm *Manager) scheduleDailyDataRefresh() *time.Ticker {
-	now := time.Now()
-	nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-	timeUntilMidnight := nextMidnight.Sub(now)
+// RecoverPanic reports a panic to the error reporter and re-panics.
+// It must be called directly via defer.
+func (pm *Manager) RecoverPanic() {
+	if r := recover(); r != nil {
+		pm.reporter.CapturePanic(r, pm.errorTags("panic"))
+		pm.reporter.Flush(5 * time.Second)
+		panic(r)
+	}
+}
 
-	pm.logger.Printf("Next data refresh scheduled in %v (at %v)",
-		timeUntilMidnight, nextMidnight.Format("2006-01-02 15:04:05"))
+// reportError sends an error to the error reporter with node context
+func (pm *Manager) reportError(err error, operation string) {
+	pm.reporter.CaptureError(err, pm.errorTags(operation))
+}
 
-	ticker := time.NewTicker(24 * time.Hour)
+// errorTags builds the context attached to error reports
+func (pm *Manager) errorTags(operation string) map[string]string {
+	var paths []string
+	for _, domain := range pm.raplMgr.GetDomains() {
+		for _, constraint := range domain.Constraints {
+			paths = append(paths, constraint.Path)
+		}
+	}
+
+	return map[string]string{
+		"operation":    operation,
+		"node":         pm.config.NodeName,
+		"provider":     pm.providerName(),
+		"domain_paths": strings.Join(paths, ","),
+	}
+}
 
-	go func() {
-		time.Sleep(timeUntilMidnight)
-		pm.logger.Println("Midnight reached - triggering data refresh...")
+// measurePower updates the measured power gauge from the RAPL energy counters,
+// averaged over the time since the previous cycle, and reads the wall power
+// from the external meter, if any
+func (pm *Manager) measurePower(ctx context.Context) {
+	pm.lastPackage = 0
+	if !pm.raplMgr.HasEnergyCounters() {
+		// Nothing to read on DTPM and cpufreq, the meter measures the node
+	} else if sample, err := pm.raplMgr.ReadEnergy(); err != nil {
+		pm.logger.Printf("⚠️  Unable to read RAPL energy counters: %v", err)
+	} else {
+		if pm.lastEnergy.Counters != nil {
+			power, err := pm.raplMgr.AveragePower(pm.lastEnergy, sample)
+			if err != nil {
+				pm.logger.Printf("⚠️  Unable to compute measured power: %v", err)
+			} else {
+				pm.metrics.SetGauge("measured_power_uw", "Average package power measured since the previous cycle (µW)", float64(power), nil)
+				pm.logger.Printf("🔌 Measured power: %d µW (%.1f W)", power, float64(power)/1000000)
+				pm.lastPackage = power
+			}
+		}
+		pm.lastEnergy = sample
+	}
 
-		today := time.Now()
-		if err := pm.dataStore.RefreshData(context.Background(), today); err != nil {
-			pm.logger.Printf("Failed to refresh data at midnight: %v", err)
+	pm.lastWall = 0
+	if pm.wallMeter != nil {
+		power, err := pm.wallMeter.ReadPower(ctx)
+		if err != nil {
+			pm.logger.Printf("⚠️  Unable to read wall power from %s: %v", pm.wallMeter.Name(), err)
 		} else {
-			pm.logger.Println("Midnight data refresh completed successfully")
+			pm.metrics.SetGauge("wall_power_uw", "Node power measured at the wall by the external meter (µW)", float64(power), nil)
+			pm.logger.Printf("🔌 Wall power: %d µW (%.1f W)", power, float64(power)/1000000)
+			pm.lastWall = power
 		}
-	}()
+	}
 
-	return ticker
+	// Demand-response compliance is measured at the wall when possible
+	switch {
+	case pm.lastWall > 0:
+		pm.recordDemandSample(pm.lastWall)
+	case pm.lastPackage > 0:
+		pm.recordDemandSample(pm.lastPackage)
+	}
+}
+
+// platformOverhead returns the power drawn outside the RAPL domains (wall
+// minus package power) when the ClosedLoop feature corrects the cap for it,
+// 0 otherwise
+func (pm *Manager) platformOverhead() int64 {
+	if !pm.config.Features.Enabled(features.ClosedLoop) || pm.lastWall <= 0 || pm.lastPackage <= 0 {
+		return 0
+	}
+	if overhead := pm.lastWall - pm.lastPackage; overhead > 0 {
+		return overhead
+	}
+	return 0
+}
+
+// recordAppliedCap updates cap metrics and emits an event when the cap changes
+func (pm *Manager) recordAppliedCap(period string, sourcePower, maxPower, pmax int64) {
+	pm.metrics.SetGauge("applied_cap_uw", "Power limit currently applied to RAPL domains (µW)", float64(pmax), nil)
+	pm.metrics.SetGauge("source_power_uw", "Power computed from market data before clamping (µW)", float64(sourcePower), nil)
+	pm.metrics.SetGauge("hardware_max_uw", "Hardware maximum power of the node (µW)", float64(maxPower), nil)
+	pm.metrics.SetGauge("min_power_uw", "Configured minimum power limit (µW)", float64(pm.config.RaplLimit), nil)
+	if pm.config.RaplMaxPower.IsSet() {
+		pm.metrics.SetGauge("admin_max_uw", "Administrative power ceiling (µW)", float64(pm.config.RaplMaxPower.Resolve(maxPower)), nil)
+	}
+
+	if point, ok := pm.dataStore.CurrentPoint(period); ok {
+		pm.metrics.SetGauge("market_volume_mwh", "Market volume of the current period (MWh)", point.Volume, nil)
+		pm.metrics.SetGauge("market_price", "Market price of the current period ("+datastore.PriceUnit(pm.config.Currency)+")", point.Price, nil)
+	}
+
+	if pmax == pm.lastApplied {
+		return
+	}
+
+	previous := pm.lastApplied
+	pm.lastApplied = pmax
+	pm.metrics.AddCounter("cap_changes_total", "Number of times the applied power limit changed", 1, nil)
+	if pmax < previous {
+		pm.metrics.AddCounter("throttle_events_total", "Number of times the applied cap was lowered", 1, nil)
+	}
+
+	if pm.events != nil {
+		pm.events.Event("Power cap changed",
+			fmt.Sprintf("Node %s: power cap changed from %.1f W to %.1f W (period %s)",
+				pm.config.NodeName, float64(previous)/1000000, float64(pmax)/1000000, period),
+			map[string]string{"period": period})
+	}
 }
 
 // Helper methods
 
-func (pm *Manager) getNode() (*v1.Node, error) {
-	return pm.clientset.CoreV1().Nodes().Get(pm.ctx, pm.config.NodeName, metav1.GetOptions{})
+// annotation returns the full key of a node annotation
+func (pm *Manager) annotation(name string) string {
+	return pm.config.AnnotationPrefix + name
+}
+
+// cycleContext bounds an adjustment cycle or a node update by CYCLE_TIMEOUT
+func (pm *Manager) cycleContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(pm.ctx, pm.config.CycleTimeout)
+}
+
+// refreshContext bounds a market data refresh by REFRESH_TIMEOUT
+func (pm *Manager) refreshContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(pm.ctx, pm.config.RefreshTimeout)
+}
+
+func (pm *Manager) getNode(ctx context.Context) (*v1.Node, error) {
+	return pm.nodes.GetNode(ctx, pm.config.NodeName)
 }
 
-func (pm *Manager) updateNode(node *v1.Node) error {
-	_, err := pm.clientset.CoreV1().Nodes().Update(pm.ctx, node, metav1.UpdateOptions{})
-	return err
+func (pm *Manager) updateNode(ctx context.Context, node *v1.Node) error {
+	return pm.nodes.UpdateNode(ctx, node)
 }
 
 func (pm *Manager) isNodeInitialized(node *v1.Node) bool {
 	if node.Annotations == nil {
 		return false
 	}
-	_, exists := node.Annotations[InitializationAnnotation]
+	_, exists := node.Annotations[pm.config.InitAnnotation]
 	return exists
 }
 
-func (pm *Manager) markNodeAsInitialized(node *v1.Node) error {
+func (pm *Manager) markNodeAsInitialized(ctx context.Context, node *v1.Node) error {
 	if node.Annotations == nil {
 		node.Annotations = make(map[string]string)
 	}
-	node.Annotations[InitializationAnnotation] = "kcas-power-manager"
-	return pm.updateNode(node)
+	node.Annotations[pm.config.InitAnnotation] = "kcas-power-manager"
+	return pm.updateNode(ctx, node)
 }
 
 func (pm *Manager) getMaxPowerValue(node *v1.Node) (int64, error) {
@@ -355,7 +1041,7 @@ func (pm *Manager) getMaxPowerValue(node *v1.Node) (int64, error) {
 		return 0, errors.New("node has no annotations")
 	}
 
-	annotation := "rapl/max_power_uw"
+	annotation := pm.annotation(AnnotationMaxPower)
 	value, ok := node.Annotations[annotation]
 	if !ok {
 		return 0, fmt.Errorf("max power annotation not found: %s", annotation)
@@ -369,36 +1055,11 @@ func (pm *Manager) getMaxPowerValue(node *v1.Node) (int64, error) {
 	return maxPower, nil
 }
 
-func (pm *Manager) applyPowerLimits(node *v1.Node, pmax int64) error {
-	// Update node annotations with detailed power information
-	if node.Annotations == nil {
-		node.Annotations = make(map[string]string)
-	}
-
-	// Core power information
-	node.Annotations["rapl/pmax"] = strconv.FormatInt(pmax, 10)
-	node.Annotations["rapl/last-update"] = time.Now().Format(time.RFC3339)
-	node.Annotations["rapl/provider"] = pm.config.DataProvider
-
-	// Get current market data for additional context
-	data := pm.dataStore.GetCurrentData()
-	if len(data) > 0 {
-		currentTime := time.Now()
-		currentPeriod := pm.calculator.GetCurrentPeriod(currentTime)
-
-		// Find current period data
-		for _, point := range data {
-			if point.Period == currentPeriod {
-				node.Annotations["rapl/market-period"] = currentPeriod
-				node.Annotations["rapl/market-volume"] = fmt.Sprintf("%.1f", point.Volume)
-				node.Annotations["rapl/market-price"] = fmt.Sprintf("%.2f", point.Price)
-				break
-			}
-		}
-	}
+func (pm *Manager) applyPowerLimits(ctx context.Context, node *v1.Node, pmax int64) error {
+	pm.setAnnotations(node, pmax)
 
 	// Apply this limit to all power_limit_uw files in all domains
-	if errs := pm.raplMgr.ApplyPowerLimits(pmax); len(errs) > 0 {
+	if errs := pm.writeLimits(ctx, pmax); len(errs) > 0 {
 		var errStrs []string
 		for _, err := range errs {
 			errStrs = append(errStrs, err.Error())
@@ -406,10 +1067,39 @@ func (pm *Manager) applyPowerLimits(node *v1.Node, pmax int64) error {
 		pm.logger.Printf("Errors applying power limits: %s", strings.Join(errStrs, "; "))
 	}
 
-	return pm.updateNode(node)
+	return pm.updateNode(ctx, node)
+}
+
+// setAnnotations updates the node annotations with the applied cap and the
+// market data, provider and cost of the cycle
+func (pm *Manager) setAnnotations(node *v1.Node, pmax int64) {
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+
+	// Core power information
+	node.Annotations[pm.annotation(AnnotationPmax)] = strconv.FormatInt(pmax, 10)
+	node.Annotations[pm.annotation(AnnotationLastUpdate)] = time.Now().Format(time.RFC3339)
+	node.Annotations[pm.annotation(AnnotationProvider)] = pm.providerName()
+	node.Annotations[pm.annotation(AnnotationConfigHash)] = pm.configHash
+	node.Annotations[pm.annotation(AnnotationState)] = StateRunning
+	node.Annotations[pm.annotation(AnnotationVersion)] = version.Get().Version
+	stale, _ := pm.dataStale(pm.now())
+	node.Annotations[pm.annotation(AnnotationDataStale)] = strconv.FormatBool(stale)
+	_, dailyCost := pm.DailyCost()
+	node.Annotations[pm.annotation(AnnotationDailyCost)] = strconv.FormatFloat(dailyCost, 'f', 4, 64)
+
+	// Current market data for additional context
+	currentPeriod := pm.calculator.GetCurrentPeriod(pm.now())
+	if point, ok := pm.dataStore.CurrentPoint(currentPeriod); ok {
+		node.Annotations[pm.annotation(AnnotationMarketPeriod)] = currentPeriod
+		node.Annotations[pm.annotation(AnnotationMarketVolume)] = fmt.Sprintf("%.1f", point.Volume)
+		node.Annotations[pm.annotation(AnnotationMarketPrice)] = fmt.Sprintf("%.2f", point.Price)
+	}
 }
 
-func createKubernetesClient() (*kubernetes.Clientset, error) {
+// NewKubernetesClient creates a clientset from the in-cluster configuration
+func NewKubernetesClient() (*kubernetes.Clientset, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
@@ -0,0 +1,216 @@
+package power
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/nomad"
+)
+
+// Orchestrators holding the node state
+const (
+	OrchestratorKubernetes = "kubernetes"
+	OrchestratorNomad      = "nomad"
+	OrchestratorStandalone = "standalone"
+)
+
+// NodeClient reads and updates the node whose annotations hold the state of
+// the manager. Orchestrators without annotations map them to their own node
+// metadata.
+type NodeClient interface {
+	GetNode(ctx context.Context, name string) (*v1.Node, error)
+	UpdateNode(ctx context.Context, node *v1.Node) error
+}
+
+// NewNodeClient creates the node client of the configured orchestrator
+func NewNodeClient(cfg *config.Config) (NodeClient, error) {
+	switch cfg.Orchestrator {
+	case OrchestratorKubernetes:
+		clientset, err := NewKubernetesClient()
+		if err != nil {
+			return nil, fmt.Errorf("%w (set %s=%s outside a cluster)", err, config.EnvOrchestrator, OrchestratorStandalone)
+		}
+		return &kubernetesNodes{clientset: clientset}, nil
+	case OrchestratorNomad:
+		client, err := nomad.NewClient(cfg.NomadAddr, cfg.NomadToken, nomad.TLSOptions{
+			CACert:     cfg.NomadCACert,
+			ClientCert: cfg.NomadClientCert,
+			ClientKey:  cfg.NomadClientKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &nomadNodes{client: client}, nil
+	case OrchestratorStandalone:
+		return &fileNodes{path: cfg.StateFilePath()}, nil
+	default:
+		return nil, fmt.Errorf("unknown orchestrator %q", cfg.Orchestrator)
+	}
+}
+
+// kubernetesNodes reads and updates Kubernetes node objects
+type kubernetesNodes struct {
+	clientset kubernetes.Interface
+}
+
+func (k *kubernetesNodes) GetNode(ctx context.Context, name string) (*v1.Node, error) {
+	return k.clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+}
+
+func (k *kubernetesNodes) UpdateNode(ctx context.Context, node *v1.Node) error {
+	_, err := k.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+// nomadNodes maps node annotations to the dynamic metadata of a Nomad node
+type nomadNodes struct {
+	client *nomad.Client
+
+	mu   sync.Mutex
+	id   string            // Node ID, resolved on first use
+	meta map[string]string // Metadata at the last read, to send only changes
+}
+
+func (n *nomadNodes) GetNode(ctx context.Context, name string) (*v1.Node, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.id == "" {
+		id, err := n.client.NodeID(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		n.id = id
+	}
+
+	meta, err := n.client.Metadata(ctx, n.id)
+	if err != nil {
+		return nil, err
+	}
+	n.meta = meta
+
+	annotations := make(map[string]string, len(meta))
+	for key, value := range meta {
+		annotations[key] = value
+	}
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}, nil
+}
+
+// UpdateNode sets the annotations that changed since the last read and
+// removes the deleted ones
+func (n *nomadNodes) UpdateNode(ctx context.Context, node *v1.Node) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.id == "" {
+		return fmt.Errorf("nomad node %q was not read before being updated", node.Name)
+	}
+
+	changes := make(map[string]*string)
+	for key, value := range node.Annotations {
+		if previous, ok := n.meta[key]; !ok || previous != value {
+			value := value
+			changes[key] = &value
+		}
+	}
+	for key := range n.meta {
+		if _, ok := node.Annotations[key]; !ok {
+			changes[key] = nil
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := n.client.ApplyMetadata(ctx, n.id, changes); err != nil {
+		return err
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(n.meta, key)
+		} else {
+			n.meta[key] = *value
+		}
+	}
+	return nil
+}
+
+// fileNodes keeps the annotations of a standalone node in a local JSON file,
+// for servers outside any cluster
+type fileNodes struct {
+	path string
+	mu   sync.Mutex
+}
+
+// nodeState is the content of the state file
+type nodeState struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (f *fileNodes) GetNode(ctx context.Context, name string) (*v1.Node, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := nodeState{Annotations: make(map[string]string)}
+	data, err := os.ReadFile(f.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// A new node, initialized on the first update
+	case err != nil:
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("invalid state file %s: %w", f.path, err)
+		}
+		if state.Annotations == nil {
+			state.Annotations = make(map[string]string)
+		}
+	}
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: state.Annotations}}, nil
+}
+
+// UpdateNode replaces the state file
+func (f *fileNodes) UpdateNode(ctx context.Context, node *v1.Node) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(nodeState{Name: node.Name, Annotations: node.Annotations}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(f.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces a file through a rename, so that a crash never
+// leaves it half written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".powercap-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
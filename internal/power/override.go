@@ -0,0 +1,214 @@
+package power
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// overrideAnnotationSetBy is recorded as the author of annotation overrides
+// that do not name one
+const overrideAnnotationSetBy = "annotation"
+
+// Override pins the power cap to a fixed value, or disables capping, until it
+// expires
+type Override struct {
+	PowerUW   int64     `json:"power_uw,omitempty"`
+	Disable   bool      `json:"disable,omitempty"` // Apply the hardware maximum instead of PowerUW
+	Reason    string    `json:"reason,omitempty"`
+	SetBy     string    `json:"set_by"`
+	SetAt     time.Time `json:"set_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// describe returns a short description of the pinned limit
+func (o Override) describe() string {
+	if o.Disable {
+		return "capping disabled"
+	}
+	return fmt.Sprintf("%d µW (%.1f W)", o.PowerUW, float64(o.PowerUW)/1000000)
+}
+
+// SetOverride pins the cap until the override expires and triggers an
+// immediate adjustment
+func (pm *Manager) SetOverride(override Override) error {
+	if err := pm.setOverride(override, false); err != nil {
+		return err
+	}
+	pm.TriggerAdjust()
+	return nil
+}
+
+// setOverride validates and installs an override, arming an adjustment at its
+// expiry so the market-based cap is restored on time
+func (pm *Manager) setOverride(override Override, fromAnnotation bool) error {
+	if !override.Disable && override.PowerUW <= 0 {
+		return fmt.Errorf("override power must be positive, got %d µW", override.PowerUW)
+	}
+	if !override.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("override expiry %s is in the past", override.ExpiresAt.Format(time.RFC3339))
+	}
+	if override.SetAt.IsZero() {
+		override.SetAt = time.Now()
+	}
+
+	pm.mu.Lock()
+	if override.SetBy != demandResponseSetBy {
+		pm.supersedeDemandEvents(override.SetAt)
+	}
+	pm.override = &override
+	pm.overrideFromAnnotation = fromAnnotation
+	if pm.overrideTimer != nil {
+		pm.overrideTimer.Stop()
+	}
+	pm.overrideTimer = time.AfterFunc(time.Until(override.ExpiresAt), pm.TriggerAdjust)
+	pm.mu.Unlock()
+
+	pm.logger.Printf("🔧 Override set by %s: %s until %s (%s)", override.SetBy,
+		override.describe(), override.ExpiresAt.Format(time.RFC3339), override.Reason)
+	pm.overrideEvent("Power cap override set", fmt.Sprintf("Node %s: override set by %s: %s until %s (%s)",
+		pm.config.NodeName, override.SetBy, override.describe(), override.ExpiresAt.Format(time.RFC3339), override.Reason))
+	return nil
+}
+
+// ClearOverride removes the active override, if any, and triggers an
+// immediate adjustment
+func (pm *Manager) ClearOverride(clearedBy string) (Override, bool) {
+	previous, ok := pm.clearOverride()
+	if !ok {
+		return Override{}, false
+	}
+	pm.logger.Printf("🔧 Override of %s cleared by %s", previous.SetBy, clearedBy)
+	pm.overrideEvent("Power cap override cleared", fmt.Sprintf("Node %s: override of %s cleared by %s",
+		pm.config.NodeName, previous.SetBy, clearedBy))
+	pm.TriggerAdjust()
+	return previous, true
+}
+
+// clearOverride removes the override and reports whether it was still active
+func (pm *Manager) clearOverride() (Override, bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	previous := pm.override
+	pm.override = nil
+	pm.overrideFromAnnotation = false
+	if pm.overrideTimer != nil {
+		pm.overrideTimer.Stop()
+		pm.overrideTimer = nil
+	}
+	pm.supersedeDemandEvents(time.Now())
+
+	if previous == nil || !previous.ExpiresAt.After(time.Now()) {
+		return Override{}, false
+	}
+	return *previous, true
+}
+
+// ActiveOverride returns the override in effect, if any
+func (pm *Manager) ActiveOverride() (Override, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.override == nil || !pm.override.ExpiresAt.After(time.Now()) {
+		return Override{}, false
+	}
+	return *pm.override, true
+}
+
+// expireOverride drops an override whose expiry has passed, removing the node
+// annotation it came from, so the market-based cap is restored
+func (pm *Manager) expireOverride(node *v1.Node) {
+	pm.mu.Lock()
+	expired := pm.override
+	fromAnnotation := pm.overrideFromAnnotation
+	if expired == nil || expired.ExpiresAt.After(time.Now()) {
+		pm.mu.Unlock()
+		return
+	}
+	pm.override = nil
+	pm.overrideFromAnnotation = false
+	pm.overrideTimer = nil
+	pm.mu.Unlock()
+
+	if fromAnnotation {
+		delete(node.Annotations, pm.annotation(AnnotationOverride))
+		pm.annotationOverride = ""
+	}
+
+	pm.logger.Printf("⏱️  Override by %s expired at %s, reverting to the market-based cap",
+		expired.SetBy, expired.ExpiresAt.Format(time.RFC3339))
+	pm.overrideEvent("Power cap override expired", fmt.Sprintf("Node %s: override set by %s expired at %s",
+		pm.config.NodeName, expired.SetBy, expired.ExpiresAt.Format(time.RFC3339)))
+}
+
+// syncAnnotationOverride applies the override declared in the node annotation
+// when it changes, and clears it when the annotation is removed
+func (pm *Manager) syncAnnotationOverride(node *v1.Node) {
+	raw := node.Annotations[pm.annotation(AnnotationOverride)]
+	if raw == pm.annotationOverride {
+		return
+	}
+	pm.annotationOverride = raw
+
+	if raw == "" {
+		pm.mu.RLock()
+		fromAnnotation := pm.overrideFromAnnotation
+		pm.mu.RUnlock()
+		if !fromAnnotation {
+			return
+		}
+		if previous, ok := pm.clearOverride(); ok {
+			pm.logger.Printf("🔧 Override of %s cleared: annotation %s removed", previous.SetBy, pm.annotation(AnnotationOverride))
+			pm.overrideEvent("Power cap override cleared", fmt.Sprintf("Node %s: override of %s cleared by removing annotation %s",
+				pm.config.NodeName, previous.SetBy, pm.annotation(AnnotationOverride)))
+		}
+		return
+	}
+
+	var override Override
+	if err := json.Unmarshal([]byte(raw), &override); err != nil {
+		pm.logger.Printf("⚠️  Ignoring invalid annotation %s: %v", pm.annotation(AnnotationOverride), err)
+		return
+	}
+	if override.SetBy == "" {
+		override.SetBy = overrideAnnotationSetBy
+	}
+	override.SetAt = time.Time{}
+	if err := pm.setOverride(override, true); err != nil {
+		pm.logger.Printf("⚠️  Ignoring annotation %s: %v", pm.annotation(AnnotationOverride), err)
+	}
+}
+
+// overrideEvent publishes an override change to the event sink, if any
+func (pm *Manager) overrideEvent(title, text string) {
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+}
+
+// overridePower returns the limit pinned by an override, within the
+// hardware maximum and the administrative ceiling
+func (pm *Manager) overridePower(override Override, maxPower int64) (int64, string) {
+	pmax := override.PowerUW
+	if override.Disable || pmax > maxPower {
+		pmax = maxPower
+	}
+	if pm.config.RaplMaxPower.IsSet() {
+		if adminMax := pm.config.RaplMaxPower.Resolve(maxPower); pmax > adminMax {
+			return adminMax, ClampAdminMax
+		}
+	}
+	return pmax, ClampOverride
+}
+
+// TriggerAdjust requests an adjustment cycle without waiting for the next tick
+func (pm *Manager) TriggerAdjust() {
+	select {
+	case pm.adjustNow <- struct{}{}:
+	default:
+		// An adjustment is already pending
+	}
+}
@@ -0,0 +1,83 @@
+package power
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// peakShavingStep is the change of the shaving factor that triggers an
+// adjustment without waiting for the next cycle
+const peakShavingStep = 0.05
+
+// SetSiteDemand records the power drawn by the whole site. Between
+// PEAK_SHAVING_START × PEAK_THRESHOLD and PEAK_THRESHOLD the cap is lowered
+// proportionally from the market-based limit down to the minimum power, so
+// every node sheds load together as the building nears its demand-charge
+// threshold.
+func (pm *Manager) SetSiteDemand(power int64) {
+	factor := pm.peakShavingFactor(power)
+
+	pm.mu.Lock()
+	previous := pm.peakShaving
+	if time.Since(pm.siteDemandAt) > pm.siteDemandTTL() {
+		previous = 0
+	}
+	pm.siteDemand, pm.siteDemandAt, pm.peakShaving = power, time.Now(), factor
+	pm.mu.Unlock()
+
+	pm.metrics.SetGauge("site_demand_uw", "Power drawn by the whole site (µW)", float64(power), nil)
+	pm.metrics.SetGauge("peak_shaving_factor", "Share of the cap above the minimum power shed for peak shaving", factor, nil)
+
+	switch {
+	case previous == 0 && factor > 0:
+		text := fmt.Sprintf("Node %s: site demand %.1f kW near the %.1f kW threshold, shaving %.0f%% of the cap",
+			pm.config.NodeName, float64(power)/1e9, float64(pm.config.PeakThreshold)/1e9, factor*100)
+		pm.logger.Printf("🏢 %s", text)
+		if pm.events != nil {
+			pm.events.Event("Peak shaving started", text, map[string]string{"node": pm.config.NodeName})
+		}
+	case previous > 0 && factor == 0:
+		text := fmt.Sprintf("Node %s: site demand %.1f kW back below the shaving level, restoring the market-based cap",
+			pm.config.NodeName, float64(power)/1e9)
+		pm.logger.Printf("🏢 %s", text)
+		if pm.events != nil {
+			pm.events.Event("Peak shaving ended", text, map[string]string{"node": pm.config.NodeName})
+		}
+	case math.Abs(factor-previous) < peakShavingStep:
+		return
+	}
+	pm.TriggerAdjust()
+}
+
+// peakShavingFactor returns the share of the cap above the minimum power to
+// shed for a site demand: 0 below the shaving level, 1 at the threshold
+func (pm *Manager) peakShavingFactor(demand int64) float64 {
+	threshold := float64(pm.config.PeakThreshold)
+	start := threshold * pm.config.PeakShavingStart
+	switch {
+	case threshold <= 0 || float64(demand) <= start:
+		return 0
+	case float64(demand) >= threshold:
+		return 1
+	default:
+		return (float64(demand) - start) / (threshold - start)
+	}
+}
+
+// PeakShaving returns the current shaving factor and the site demand it was
+// computed from. Readings older than three meter intervals are ignored, so a
+// meter gone silent does not keep the caps down.
+func (pm *Manager) PeakShaving() (factor float64, demand int64) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	if pm.siteDemandAt.IsZero() || time.Since(pm.siteDemandAt) > pm.siteDemandTTL() {
+		return 0, 0
+	}
+	return pm.peakShaving, pm.siteDemand
+}
+
+// siteDemandTTL is how long a site meter reading stays valid
+func (pm *Manager) siteDemandTTL() time.Duration {
+	return 3 * pm.config.SiteMeterInterval
+}
@@ -0,0 +1,185 @@
+package power
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// PlannedCap is the cap computed for one market period of the current day
+type PlannedCap struct {
+	Period      string  `json:"period"`
+	Volume      float64 `json:"volume_mwh"`
+	Price       float64 `json:"price_eur_mwh"`
+	SourcePower int64   `json:"source_power_uw"`
+	CapUW       int64   `json:"cap_uw"`
+	Clamp       string  `json:"clamp"`
+}
+
+// dayPlan is the market-based cap of every period of the loaded day, computed
+// once per data refresh, hardware maximum and market closure so that each
+// cycle only looks up its period
+type dayPlan struct {
+	dataUpdatedAt time.Time // Load time of the data planned
+	hardwareMax   int64
+	closed        bool  // The closed day cap applies
+	adminMax      int64 // Resolved administrative ceiling, 0 if none
+	caps          []PlannedCap
+	index         map[string]int // Position of each period in caps
+
+	day      string                               // Market day the plan was computed on
+	previous map[string]datastore.MarketDataPoint // Data of the previous refresh of the day, by period
+}
+
+// lookup returns the planned cap of a period
+func (p *dayPlan) lookup(period string) (PlannedCap, bool) {
+	if i, ok := p.index[period]; ok {
+		return p.caps[i], true
+	}
+	return PlannedCap{}, false
+}
+
+// plan returns the plan of the loaded day for a hardware maximum, computing
+// it again only when the data, the hardware maximum or the market closure
+// changed since the last one
+func (pm *Manager) plan(hardwareMax int64) *dayPlan {
+	updatedAt := pm.dataStore.GetLastUpdate()
+	closed := pm.closedDayCap(pm.now())
+
+	pm.planMu.Lock()
+	defer pm.planMu.Unlock()
+
+	if p := pm.dayPlan; p != nil && p.dataUpdatedAt.Equal(updatedAt) && p.hardwareMax == hardwareMax && p.closed == closed {
+		return p
+	}
+	p := pm.computePlan(hardwareMax, closed)
+	p.dataUpdatedAt = updatedAt
+	p.day = pm.now().Format("2006-01-02")
+	if old := pm.dayPlan; old != nil && old.day == p.day {
+		p.previous = old.previous
+		if !old.dataUpdatedAt.Equal(updatedAt) {
+			p.previous = old.points()
+		}
+	}
+	pm.dayPlan = p
+	pm.logger.Printf("🗓️  Planned the caps of %d periods with a hardware maximum of %d µW (%.1f W)",
+		len(p.caps), hardwareMax, float64(hardwareMax)/1000000)
+	return p
+}
+
+// points returns the data points of the planned periods with a volume,
+// completed by those of the previous refresh
+func (p *dayPlan) points() map[string]datastore.MarketDataPoint {
+	points := make(map[string]datastore.MarketDataPoint, len(p.caps))
+	for period, point := range p.previous {
+		points[period] = point
+	}
+	for _, planned := range p.caps {
+		if planned.Volume != 0 {
+			points[planned.Period] = datastore.MarketDataPoint{Period: planned.Period, Volume: planned.Volume, Price: planned.Price}
+		}
+	}
+	return points
+}
+
+// replan computes the plan after the data changed, once the hardware maximum
+// is known, so that the next cycle finds it ready
+func (pm *Manager) replan() {
+	if decision, ok := pm.LastDecision(); ok && decision.HardwareMax > 0 {
+		pm.plan(decision.HardwareMax)
+	}
+}
+
+// computePlan computes the cap of every period of the loaded day: the rule
+// of three clamped by limitPower, then the closed day cap or the floor of
+// price spikes
+func (pm *Manager) computePlan(hardwareMax int64, closed bool) *dayPlan {
+	data := pm.dataStore.GetCurrentData()
+	referenceVolume := pm.referenceVolume()
+	p := &dayPlan{hardwareMax: hardwareMax, closed: closed, index: make(map[string]int, len(data))}
+	if pm.config.RaplMaxPower.IsSet() {
+		p.adminMax = pm.config.RaplMaxPower.Resolve(hardwareMax)
+	}
+	if referenceVolume <= 0 {
+		return p
+	}
+
+	median := datastore.MedianPrice(data)
+	p.caps = make([]PlannedCap, 0, len(data))
+	for _, point := range data {
+		if _, exists := p.index[point.Period]; exists {
+			continue
+		}
+		p.index[point.Period] = len(p.caps)
+		p.caps = append(p.caps, pm.plannedCap(point, hardwareMax, referenceVolume, median, closed))
+	}
+	return p
+}
+
+// plannedCap computes the cap of one period from a reference volume
+func (pm *Manager) plannedCap(point datastore.MarketDataPoint, hardwareMax int64, referenceVolume, median float64, closed bool) PlannedCap {
+	// Rule of three, as in the adjustment cycle
+	source := pm.calculator.PowerForVolume(float64(hardwareMax), referenceVolume, point.Volume)
+	if source == 0 {
+		source = pm.config.RaplLimit
+	}
+	capPower, clamp, _ := pm.limitPower(source, hardwareMax)
+	if closed {
+		capPower, clamp = pm.closedDayPower(hardwareMax), ClampClosedDay
+	} else if capPower > pm.config.RaplLimit &&
+		datastore.IsPriceSpike(point.Price, median, pm.config.PriceSpikeThreshold, pm.config.PriceSpikeFactor) {
+		capPower, clamp = pm.config.RaplLimit, ClampPriceSpike
+	}
+	return PlannedCap{
+		Period:      point.Period,
+		Volume:      point.Volume,
+		Price:       point.Price,
+		SourcePower: source,
+		CapUW:       capPower,
+		Clamp:       clamp,
+	}
+}
+
+// Schedule returns the cap of every period of the loaded day with the
+// hardware maximum of the last decision. Overrides are not applied.
+func (pm *Manager) Schedule() ([]PlannedCap, error) {
+	decision, ok := pm.LastDecision()
+	if !ok || decision.HardwareMax <= 0 {
+		return nil, errors.New("the hardware maximum is not known until the first cycle completes")
+	}
+
+	if len(pm.dataStore.GetCurrentData()) == 0 || pm.referenceVolume() <= 0 {
+		return nil, errors.New("no market data loaded")
+	}
+	return append([]PlannedCap(nil), pm.plan(decision.HardwareMax).caps...), nil
+}
+
+// MarketData returns the market data of the loaded day
+func (pm *Manager) MarketData() []datastore.MarketDataPoint {
+	data := pm.dataStore.GetCurrentData()
+	return append([]datastore.MarketDataPoint(nil), data...)
+}
+
+// Currency returns the currency of the market prices
+func (pm *Manager) Currency() string {
+	return pm.config.Currency
+}
+
+// TriggerRefresh fetches today's market data again, replacing the stored
+// file, then triggers an adjustment with the new data
+func (pm *Manager) TriggerRefresh() error {
+	pm.refreshMu.Lock()
+	defer pm.refreshMu.Unlock()
+
+	ctx, cancel := pm.refreshContext()
+	defer cancel()
+	if err := pm.refreshToday(ctx, pm.now()); err != nil {
+		return fmt.Errorf("failed to refresh data: %w", err)
+	}
+	pm.refreshPending = false
+	pm.replan()
+	pm.TriggerAdjust()
+	return nil
+}
@@ -0,0 +1,93 @@
+package power
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// hardwareMax returns the hardware maximum power used as the calculation
+// reference. With PMAX_SOURCE=live it is read from RAPL every PMAX_REFRESH
+// and the max power annotation is corrected when it has drifted; otherwise
+// the annotation written at initialization is used, checked against RAPL
+// every PMAX_RECHECK.
+func (pm *Manager) hardwareMax(node *v1.Node) (int64, error) {
+	if pm.config.PmaxSource != "live" {
+		return pm.annotatedMax(node)
+	}
+
+	if pm.liveMaxPower == 0 || time.Since(pm.liveMaxPowerAt) >= pm.config.PmaxRefresh {
+		maxPower, err := pm.raplMgr.ReadMaxPower()
+		if err != nil {
+			if pm.liveMaxPower == 0 {
+				pm.logger.Printf("⚠️  Failed to read hardware max power from RAPL, using annotation: %v", err)
+				return pm.getMaxPowerValue(node)
+			}
+			pm.logger.Printf("⚠️  Failed to read hardware max power from RAPL, keeping %d µW: %v", pm.liveMaxPower, err)
+			return pm.liveMaxPower, nil
+		}
+		if pm.liveMaxPower != 0 && maxPower != pm.liveMaxPower {
+			pm.hardwareMaxChanged(pm.liveMaxPower, maxPower)
+		}
+		pm.liveMaxPower = maxPower
+		pm.liveMaxPowerAt = time.Now()
+	}
+
+	// Keep the annotation in line with the hardware for other consumers
+	pm.setMaxPowerAnnotation(node, pm.liveMaxPower)
+	return pm.liveMaxPower, nil
+}
+
+// annotatedMax returns the maximum of the annotation. Every PMAX_RECHECK,
+// starting with the first cycle, it is compared with RAPL: a BIOS or
+// firmware update may have changed it since the node was initialized, and
+// the annotation is then rewritten with the new value.
+func (pm *Manager) annotatedMax(node *v1.Node) (int64, error) {
+	maxPower, err := pm.getMaxPowerValue(node)
+	if err != nil || pm.config.PmaxRecheck <= 0 || time.Since(pm.pmaxCheckedAt) < pm.config.PmaxRecheck {
+		return maxPower, err
+	}
+	pm.pmaxCheckedAt = time.Now()
+
+	current, err := pm.raplMgr.ReadMaxPower()
+	if err != nil {
+		pm.logger.Printf("⚠️  Failed to check the hardware max power against RAPL, keeping %d µW: %v", maxPower, err)
+		return maxPower, nil
+	}
+	if current != maxPower {
+		pm.hardwareMaxChanged(maxPower, current)
+		pm.setMaxPowerAnnotation(node, current)
+	}
+	return current, nil
+}
+
+// setMaxPowerAnnotation updates the max power annotation of the node, written
+// with the rest of the cycle's annotations
+func (pm *Manager) setMaxPowerAnnotation(node *v1.Node, maxPower int64) {
+	annotation := pm.annotation(AnnotationMaxPower)
+	value := strconv.FormatInt(maxPower, 10)
+	if node.Annotations[annotation] == value {
+		return
+	}
+	if current, exists := node.Annotations[annotation]; exists {
+		pm.logger.Printf("⚠️  Annotation %s is stale (%s µW), updating to %s µW", annotation, current, value)
+	}
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[annotation] = value
+}
+
+// hardwareMaxChanged reports a change of the hardware maximum, from which
+// every later cap is computed
+func (pm *Manager) hardwareMaxChanged(previous, current int64) {
+	text := fmt.Sprintf("Node %s: hardware max power changed from %.1f W to %.1f W, recomputing the cap from the new value",
+		pm.config.NodeName, float64(previous)/1000000, float64(current)/1000000)
+	pm.logger.Printf("⚠️  %s", text)
+	pm.metrics.AddCounter("hardware_max_changes_total", "Number of times the hardware maximum power changed", 1, nil)
+	if pm.events != nil {
+		pm.events.Event("Hardware max power changed", text, map[string]string{"node": pm.config.NodeName})
+	}
+}
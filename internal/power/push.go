@@ -0,0 +1,61 @@
+package power
+
+import (
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// consumePushes applies the updates of a provider receiving data as it is
+// published, until the manager stops
+func (pm *Manager) consumePushes() {
+	provider, ok := pm.provider.(datastore.PushProvider)
+	if !ok {
+		return
+	}
+	updates, err := provider.Subscribe(pm.ctx)
+	if err != nil {
+		pm.logger.Printf("❌ Failed to subscribe to %s: %v, relying on the refresh schedule", pm.provider.GetName(), err)
+		pm.reportError(err, "subscribe")
+		return
+	}
+	if updates == nil {
+		return
+	}
+
+	pm.logger.Printf("📡 Subscribed to %s, applying data as it is published", pm.provider.GetName())
+	go func() {
+		for date := range updates {
+			pm.applyPush(date)
+		}
+	}()
+}
+
+// applyPush stores the data of a day updated by the provider, planning and
+// adjusting again when it is today's. Updates are ignored while a failover
+// provider stands in, the refresh retries switching back first.
+func (pm *Manager) applyPush(date time.Time) {
+	defer pm.RecoverPanic()
+
+	pm.refreshMu.Lock()
+	defer pm.refreshMu.Unlock()
+	if pm.usingFailover() {
+		return
+	}
+
+	ctx, cancel := pm.refreshContext()
+	defer cancel()
+	today := pm.now()
+	if err := pm.dataStore.ApplyPush(ctx, date, today); err != nil {
+		pm.logger.Printf("Failed to apply the data pushed for %s: %v", date.Format("2006-01-02"), err)
+		pm.reportError(err, "push")
+		return
+	}
+	pm.metrics.AddCounter("data_pushes_total", "Number of market data updates pushed by the provider", 1, nil)
+
+	if marketDay(date, pm.location).Equal(marketDay(today, pm.location)) {
+		pm.refreshPending = false
+		pm.replan()
+		pm.TriggerAdjust()
+	}
+}
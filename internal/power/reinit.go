@@ -0,0 +1,51 @@
+package power
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// lostState returns why the annotations written by InitializeNode are gone
+// from the node, or an empty string when they are intact
+func (pm *Manager) lostState(node *v1.Node) string {
+	if !pm.isNodeInitialized(node) {
+		return fmt.Sprintf("annotation %s missing", pm.config.InitAnnotation)
+	}
+	annotation := pm.annotation(AnnotationMaxPower)
+	value, ok := node.Annotations[annotation]
+	if !ok {
+		return fmt.Sprintf("annotation %s missing", annotation)
+	}
+	if maxPower, err := strconv.ParseInt(value, 10, 64); err != nil || maxPower <= 0 {
+		return fmt.Sprintf("annotation %s corrupted (%q)", annotation, value)
+	}
+	return ""
+}
+
+// restoreNodeState re-runs the initialization when the node lost its state,
+// as when the cloud provider recreates the node object. The annotations are
+// set on the node read by the cycle and written with the rest of them, so
+// that the cycle carries on rather than failing on the missing max power. It
+// returns why the state was restored, empty when it was intact.
+func (pm *Manager) restoreNodeState(node *v1.Node) (string, error) {
+	reason := pm.lostState(node)
+	if reason == "" {
+		return "", nil
+	}
+
+	pm.logger.Printf("🩹 Node '%s' lost its state (%s), initializing it again...", node.Name, reason)
+	maxPower, err := pm.initAnnotations(node)
+	if err != nil {
+		return reason, err
+	}
+	node.Annotations[pm.config.InitAnnotation] = "kcas-power-manager"
+
+	pm.metrics.AddCounter("node_reinitializations_total", "Number of times the node was initialized again after losing its annotations", 1, nil)
+	text := fmt.Sprintf("Node %s: %s, initialized again with max power %.1f W", pm.config.NodeName, reason, float64(maxPower)/1000000)
+	if pm.events != nil {
+		pm.events.Event("Node state restored", text, map[string]string{"node": pm.config.NodeName})
+	}
+	return reason, nil
+}
@@ -0,0 +1,137 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+// Baselines of the savings estimate (SAVINGS_BASELINE)
+const (
+	BaselineHardwareMax = "hardware_max" // The node would run at the hardware maximum
+	BaselineMeasured    = "measured"     // The node would draw what it last drew uncapped
+)
+
+// Savings is the energy, cost and CO2 saved by the applied caps against the
+// baseline over a span of time
+type Savings struct {
+	Since    time.Time `json:"since"`
+	EnergyWh float64   `json:"energy_wh"`
+	Cost     float64   `json:"cost"`             // In Currency, negative when the energy saved had a negative price
+	CO2Kg    float64   `json:"co2_kg,omitempty"` // With CARBON_INTENSITY
+}
+
+// add adds the savings of one interval
+func (s *Savings) add(energyWh, cost, co2Kg float64) {
+	s.EnergyWh += energyWh
+	s.Cost += cost
+	s.CO2Kg += co2Kg
+}
+
+// SavingsReport compares the applied caps with a counterfactual baseline, the
+// node running uncapped, over the current market day and since the start
+type SavingsReport struct {
+	Baseline   string  `json:"baseline"`          // hardware_max or measured
+	BaselineUW int64   `json:"baseline_power_uw"` // Power the node would have drawn uncapped in the last interval
+	Currency   string  `json:"currency"`
+	Today      Savings `json:"today"`
+	Total      Savings `json:"total"`
+}
+
+// Savings returns the savings estimated so far, false before the second
+// cycle
+func (pm *Manager) Savings() (SavingsReport, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.savings == nil {
+		return SavingsReport{}, false
+	}
+	return *pm.savings, true
+}
+
+// accountSavings adds the savings of the interval of the given length ending
+// at now, during which the cap of the previous decision applied and the node
+// drew the given power. The cost uses the price of the period of that
+// decision.
+func (pm *Manager) accountSavings(elapsed time.Duration, now time.Time, previous PowerDecision, drawn int64) {
+	baseline := previous.HardwareMax
+	if pm.config.SavingsBaseline == BaselineMeasured && pm.uncappedDraw > 0 {
+		baseline = pm.uncappedDraw
+	}
+	saved := baseline - drawn
+	if saved < 0 {
+		saved = 0
+	}
+
+	energyWh := float64(saved) / 1000000 * elapsed.Hours()
+	cost := energyWh / 1000000 * previous.Price
+	co2Kg := energyWh * pm.config.CarbonIntensity / 1000000
+
+	pm.mu.Lock()
+	report := pm.savings
+	day := marketDay(now, pm.location)
+	if report == nil {
+		since := now.Add(-elapsed)
+		report = &SavingsReport{
+			Baseline: pm.config.SavingsBaseline,
+			Currency: pm.config.Currency,
+			Today:    Savings{Since: since},
+			Total:    Savings{Since: since},
+		}
+		if day.After(since) {
+			report.Today.Since = day
+		}
+		pm.savings = report
+	}
+	var finished *Savings
+	if day.After(report.Today.Since) {
+		done := report.Today
+		finished = &done
+		report.Today = Savings{Since: day}
+	}
+	report.BaselineUW = baseline
+	report.Today.add(energyWh, cost, co2Kg)
+	report.Total.add(energyWh, cost, co2Kg)
+	today, total := report.Today, report.Total
+	pm.mu.Unlock()
+
+	if finished != nil {
+		pm.reportDailySavings(*finished)
+	}
+	pm.recordSavings(baseline, energyWh, co2Kg, today, total)
+}
+
+// recordSavings exports the savings of the interval, the day and since the
+// start
+func (pm *Manager) recordSavings(baseline int64, energyWh, co2Kg float64, today, total Savings) {
+	pm.metrics.SetGauge("baseline_power_uw", "Power the node would have drawn uncapped, the baseline of the savings (µW)", float64(baseline), nil)
+	pm.metrics.AddCounter("energy_saved_wh_total", "Energy saved by the applied caps against the baseline (Wh)", energyWh, nil)
+	pm.metrics.SetGauge("cost_saved", "Cost of the energy saved against the baseline since the start (in "+pm.config.Currency+")", total.Cost, nil)
+	pm.metrics.SetGauge("energy_saved_today_wh", "Energy saved against the baseline since the market midnight (Wh)", today.EnergyWh, nil)
+	pm.metrics.SetGauge("cost_saved_today", "Cost of the energy saved against the baseline since the market midnight (in "+pm.config.Currency+")", today.Cost, nil)
+	if pm.config.CarbonIntensity > 0 {
+		pm.metrics.AddCounter("co2_saved_kg_total", "CO2 emissions avoided against the baseline at CARBON_INTENSITY (kg)", co2Kg, nil)
+		pm.metrics.SetGauge("co2_saved_today_kg", "CO2 emissions avoided against the baseline since the market midnight (kg)", today.CO2Kg, nil)
+	}
+}
+
+// reportDailySavings logs the savings of a finished market day and sends
+// them as an event
+func (pm *Manager) reportDailySavings(day Savings) {
+	text := fmt.Sprintf("Node %s: the caps of %s saved %.2f kWh (%.2f %s) against the %s baseline",
+		pm.config.NodeName, day.Since.In(pm.location).Format("2006-01-02"), day.EnergyWh/1000,
+		day.Cost, pm.config.Currency, pm.config.SavingsBaseline)
+	if pm.config.CarbonIntensity > 0 {
+		text += fmt.Sprintf(", avoiding %.2f kg of CO2", day.CO2Kg)
+	}
+	pm.logger.Printf("📈 %s", text)
+	if pm.events != nil {
+		pm.events.Event("Daily savings", text, map[string]string{"node": pm.config.NodeName})
+	}
+}
+
+// marketDay returns the market midnight starting the day of t
+func marketDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
@@ -0,0 +1,143 @@
+package power
+
+import (
+	"github.com/robfig/cron/v3"
+
+	"kcas/new/internal/datastore"
+)
+
+const (
+	// rolloverCron loads the new day's data at midnight
+	rolloverCron = "0 0 * * *"
+
+	// defaultRefreshCron is used when neither the configuration nor the provider sets a schedule
+	defaultRefreshCron = "0 0 * * *"
+)
+
+// refreshSchedule returns the configured refresh cron expression, or the provider default
+func (pm *Manager) refreshSchedule() string {
+	if pm.config.DataRefreshCron != "" {
+		return pm.config.DataRefreshCron
+	}
+	if scheduler, ok := pm.provider.(datastore.RefreshScheduler); ok {
+		return scheduler.DefaultRefreshCron()
+	}
+	return defaultRefreshCron
+}
+
+// startScheduler schedules the day rollover, the data refresh and its retries
+func (pm *Manager) startScheduler() *cron.Cron {
+	scheduler := cron.New(cron.WithLocation(pm.location))
+
+	if _, err := scheduler.AddFunc(rolloverCron, pm.rolloverData); err != nil {
+		pm.logger.Printf("❌ Failed to schedule day rollover: %v", err)
+	}
+
+	refreshCron := pm.refreshSchedule()
+	if _, err := scheduler.AddFunc(refreshCron, pm.refreshData); err != nil {
+		pm.logger.Printf("❌ Invalid data refresh schedule %q: %v, falling back to %q", refreshCron, err, defaultRefreshCron)
+		refreshCron = defaultRefreshCron
+		scheduler.AddFunc(refreshCron, pm.refreshData)
+	}
+
+	if pm.config.DataRetryCron != "" {
+		if _, err := scheduler.AddFunc(pm.config.DataRetryCron, pm.retryRefresh); err != nil {
+			pm.logger.Printf("❌ Invalid data refresh retry schedule %q: %v", pm.config.DataRetryCron, err)
+		}
+	}
+
+	scheduler.Start()
+
+	pm.logger.Printf("📅 Data refresh scheduled with %q (retries: %q)", refreshCron, pm.config.DataRetryCron)
+	for _, entry := range scheduler.Entries() {
+		pm.logger.Printf("   Next run: %s", entry.Next.Format("2006-01-02 15:04:05"))
+	}
+	return scheduler
+}
+
+// rolloverData loads the data of the day that just started
+func (pm *Manager) rolloverData() {
+	defer pm.RecoverPanic()
+
+	pm.refreshMu.Lock()
+	defer pm.refreshMu.Unlock()
+
+	pm.logger.Println("Midnight reached - loading the new day's data...")
+	today := pm.now()
+	// LoadData falls back to the previous day when today's data cannot be
+	// fetched, and marks the refresh pending
+	if err := pm.LoadData(today); err != nil {
+		pm.reportError(err, "rollover")
+		pm.refreshPending = true
+	}
+}
+
+// refreshData fetches today's data if missing, the previous days missing
+// and tomorrow's. Intraday providers fetch today's data every time and
+// prefetch nothing.
+func (pm *Manager) refreshData() {
+	defer pm.RecoverPanic()
+
+	pm.refreshMu.Lock()
+	defer pm.refreshMu.Unlock()
+
+	ctx, cancel := pm.refreshContext()
+	defer cancel()
+
+	pm.refreshPending = false
+	today := pm.now()
+	intraday := false
+	if provider, ok := pm.activeProvider().(datastore.IntradayProvider); ok {
+		intraday = provider.Intraday()
+	}
+
+	if intraday || !pm.dataStore.HasData(today) || pm.usingFailover() {
+		if err := pm.refreshToday(ctx, today); err != nil {
+			pm.logger.Printf("Failed to refresh today's data: %v", err)
+			pm.reportError(err, "refresh")
+			pm.refreshPending = true
+		} else {
+			pm.replan()
+		}
+	}
+
+	// The days missed over the last BACKFILL_DAYS, in one request when the
+	// provider fetches ranges, today's being left to the refresh above. Only
+	// a failed prefetch of tomorrow is retried.
+	if pm.config.BackfillDays > 0 {
+		from, to := today.AddDate(0, 0, -pm.config.BackfillDays), today.AddDate(0, 0, -1)
+		if err := pm.dataStore.BackfillData(ctx, from, to); err != nil {
+			pm.logger.Printf("Failed to backfill previous days: %v", err)
+		}
+	}
+
+	tomorrow := today.AddDate(0, 0, 1)
+	if !intraday && !pm.dataStore.HasData(tomorrow) {
+		if err := pm.dataStore.PrefetchData(ctx, tomorrow); err != nil {
+			pm.logger.Printf("Failed to prefetch tomorrow's data: %v", err)
+			pm.reportError(err, "prefetch")
+			pm.refreshPending = true
+		}
+	}
+
+	if !pm.refreshPending {
+		pm.logger.Println("Data refresh completed successfully")
+	}
+}
+
+// retryRefresh repeats the refresh after a failure, spacing out the retries
+// of a refresh that keeps failing, and tries to leave the failover provider
+func (pm *Manager) retryRefresh() {
+	pm.refreshMu.Lock()
+	pending := pm.refreshPending && !pm.skipRetry()
+	pm.refreshMu.Unlock()
+
+	switch {
+	case pending:
+		pm.logger.Println("🔁 Retrying failed data refresh...")
+		pm.refreshData()
+	case pm.usingFailover():
+		pm.logger.Printf("🔁 Trying provider %s again...", pm.config.DataProvider)
+		pm.refreshData()
+	}
+}
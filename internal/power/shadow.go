@@ -0,0 +1,56 @@
+package power
+
+import (
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/metrics"
+)
+
+// ShadowDecision is the cap a calculation mode of SHADOW_CALC_MODES would
+// have applied in a cycle, compared with the active POWER_CALC_MODE
+type ShadowDecision struct {
+	Mode            string  `json:"mode"`
+	ReferenceVolume float64 `json:"reference_volume_mwh"`
+	SourcePower     int64   `json:"source_power_uw"`
+	CapUW           int64   `json:"cap_uw"`
+	Clamp           string  `json:"clamp"`
+	Difference      int64   `json:"difference_uw"` // Shadow cap minus the active market-based cap
+}
+
+// evaluateShadows computes the cap of the period with every shadow mode, from
+// the same data, clamps and closures as the active plan, and exports how far
+// each one is from the active market-based cap. Overrides, the battery and
+// peak shaving are left out, as they apply to any mode alike.
+func (pm *Manager) evaluateShadows(point datastore.MarketDataPoint, hardwareMax int64, closed bool, active int64) []ShadowDecision {
+	if len(pm.config.ShadowCalcModes) == 0 {
+		return nil
+	}
+
+	median := datastore.MedianPrice(pm.dataStore.GetCurrentData())
+	shadows := make([]ShadowDecision, 0, len(pm.config.ShadowCalcModes))
+	for _, mode := range pm.config.ShadowCalcModes {
+		referenceVolume := pm.dataStore.GetReferenceVolume(mode)
+		if referenceVolume <= 0 {
+			continue
+		}
+		planned := pm.plannedCap(point, hardwareMax, referenceVolume, median, closed)
+		shadow := ShadowDecision{
+			Mode:            mode,
+			ReferenceVolume: referenceVolume,
+			SourcePower:     planned.SourcePower,
+			CapUW:           planned.CapUW,
+			Clamp:           planned.Clamp,
+			Difference:      planned.CapUW - active,
+		}
+		shadows = append(shadows, shadow)
+
+		labels := metrics.Labels{"mode": mode}
+		pm.metrics.SetGauge("shadow_cap_uw", "Cap a shadow calculation mode would apply (µW)", float64(shadow.CapUW), labels)
+		pm.metrics.SetGauge("shadow_cap_difference_uw", "Shadow cap minus the active market-based cap (µW)", float64(shadow.Difference), labels)
+		if shadow.Difference != 0 {
+			pm.metrics.AddCounter("shadow_disagreements_total", "Number of cycles in which a shadow calculation mode would apply another cap", 1, labels)
+		}
+		pm.logger.Printf("   👥 Shadow mode %s: %d µW (%.1f W), %+.1f W from %s",
+			mode, shadow.CapUW, float64(shadow.CapUW)/1000000, float64(shadow.Difference)/1000000, pm.config.PowerCalcMode)
+	}
+	return shadows
+}
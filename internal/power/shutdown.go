@@ -0,0 +1,67 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Values of the state annotation
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+)
+
+// Shutdown runs the shutdown steps once Run has returned: it brings CPUs
+// offlined for a shed request back online; with RESTORE_LIMITS_ON_EXIT it
+// writes the hardware maximum back to RAPL and the BUDGET_SPLIT actuators,
+// lifts the CPU bandwidth limit of the CGROUP_SLICES and restores the idle
+// policy found at startup, then it marks the node annotations as stopped.
+// The manager's own context is already cancelled at this point, so ctx
+// bounds the RAPL writes and the node update.
+func (pm *Manager) Shutdown(ctx context.Context) error {
+	pm.logger.Println("🛑 Running shutdown steps...")
+
+	pm.onlineCPUs()
+
+	var restored int64
+	if pm.config.RestoreOnExit {
+		maxPower, err := pm.raplMgr.ReadMaxPower()
+		if err != nil {
+			maxPower, err = pm.raplMgr.FindMaxPowerValue()
+		}
+		if err != nil {
+			pm.logger.Printf("❌ Failed to find the hardware maximum to restore: %v", err)
+		} else if errs := pm.raplMgr.ApplyPowerLimitsContext(ctx, maxPower); len(errs) > 0 {
+			for _, err := range errs {
+				pm.logger.Printf("❌ Failed to restore power limit: %v", err)
+			}
+		} else {
+			restored = maxPower
+			pm.logger.Printf("✅ Restored hardware maximum power limit %d µW (%.1f W)", maxPower, float64(maxPower)/1000000)
+		}
+		pm.restoreBudget()
+		pm.restoreCgroups()
+		pm.restoreIdlePolicy()
+	}
+
+	node, err := pm.nodes.GetNode(ctx, pm.config.NodeName)
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[pm.annotation(AnnotationState)] = StateStopped
+	node.Annotations[pm.annotation(AnnotationLastUpdate)] = time.Now().Format(time.RFC3339)
+	if restored > 0 {
+		node.Annotations[pm.annotation(AnnotationPmax)] = strconv.FormatInt(restored, 10)
+	}
+	if err := pm.nodes.UpdateNode(ctx, node); err != nil {
+		return fmt.Errorf("failed to update node annotations: %w", err)
+	}
+
+	pm.logger.Printf("✅ Node '%s' marked as %s", node.Name, StateStopped)
+	return nil
+}
@@ -0,0 +1,46 @@
+package power
+
+import (
+	"fmt"
+
+	"kcas/new/internal/datastore"
+)
+
+// priceSpike reports whether the price of the current period is a spike,
+// above PRICE_SPIKE_THRESHOLD or PRICE_SPIKE_FACTOR × the median price of the
+// day. The floor is then enforced whatever the volume, since spikes are when
+// capping matters most. Entering and leaving a spike is logged and sent as an
+// event.
+func (pm *Manager) priceSpike(price float64, data []datastore.MarketDataPoint) bool {
+	if pm.config.PriceSpikeThreshold <= 0 && pm.config.PriceSpikeFactor <= 0 {
+		return false
+	}
+	median := datastore.MedianPrice(data)
+	spike := datastore.IsPriceSpike(price, median, pm.config.PriceSpikeThreshold, pm.config.PriceSpikeFactor)
+
+	value := 0.0
+	if spike {
+		value = 1
+	}
+	pm.metrics.SetGauge("price_spike", "Whether the price of the current period is a spike", value, nil)
+
+	if spike == pm.inPriceSpike {
+		return spike
+	}
+	pm.inPriceSpike = spike
+
+	unit := datastore.PriceUnit(pm.config.Currency)
+	title := "Price spike ended"
+	text := fmt.Sprintf("Node %s: price %.2f %s back to normal (median %.2f %s), restoring the market-based cap",
+		pm.config.NodeName, price, unit, median, unit)
+	if spike {
+		title = "Price spike"
+		text = fmt.Sprintf("Node %s: price %.2f %s is a spike (median %.2f %s), enforcing the minimum power",
+			pm.config.NodeName, price, unit, median, unit)
+	}
+	pm.logger.Printf("💥 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+	return spike
+}
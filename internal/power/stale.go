@@ -0,0 +1,73 @@
+package power
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnnotationDataStale tells whether the cap follows the data of a previous day
+const AnnotationDataStale = "data-stale"
+
+// dataStale reports whether the current data belongs to a day before the one
+// of t, and returns that day. No data at all is missing, not stale.
+func (pm *Manager) dataStale(t time.Time) (bool, time.Time) {
+	date := pm.dataStore.GetDataDate()
+	if date.IsZero() {
+		return false, date
+	}
+	return date.Format("2006-01-02") < t.In(pm.location).Format("2006-01-02"), date
+}
+
+// updateStaleData sets the market_data_stale gauge, logging and sending an
+// event when the cap starts or stops following a previous day's data
+func (pm *Manager) updateStaleData(stale bool, date time.Time) {
+	value := 0.0
+	if stale {
+		value = 1
+	}
+	pm.metrics.SetGauge("market_data_stale", "Whether the cap follows the market data of a previous day", value, nil)
+
+	pm.mu.Lock()
+	changed := stale != pm.staleData
+	pm.staleData = stale
+	pm.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	title := "Market data up to date"
+	text := fmt.Sprintf("Node %s: today's market data is loaded again", pm.config.NodeName)
+	if stale {
+		title = "Stale market data"
+		text = fmt.Sprintf("Node %s: today's market data could not be fetched, following the data of %s until it is",
+			pm.config.NodeName, date.Format("2006-01-02"))
+	}
+	pm.logger.Printf("📆 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+	pm.updateDegraded()
+}
+
+// followsStaleData reports whether the last cycle followed a previous day's data
+func (pm *Manager) followsStaleData() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.staleData
+}
+
+// dataConfidence returns the confidence in the current data at t and its age
+// in days: 1 for the day's own data, (1 - FALLBACK_DECAY) to the power of
+// the age for a previous day's
+func (pm *Manager) dataConfidence(t time.Time) (float64, int) {
+	stale, date := pm.dataStale(t)
+	if !stale {
+		return 1, 0
+	}
+	t = t.In(pm.location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	dataDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	age := int(day.Sub(dataDay).Hours() / 24)
+	return math.Pow(1-pm.config.FallbackDecay, float64(age)), age
+}
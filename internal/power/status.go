@@ -0,0 +1,101 @@
+package power
+
+import (
+	"strconv"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/version"
+)
+
+// ConstraintStatus is the limit currently set on one RAPL constraint
+type ConstraintStatus struct {
+	Path       string `json:"path"`
+	LimitUW    int64  `json:"limit_uw"`
+	ReadFailed bool   `json:"read_failed,omitempty"`
+}
+
+// DomainStatus groups the constraints of one RAPL domain
+type DomainStatus struct {
+	ID          string             `json:"id"`
+	Constraints []ConstraintStatus `json:"constraints"`
+}
+
+// DataStatus describes the market data currently loaded
+type DataStatus struct {
+	Points    int       `json:"points"`
+	MaxVolume float64   `json:"max_volume_mwh"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Age       string    `json:"age,omitempty"`
+}
+
+// Status is a snapshot of the running manager
+type Status struct {
+	Node         string                `json:"node"`
+	Version      string                `json:"version"`
+	StartedAt    time.Time             `json:"started_at"`
+	AppliedCapUW int64                 `json:"applied_cap_uw"`
+	MeasuredUW   int64                 `json:"measured_power_uw,omitempty"` // Average since the previous cycle, 0 if unknown
+	Domains      []DomainStatus        `json:"domains"`
+	LastDecision *PowerDecision        `json:"last_decision,omitempty"`
+	Override     *Override             `json:"override,omitempty"`
+	Data         DataStatus            `json:"data"`
+	Provider     datastore.FetchStatus `json:"provider"`
+	Providers    []ProviderHealth      `json:"providers"`                // Health of the failover chain, the configured provider first
+	Savings      *SavingsReport        `json:"savings,omitempty"`        // Against the SAVINGS_BASELINE
+	Experiment   string                `json:"experiment_arm,omitempty"` // Arm of the node in a policy experiment
+}
+
+// Status returns the current state of the manager, reading the limits
+// applied to RAPL domains live from sysfs
+func (pm *Manager) Status() Status {
+	now := time.Now()
+
+	status := Status{
+		Node:      pm.config.NodeName,
+		Version:   version.Get().Version,
+		StartedAt: pm.startedAt,
+		Data: DataStatus{
+			Points:    len(pm.dataStore.GetCurrentData()),
+			MaxVolume: pm.dataStore.GetMaxVolume(),
+			UpdatedAt: pm.dataStore.GetLastUpdate(),
+		},
+		Provider:   pm.dataStore.GetFetchStatus(),
+		Providers:  pm.ProviderHealth(),
+		Experiment: pm.ExperimentArm(),
+	}
+	if !status.Data.UpdatedAt.IsZero() {
+		status.Data.Age = now.Sub(status.Data.UpdatedAt).Round(time.Second).String()
+	}
+
+	if decision, ok := pm.LastDecision(); ok {
+		status.LastDecision = &decision
+		status.AppliedCapUW = decision.AppliedPower
+	}
+	if override, ok := pm.ActiveOverride(); ok {
+		status.Override = &override
+	}
+	if savings, ok := pm.Savings(); ok {
+		status.Savings = &savings
+	}
+	if measured, ok := pm.metrics.Get("measured_power_uw", nil); ok {
+		status.MeasuredUW = int64(measured)
+	}
+
+	for _, domain := range pm.raplMgr.ReadCurrentLimits() {
+		ds := DomainStatus{ID: domain.ID}
+		for _, constraint := range domain.Constraints {
+			cs := ConstraintStatus{Path: constraint.Path}
+			limit, err := strconv.ParseInt(constraint.Value, 10, 64)
+			if err != nil {
+				cs.ReadFailed = true
+			} else {
+				cs.LimitUW = limit
+			}
+			ds.Constraints = append(ds.Constraints, cs)
+		}
+		status.Domains = append(status.Domains, ds)
+	}
+
+	return status
+}
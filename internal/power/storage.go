@@ -0,0 +1,130 @@
+package power
+
+import (
+	"fmt"
+	"time"
+)
+
+// Battery storage bands of the state of charge
+const (
+	batteryLow     = "low"     // At or below BATTERY_LOW_SOC: throttle to the minimum
+	batteryPartial = "partial" // In between: scale the market-based cap with the charge
+	batteryFull    = "full"    // At or above BATTERY_HIGH_SOC with PV producing: run at full power
+)
+
+// SetBatterySoC records the state of charge (%) of the site battery. A full
+// battery while the solar panels produce lets the node run at full power, a
+// low battery drops the cap to the minimum power, and in between the
+// market-based cap is scaled with the charge.
+func (pm *Manager) SetBatterySoC(soc float64) {
+	pm.mu.Lock()
+	previous := pm.batteryBandLocked()
+	pm.batterySoC, pm.batterySoCAt = soc, time.Now()
+	band := pm.batteryBandLocked()
+	pm.mu.Unlock()
+
+	pm.metrics.SetGauge("battery_soc_percent", "State of charge of the site battery (%)", soc, nil)
+	pm.batteryBandChanged(previous, band)
+}
+
+// SetPVPower records the solar production (µW), which decides whether a full
+// battery lets the node run at full power
+func (pm *Manager) SetPVPower(power int64) {
+	pm.mu.Lock()
+	previous := pm.batteryBandLocked()
+	pm.pvPower, pm.pvPowerAt = power, time.Now()
+	band := pm.batteryBandLocked()
+	pm.mu.Unlock()
+
+	pm.metrics.SetGauge("pv_power_uw", "Solar production of the site (µW)", float64(power), nil)
+	pm.batteryBandChanged(previous, band)
+}
+
+// batteryBandChanged logs a band change, sends it to the event sink and
+// adjusts the cap without waiting for the next cycle
+func (pm *Manager) batteryBandChanged(previous, band string) {
+	if band == previous || band == "" {
+		return
+	}
+	soc, _ := pm.BatterySoC()
+
+	var text string
+	switch band {
+	case batteryLow:
+		text = fmt.Sprintf("Node %s: battery at %.0f%%, dropping the cap to the minimum power", pm.config.NodeName, soc)
+	case batteryFull:
+		text = fmt.Sprintf("Node %s: battery full (%.0f%%) with solar production, running at full power", pm.config.NodeName, soc)
+	default:
+		text = fmt.Sprintf("Node %s: battery at %.0f%%, scaling the market-based cap with the charge", pm.config.NodeName, soc)
+	}
+	pm.logger.Printf("🔆 %s", text)
+	if pm.events != nil {
+		pm.events.Event("Battery "+band, text, map[string]string{"node": pm.config.NodeName})
+	}
+	pm.TriggerAdjust()
+}
+
+// BatterySoC returns the state of charge of the site battery, if a reading
+// newer than three poll intervals is known
+func (pm *Manager) BatterySoC() (float64, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.batterySoC, pm.batteryFreshLocked()
+}
+
+// batteryFreshLocked reports whether the state of charge is recent enough to
+// act on. Callers must hold pm.mu.
+func (pm *Manager) batteryFreshLocked() bool {
+	return !pm.batterySoCAt.IsZero() && time.Since(pm.batterySoCAt) <= 3*pm.config.BatteryPollInterval
+}
+
+// pvProducingLocked reports whether the solar panels produce, always true
+// without a PV meter. Callers must hold pm.mu.
+func (pm *Manager) pvProducingLocked() bool {
+	if pm.config.PVMeterAddr == "" {
+		return true
+	}
+	fresh := !pm.pvPowerAt.IsZero() && time.Since(pm.pvPowerAt) <= 3*pm.config.BatteryPollInterval
+	return fresh && pm.pvPower >= pm.config.PVMinPower
+}
+
+// batteryBandLocked returns the band of the state of charge, empty when
+// unknown. Callers must hold pm.mu.
+func (pm *Manager) batteryBandLocked() string {
+	switch {
+	case !pm.batteryFreshLocked():
+		return ""
+	case pm.batterySoC <= pm.config.BatteryLowSoC:
+		return batteryLow
+	case pm.batterySoC >= pm.config.BatteryHighSoC && pm.pvProducingLocked():
+		return batteryFull
+	default:
+		return batteryPartial
+	}
+}
+
+// batteryPower applies the battery policy to the market-based limit and
+// returns the new limit, the clamp to record (empty when unchanged), the
+// state of charge and the solar production
+func (pm *Manager) batteryPower(pmax, maxPower int64) (int64, string, *float64, int64) {
+	pm.mu.RLock()
+	band, soc, pv := pm.batteryBandLocked(), pm.batterySoC, pm.pvPower
+	pm.mu.RUnlock()
+
+	minPower := pm.config.RaplLimit
+	switch band {
+	case "":
+		return pmax, "", nil, 0
+	case batteryLow:
+		return minPower, ClampBatteryLow, &soc, pv
+	case batteryFull:
+		full, _, _ := pm.limitPower(maxPower, maxPower)
+		return full, ClampBatteryFull, &soc, pv
+	}
+
+	if soc >= pm.config.BatteryHighSoC || pmax <= minPower {
+		return pmax, "", &soc, pv
+	}
+	share := (soc - pm.config.BatteryLowSoC) / (pm.config.BatteryHighSoC - pm.config.BatteryLowSoC)
+	return minPower + int64(share*float64(pmax-minPower)), ClampBatterySoC, &soc, pv
+}
@@ -0,0 +1,42 @@
+package power
+
+import "fmt"
+
+// SetOnBattery records whether the UPS of the node runs on battery. On
+// battery the cap drops to the minimum power at once, whatever the market
+// signal or an override; the market-based cap is restored as soon as mains
+// power returns.
+func (pm *Manager) SetOnBattery(onBattery bool, status string) {
+	pm.mu.Lock()
+	changed := pm.onBattery != onBattery
+	pm.onBattery = onBattery
+	pm.mu.Unlock()
+
+	value := 0.0
+	if onBattery {
+		value = 1
+	}
+	pm.metrics.SetGauge("ups_on_battery", "Whether the UPS of the node runs on battery", value, nil)
+
+	if !changed {
+		pm.logger.Printf("🔋 UPS status: %s", status)
+		return
+	}
+
+	title, text := "Mains power restored", fmt.Sprintf("Node %s: UPS back on mains (%s), restoring the market-based cap", pm.config.NodeName, status)
+	if onBattery {
+		title, text = "Running on battery", fmt.Sprintf("Node %s: UPS on battery (%s), dropping the cap to the minimum power", pm.config.NodeName, status)
+	}
+	pm.logger.Printf("🔋 %s", text)
+	if pm.events != nil {
+		pm.events.Event(title, text, map[string]string{"node": pm.config.NodeName})
+	}
+	pm.TriggerAdjust()
+}
+
+// OnBattery reports whether the UPS of the node runs on battery
+func (pm *Manager) OnBattery() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.onBattery
+}
@@ -0,0 +1,153 @@
+package rapl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CPUFreqBasePath is the base path for the cpufreq policies
+const CPUFreqBasePath = "/sys/devices/system/cpu/cpufreq"
+
+// Backend is the kernel interface used to cap the power
+type Backend string
+
+// Power backends
+const (
+	BackendAuto    Backend = "auto"       // First available of intel-rapl, dtpm and cpufreq
+	BackendRAPL    Backend = "intel-rapl" // RAPL powercap zones (Intel and AMD)
+	BackendDTPM    Backend = "dtpm"       // Dynamic Thermal Power Management zones (ARM SoCs)
+	BackendCPUFreq Backend = "cpufreq"    // Highest frequency of every cpufreq policy
+)
+
+// frequencyRange is the frequency range of a cpufreq policy, in kHz
+type frequencyRange struct {
+	min       int64
+	max       int64
+	available []int64 // Ascending frequencies the policy supports, empty if any
+}
+
+// SetBackend selects the power backend. cpufreqMaxPower is the power of the
+// CPUs at their highest frequency (µW), which the cpufreq backend needs to
+// translate power limits into frequencies.
+func (m *Manager) SetBackend(backend Backend, cpufreqMaxPower int64) {
+	m.backend = backend
+	m.cpufreqMaxPower = cpufreqMaxPower
+}
+
+// discoverCPUFreq finds the cpufreq policies, each capped through its
+// scaling_max_freq file
+func (m *Manager) discoverCPUFreq() error {
+	basePath := m.sysfsPath(CPUFreqBasePath)
+	m.logger.Printf("🔍 Discovering cpufreq policies in %s...", basePath)
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return fmt.Errorf("failed to read cpufreq base path: %w", err)
+	}
+
+	var domains []Domain
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "policy") {
+			continue
+		}
+		policyPath := filepath.Join(basePath, entry.Name())
+		freq, err := readFrequencyRange(policyPath)
+		if err != nil {
+			m.logger.Printf("   ⚠️  Skipped policy %s: %v", entry.Name(), err)
+			continue
+		}
+
+		path := filepath.Join(policyPath, "scaling_max_freq")
+		domain := Domain{ID: "cpufreq:" + entry.Name(), freq: freq}
+		value, err := readPowerLimit(path)
+		if err != nil {
+			value = "0"
+		}
+		domain.Constraints = []PowerConstraint{{ID: 0, Path: path, Value: m.frequencyPower(domain, value)}}
+		m.logger.Printf("   ✅ Added policy %s: %d-%d kHz", entry.Name(), freq.min, freq.max)
+		domains = append(domains, domain)
+	}
+
+	if len(domains) > 0 && m.cpufreqMaxPower <= 0 {
+		return fmt.Errorf("the cpufreq backend needs the power of the CPUs at their highest frequency (CPUFREQ_MAX_POWER)")
+	}
+	m.domains = domains
+	m.logger.Printf("✅ Domain discovery completed: found %d cpufreq policies", len(domains))
+	return nil
+}
+
+// readFrequencyRange reads the hardware frequency range of a policy
+func readFrequencyRange(policyPath string) (*frequencyRange, error) {
+	freq := &frequencyRange{}
+	for name, target := range map[string]*int64{"cpuinfo_min_freq": &freq.min, "cpuinfo_max_freq": &freq.max} {
+		value, err := readPowerLimit(filepath.Join(policyPath, name))
+		if err != nil {
+			return nil, err
+		}
+		if *target, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", name, value, err)
+		}
+	}
+	if freq.max <= 0 || freq.min > freq.max {
+		return nil, fmt.Errorf("invalid frequency range %d-%d kHz", freq.min, freq.max)
+	}
+
+	// Optional, not exposed by every driver
+	if value, err := readPowerLimit(filepath.Join(policyPath, "scaling_available_frequencies")); err == nil {
+		for _, field := range strings.Fields(value) {
+			if f, err := strconv.ParseInt(field, 10, 64); err == nil {
+				freq.available = append(freq.available, f)
+			}
+		}
+		sort.Slice(freq.available, func(i, j int) bool { return freq.available[i] < freq.available[j] })
+	}
+	return freq, nil
+}
+
+// frequencyFor returns the highest frequency whose estimated power fits in
+// pmax. Power is taken as proportional to the frequency, which overestimates
+// it below the maximum since the voltage drops too, so the cap errs on the
+// safe side.
+func (m *Manager) frequencyFor(freq *frequencyRange, pmax int64) int64 {
+	target := freq.max
+	if pmax < m.cpufreqMaxPower {
+		target = int64(float64(freq.max) * float64(pmax) / float64(m.cpufreqMaxPower))
+	}
+	if target < freq.min {
+		target = freq.min
+	}
+
+	if len(freq.available) > 0 {
+		chosen := freq.available[0]
+		for _, f := range freq.available {
+			if f <= target {
+				chosen = f
+			}
+		}
+		target = chosen
+	}
+	return target
+}
+
+// frequencyPower converts a scaling_max_freq value into the power limit it
+// stands for, as a string in µW
+func (m *Manager) frequencyPower(domain Domain, value string) string {
+	f, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || domain.freq == nil || domain.freq.max <= 0 {
+		return "0"
+	}
+	return strconv.FormatInt(int64(float64(m.cpufreqMaxPower)*float64(f)/float64(domain.freq.max)), 10)
+}
+
+// applyFrequency caps a cpufreq policy at the frequency matching pmax
+func (m *Manager) applyFrequency(domain Domain, pmax int64) error {
+	target := m.frequencyFor(domain.freq, pmax)
+	path := domain.Constraints[0].Path
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(target, 10)), 0644); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
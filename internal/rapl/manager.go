@@ -1,17 +1,26 @@
 package rapl
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	// RaplBasePath is the base path for RAPL domains
 	RaplBasePath = "/sys/devices/virtual/powercap/intel-rapl"
+	// DTPMBasePath is the base path for the Dynamic Thermal Power Management
+	// zones of ARM SoCs, exposed through the same powercap interface
+	DTPMBasePath = "/sys/devices/virtual/powercap/dtpm"
 )
 
 // PowerConstraint represents a RAPL power constraint configuration
@@ -26,36 +35,106 @@ type Domain struct {
 	ID             string // e.g., "intel-rapl:0"
 	Constraints    []PowerConstraint
 	ConstraintsMax []PowerConstraint
+	EnergyPath     string // path to the cumulative energy_uj counter, if present
+	MaxEnergyRange int64  // value at which the energy counter wraps around (µJ)
+
+	freq *frequencyRange // Frequency range of a cpufreq policy, nil for powercap zones
+}
+
+// EnergySample is a reading of the cumulative energy counters of all domains
+type EnergySample struct {
+	Time     time.Time
+	Counters map[string]int64 // domain ID → energy in µJ
 }
 
 // Manager handles RAPL domain operations
 type Manager struct {
 	domains []Domain
 	logger  *log.Logger
+
+	backend         Backend // Requested backend, BackendAuto picks the first available
+	active          Backend // Backend of the discovered domains
+	cpufreqMaxPower int64   // Power of the CPUs at their highest frequency (µW)
+	root            string  // Directory holding the sysfs tree, empty for /
+
+	applyMu sync.Mutex // Serialises limit writes, including ones outliving their context
+
+	pendingMu sync.Mutex
+	pending   bool // A write outlived its context and is still running
 }
 
 // NewManager creates a new RAPL manager
 func NewManager(logger *log.Logger) *Manager {
 	return &Manager{
-		logger: logger,
+		logger:  logger,
+		backend: BackendAuto,
 	}
 }
 
-// DiscoverDomains finds all RAPL domains and their constraints in the system
+// SetRoot sets the directory the sysfs paths are resolved under, e.g. /host
+// when the host's /sys is mounted at /host/sys, or a fake tree in tests
+func (m *Manager) SetRoot(root string) {
+	m.root = root
+}
+
+// sysfsPath returns a sysfs path under the configured root
+func (m *Manager) sysfsPath(path string) string {
+	return filepath.Join(m.root, path)
+}
+
+// DiscoverDomains finds all power domains and their constraints in the
+// system, with the configured backend or the first one available
 func (m *Manager) DiscoverDomains() error {
-	m.logger.Printf("🔍 Discovering RAPL domains in %s...", RaplBasePath)
+	backends := []Backend{m.backend}
+	if m.backend == BackendAuto || m.backend == "" {
+		backends = []Backend{BackendRAPL, BackendDTPM, BackendCPUFreq}
+	}
+
+	for _, backend := range backends {
+		var err error
+		switch backend {
+		case BackendRAPL:
+			err = m.discoverPowercap(m.sysfsPath(RaplBasePath), "intel-rapl:")
+		case BackendDTPM:
+			err = m.discoverPowercap(m.sysfsPath(DTPMBasePath), "dtpm:")
+		case BackendCPUFreq:
+			err = m.discoverCPUFreq()
+		default:
+			return fmt.Errorf("unknown power backend %q", backend)
+		}
+		if err == nil && len(m.domains) > 0 {
+			m.active = backend
+			return nil
+		}
+		if err != nil && (len(backends) == 1 || !errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+		m.logger.Printf("   ⏭️  No usable %s domains, trying the next backend", backend)
+	}
+	return fmt.Errorf("no power capping interface found (intel-rapl, dtpm or cpufreq)")
+}
+
+// Backend returns the backend of the discovered domains
+func (m *Manager) Backend() Backend {
+	return m.active
+}
+
+// discoverPowercap finds the top-level zones of a powercap control type
+// (intel-rapl or dtpm) and their constraints
+func (m *Manager) discoverPowercap(basePath, prefix string) error {
+	m.logger.Printf("🔍 Discovering RAPL domains in %s...", basePath)
 	var domains []Domain
 
 	// List all RAPL domains
-	entries, err := os.ReadDir(RaplBasePath)
+	entries, err := os.ReadDir(basePath)
 	if err != nil {
-		m.logger.Printf("❌ Failed to read RAPL base path %s: %v", RaplBasePath, err)
+		m.logger.Printf("❌ Failed to read RAPL base path %s: %v", basePath, err)
 		return fmt.Errorf("failed to read RAPL base path: %w", err)
 	}
 	m.logger.Printf("📁 Found %d entries in RAPL directory", len(entries))
 
 	for _, entry := range entries {
-		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "intel-rapl:") {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
 			m.logger.Printf("   ⏭️  Skipping non-RAPL entry: %s", entry.Name())
 			continue
 		}
@@ -66,7 +145,7 @@ func (m *Manager) DiscoverDomains() error {
 		}
 
 		// Read only direct constraint files in this domain
-		domainPath := filepath.Join(RaplBasePath, entry.Name())
+		domainPath := filepath.Join(basePath, entry.Name())
 		constraintEntries, err := os.ReadDir(domainPath)
 		if err != nil {
 			return fmt.Errorf("failed to read domain directory %s: %w", domainPath, err)
@@ -78,6 +157,18 @@ func (m *Manager) DiscoverDomains() error {
 				continue // Skip directories
 			}
 
+			// Energy counters used to measure actual consumption
+			switch name {
+			case "energy_uj":
+				domain.EnergyPath = filepath.Join(domainPath, name)
+				continue
+			case "max_energy_range_uj":
+				if value, err := readPowerLimit(filepath.Join(domainPath, name)); err == nil {
+					domain.MaxEnergyRange, _ = strconv.ParseInt(value, 10, 64)
+				}
+				continue
+			}
+
 			// Process only constraint files
 			if !strings.HasPrefix(name, "constraint_") {
 				continue
@@ -151,6 +242,9 @@ func (m *Manager) GetDomains() []Domain {
 
 // FindMaxPowerValue finds the maximum power value across all domains and constraints
 func (m *Manager) FindMaxPowerValue() (int64, error) {
+	if m.active == BackendCPUFreq {
+		return m.cpufreqMaxPower, nil
+	}
 	m.logger.Printf("🔍 Searching for maximum power value across %d RAPL domains...", len(m.domains))
 	var maxPower int64
 	var maxPowerSource string
@@ -197,12 +291,59 @@ func (m *Manager) FindMaxPowerValue() (int64, error) {
 	return maxPower, nil
 }
 
+// ReadMaxPower re-reads the max_power_uw files of every domain and returns
+// the largest value, so hardware or firmware changes since discovery are seen
+func (m *Manager) ReadMaxPower() (int64, error) {
+	if m.active == BackendCPUFreq {
+		return m.cpufreqMaxPower, nil
+	}
+	var maxPower int64
+	for _, domain := range m.domains {
+		for _, constraint := range domain.ConstraintsMax {
+			value, err := readPowerLimit(constraint.Path)
+			if err != nil {
+				return 0, err
+			}
+			power, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid max power value '%s' at %s: %w", value, constraint.Path, err)
+			}
+			if power > maxPower {
+				maxPower = power
+			}
+		}
+	}
+
+	if maxPower == 0 {
+		return 0, fmt.Errorf("no valid max power values found")
+	}
+	return maxPower, nil
+}
+
 // ApplyPowerLimits applies the given power limit to all power_limit_uw files
 func (m *Manager) ApplyPowerLimits(pmax int64) []error {
+	return m.ApplyDomainLimits(pmax, nil)
+}
+
+// ApplyDomainLimits applies the given power limit to the domains with the
+// given IDs only, or to all of them when ids is nil
+func (m *Manager) ApplyDomainLimits(pmax int64, ids []string) []error {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
 	pmaxStr := strconv.FormatInt(pmax, 10)
 	var errors []error
 
 	for _, domain := range m.domains {
+		if ids != nil && !slices.Contains(ids, domain.ID) {
+			continue
+		}
+		if domain.freq != nil {
+			if err := m.applyFrequency(domain, pmax); err != nil {
+				errors = append(errors, err)
+			}
+			continue
+		}
 		for _, constraint := range domain.Constraints {
 			if err := os.WriteFile(constraint.Path, []byte(pmaxStr), 0644); err != nil {
 				errors = append(errors, fmt.Errorf("%s: %w", constraint.Path, err))
@@ -213,6 +354,151 @@ func (m *Manager) ApplyPowerLimits(pmax int64) []error {
 	return errors
 }
 
+// ApplyPowerLimitsContext applies the power limit like ApplyPowerLimits, but
+// returns once ctx is done. A sysfs write cannot be interrupted: a stuck one
+// completes in the background, and later writes are skipped until it returns.
+func (m *Manager) ApplyPowerLimitsContext(ctx context.Context, pmax int64) []error {
+	return m.ApplyDomainLimitsContext(ctx, pmax, nil)
+}
+
+// ApplyDomainLimitsContext applies the power limit like ApplyDomainLimits,
+// returning once ctx is done
+func (m *Manager) ApplyDomainLimitsContext(ctx context.Context, pmax int64, ids []string) []error {
+	m.pendingMu.Lock()
+	pending := m.pending
+	m.pendingMu.Unlock()
+	if pending {
+		// Another goroutine would only queue up behind the stuck write
+		return []error{fmt.Errorf("writing power limit %d µW: a previous write is still pending", pmax)}
+	}
+
+	var finished, stuck bool // Guarded by pendingMu
+	done := make(chan []error, 1)
+	go func() {
+		errs := m.ApplyDomainLimits(pmax, ids)
+		m.pendingMu.Lock()
+		finished = true
+		if stuck {
+			m.pending = false
+		}
+		m.pendingMu.Unlock()
+		done <- errs
+	}()
+
+	select {
+	case errs := <-done:
+		return errs
+	case <-ctx.Done():
+		m.pendingMu.Lock()
+		if !finished {
+			stuck = true
+			m.pending = true
+		}
+		m.pendingMu.Unlock()
+		return []error{fmt.Errorf("writing power limit %d µW: %w", pmax, ctx.Err())}
+	}
+}
+
+// ReadCurrentLimits re-reads the power limit currently set on every constraint
+func (m *Manager) ReadCurrentLimits() []Domain {
+	domains := make([]Domain, 0, len(m.domains))
+	for _, domain := range m.domains {
+		current := Domain{ID: domain.ID}
+		for _, constraint := range domain.Constraints {
+			value, err := readPowerLimit(constraint.Path)
+			if err != nil {
+				value = ""
+			} else if domain.freq != nil {
+				value = m.frequencyPower(domain, value)
+			}
+			current.Constraints = append(current.Constraints, PowerConstraint{
+				ID:    constraint.ID,
+				Path:  constraint.Path,
+				Value: value,
+			})
+		}
+		domains = append(domains, current)
+	}
+	return domains
+}
+
+// HasEnergyCounters reports whether any domain exposes an energy counter.
+// DTPM zones and cpufreq policies do not, their power comes from a meter.
+func (m *Manager) HasEnergyCounters() bool {
+	for _, domain := range m.domains {
+		if domain.EnergyPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadEnergy reads the cumulative energy counter of every domain
+func (m *Manager) ReadEnergy() (EnergySample, error) {
+	sample := EnergySample{
+		Time:     time.Now(),
+		Counters: make(map[string]int64),
+	}
+
+	for _, domain := range m.domains {
+		if domain.EnergyPath == "" {
+			continue
+		}
+		value, err := readPowerLimit(domain.EnergyPath)
+		if err != nil {
+			return sample, err
+		}
+		energy, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return sample, fmt.Errorf("invalid energy value '%s' at %s: %w", value, domain.EnergyPath, err)
+		}
+		sample.Counters[domain.ID] = energy
+	}
+
+	if len(sample.Counters) == 0 {
+		return sample, fmt.Errorf("no RAPL energy counters available (%s backend), measure power with an external meter", m.active)
+	}
+	return sample, nil
+}
+
+// AveragePower returns the mean power (µW) consumed between two energy samples,
+// accounting for counters that wrapped around in between
+func (m *Manager) AveragePower(prev, cur EnergySample) (int64, error) {
+	return m.AverageDomainPower(prev, cur, nil)
+}
+
+// AverageDomainPower returns the mean power (µW) consumed between two energy
+// samples by the domains with the given IDs, or by all of them when ids is nil
+func (m *Manager) AverageDomainPower(prev, cur EnergySample, ids []string) (int64, error) {
+	elapsed := cur.Time.Sub(prev.Time).Seconds()
+	if elapsed <= 0 {
+		return 0, fmt.Errorf("energy samples are not in chronological order")
+	}
+
+	var totalEnergy int64
+	for _, domain := range m.domains {
+		if ids != nil && !slices.Contains(ids, domain.ID) {
+			continue
+		}
+		before, ok1 := prev.Counters[domain.ID]
+		after, ok2 := cur.Counters[domain.ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		delta := after - before
+		if delta < 0 && domain.MaxEnergyRange > 0 {
+			delta += domain.MaxEnergyRange
+		}
+		if delta < 0 {
+			return 0, fmt.Errorf("energy counter of domain %s went backwards", domain.ID)
+		}
+		totalEnergy += delta
+	}
+
+	return int64(float64(totalEnergy) / elapsed), nil
+}
+
 // readPowerLimit reads power limit from a file
 func readPowerLimit(path string) (string, error) {
 	data, err := os.ReadFile(path)
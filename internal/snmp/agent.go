@@ -0,0 +1,335 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+)
+
+// SNMP versions as encoded on the wire
+const (
+	versionV1  = 0
+	versionV2c = 1
+)
+
+// Error statuses
+const (
+	errNoError    = 0
+	errNoSuchName = 2
+	errReadOnly   = 4
+)
+
+// maxBulkRepetitions bounds GetBulk responses
+const maxBulkRepetitions = 64
+
+// object is a readable scalar exposed by the agent
+type object struct {
+	oid   OID
+	value func() Value
+}
+
+// varBind is an OID/value pair of a PDU
+type varBind struct {
+	oid   OID
+	value Value
+}
+
+// Agent is a minimal read-only SNMP v1/v2c agent
+type Agent struct {
+	addr      string
+	community string
+	logger    *log.Logger
+
+	mu      sync.RWMutex
+	objects []object // sorted by OID
+}
+
+// NewAgent creates an agent listening on addr (e.g. ":1161") for the given community
+func NewAgent(addr, community string, logger *log.Logger) *Agent {
+	return &Agent{
+		addr:      addr,
+		community: community,
+		logger:    logger,
+	}
+}
+
+// Register exposes a scalar object; value is evaluated on every request
+func (a *Agent) Register(oid OID, value func() Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.objects = append(a.objects, object{oid: oid, value: value})
+	sort.Slice(a.objects, func(i, j int) bool {
+		return a.objects[i].oid.Compare(a.objects[j].oid) < 0
+	})
+}
+
+// Run serves requests until the context is cancelled
+func (a *Agent) Run(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", a.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 65535)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read SNMP request: %w", err)
+		}
+
+		response, err := a.handle(buf[:n])
+		if err != nil {
+			a.logger.Printf("⚠️  Ignoring invalid SNMP request from %s: %v", peer, err)
+			continue
+		}
+		if response == nil {
+			continue
+		}
+		if _, err := conn.WriteTo(response, peer); err != nil {
+			a.logger.Printf("⚠️  Failed to send SNMP response to %s: %v", peer, err)
+		}
+	}
+}
+
+// handle decodes a request message and builds the response message
+func (a *Agent) handle(packet []byte) ([]byte, error) {
+	msg, _, err := decodeTLV(packet)
+	if err != nil || msg.tag != tagSequence {
+		return nil, fmt.Errorf("not an SNMP message")
+	}
+
+	versionTLV, rest, err := decodeTLV(msg.content)
+	if err != nil {
+		return nil, err
+	}
+	version, err := decodeInt(versionTLV.content)
+	if err != nil {
+		return nil, err
+	}
+	if version != versionV1 && version != versionV2c {
+		return nil, fmt.Errorf("unsupported SNMP version %d", version)
+	}
+
+	communityTLV, rest, err := decodeTLV(rest)
+	if err != nil {
+		return nil, err
+	}
+	if string(communityTLV.content) != a.community {
+		// Silently drop requests with a wrong community, like standard agents
+		return nil, nil
+	}
+
+	pdu, _, err := decodeTLV(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, errorStatus, errorIndex, binds, err := decodePDU(pdu.content)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []varBind
+	status, index := int64(errNoError), int64(0)
+
+	switch pdu.tag {
+	case pduGetRequest:
+		results, status, index = a.get(binds, version)
+	case pduGetNextRequest:
+		results, status, index = a.getNext(binds, version)
+	case pduGetBulkRequest:
+		if version == versionV1 {
+			return nil, fmt.Errorf("GetBulk is not supported in SNMPv1")
+		}
+		results = a.getBulk(binds, int(errorStatus), int(errorIndex))
+	case pduSetRequest:
+		results, status, index = binds, errReadOnly, 1
+		if version == versionV1 {
+			status = errNoSuchName
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PDU type 0x%x", pdu.tag)
+	}
+
+	return encodeMessage(version, a.community, requestID, status, index, results), nil
+}
+
+// get answers a GetRequest
+func (a *Agent) get(binds []varBind, version int64) ([]varBind, int64, int64) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	results := make([]varBind, len(binds))
+	for i, bind := range binds {
+		results[i] = varBind{oid: bind.oid, value: Value{Tag: tagNoSuchObject}}
+		for _, obj := range a.objects {
+			if obj.oid.Compare(bind.oid) == 0 {
+				results[i].value = obj.value()
+				break
+			}
+		}
+		if results[i].value.Tag == tagNoSuchObject && version == versionV1 {
+			return binds, errNoSuchName, int64(i + 1)
+		}
+	}
+	return results, errNoError, 0
+}
+
+// getNext answers a GetNextRequest
+func (a *Agent) getNext(binds []varBind, version int64) ([]varBind, int64, int64) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	results := make([]varBind, len(binds))
+	for i, bind := range binds {
+		next, ok := a.next(bind.oid)
+		if !ok {
+			if version == versionV1 {
+				return binds, errNoSuchName, int64(i + 1)
+			}
+			results[i] = varBind{oid: bind.oid, value: Value{Tag: tagEndOfMibView}}
+			continue
+		}
+		results[i] = varBind{oid: next.oid, value: next.value()}
+	}
+	return results, errNoError, 0
+}
+
+// getBulk answers a GetBulkRequest
+func (a *Agent) getBulk(binds []varBind, nonRepeaters, maxRepetitions int) []varBind {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if nonRepeaters < 0 {
+		nonRepeaters = 0
+	}
+	if nonRepeaters > len(binds) {
+		nonRepeaters = len(binds)
+	}
+	if maxRepetitions < 0 {
+		maxRepetitions = 0
+	}
+	if maxRepetitions > maxBulkRepetitions {
+		maxRepetitions = maxBulkRepetitions
+	}
+
+	var results []varBind
+	for _, bind := range binds[:nonRepeaters] {
+		if next, ok := a.next(bind.oid); ok {
+			results = append(results, varBind{oid: next.oid, value: next.value()})
+		} else {
+			results = append(results, varBind{oid: bind.oid, value: Value{Tag: tagEndOfMibView}})
+		}
+	}
+
+	for _, bind := range binds[nonRepeaters:] {
+		current := bind.oid
+		for r := 0; r < maxRepetitions; r++ {
+			next, ok := a.next(current)
+			if !ok {
+				results = append(results, varBind{oid: current, value: Value{Tag: tagEndOfMibView}})
+				break
+			}
+			results = append(results, varBind{oid: next.oid, value: next.value()})
+			current = next.oid
+		}
+	}
+	return results
+}
+
+// next finds the first object strictly after oid
+func (a *Agent) next(oid OID) (object, bool) {
+	for _, obj := range a.objects {
+		if obj.oid.Compare(oid) > 0 {
+			return obj, true
+		}
+	}
+	return object{}, false
+}
+
+// decodePDU decodes the request-id, error fields and variable bindings
+func decodePDU(content []byte) (requestID, errorStatus, errorIndex int64, binds []varBind, err error) {
+	fields := make([]int64, 3)
+	rest := content
+	for i := range fields {
+		var field tlv
+		field, rest, err = decodeTLV(rest)
+		if err != nil {
+			return
+		}
+		if fields[i], err = decodeInt(field.content); err != nil {
+			return
+		}
+	}
+
+	list, _, err := decodeTLV(rest)
+	if err != nil {
+		return
+	}
+
+	rest = list.content
+	for len(rest) > 0 {
+		var bind, oidTLV, valueTLV tlv
+		bind, rest, err = decodeTLV(rest)
+		if err != nil {
+			return
+		}
+		var inner []byte
+		oidTLV, inner, err = decodeTLV(bind.content)
+		if err != nil {
+			return
+		}
+		valueTLV, _, err = decodeTLV(inner)
+		if err != nil {
+			return
+		}
+
+		var oid OID
+		if oid, err = decodeOID(oidTLV.content); err != nil {
+			return
+		}
+		binds = append(binds, varBind{oid: oid, value: Value{Tag: valueTLV.tag, Bytes: valueTLV.content}})
+	}
+
+	return fields[0], fields[1], fields[2], binds, nil
+}
+
+// encodeMessage builds a GetResponse message
+func encodeMessage(version int64, community string, requestID, errorStatus, errorIndex int64, binds []varBind) []byte {
+	return encodePDUMessage(pduGetResponse, version, community, requestID, errorStatus, errorIndex, binds)
+}
+
+// encodePDUMessage builds a message carrying a PDU of the given type
+func encodePDUMessage(pduType byte, version int64, community string, requestID, errorStatus, errorIndex int64, binds []varBind) []byte {
+	var bindList []byte
+	for _, bind := range binds {
+		pair := encodeTLV(tagOID, encodeOID(bind.oid))
+		pair = append(pair, encodeTLV(bind.value.Tag, bind.value.Bytes)...)
+		bindList = append(bindList, encodeTLV(tagSequence, pair)...)
+	}
+
+	var pdu []byte
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInt(requestID))...)
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInt(errorStatus))...)
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInt(errorIndex))...)
+	pdu = append(pdu, encodeTLV(tagSequence, bindList)...)
+
+	var msg []byte
+	msg = append(msg, encodeTLV(tagInteger, encodeInt(version))...)
+	msg = append(msg, encodeTLV(tagOctetString, []byte(community))...)
+	msg = append(msg, encodeTLV(pduType, pdu)...)
+
+	return encodeTLV(tagSequence, msg)
+}
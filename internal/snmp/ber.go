@@ -0,0 +1,251 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER tags used by SNMP
+const (
+	tagInteger     byte = 0x02
+	tagOctetString byte = 0x04
+	tagNull        byte = 0x05
+	tagOID         byte = 0x06
+	tagSequence    byte = 0x30
+	tagCounter32   byte = 0x41
+	tagGauge32     byte = 0x42
+	tagTimeTicks   byte = 0x43
+
+	tagNoSuchObject   byte = 0x80
+	tagNoSuchInstance byte = 0x81
+	tagEndOfMibView   byte = 0x82
+
+	pduGetRequest     byte = 0xa0
+	pduGetNextRequest byte = 0xa1
+	pduGetResponse    byte = 0xa2
+	pduSetRequest     byte = 0xa3
+	pduGetBulkRequest byte = 0xa5
+)
+
+var errTruncated = errors.New("truncated BER data")
+
+// Value is a typed SNMP value
+type Value struct {
+	Tag   byte
+	Bytes []byte
+}
+
+// OctetString creates a string value
+func OctetString(s string) Value {
+	return Value{Tag: tagOctetString, Bytes: []byte(s)}
+}
+
+// Integer creates a signed 32-bit integer value
+func Integer(v int64) Value {
+	return Value{Tag: tagInteger, Bytes: encodeInt(v)}
+}
+
+// Gauge32 creates an unsigned gauge value, saturating at 2^32-1
+func Gauge32(v int64) Value {
+	if v < 0 {
+		v = 0
+	}
+	if v > 0xffffffff {
+		v = 0xffffffff
+	}
+	return Value{Tag: tagGauge32, Bytes: encodeUint(uint64(v))}
+}
+
+// TimeTicks creates a hundredths-of-second value
+func TimeTicks(v int64) Value {
+	return Value{Tag: tagTimeTicks, Bytes: encodeUint(uint64(v))}
+}
+
+// OID is an object identifier
+type OID []int
+
+// ParseOID parses a dotted OID string such as "1.3.6.1.4.1"
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(strings.Trim(s, "."), ".")
+	oid := make(OID, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid = append(oid, n)
+	}
+	if len(oid) < 2 {
+		return nil, fmt.Errorf("OID %q is too short", s)
+	}
+	return oid, nil
+}
+
+// String returns the dotted representation
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, n := range o {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Append returns a new OID with the given sub-identifiers appended
+func (o OID) Append(ids ...int) OID {
+	result := make(OID, 0, len(o)+len(ids))
+	result = append(result, o...)
+	return append(result, ids...)
+}
+
+// Compare orders OIDs lexicographically
+func (o OID) Compare(other OID) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		if o[i] != other[i] {
+			if o[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(o) < len(other):
+		return -1
+	case len(o) > len(other):
+		return 1
+	}
+	return 0
+}
+
+// tlv is a decoded BER element
+type tlv struct {
+	tag     byte
+	content []byte
+}
+
+// decodeTLV reads one element and returns it with the remaining bytes
+func decodeTLV(data []byte) (tlv, []byte, error) {
+	if len(data) < 2 {
+		return tlv{}, nil, errTruncated
+	}
+
+	tag := data[0]
+	length := int(data[1])
+	offset := 2
+
+	if length&0x80 != 0 {
+		numBytes := length & 0x7f
+		if numBytes == 0 || numBytes > 4 || len(data) < 2+numBytes {
+			return tlv{}, nil, errTruncated
+		}
+		length = 0
+		for i := 0; i < numBytes; i++ {
+			length = length<<8 | int(data[2+i])
+		}
+		offset += numBytes
+	}
+
+	if length < 0 || len(data) < offset+length {
+		return tlv{}, nil, errTruncated
+	}
+
+	return tlv{tag: tag, content: data[offset : offset+length]}, data[offset+length:], nil
+}
+
+// decodeInt decodes a two's complement integer
+func decodeInt(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("invalid integer length %d", len(content))
+	}
+	var v int64
+	if content[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range content {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// decodeOID decodes an object identifier
+func decodeOID(content []byte) (OID, error) {
+	if len(content) == 0 {
+		return nil, errors.New("empty OID")
+	}
+
+	oid := OID{int(content[0]) / 40, int(content[0]) % 40}
+	value := 0
+	for _, b := range content[1:] {
+		value = value<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			oid = append(oid, value)
+			value = 0
+		}
+	}
+	return oid, nil
+}
+
+// encodeTLV encodes a tag, length and content
+func encodeTLV(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	out = append(out, encodeLength(len(content))...)
+	return append(out, content...)
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var buf []byte
+	for length > 0 {
+		buf = append([]byte{byte(length & 0xff)}, buf...)
+		length >>= 8
+	}
+	return append([]byte{0x80 | byte(len(buf))}, buf...)
+}
+
+func encodeInt(v int64) []byte {
+	var buf []byte
+	for {
+		buf = append([]byte{byte(v & 0xff)}, buf...)
+		v >>= 8
+		// Stop once the remaining value is pure sign extension of the top bit
+		if (v == 0 && buf[0]&0x80 == 0) || (v == -1 && buf[0]&0x80 != 0) {
+			return buf
+		}
+	}
+}
+
+func encodeUint(v uint64) []byte {
+	var buf []byte
+	for {
+		buf = append([]byte{byte(v & 0xff)}, buf...)
+		v >>= 8
+		if v == 0 {
+			break
+		}
+	}
+	if buf[0]&0x80 != 0 {
+		buf = append([]byte{0}, buf...)
+	}
+	return buf
+}
+
+func encodeOID(oid OID) []byte {
+	if len(oid) < 2 {
+		return []byte{0}
+	}
+	content := []byte{byte(oid[0]*40 + oid[1])}
+	for _, n := range oid[2:] {
+		var sub []byte
+		sub = append(sub, byte(n&0x7f))
+		n >>= 7
+		for n > 0 {
+			sub = append([]byte{byte(n&0x7f) | 0x80}, sub...)
+			n >>= 7
+		}
+		content = append(content, sub...)
+	}
+	return content
+}
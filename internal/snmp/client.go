@@ -0,0 +1,105 @@
+package snmp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// clientTimeout bounds a request when the context has no deadline
+const clientTimeout = 5 * time.Second
+
+// Get reads one object from the SNMPv2c agent at addr (host:port)
+func Get(ctx context.Context, addr, community string, oid OID) (Value, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return Value{}, fmt.Errorf("failed to reach SNMP agent %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(clientTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	var id [4]byte
+	rand.Read(id[:])
+	requestID := int64(binary.BigEndian.Uint32(id[:]) >> 1)
+
+	request := encodePDUMessage(pduGetRequest, versionV2c, community, requestID, 0, 0,
+		[]varBind{{oid: oid, value: Value{Tag: tagNull}}})
+	if _, err := conn.Write(request); err != nil {
+		return Value{}, fmt.Errorf("failed to send SNMP request: %w", err)
+	}
+
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return Value{}, fmt.Errorf("no SNMP response from %s: %w", addr, err)
+		}
+
+		responseID, status, binds, err := decodeResponse(buf[:n])
+		if err != nil || responseID != requestID {
+			continue // Not the answer to this request
+		}
+		if status != errNoError {
+			return Value{}, fmt.Errorf("SNMP agent %s returned error status %d", addr, status)
+		}
+		if len(binds) != 1 {
+			return Value{}, fmt.Errorf("SNMP agent %s returned %d objects", addr, len(binds))
+		}
+		switch binds[0].value.Tag {
+		case tagNoSuchObject, tagNoSuchInstance, tagEndOfMibView:
+			return Value{}, fmt.Errorf("SNMP agent %s has no object %s", addr, oid)
+		}
+		return binds[0].value, nil
+	}
+}
+
+// decodeResponse decodes the request-id, error status and bindings of a
+// GetResponse message
+func decodeResponse(packet []byte) (int64, int64, []varBind, error) {
+	msg, _, err := decodeTLV(packet)
+	if err != nil || msg.tag != tagSequence {
+		return 0, 0, nil, fmt.Errorf("not an SNMP message")
+	}
+	_, rest, err := decodeTLV(msg.content) // Version
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	_, rest, err = decodeTLV(rest) // Community
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	pdu, _, err := decodeTLV(rest)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if pdu.tag != pduGetResponse {
+		return 0, 0, nil, fmt.Errorf("unexpected PDU type 0x%x", pdu.tag)
+	}
+	requestID, status, _, binds, err := decodePDU(pdu.content)
+	return requestID, status, binds, err
+}
+
+// Int returns the value of an integer, gauge, counter or time ticks object
+func (v Value) Int() (int64, error) {
+	switch v.Tag {
+	case tagInteger:
+		return decodeInt(v.Bytes)
+	case tagCounter32, tagGauge32, tagTimeTicks:
+		var n uint64
+		for _, b := range v.Bytes {
+			n = n<<8 | uint64(b)
+		}
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("SNMP value of type 0x%x is not a number", v.Tag)
+	}
+}
@@ -0,0 +1,39 @@
+package snmp
+
+import (
+	"time"
+
+	"kcas/new/internal/metrics"
+)
+
+// Scalar objects of POWERCAP-MIB, relative to the base OID (see mibs/POWERCAP-MIB.txt)
+const (
+	oidNodeName      = 1
+	oidAppliedCapMW  = 2
+	oidHardwareMaxMW = 3
+	oidMeasuredMW    = 4
+	oidMinPowerMW    = 5
+	oidUptime        = 6
+)
+
+// RegisterPowerMIB exposes the power manager state read from the metrics
+// registry as POWERCAP-MIB scalars. Power values are reported in milliwatts.
+func RegisterPowerMIB(agent *Agent, base OID, nodeName string, registry *metrics.Registry) {
+	started := time.Now()
+
+	milliwatts := func(metric string) func() Value {
+		return func() Value {
+			microwatts, _ := registry.Get(metric, nil)
+			return Gauge32(int64(microwatts / 1000))
+		}
+	}
+
+	agent.Register(base.Append(oidNodeName, 0), func() Value { return OctetString(nodeName) })
+	agent.Register(base.Append(oidAppliedCapMW, 0), milliwatts("applied_cap_uw"))
+	agent.Register(base.Append(oidHardwareMaxMW, 0), milliwatts("hardware_max_uw"))
+	agent.Register(base.Append(oidMeasuredMW, 0), milliwatts("measured_power_uw"))
+	agent.Register(base.Append(oidMinPowerMW, 0), milliwatts("min_power_uw"))
+	agent.Register(base.Append(oidUptime, 0), func() Value {
+		return TimeTicks(int64(time.Since(started) / (10 * time.Millisecond)))
+	})
+}
@@ -0,0 +1,84 @@
+// Package systemd implements the sd_notify protocol, so the manager can run
+// as a Type=notify service with a watchdog.
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends a state such as "READY=1" to the service manager. It does
+// nothing and returns false when not started by systemd with NOTIFY_SOCKET.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// Abstract socket names start with @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to the notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout set by WatchdogSec=, or 0
+// when the watchdog is disabled or meant for another process
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog sends a keepalive every half timeout while healthy reports the
+// control loop as alive, until the context is cancelled. Once the loop stops
+// making progress the keepalives stop and systemd restarts the service.
+// status, when not nil, is sent as the STATUS= line shown by systemctl.
+func RunWatchdog(ctx context.Context, timeout time.Duration, healthy func() bool, status func() string, logger *log.Logger) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+
+	stalled := false
+	for {
+		select {
+		case <-ticker.C:
+			if !healthy() {
+				if !stalled {
+					logger.Printf("🐕 Control loop stalled, withholding systemd watchdog keepalives")
+					stalled = true
+				}
+				continue
+			}
+			stalled = false
+
+			state := "WATCHDOG=1"
+			if status != nil {
+				state += "\nSTATUS=" + status()
+			}
+			if _, err := Notify(state); err != nil {
+				logger.Printf("⚠️  %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/power"
+)
+
+// ANSI styles of the dashboard
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleYellow  = "\x1b[33m"
+)
+
+// blocks are the eighths of a chart cell, from empty to full
+var blocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// chartLabelWidth is the width of the axis labels left of the chart
+const chartLabelWidth = 10
+
+// render returns the lines of the dashboard for a terminal of the given size
+func render(s snapshot, admin bool, width, height int, interval time.Duration) []string {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	title := fmt.Sprintf(" PowerCap │ %s │ %s │ %s ", s.status.Node, s.status.Version, s.refreshedAt.Format("15:04:05"))
+	add("%s%s%s%s", styleReverse, styleBold, pad(title, width), styleReset)
+	add("")
+
+	if s.err != nil {
+		add(" %s%v%s", styleRed, s.err, styleReset)
+		return finish(lines, width, height, interval)
+	}
+
+	status := s.status
+	decision := status.LastDecision
+	measured := "unknown"
+	if status.MeasuredUW > 0 {
+		measured = watts(status.MeasuredUW)
+	}
+	clamp := "none yet"
+	if decision != nil {
+		clamp = decision.Clamp
+	}
+	add(" %sCap%s       %s%s%s (clamp %s)   %sMeasured%s  %s",
+		styleBold, styleReset, styleGreen, watts(status.AppliedCapUW), styleReset, clamp, styleBold, styleReset, measured)
+
+	if decision != nil {
+		add(" %sPeriod%s    %s   volume %.1f / %.1f   price %.2f %s   hardware max %s",
+			styleBold, styleReset, decision.Period, decision.Volume, decision.ReferenceVolume,
+			decision.Price, datastore.PriceUnit(decision.Currency), watts(decision.HardwareMax))
+	}
+
+	if o := status.Override; o != nil {
+		add(" %sOverride%s  %s%s by %s until %s%s", styleBold, styleReset, styleYellow,
+			overridePower(o), o.SetBy, o.ExpiresAt.Format("15:04"), styleReset)
+	}
+
+	data := fmt.Sprintf("%d points", status.Data.Points)
+	if status.Data.Age != "" {
+		data += ", loaded " + status.Data.Age + " ago"
+	}
+	add(" %sData%s      %s   %sProvider%s %s", styleBold, styleReset, data, styleBold, styleReset, providerHealth(status))
+	add("")
+
+	switch {
+	case !admin:
+		add(" %sSchedule and decisions need an admin API token (--token or ADMIN_API_TOKENS)%s", styleDim, styleReset)
+	case s.adminErr != nil:
+		add(" %s%v%s", styleRed, s.adminErr, styleReset)
+	default:
+		// Keep at least a few decision rows below the chart
+		chartHeight := height - len(lines) - 8
+		if chartHeight > 10 {
+			chartHeight = 10
+		}
+		if chartHeight >= 3 && len(s.schedule) > 0 {
+			add(" %sToday's cap%s", styleBold, styleReset)
+			current := ""
+			if decision != nil {
+				current = decision.Period
+			}
+			lines = append(lines, chart(s.schedule, current, width, chartHeight)...)
+			add("")
+		}
+		lines = append(lines, decisionTable(s.decisions, height-len(lines)-2)...)
+	}
+	return finish(lines, width, height, interval)
+}
+
+// finish fits the lines to the terminal and appends the key help
+func finish(lines []string, width, height int, interval time.Duration) []string {
+	if len(lines) > height-1 {
+		lines = lines[:height-1]
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, fmt.Sprintf("%s q quit  r refresh  (every %v)%s", styleDim, interval, styleReset))
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	return lines
+}
+
+// chart draws the cap of every period as columns of block characters,
+// highlighting the current period
+func chart(schedule []power.PlannedCap, current string, width, height int) []string {
+	plotWidth := width - chartLabelWidth - 1
+	if plotWidth < 8 {
+		return nil
+	}
+
+	// Each column shows the average cap of the periods it covers
+	columns := plotWidth
+	if len(schedule) < columns {
+		columns = len(schedule) * (plotWidth / len(schedule))
+	}
+	values := make([]float64, columns)
+	highlight := make([]bool, columns)
+	var maxValue float64
+	for c := 0; c < columns; c++ {
+		first := c * len(schedule) / columns
+		last := (c + 1) * len(schedule) / columns
+		if last <= first {
+			last = first + 1
+		}
+		var sum float64
+		for _, planned := range schedule[first:last] {
+			sum += float64(planned.CapUW)
+			if planned.Period == current {
+				highlight[c] = true
+			}
+		}
+		values[c] = sum / float64(last-first)
+		maxValue = math.Max(maxValue, values[c])
+	}
+	if maxValue <= 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, height+2)
+	for row := height - 1; row >= 0; row-- {
+		label := strings.Repeat(" ", chartLabelWidth-1)
+		switch row {
+		case height - 1:
+			label = fmt.Sprintf("%*s", chartLabelWidth-1, watts(int64(maxValue)))
+		case 0:
+			label = fmt.Sprintf("%*s", chartLabelWidth-1, "0 W")
+		}
+
+		var b strings.Builder
+		b.WriteString(label + "│")
+		for c, value := range values {
+			eighths := int(math.Round(value / maxValue * float64(height*8)))
+			cell := blocks[0]
+			switch {
+			case eighths >= (row+1)*8:
+				cell = blocks[8]
+			case eighths > row*8:
+				cell = blocks[eighths-row*8]
+			}
+			if highlight[c] {
+				b.WriteString(styleYellow + string(cell) + styleReset)
+			} else {
+				b.WriteString(styleGreen + string(cell) + styleReset)
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	lines = append(lines, strings.Repeat(" ", chartLabelWidth-1)+"└"+strings.Repeat("─", columns))
+
+	// Start times of the periods at each quarter of the axis
+	axis := []rune(strings.Repeat(" ", chartLabelWidth+columns+5))
+	for quarter := 0; quarter < 4; quarter++ {
+		c := quarter * columns / 4
+		start, _, _ := strings.Cut(schedule[c*len(schedule)/columns].Period, "-")
+		copy(axis[chartLabelWidth+c:], []rune(start))
+	}
+	lines = append(lines, strings.TrimRight(string(axis), " "))
+	return lines
+}
+
+// decisionTable lists the most recent decisions first, within rows lines
+func decisionTable(decisions []power.PowerDecision, rows int) []string {
+	if rows < 2 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf(" %s%-9s %-13s %10s %10s %10s  %s%s",
+		styleBold, "TIME", "PERIOD", "VOLUME", "SOURCE", "APPLIED", "CLAMP", styleReset)}
+	if len(decisions) == 0 {
+		return append(lines, " no decisions yet")
+	}
+	for i := len(decisions) - 1; i >= 0 && len(lines) < rows; i-- {
+		d := decisions[i]
+		lines = append(lines, fmt.Sprintf(" %-9s %-13s %10.1f %10s %10s  %s",
+			d.Timestamp.Format("15:04:05"), d.Period, d.Volume, watts(d.SourcePower), watts(d.AppliedPower), d.Clamp))
+	}
+	return lines
+}
+
+// providerHealth summarizes the recent provider fetches
+func providerHealth(status power.Status) string {
+	p := status.Provider
+	switch {
+	case p.LastAttempt.IsZero():
+		return p.Provider + ", no fetch yet"
+	case p.ConsecutiveFailures > 0:
+		return fmt.Sprintf("%s%s FAILING (%d): %s%s", styleRed, p.Provider, p.ConsecutiveFailures, p.LastError, styleReset)
+	default:
+		return fmt.Sprintf("%s ok, last fetch %s", p.Provider, p.LastSuccess.Format("15:04"))
+	}
+}
+
+// overridePower describes the power pinned by an override
+func overridePower(o *power.Override) string {
+	if o.Disable {
+		return "capping disabled"
+	}
+	return watts(o.PowerUW)
+}
+
+// watts formats a power in µW as watts
+func watts(uw int64) string {
+	return fmt.Sprintf("%.1f W", float64(uw)/1000000)
+}
+
+// pad extends s with spaces to width columns
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// truncate cuts a line to width visible columns, keeping ANSI sequences
+// intact and resetting the style when cut
+func truncate(line string, width int) string {
+	visible := 0
+	inEscape := false
+	for i, r := range line {
+		switch {
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		case r == 0x1b:
+			inEscape = true
+		default:
+			if visible == width {
+				return line[:i] + styleReset
+			}
+			visible++
+		}
+	}
+	return line
+}
@@ -0,0 +1,147 @@
+// Package tui shows a live dashboard of a running power manager in the
+// terminal: applied cap, measured power, the cap curve of the day and the
+// recent decisions, read from its HTTP API.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"kcas/new/internal/api"
+	"kcas/new/internal/power"
+)
+
+// ANSI sequences driving the terminal
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // Alternate screen, hidden cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// decisionLimit is the number of recent decisions requested
+const decisionLimit = 50
+
+// snapshot is the state of the manager at one refresh
+type snapshot struct {
+	status      power.Status
+	schedule    []power.PlannedCap
+	decisions   []power.PowerDecision
+	err         error // The status could not be read
+	adminErr    error // The schedule and decisions could not be read
+	refreshedAt time.Time
+}
+
+// Dashboard refreshes and draws the state of a manager
+type Dashboard struct {
+	client   *api.Client
+	admin    bool // A token was given for the admin API
+	interval time.Duration
+	in       *os.File
+	out      io.Writer
+}
+
+// NewDashboard creates a dashboard of the manager reached by client,
+// refreshed every interval. With admin set the schedule and the decision
+// history are read from the admin API.
+func NewDashboard(client *api.Client, admin bool, interval time.Duration) *Dashboard {
+	return &Dashboard{client: client, admin: admin, interval: interval, in: os.Stdin, out: os.Stdout}
+}
+
+// Run draws the dashboard until ctx is done or q, Esc or Ctrl-C is pressed;
+// r refreshes immediately
+func (d *Dashboard) Run(ctx context.Context) error {
+	fd := int(d.in.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("the dashboard needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set the terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+	fmt.Fprint(d.out, enterScreen)
+	defer fmt.Fprint(d.out, leaveScreen)
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := d.in.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			for _, key := range buf[:n] {
+				keys <- key
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+
+	current := d.fetch()
+	width, height := d.size()
+	d.draw(current, width, height)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			switch {
+			case !ok || key == 'q' || key == 'Q' || key == 0x1b || key == 0x03:
+				return nil
+			case key == 'r' || key == 'R':
+				current = d.fetch()
+				d.draw(current, width, height)
+			}
+		case <-ticker.C:
+			current = d.fetch()
+			d.draw(current, width, height)
+		case <-resize.C:
+			if w, h := d.size(); w != width || h != height {
+				width, height = w, h
+				d.draw(current, width, height)
+			}
+		}
+	}
+}
+
+// fetch reads the status, and the schedule and decisions when admin
+func (d *Dashboard) fetch() snapshot {
+	s := snapshot{refreshedAt: time.Now()}
+	if s.err = d.client.GetJSON("/status", &s.status); s.err != nil {
+		return s
+	}
+	if !d.admin {
+		return s
+	}
+	if s.adminErr = d.client.GetJSON("/api/v1/schedule", &s.schedule); s.adminErr != nil {
+		return s
+	}
+	s.adminErr = d.client.GetJSON(fmt.Sprintf("/api/v1/decisions?limit=%d", decisionLimit), &s.decisions)
+	return s
+}
+
+// size returns the terminal size, 80×24 if unknown
+func (d *Dashboard) size() (int, int) {
+	width, height, err := term.GetSize(int(d.in.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw renders a snapshot; raw mode needs explicit carriage returns
+func (d *Dashboard) draw(s snapshot, width, height int) {
+	lines := render(s, d.admin, width, height, d.interval)
+	fmt.Fprint(d.out, clearScreen+strings.Join(lines, "\r\n"))
+}
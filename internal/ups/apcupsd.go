@@ -0,0 +1,80 @@
+package ups
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApcupsdSource reads a UPS from the network information server of apcupsd
+type ApcupsdSource struct {
+	addr string
+}
+
+// Status sends the "status" request and parses the STATUS and BCHARGE records
+func (s *ApcupsdSource) Status(ctx context.Context) (Status, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to connect to apcupsd at %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	// Messages are prefixed with their length as a 16-bit big-endian integer
+	request := []byte{0, 6, 's', 't', 'a', 't', 'u', 's'}
+	if _, err := conn.Write(request); err != nil {
+		return Status{}, fmt.Errorf("failed to send to apcupsd: %w", err)
+	}
+
+	records := make(map[string]string)
+	var size [2]byte
+	for {
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return Status{}, fmt.Errorf("failed to read from apcupsd: %w", err)
+		}
+		n := binary.BigEndian.Uint16(size[:])
+		if n == 0 {
+			break // End of the status report
+		}
+		line := make([]byte, n)
+		if _, err := io.ReadFull(conn, line); err != nil {
+			return Status{}, fmt.Errorf("failed to read from apcupsd: %w", err)
+		}
+		// Records look like "STATUS   : ONBATT"
+		if key, value, found := strings.Cut(string(line), ":"); found {
+			records[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	raw, ok := records["STATUS"]
+	if !ok {
+		return Status{}, fmt.Errorf("apcupsd report has no STATUS record")
+	}
+	status := Status{Raw: raw, Charge: -1}
+	for _, flag := range strings.Fields(raw) {
+		switch flag {
+		case "ONBATT":
+			status.OnBattery = true
+		case "LOWBATT":
+			status.LowBattery = true
+		}
+	}
+	// e.g. "BCHARGE  : 100.0 Percent"
+	if fields := strings.Fields(records["BCHARGE"]); len(fields) > 0 {
+		if charge, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			status.Charge = charge
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,113 @@
+package ups
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NUTSource reads a UPS from the upsd daemon of Network UPS Tools
+type NUTSource struct {
+	addr     string
+	ups      string
+	username string
+	password string
+}
+
+// Status reads ups.status and battery.charge
+func (s *NUTSource) Status(ctx context.Context) (Status, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to connect to upsd at %s: %w", s.addr, err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(dialTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	session := &nutSession{conn: conn, reader: bufio.NewReader(conn)}
+	if s.username != "" {
+		if _, err := session.command("USERNAME " + s.username); err != nil {
+			return Status{}, err
+		}
+		if _, err := session.command("PASSWORD " + s.password); err != nil {
+			return Status{}, err
+		}
+	}
+
+	raw, err := session.variable(s.ups, "ups.status")
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Raw: raw, Charge: -1}
+	for _, flag := range strings.Fields(raw) {
+		switch flag {
+		case "OB":
+			status.OnBattery = true
+		case "LB":
+			status.LowBattery = true
+		}
+	}
+
+	// battery.charge is optional
+	if charge, err := session.variable(s.ups, "battery.charge"); err == nil {
+		if value, err := strconv.ParseFloat(charge, 64); err == nil {
+			status.Charge = value
+		}
+	}
+
+	session.command("LOGOUT")
+	return status, nil
+}
+
+// nutSession exchanges line-based commands with upsd
+type nutSession struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// command sends a command and returns its one-line answer, or an error for
+// an "ERR" answer
+func (s *nutSession) command(line string) (string, error) {
+	if _, err := fmt.Fprintf(s.conn, "%s\n", line); err != nil {
+		return "", fmt.Errorf("failed to send to upsd: %w", err)
+	}
+	answer, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read from upsd: %w", err)
+	}
+	answer = strings.TrimRight(answer, "\r\n")
+	if strings.HasPrefix(answer, "ERR ") {
+		command := line
+		if strings.HasPrefix(command, "PASSWORD ") {
+			command = "PASSWORD"
+		}
+		return "", fmt.Errorf("upsd rejected %q: %s", command, strings.TrimPrefix(answer, "ERR "))
+	}
+	return answer, nil
+}
+
+// variable reads a variable of a UPS, answered as: VAR <ups> <name> "<value>"
+func (s *nutSession) variable(ups, name string) (string, error) {
+	answer, err := s.command("GET VAR " + ups + " " + name)
+	if err != nil {
+		return "", err
+	}
+	prefix := "VAR " + ups + " " + name + " "
+	if !strings.HasPrefix(answer, prefix) {
+		return "", fmt.Errorf("unexpected upsd answer %q", answer)
+	}
+	value, err := strconv.Unquote(strings.TrimPrefix(answer, prefix))
+	if err != nil {
+		return "", fmt.Errorf("unexpected upsd value in %q", answer)
+	}
+	return value, nil
+}
@@ -0,0 +1,133 @@
+// Package ups monitors an uninterruptible power supply through Network UPS
+// Tools (upsd) or apcupsd, to detect when the node runs on battery.
+package ups
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default ports of the UPS daemons
+const (
+	DefaultNUTPort     = "3493"
+	DefaultApcupsdPort = "3551"
+)
+
+// dialTimeout bounds the connection and exchange with the UPS daemon
+const dialTimeout = 5 * time.Second
+
+// Status is the state of the UPS
+type Status struct {
+	OnBattery  bool    // Running on battery, mains power lost
+	LowBattery bool    // Battery charge below the UPS low threshold
+	Charge     float64 // Battery charge in %, -1 if unknown
+	Raw        string  // Status reported by the daemon, e.g. "OB DISCHRG" or "ONBATT"
+}
+
+// Source reads the status of a UPS
+type Source interface {
+	Status(ctx context.Context) (Status, error)
+}
+
+// NewSource creates the source of a UPS address: nut://[user:pass@]host[:port]/ups
+// for Network UPS Tools or apcupsd://host[:port] for apcupsd
+func NewSource(addr string) (Source, error) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid UPS address %q, expected nut://host/ups or apcupsd://host", addr)
+	}
+
+	switch u.Scheme {
+	case "nut":
+		name := strings.Trim(u.Path, "/")
+		if name == "" {
+			return nil, fmt.Errorf("missing UPS name in %q, expected nut://host/ups", addr)
+		}
+		source := &NUTSource{addr: hostPort(u, DefaultNUTPort), ups: name}
+		if u.User != nil {
+			source.username = u.User.Username()
+			source.password, _ = u.User.Password()
+		}
+		return source, nil
+	case "apcupsd":
+		return &ApcupsdSource{addr: hostPort(u, DefaultApcupsdPort)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported UPS scheme %q, expected nut or apcupsd", u.Scheme)
+	}
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// Monitor polls a UPS and reports every change between mains and battery
+type Monitor struct {
+	source   Source
+	interval time.Duration
+	onChange func(onBattery bool, status string)
+	logger   *log.Logger
+}
+
+// NewMonitor creates a monitor calling onChange with the first status read
+// and then on every switch between mains and battery
+func NewMonitor(source Source, interval time.Duration, onChange func(onBattery bool, status string), logger *log.Logger) *Monitor {
+	return &Monitor{source: source, interval: interval, onChange: onChange, logger: logger}
+}
+
+// Run polls the UPS until the context is cancelled. While the UPS cannot be
+// read the last known state is kept.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	known, onBattery, failing := false, false, false
+	for {
+		poll, cancel := context.WithTimeout(ctx, dialTimeout)
+		status, err := m.source.Status(poll)
+		cancel()
+
+		switch {
+		case err != nil:
+			if !failing {
+				m.logger.Printf("⚠️  Failed to read UPS status: %v", err)
+				failing = true
+			}
+		default:
+			if failing {
+				m.logger.Printf("✅ UPS status readable again: %s", status.Raw)
+				failing = false
+			}
+			if !known || status.OnBattery != onBattery {
+				known, onBattery = true, status.OnBattery
+				m.onChange(onBattery, describe(status))
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// describe summarizes a status for logs and events
+func describe(status Status) string {
+	text := status.Raw
+	if status.Charge >= 0 {
+		text += fmt.Sprintf(", battery %.0f%%", status.Charge)
+	}
+	if status.LowBattery {
+		text += ", low battery"
+	}
+	return text
+}
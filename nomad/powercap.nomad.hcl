@@ -0,0 +1,44 @@
+# PowerCap Manager as a Nomad system job: one instance per client node,
+# publishing its state as dynamic node metadata (rapl.pmax, rapl.state, ...).
+#
+# The ACL token needs node:write, e.g. a policy with:
+#   node { policy = "write" }
+job "powercap-manager" {
+  type = "system"
+
+  group "powercap" {
+    task "powercap" {
+      driver       = "docker"
+      kill_timeout = "30s" # Leaves time for the shutdown steps (SHUTDOWN_TIMEOUT) after SIGTERM
+
+      config {
+        image        = "powercap:latest"
+        privileged   = true # Required for RAPL access
+        network_mode = "host"
+        volumes = [
+          "/sys/devices/virtual/powercap:/sys/devices/virtual/powercap",
+          "/var/lib/powercap:/app/data",
+        ]
+      }
+
+      env {
+        ORCHESTRATOR = "nomad"
+        NODE_NAME    = "${node.unique.name}"
+        NOMAD_ADDR   = "http://${attr.unique.network.ip-address}:4646"
+        DATA_DIR     = "/app/data"
+      }
+
+      # NOMAD_TOKEN from a Nomad variable (or Vault)
+      template {
+        data        = "NOMAD_TOKEN={{ with nomadVar \"nomad/jobs/powercap-manager\" }}{{ .token }}{{ end }}"
+        destination = "secrets/nomad.env"
+        env         = true
+      }
+
+      resources {
+        cpu    = 50
+        memory = 64
+      }
+    }
+  }
+}
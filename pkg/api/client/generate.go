@@ -0,0 +1,5 @@
+// Package client is a Go client of the PowerCap manager HTTP API, generated
+// from pkg/api/openapi.yaml.
+package client
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.4.1 -config oapi-codegen.yaml ../openapi.yaml
@@ -0,0 +1,1209 @@
+// Control API of the power manager, served on GRPC_ADDR.
+//
+// Generate the Go code in pkg/api/powercapv1 with:
+//   protoc -I proto --go_out=. --go_opt=module=kcas/new \
+//          --go-grpc_out=. --go-grpc_opt=module=kcas/new \
+//          powercap/v1/powercap.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.28.3
+// source: powercap/v1/powercap.proto
+
+package powercapv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{0}
+}
+
+// Status is a snapshot of the manager state
+type Status struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	AppliedCapUw  int64                  `protobuf:"varint,4,opt,name=applied_cap_uw,json=appliedCapUw,proto3" json:"applied_cap_uw,omitempty"`
+	LastDecision  *Decision              `protobuf:"bytes,5,opt,name=last_decision,json=lastDecision,proto3" json:"last_decision,omitempty"`
+	Override      *Override              `protobuf:"bytes,6,opt,name=override,proto3" json:"override,omitempty"`
+	DataPoints    int32                  `protobuf:"varint,7,opt,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	DataUpdatedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=data_updated_at,json=dataUpdatedAt,proto3" json:"data_updated_at,omitempty"`
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Status) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *Status) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Status) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Status) GetAppliedCapUw() int64 {
+	if x != nil {
+		return x.AppliedCapUw
+	}
+	return 0
+}
+
+func (x *Status) GetLastDecision() *Decision {
+	if x != nil {
+		return x.LastDecision
+	}
+	return nil
+}
+
+func (x *Status) GetOverride() *Override {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+func (x *Status) GetDataPoints() int32 {
+	if x != nil {
+		return x.DataPoints
+	}
+	return 0
+}
+
+func (x *Status) GetDataUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataUpdatedAt
+	}
+	return nil
+}
+
+// Decision records the inputs and outcome of one adjustment cycle
+type Decision struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Node               string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	Provider           string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	Period             string                 `protobuf:"bytes,4,opt,name=period,proto3" json:"period,omitempty"`
+	PeriodFound        bool                   `protobuf:"varint,5,opt,name=period_found,json=periodFound,proto3" json:"period_found,omitempty"`
+	VolumeMwh          float64                `protobuf:"fixed64,6,opt,name=volume_mwh,json=volumeMwh,proto3" json:"volume_mwh,omitempty"`
+	PriceEurMwh        float64                `protobuf:"fixed64,7,opt,name=price_eur_mwh,json=priceEurMwh,proto3" json:"price_eur_mwh,omitempty"`
+	ReferenceVolumeMwh float64                `protobuf:"fixed64,8,opt,name=reference_volume_mwh,json=referenceVolumeMwh,proto3" json:"reference_volume_mwh,omitempty"`
+	HardwareMaxUw      int64                  `protobuf:"varint,9,opt,name=hardware_max_uw,json=hardwareMaxUw,proto3" json:"hardware_max_uw,omitempty"`
+	AdminMaxUw         int64                  `protobuf:"varint,10,opt,name=admin_max_uw,json=adminMaxUw,proto3" json:"admin_max_uw,omitempty"`
+	MinPowerUw         int64                  `protobuf:"varint,11,opt,name=min_power_uw,json=minPowerUw,proto3" json:"min_power_uw,omitempty"`
+	SourcePowerUw      int64                  `protobuf:"varint,12,opt,name=source_power_uw,json=sourcePowerUw,proto3" json:"source_power_uw,omitempty"`
+	AppliedPowerUw     int64                  `protobuf:"varint,13,opt,name=applied_power_uw,json=appliedPowerUw,proto3" json:"applied_power_uw,omitempty"`
+	Clamp              string                 `protobuf:"bytes,14,opt,name=clamp,proto3" json:"clamp,omitempty"`
+	Fallbacks          []string               `protobuf:"bytes,15,rep,name=fallbacks,proto3" json:"fallbacks,omitempty"`
+	Override           *Override              `protobuf:"bytes,16,opt,name=override,proto3" json:"override,omitempty"`
+}
+
+func (x *Decision) Reset() {
+	*x = Decision{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Decision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Decision) ProtoMessage() {}
+
+func (x *Decision) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Decision.ProtoReflect.Descriptor instead.
+func (*Decision) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Decision) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Decision) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *Decision) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Decision) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Decision) GetPeriodFound() bool {
+	if x != nil {
+		return x.PeriodFound
+	}
+	return false
+}
+
+func (x *Decision) GetVolumeMwh() float64 {
+	if x != nil {
+		return x.VolumeMwh
+	}
+	return 0
+}
+
+func (x *Decision) GetPriceEurMwh() float64 {
+	if x != nil {
+		return x.PriceEurMwh
+	}
+	return 0
+}
+
+func (x *Decision) GetReferenceVolumeMwh() float64 {
+	if x != nil {
+		return x.ReferenceVolumeMwh
+	}
+	return 0
+}
+
+func (x *Decision) GetHardwareMaxUw() int64 {
+	if x != nil {
+		return x.HardwareMaxUw
+	}
+	return 0
+}
+
+func (x *Decision) GetAdminMaxUw() int64 {
+	if x != nil {
+		return x.AdminMaxUw
+	}
+	return 0
+}
+
+func (x *Decision) GetMinPowerUw() int64 {
+	if x != nil {
+		return x.MinPowerUw
+	}
+	return 0
+}
+
+func (x *Decision) GetSourcePowerUw() int64 {
+	if x != nil {
+		return x.SourcePowerUw
+	}
+	return 0
+}
+
+func (x *Decision) GetAppliedPowerUw() int64 {
+	if x != nil {
+		return x.AppliedPowerUw
+	}
+	return 0
+}
+
+func (x *Decision) GetClamp() string {
+	if x != nil {
+		return x.Clamp
+	}
+	return ""
+}
+
+func (x *Decision) GetFallbacks() []string {
+	if x != nil {
+		return x.Fallbacks
+	}
+	return nil
+}
+
+func (x *Decision) GetOverride() *Override {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+type GetScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetScheduleRequest) Reset() {
+	*x = GetScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScheduleRequest) ProtoMessage() {}
+
+func (x *GetScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScheduleRequest.ProtoReflect.Descriptor instead.
+func (*GetScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{3}
+}
+
+// Schedule is the cap of every period of the current day
+type Schedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Periods []*PlannedCap `protobuf:"bytes,1,rep,name=periods,proto3" json:"periods,omitempty"`
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Schedule) GetPeriods() []*PlannedCap {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+// PlannedCap is the cap computed for one market period
+type PlannedCap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period        string  `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	VolumeMwh     float64 `protobuf:"fixed64,2,opt,name=volume_mwh,json=volumeMwh,proto3" json:"volume_mwh,omitempty"`
+	PriceEurMwh   float64 `protobuf:"fixed64,3,opt,name=price_eur_mwh,json=priceEurMwh,proto3" json:"price_eur_mwh,omitempty"`
+	SourcePowerUw int64   `protobuf:"varint,4,opt,name=source_power_uw,json=sourcePowerUw,proto3" json:"source_power_uw,omitempty"`
+	CapUw         int64   `protobuf:"varint,5,opt,name=cap_uw,json=capUw,proto3" json:"cap_uw,omitempty"`
+	Clamp         string  `protobuf:"bytes,6,opt,name=clamp,proto3" json:"clamp,omitempty"`
+}
+
+func (x *PlannedCap) Reset() {
+	*x = PlannedCap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlannedCap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlannedCap) ProtoMessage() {}
+
+func (x *PlannedCap) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlannedCap.ProtoReflect.Descriptor instead.
+func (*PlannedCap) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PlannedCap) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *PlannedCap) GetVolumeMwh() float64 {
+	if x != nil {
+		return x.VolumeMwh
+	}
+	return 0
+}
+
+func (x *PlannedCap) GetPriceEurMwh() float64 {
+	if x != nil {
+		return x.PriceEurMwh
+	}
+	return 0
+}
+
+func (x *PlannedCap) GetSourcePowerUw() int64 {
+	if x != nil {
+		return x.SourcePowerUw
+	}
+	return 0
+}
+
+func (x *PlannedCap) GetCapUw() int64 {
+	if x != nil {
+		return x.CapUw
+	}
+	return 0
+}
+
+func (x *PlannedCap) GetClamp() string {
+	if x != nil {
+		return x.Clamp
+	}
+	return ""
+}
+
+type SetOverrideRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PowerUw  int64                `protobuf:"varint,1,opt,name=power_uw,json=powerUw,proto3" json:"power_uw,omitempty"`
+	Duration *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason   string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Disable capping (apply the hardware maximum) instead of pinning power_uw
+	Disable bool `protobuf:"varint,4,opt,name=disable,proto3" json:"disable,omitempty"`
+}
+
+func (x *SetOverrideRequest) Reset() {
+	*x = SetOverrideRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOverrideRequest) ProtoMessage() {}
+
+func (x *SetOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOverrideRequest.ProtoReflect.Descriptor instead.
+func (*SetOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetOverrideRequest) GetPowerUw() int64 {
+	if x != nil {
+		return x.PowerUw
+	}
+	return 0
+}
+
+func (x *SetOverrideRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *SetOverrideRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SetOverrideRequest) GetDisable() bool {
+	if x != nil {
+		return x.Disable
+	}
+	return false
+}
+
+type ClearOverrideRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClearOverrideRequest) Reset() {
+	*x = ClearOverrideRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOverrideRequest) ProtoMessage() {}
+
+func (x *ClearOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOverrideRequest.ProtoReflect.Descriptor instead.
+func (*ClearOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{7}
+}
+
+// Override pins the power cap to a fixed value, or disables capping, until it
+// expires
+type Override struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PowerUw   int64                  `protobuf:"varint,1,opt,name=power_uw,json=powerUw,proto3" json:"power_uw,omitempty"`
+	Reason    string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	SetBy     string                 `protobuf:"bytes,3,opt,name=set_by,json=setBy,proto3" json:"set_by,omitempty"`
+	SetAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=set_at,json=setAt,proto3" json:"set_at,omitempty"`
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Disable   bool                   `protobuf:"varint,6,opt,name=disable,proto3" json:"disable,omitempty"`
+}
+
+func (x *Override) Reset() {
+	*x = Override{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Override) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Override) ProtoMessage() {}
+
+func (x *Override) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Override.ProtoReflect.Descriptor instead.
+func (*Override) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Override) GetPowerUw() int64 {
+	if x != nil {
+		return x.PowerUw
+	}
+	return 0
+}
+
+func (x *Override) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Override) GetSetBy() string {
+	if x != nil {
+		return x.SetBy
+	}
+	return ""
+}
+
+func (x *Override) GetSetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SetAt
+	}
+	return nil
+}
+
+func (x *Override) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Override) GetDisable() bool {
+	if x != nil {
+		return x.Disable
+	}
+	return false
+}
+
+type TriggerRefreshRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TriggerRefreshRequest) Reset() {
+	*x = TriggerRefreshRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TriggerRefreshRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerRefreshRequest) ProtoMessage() {}
+
+func (x *TriggerRefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerRefreshRequest.ProtoReflect.Descriptor instead.
+func (*TriggerRefreshRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{9}
+}
+
+type TriggerRefreshResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TriggerRefreshResponse) Reset() {
+	*x = TriggerRefreshResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TriggerRefreshResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerRefreshResponse) ProtoMessage() {}
+
+func (x *TriggerRefreshResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerRefreshResponse.ProtoReflect.Descriptor instead.
+func (*TriggerRefreshResponse) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{10}
+}
+
+type StreamDecisionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StreamDecisionsRequest) Reset() {
+	*x = StreamDecisionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_powercap_v1_powercap_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamDecisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDecisionsRequest) ProtoMessage() {}
+
+func (x *StreamDecisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_powercap_v1_powercap_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDecisionsRequest.ProtoReflect.Descriptor instead.
+func (*StreamDecisionsRequest) Descriptor() ([]byte, []int) {
+	return file_powercap_v1_powercap_proto_rawDescGZIP(), []int{11}
+}
+
+var File_powercap_v1_powercap_proto protoreflect.FileDescriptor
+
+var file_powercap_v1_powercap_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x6f,
+	0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x6f,
+	0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xeb,
+	0x02, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x5f, 0x63, 0x61,
+	0x70, 0x5f, 0x75, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x61, 0x70, 0x70, 0x6c,
+	0x69, 0x65, 0x64, 0x43, 0x61, 0x70, 0x55, 0x77, 0x12, 0x3a, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x44, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x08, 0x6f,
+	0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x61,
+	0x74, 0x61, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x42, 0x0a, 0x0f, 0x64, 0x61, 0x74, 0x61,
+	0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x64,
+	0x61, 0x74, 0x61, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xc9, 0x04, 0x0a,
+	0x08, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x6d, 0x77, 0x68, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x4d, 0x77, 0x68, 0x12, 0x22, 0x0a,
+	0x0d, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x65, 0x75, 0x72, 0x5f, 0x6d, 0x77, 0x68, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x70, 0x72, 0x69, 0x63, 0x65, 0x45, 0x75, 0x72, 0x4d, 0x77,
+	0x68, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x6d, 0x77, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x12, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x4d, 0x77, 0x68, 0x12, 0x26, 0x0a, 0x0f, 0x68, 0x61, 0x72, 0x64, 0x77, 0x61, 0x72, 0x65, 0x5f,
+	0x6d, 0x61, 0x78, 0x5f, 0x75, 0x77, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x68, 0x61,
+	0x72, 0x64, 0x77, 0x61, 0x72, 0x65, 0x4d, 0x61, 0x78, 0x55, 0x77, 0x12, 0x20, 0x0a, 0x0c, 0x61,
+	0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x75, 0x77, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x4d, 0x61, 0x78, 0x55, 0x77, 0x12, 0x20, 0x0a,
+	0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x77, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x6d, 0x69, 0x6e, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x77, 0x12,
+	0x26, 0x0a, 0x0f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f,
+	0x75, 0x77, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x50, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x77, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x70, 0x70, 0x6c, 0x69,
+	0x65, 0x64, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x77, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0e, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x55,
+	0x77, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x6d, 0x70, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x63, 0x6c, 0x61, 0x6d, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x61, 0x6c, 0x6c, 0x62,
+	0x61, 0x63, 0x6b, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x66, 0x61, 0x6c, 0x6c,
+	0x62, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x31, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64,
+	0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63,
+	0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x08,
+	0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x22, 0x14, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x53,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3d,
+	0x0a, 0x08, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x6f,
+	0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x6e, 0x6e, 0x65,
+	0x64, 0x43, 0x61, 0x70, 0x52, 0x07, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x73, 0x22, 0xbc, 0x01,
+	0x0a, 0x0a, 0x50, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x43, 0x61, 0x70, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x6d,
+	0x77, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x4d, 0x77, 0x68, 0x12, 0x22, 0x0a, 0x0d, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x65, 0x75, 0x72,
+	0x5f, 0x6d, 0x77, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x70, 0x72, 0x69, 0x63,
+	0x65, 0x45, 0x75, 0x72, 0x4d, 0x77, 0x68, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x77, 0x12,
+	0x15, 0x0a, 0x06, 0x63, 0x61, 0x70, 0x5f, 0x75, 0x77, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x63, 0x61, 0x70, 0x55, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x6d, 0x70, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x6d, 0x70, 0x22, 0x98, 0x01, 0x0a,
+	0x12, 0x53, 0x65, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x77, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x77, 0x12, 0x35,
+	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x16, 0x0a, 0x14, 0x43, 0x6c, 0x65, 0x61, 0x72,
+	0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0xdc, 0x01, 0x0a, 0x08, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x19, 0x0a, 0x08,
+	0x70, 0x6f, 0x77, 0x65, 0x72, 0x5f, 0x75, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x70, 0x6f, 0x77, 0x65, 0x72, 0x55, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12,
+	0x15, 0x0a, 0x06, 0x73, 0x65, 0x74, 0x5f, 0x62, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x73, 0x65, 0x74, 0x42, 0x79, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x65, 0x74, 0x5f, 0x61, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x05, 0x73, 0x65, 0x74, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x73, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x17,
+	0x0a, 0x15, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x18, 0x0a, 0x16, 0x54, 0x72, 0x69, 0x67, 0x67,
+	0x65, 0x72, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x18, 0x0a, 0x16, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x32, 0xd0, 0x03, 0x0a, 0x08,
+	0x50, 0x6f, 0x77, 0x65, 0x72, 0x43, 0x61, 0x70, 0x12, 0x3f, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x45, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x1f, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72,
+	0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75,
+	0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65,
+	0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x12, 0x45, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12,
+	0x1f, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x49, 0x0a, 0x0d, 0x43, 0x6c, 0x65, 0x61, 0x72,
+	0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x21, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72,
+	0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x6f,
+	0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69,
+	0x64, 0x65, 0x12, 0x59, 0x0a, 0x0e, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x65, 0x66,
+	0x72, 0x65, 0x73, 0x68, 0x12, 0x22, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72,
+	0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x65,
+	0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a,
+	0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x23, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x30, 0x01, 0x42, 0x1d,
+	0x5a, 0x1b, 0x6b, 0x63, 0x61, 0x73, 0x2f, 0x6e, 0x65, 0x77, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x63, 0x61, 0x70, 0x76, 0x31, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_powercap_v1_powercap_proto_rawDescOnce sync.Once
+	file_powercap_v1_powercap_proto_rawDescData = file_powercap_v1_powercap_proto_rawDesc
+)
+
+func file_powercap_v1_powercap_proto_rawDescGZIP() []byte {
+	file_powercap_v1_powercap_proto_rawDescOnce.Do(func() {
+		file_powercap_v1_powercap_proto_rawDescData = protoimpl.X.CompressGZIP(file_powercap_v1_powercap_proto_rawDescData)
+	})
+	return file_powercap_v1_powercap_proto_rawDescData
+}
+
+var file_powercap_v1_powercap_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_powercap_v1_powercap_proto_goTypes = []any{
+	(*GetStatusRequest)(nil),       // 0: powercap.v1.GetStatusRequest
+	(*Status)(nil),                 // 1: powercap.v1.Status
+	(*Decision)(nil),               // 2: powercap.v1.Decision
+	(*GetScheduleRequest)(nil),     // 3: powercap.v1.GetScheduleRequest
+	(*Schedule)(nil),               // 4: powercap.v1.Schedule
+	(*PlannedCap)(nil),             // 5: powercap.v1.PlannedCap
+	(*SetOverrideRequest)(nil),     // 6: powercap.v1.SetOverrideRequest
+	(*ClearOverrideRequest)(nil),   // 7: powercap.v1.ClearOverrideRequest
+	(*Override)(nil),               // 8: powercap.v1.Override
+	(*TriggerRefreshRequest)(nil),  // 9: powercap.v1.TriggerRefreshRequest
+	(*TriggerRefreshResponse)(nil), // 10: powercap.v1.TriggerRefreshResponse
+	(*StreamDecisionsRequest)(nil), // 11: powercap.v1.StreamDecisionsRequest
+	(*timestamppb.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),    // 13: google.protobuf.Duration
+}
+var file_powercap_v1_powercap_proto_depIdxs = []int32{
+	12, // 0: powercap.v1.Status.started_at:type_name -> google.protobuf.Timestamp
+	2,  // 1: powercap.v1.Status.last_decision:type_name -> powercap.v1.Decision
+	8,  // 2: powercap.v1.Status.override:type_name -> powercap.v1.Override
+	12, // 3: powercap.v1.Status.data_updated_at:type_name -> google.protobuf.Timestamp
+	12, // 4: powercap.v1.Decision.timestamp:type_name -> google.protobuf.Timestamp
+	8,  // 5: powercap.v1.Decision.override:type_name -> powercap.v1.Override
+	5,  // 6: powercap.v1.Schedule.periods:type_name -> powercap.v1.PlannedCap
+	13, // 7: powercap.v1.SetOverrideRequest.duration:type_name -> google.protobuf.Duration
+	12, // 8: powercap.v1.Override.set_at:type_name -> google.protobuf.Timestamp
+	12, // 9: powercap.v1.Override.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: powercap.v1.PowerCap.GetStatus:input_type -> powercap.v1.GetStatusRequest
+	3,  // 11: powercap.v1.PowerCap.GetSchedule:input_type -> powercap.v1.GetScheduleRequest
+	6,  // 12: powercap.v1.PowerCap.SetOverride:input_type -> powercap.v1.SetOverrideRequest
+	7,  // 13: powercap.v1.PowerCap.ClearOverride:input_type -> powercap.v1.ClearOverrideRequest
+	9,  // 14: powercap.v1.PowerCap.TriggerRefresh:input_type -> powercap.v1.TriggerRefreshRequest
+	11, // 15: powercap.v1.PowerCap.StreamDecisions:input_type -> powercap.v1.StreamDecisionsRequest
+	1,  // 16: powercap.v1.PowerCap.GetStatus:output_type -> powercap.v1.Status
+	4,  // 17: powercap.v1.PowerCap.GetSchedule:output_type -> powercap.v1.Schedule
+	8,  // 18: powercap.v1.PowerCap.SetOverride:output_type -> powercap.v1.Override
+	8,  // 19: powercap.v1.PowerCap.ClearOverride:output_type -> powercap.v1.Override
+	10, // 20: powercap.v1.PowerCap.TriggerRefresh:output_type -> powercap.v1.TriggerRefreshResponse
+	2,  // 21: powercap.v1.PowerCap.StreamDecisions:output_type -> powercap.v1.Decision
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_powercap_v1_powercap_proto_init() }
+func file_powercap_v1_powercap_proto_init() {
+	if File_powercap_v1_powercap_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_powercap_v1_powercap_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Status); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Decision); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Schedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*PlannedCap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*SetOverrideRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ClearOverrideRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Override); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*TriggerRefreshRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*TriggerRefreshResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_powercap_v1_powercap_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamDecisionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_powercap_v1_powercap_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_powercap_v1_powercap_proto_goTypes,
+		DependencyIndexes: file_powercap_v1_powercap_proto_depIdxs,
+		MessageInfos:      file_powercap_v1_powercap_proto_msgTypes,
+	}.Build()
+	File_powercap_v1_powercap_proto = out.File
+	file_powercap_v1_powercap_proto_rawDesc = nil
+	file_powercap_v1_powercap_proto_goTypes = nil
+	file_powercap_v1_powercap_proto_depIdxs = nil
+}
@@ -0,0 +1,340 @@
+// Control API of the power manager, served on GRPC_ADDR.
+//
+// Generate the Go code in pkg/api/powercapv1 with:
+//   protoc -I proto --go_out=. --go_opt=module=kcas/new \
+//          --go-grpc_out=. --go-grpc_opt=module=kcas/new \
+//          powercap/v1/powercap.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: powercap/v1/powercap.proto
+
+package powercapv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PowerCap_GetStatus_FullMethodName       = "/powercap.v1.PowerCap/GetStatus"
+	PowerCap_GetSchedule_FullMethodName     = "/powercap.v1.PowerCap/GetSchedule"
+	PowerCap_SetOverride_FullMethodName     = "/powercap.v1.PowerCap/SetOverride"
+	PowerCap_ClearOverride_FullMethodName   = "/powercap.v1.PowerCap/ClearOverride"
+	PowerCap_TriggerRefresh_FullMethodName  = "/powercap.v1.PowerCap/TriggerRefresh"
+	PowerCap_StreamDecisions_FullMethodName = "/powercap.v1.PowerCap/StreamDecisions"
+)
+
+// PowerCapClient is the client API for PowerCap service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PowerCap controls the power manager of one node. Calls must carry an
+// "authorization: Bearer <token>" metadata entry with an ADMIN_API_TOKENS token.
+type PowerCapClient interface {
+	// GetStatus returns the current state of the manager
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// GetSchedule returns the cap of every period of the current day
+	GetSchedule(ctx context.Context, in *GetScheduleRequest, opts ...grpc.CallOption) (*Schedule, error)
+	// SetOverride pins the cap until the override expires
+	SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*Override, error)
+	// ClearOverride removes the active override
+	ClearOverride(ctx context.Context, in *ClearOverrideRequest, opts ...grpc.CallOption) (*Override, error)
+	// TriggerRefresh fetches today's market data again and re-adjusts the cap
+	TriggerRefresh(ctx context.Context, in *TriggerRefreshRequest, opts ...grpc.CallOption) (*TriggerRefreshResponse, error)
+	// StreamDecisions sends every decision as it is made
+	StreamDecisions(ctx context.Context, in *StreamDecisionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Decision], error)
+}
+
+type powerCapClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPowerCapClient(cc grpc.ClientConnInterface) PowerCapClient {
+	return &powerCapClient{cc}
+}
+
+func (c *powerCapClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, PowerCap_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerCapClient) GetSchedule(ctx context.Context, in *GetScheduleRequest, opts ...grpc.CallOption) (*Schedule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Schedule)
+	err := c.cc.Invoke(ctx, PowerCap_GetSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerCapClient) SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*Override, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Override)
+	err := c.cc.Invoke(ctx, PowerCap_SetOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerCapClient) ClearOverride(ctx context.Context, in *ClearOverrideRequest, opts ...grpc.CallOption) (*Override, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Override)
+	err := c.cc.Invoke(ctx, PowerCap_ClearOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerCapClient) TriggerRefresh(ctx context.Context, in *TriggerRefreshRequest, opts ...grpc.CallOption) (*TriggerRefreshResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerRefreshResponse)
+	err := c.cc.Invoke(ctx, PowerCap_TriggerRefresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerCapClient) StreamDecisions(ctx context.Context, in *StreamDecisionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Decision], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PowerCap_ServiceDesc.Streams[0], PowerCap_StreamDecisions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamDecisionsRequest, Decision]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PowerCap_StreamDecisionsClient = grpc.ServerStreamingClient[Decision]
+
+// PowerCapServer is the server API for PowerCap service.
+// All implementations must embed UnimplementedPowerCapServer
+// for forward compatibility.
+//
+// PowerCap controls the power manager of one node. Calls must carry an
+// "authorization: Bearer <token>" metadata entry with an ADMIN_API_TOKENS token.
+type PowerCapServer interface {
+	// GetStatus returns the current state of the manager
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// GetSchedule returns the cap of every period of the current day
+	GetSchedule(context.Context, *GetScheduleRequest) (*Schedule, error)
+	// SetOverride pins the cap until the override expires
+	SetOverride(context.Context, *SetOverrideRequest) (*Override, error)
+	// ClearOverride removes the active override
+	ClearOverride(context.Context, *ClearOverrideRequest) (*Override, error)
+	// TriggerRefresh fetches today's market data again and re-adjusts the cap
+	TriggerRefresh(context.Context, *TriggerRefreshRequest) (*TriggerRefreshResponse, error)
+	// StreamDecisions sends every decision as it is made
+	StreamDecisions(*StreamDecisionsRequest, grpc.ServerStreamingServer[Decision]) error
+	mustEmbedUnimplementedPowerCapServer()
+}
+
+// UnimplementedPowerCapServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPowerCapServer struct{}
+
+func (UnimplementedPowerCapServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedPowerCapServer) GetSchedule(context.Context, *GetScheduleRequest) (*Schedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchedule not implemented")
+}
+func (UnimplementedPowerCapServer) SetOverride(context.Context, *SetOverrideRequest) (*Override, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOverride not implemented")
+}
+func (UnimplementedPowerCapServer) ClearOverride(context.Context, *ClearOverrideRequest) (*Override, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearOverride not implemented")
+}
+func (UnimplementedPowerCapServer) TriggerRefresh(context.Context, *TriggerRefreshRequest) (*TriggerRefreshResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerRefresh not implemented")
+}
+func (UnimplementedPowerCapServer) StreamDecisions(*StreamDecisionsRequest, grpc.ServerStreamingServer[Decision]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDecisions not implemented")
+}
+func (UnimplementedPowerCapServer) mustEmbedUnimplementedPowerCapServer() {}
+func (UnimplementedPowerCapServer) testEmbeddedByValue()                  {}
+
+// UnsafePowerCapServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PowerCapServer will
+// result in compilation errors.
+type UnsafePowerCapServer interface {
+	mustEmbedUnimplementedPowerCapServer()
+}
+
+func RegisterPowerCapServer(s grpc.ServiceRegistrar, srv PowerCapServer) {
+	// If the following call pancis, it indicates UnimplementedPowerCapServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PowerCap_ServiceDesc, srv)
+}
+
+func _PowerCap_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerCapServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PowerCap_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerCapServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerCap_GetSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerCapServer).GetSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PowerCap_GetSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerCapServer).GetSchedule(ctx, req.(*GetScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerCap_SetOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerCapServer).SetOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PowerCap_SetOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerCapServer).SetOverride(ctx, req.(*SetOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerCap_ClearOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerCapServer).ClearOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PowerCap_ClearOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerCapServer).ClearOverride(ctx, req.(*ClearOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerCap_TriggerRefresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerRefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerCapServer).TriggerRefresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PowerCap_TriggerRefresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerCapServer).TriggerRefresh(ctx, req.(*TriggerRefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerCap_StreamDecisions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDecisionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PowerCapServer).StreamDecisions(m, &grpc.GenericServerStream[StreamDecisionsRequest, Decision]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PowerCap_StreamDecisionsServer = grpc.ServerStreamingServer[Decision]
+
+// PowerCap_ServiceDesc is the grpc.ServiceDesc for PowerCap service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PowerCap_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "powercap.v1.PowerCap",
+	HandlerType: (*PowerCapServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _PowerCap_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetSchedule",
+			Handler:    _PowerCap_GetSchedule_Handler,
+		},
+		{
+			MethodName: "SetOverride",
+			Handler:    _PowerCap_SetOverride_Handler,
+		},
+		{
+			MethodName: "ClearOverride",
+			Handler:    _PowerCap_ClearOverride_Handler,
+		},
+		{
+			MethodName: "TriggerRefresh",
+			Handler:    _PowerCap_TriggerRefresh_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDecisions",
+			Handler:       _PowerCap_StreamDecisions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "powercap/v1/powercap.proto",
+}
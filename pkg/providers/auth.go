@@ -0,0 +1,250 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication schemes of the auth provider parameter
+const (
+	AuthAPIKey = "apikey"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthOAuth2 = "oauth2"
+)
+
+// defaultAPIKeyHeader carries API keys when no header or query parameter is
+// configured
+const defaultAPIKeyHeader = "X-Api-Key"
+
+// oauthExpiryMargin renews OAuth2 tokens this long before they expire
+const oauthExpiryMargin = 30 * time.Second
+
+// Auth authenticates the requests of a provider, whatever the provider. It
+// is configured by the auth.* provider parameters, whose secrets are best
+// read from files (auth.token_file) or from the secrets directory
+// (provider.auth.token) rather than written in PROVIDER_PARAMS. The
+// credentials are bound to the host of the provider's own URL: requests to
+// other hosts, such as a redirect, are refused.
+type Auth struct {
+	Scheme string // apikey, bearer, basic or oauth2
+
+	key    string // API key
+	header string // Header of the API key
+	query  string // Query parameter of the API key, instead of a header
+
+	token string // Bearer token
+
+	username string
+	password string
+
+	tokenURL     string // OAuth2 client credentials
+	clientID     string
+	clientSecret string
+	scope        string
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+// ParseAuth reads the auth.* provider parameters, nil without the auth
+// parameter:
+//   - apikey: auth.key, sent in auth.header (default X-Api-Key) or in the
+//     auth.query parameter
+//   - bearer: auth.token
+//   - basic: auth.username and auth.password
+//   - oauth2: auth.token_url, auth.client_id, auth.client_secret and
+//     optionally auth.scope, for the client credentials grant
+func ParseAuth(params map[string]string) (*Auth, error) {
+	scheme := strings.ToLower(params["auth"])
+	if scheme == "" || scheme == "none" {
+		return nil, nil
+	}
+	a := &Auth{
+		Scheme:       scheme,
+		key:          params["auth.key"],
+		header:       params["auth.header"],
+		query:        params["auth.query"],
+		token:        params["auth.token"],
+		username:     params["auth.username"],
+		password:     params["auth.password"],
+		tokenURL:     params["auth.token_url"],
+		clientID:     params["auth.client_id"],
+		clientSecret: params["auth.client_secret"],
+		scope:        params["auth.scope"],
+	}
+
+	var missing []string
+	require := func(name, value string) {
+		if value == "" {
+			missing = append(missing, "auth."+name)
+		}
+	}
+	switch scheme {
+	case AuthAPIKey:
+		require("key", a.key)
+		if a.header == "" && a.query == "" {
+			a.header = defaultAPIKeyHeader
+		}
+	case AuthBearer:
+		require("token", a.token)
+	case AuthBasic:
+		require("username", a.username)
+	case AuthOAuth2:
+		require("token_url", a.tokenURL)
+		require("client_id", a.clientID)
+		require("client_secret", a.clientSecret)
+		if a.tokenURL != "" && !strings.HasPrefix(a.tokenURL, "https://") && !strings.HasPrefix(a.tokenURL, "http://") {
+			return nil, fmt.Errorf("auth.token_url must be an http(s) URL, got %q", a.tokenURL)
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth scheme %q, expected apikey, bearer, basic or oauth2", scheme)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s auth requires %s", scheme, strings.Join(missing, ", "))
+	}
+	return a, nil
+}
+
+// isAuthParam reports whether a provider parameter configures the
+// authentication, and so must not be sent as a query parameter
+func isAuthParam(key string) bool {
+	return key == "auth" || strings.HasPrefix(key, "auth.")
+}
+
+// authTransport authenticates the requests sent through it. Credentials the
+// provider set itself, such as a login token, are left alone.
+type authTransport struct {
+	base    http.RoundTripper
+	auth    *Auth
+	hosts   []string      // Hosts the credentials are bound to, host[:port] in lower case
+	timeout time.Duration // Of OAuth2 token requests
+}
+
+// authHosts returns the hosts of the endpoints of a provider, leaving out
+// those that cannot be parsed, such as a host given by a placeholder
+func authHosts(endpoints []string) []string {
+	var hosts []string
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" || strings.ContainsAny(u.Host, "{}") {
+			continue
+		}
+		hosts = append(hosts, strings.ToLower(u.Host))
+	}
+	return hosts
+}
+
+// RoundTrip adds the credentials to a copy of the request, refusing to send
+// it to a host the credentials are not bound to. The client strips the
+// Authorization header of a redirect to another host; adding the
+// credentials back would leak them.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host := strings.ToLower(req.URL.Host); !slices.Contains(t.hosts, host) {
+		return nil, fmt.Errorf("refusing to send %s credentials to %s, they are bound to %s",
+			t.auth.Scheme, req.URL.Host, strings.Join(t.hosts, ", "))
+	}
+	req = req.Clone(req.Context())
+	if err := t.auth.apply(req, t); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && t.auth.Scheme == AuthOAuth2 {
+		// Revoked or rotated: the next request gets a new token
+		t.auth.mu.Lock()
+		t.auth.accessToken = ""
+		t.auth.mu.Unlock()
+	}
+	return resp, err
+}
+
+// apply adds the credentials to a request
+func (a *Auth) apply(req *http.Request, t *authTransport) error {
+	switch a.Scheme {
+	case AuthAPIKey:
+		if a.query != "" {
+			query := req.URL.Query()
+			query.Set(a.query, a.key)
+			req.URL.RawQuery = query.Encode()
+		} else if req.Header.Get(a.header) == "" {
+			req.Header.Set(a.header, a.key)
+		}
+		return nil
+	}
+
+	if req.Header.Get("Authorization") != "" {
+		return nil
+	}
+	switch a.Scheme {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	case AuthBasic:
+		req.SetBasicAuth(a.username, a.password)
+	case AuthOAuth2:
+		token, err := a.oauthToken(req.Context(), t)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// oauthToken returns the access token of the client credentials grant,
+// requesting a new one when it is about to expire
+func (a *Auth) oauthToken(ctx context.Context, t *authTransport) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.accessToken != "" && time.Now().Before(a.expires) {
+		return a.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {a.clientID}, "client_secret": {a.clientSecret}}
+	if a.scope != "" {
+		form.Set("scope", a.scope)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Transport: t.base, Timeout: t.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OAuth2 token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return "", fmt.Errorf("OAuth2 token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		AccessToken string  `json:"access_token"`
+		ExpiresIn   float64 `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid OAuth2 token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("OAuth2 token response has no access_token")
+	}
+	lifetime := time.Hour
+	if result.ExpiresIn > 0 {
+		lifetime = time.Duration(result.ExpiresIn * float64(time.Second))
+	}
+	a.accessToken = result.AccessToken
+	a.expires = time.Now().Add(max(lifetime-oauthExpiryMargin, 0))
+	return a.accessToken, nil
+}
@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// cacheEntry is a fetched day, as kept in memory and on disk
+type cacheEntry struct {
+	FetchedAt time.Time                   `json:"fetched_at"`
+	Data      []datastore.MarketDataPoint `json:"data"`
+}
+
+// CachingProvider reuses the days fetched by another provider for a while,
+// so repeated refreshes do not hit an external API each time. The days are
+// also written to a directory, so a restarted manager reuses them. Failed
+// fetches are not cached.
+type CachingProvider struct {
+	datastore.MarketDataProvider
+	ttl time.Duration
+	dir string // Empty keeps the cache in memory only
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry // Data path of the day → entry
+}
+
+// NewCachingProvider wraps provider, reusing a fetched day for ttl. dir
+// holds the cache across restarts, empty to keep it in memory.
+func NewCachingProvider(provider datastore.MarketDataProvider, ttl time.Duration, dir string) *CachingProvider {
+	return &CachingProvider{
+		MarketDataProvider: provider,
+		ttl:                ttl,
+		dir:                dir,
+		now:                time.Now,
+		entries:            make(map[string]cacheEntry),
+	}
+}
+
+// FetchData returns the cached data of a date fetched less than the TTL ago,
+// fetching it from the wrapped provider otherwise
+func (p *CachingProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	key := p.MarketDataProvider.GetDataPath(date)
+	now := p.now()
+	if entry, ok := p.lookup(key); ok && now.Sub(entry.FetchedAt) < p.ttl {
+		return append([]datastore.MarketDataPoint(nil), entry.Data...), nil
+	}
+
+	data, err := p.MarketDataProvider.FetchData(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	p.store(key, cacheEntry{FetchedAt: now, Data: append([]datastore.MarketDataPoint(nil), data...)})
+	return data, nil
+}
+
+// FetchRange returns the days cached less than the TTL ago, fetching the
+// range from the wrapped provider when one of them is not
+func (p *CachingProvider) FetchRange(ctx context.Context, from, to time.Time) (map[string][]datastore.MarketDataPoint, error) {
+	now := p.now()
+	days := make(map[string][]datastore.MarketDataPoint)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		entry, ok := p.lookup(p.MarketDataProvider.GetDataPath(day))
+		if !ok || now.Sub(entry.FetchedAt) >= p.ttl {
+			days = nil
+			break
+		}
+		days[day.Format("2006-01-02")] = append([]datastore.MarketDataPoint(nil), entry.Data...)
+	}
+	if days != nil {
+		return days, nil
+	}
+
+	days, err := datastore.FetchRange(ctx, p.MarketDataProvider, from, to)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if data := days[day.Format("2006-01-02")]; len(data) > 0 {
+			p.store(p.MarketDataProvider.GetDataPath(day), cacheEntry{FetchedAt: now, Data: append([]datastore.MarketDataPoint(nil), data...)})
+		}
+	}
+	return days, err
+}
+
+// lookup returns the entry of a day from memory, or from disk after a restart
+func (p *CachingProvider) lookup(key string) (cacheEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if entry, ok := p.entries[key]; ok {
+		return entry, true
+	}
+	if p.dir == "" {
+		return cacheEntry{}, false
+	}
+	content, err := os.ReadFile(p.cachePath(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	p.entries[key] = entry
+	return entry, true
+}
+
+// store keeps the entry of a day, dropping the expired ones. The file is
+// replaced atomically; failing to write it only loses the cache on restart.
+func (p *CachingProvider) store(key string, entry cacheEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for other, cached := range p.entries {
+		if entry.FetchedAt.Sub(cached.FetchedAt) >= p.ttl {
+			delete(p.entries, other)
+		}
+	}
+	p.entries[key] = entry
+
+	if p.dir == "" {
+		return
+	}
+	content, err := json.Marshal(entry)
+	if err != nil || os.MkdirAll(p.dir, 0755) != nil {
+		return
+	}
+	path := p.cachePath(key)
+	if os.WriteFile(path+".tmp", content, 0644) == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// cachePath returns the cache file of a day from its data path
+func (p *CachingProvider) cachePath(key string) string {
+	name := filepath.Base(key)
+	return filepath.Join(p.dir, strings.TrimSuffix(name, filepath.Ext(name))+".json")
+}
+
+// DefaultRefreshCron returns the refresh schedule of the wrapped provider
+func (p *CachingProvider) DefaultRefreshCron() string {
+	if scheduler, ok := p.MarketDataProvider.(datastore.RefreshScheduler); ok {
+		return scheduler.DefaultRefreshCron()
+	}
+	return "0 0 * * *"
+}
+
+// Intraday reports whether the wrapped provider publishes through the day
+func (p *CachingProvider) Intraday() bool {
+	if provider, ok := p.MarketDataProvider.(datastore.IntradayProvider); ok {
+		return provider.Intraday()
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kcas/new/internal/calendar"
+	"kcas/new/internal/datastore"
+)
+
+// CalendarProvider switches to another provider on the days the market is
+// closed, such as weekends and public holidays
+type CalendarProvider struct {
+	datastore.MarketDataProvider
+	closedDay datastore.MarketDataProvider
+	calendar  *calendar.Calendar
+}
+
+// NewCalendarProvider wraps provider, fetching the closed days of cal from closedDay
+func NewCalendarProvider(provider, closedDay datastore.MarketDataProvider, cal *calendar.Calendar) *CalendarProvider {
+	return &CalendarProvider{MarketDataProvider: provider, closedDay: closedDay, calendar: cal}
+}
+
+// providerFor returns the provider of a market date
+func (p *CalendarProvider) providerFor(date time.Time) datastore.MarketDataProvider {
+	if closed, _ := p.calendar.Closed(date); closed {
+		return p.closedDay
+	}
+	return p.MarketDataProvider
+}
+
+// FetchData fetches the data of a date from the provider of that day
+func (p *CalendarProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	return p.providerFor(date).FetchData(ctx, date)
+}
+
+// FetchRange fetches the days from the provider of each: the runs of open
+// days in one request when the provider fetches ranges, the closed days one
+// by one
+func (p *CalendarProvider) FetchRange(ctx context.Context, from, to time.Time) (map[string][]datastore.MarketDataPoint, error) {
+	days := make(map[string][]datastore.MarketDataPoint)
+	var errs []error
+	collect := func(fetched map[string][]datastore.MarketDataPoint, err error) {
+		for day, data := range fetched {
+			days[day] = data
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var open time.Time // First day of the current run of open days
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if p.providerFor(day) == p.MarketDataProvider {
+			if open.IsZero() {
+				open = day
+			}
+			continue
+		}
+		if !open.IsZero() {
+			collect(datastore.FetchRange(ctx, p.MarketDataProvider, open, day.AddDate(0, 0, -1)))
+			open = time.Time{}
+		}
+		collect(datastore.FetchRange(ctx, p.closedDay, day, day))
+	}
+	if !open.IsZero() {
+		collect(datastore.FetchRange(ctx, p.MarketDataProvider, open, to))
+	}
+	return days, errors.Join(errs...)
+}
+
+// GetDataPath returns the file path of a date from the provider of that day
+func (p *CalendarProvider) GetDataPath(date time.Time) string {
+	return p.providerFor(date).GetDataPath(date)
+}
+
+// DefaultRefreshCron returns the refresh schedule of the wrapped provider
+func (p *CalendarProvider) DefaultRefreshCron() string {
+	if scheduler, ok := p.MarketDataProvider.(datastore.RefreshScheduler); ok {
+		return scheduler.DefaultRefreshCron()
+	}
+	return "0 0 * * *"
+}
+
+// Intraday reports whether the wrapped provider publishes through the day
+func (p *CalendarProvider) Intraday() bool {
+	if provider, ok := p.MarketDataProvider.(datastore.IntradayProvider); ok {
+		return provider.Intraday()
+	}
+	return false
+}
+
+// Subscribe receives the updates of the wrapped provider when it pushes its
+// data
+func (p *CalendarProvider) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	if provider, ok := p.MarketDataProvider.(datastore.PushProvider); ok {
+		return provider.Subscribe(ctx)
+	}
+	return nil, nil
+}
+
+// Clock returns the clock of the wrapped provider when it replays recorded
+// data
+func (p *CalendarProvider) Clock() func() time.Time {
+	if provider, ok := p.MarketDataProvider.(datastore.ClockProvider); ok {
+		return provider.Clock()
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// carbonIntensityTime is the time format of the Carbon Intensity API
+const carbonIntensityTime = "2006-01-02T15:04Z07:00"
+
+// carbonIntensityPeriod is a half-hour of the Carbon Intensity API
+type carbonIntensityPeriod struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Intensity struct {
+		Forecast *float64 `json:"forecast"`
+		Actual   *float64 `json:"actual"`
+	} `json:"intensity"`
+}
+
+// CarbonIntensityProvider follows the carbon intensity of the British grid
+// from the free Carbon Intensity API of National Grid ESO, nationally or for
+// a region. The day is read from the 24-hour forecast window starting at
+// midnight, the measured intensity replacing the forecast where the API has
+// it. As for WattTime, the volume of each period is its cleanliness relative
+// to the cleanest period of the day, 100 × lowest intensity / intensity.
+type CarbonIntensityProvider struct {
+	baseURL  string
+	region   string // Region ID, 1 to 17
+	postcode string // Outward code such as RG10, instead of the region
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
+}
+
+// NewCarbonIntensityProvider creates a Carbon Intensity provider from the
+// parameters: region, the ID of a region, or postcode, the outward code of a
+// postcode whose region is followed, the whole country without either
+func NewCarbonIntensityProvider(baseURL string, params map[string]string, location *time.Location) *CarbonIntensityProvider {
+	if baseURL == "" {
+		baseURL = "https://api.carbonintensity.org.uk"
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &CarbonIntensityProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		region:   params["region"],
+		postcode: strings.ToUpper(strings.TrimSpace(params["postcode"])),
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of Carbon Intensity requests
+func (p *CarbonIntensityProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
+// GetName returns the provider name
+func (p *CarbonIntensityProvider) GetName() string {
+	return "Carbon Intensity"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *CarbonIntensityProvider) GetDataPath(date time.Time) string {
+	place := "gb"
+	if p.region != "" {
+		place = "region" + p.region
+	} else if p.postcode != "" {
+		place = strings.ToLower(p.postcode)
+	}
+	return fmt.Sprintf("carbon_intensity_%s_%s.csv", place, date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron follows the forecast, updated every half-hour
+func (p *CarbonIntensityProvider) DefaultRefreshCron() string {
+	return "*/30 * * * *"
+}
+
+// Intraday reports that the data of the current day changes with every
+// forecast
+func (p *CarbonIntensityProvider) Intraday() bool {
+	return true
+}
+
+// FetchData fetches the carbon intensity of every period of the given date.
+// Each half-hour fills its two periods, and half-hours the API leaves out
+// carry the previous value forward.
+func (p *CarbonIntensityProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	periods, err := p.forecastWindow(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	intensities := make(map[string]float64)
+	for _, period := range periods {
+		from, err := time.Parse(carbonIntensityTime, period.From)
+		if err != nil {
+			continue
+		}
+		until, err := time.Parse(carbonIntensityTime, period.To)
+		if err != nil || !until.After(from) {
+			until = from.Add(30 * time.Minute)
+		}
+		intensity := period.Intensity.Forecast
+		if period.Intensity.Actual != nil {
+			intensity = period.Intensity.Actual
+		}
+		if intensity == nil || *intensity <= 0 {
+			continue
+		}
+		for t := from; t.Before(until); t = t.Add(15 * time.Minute) {
+			if !t.Before(start) && t.Before(end) {
+				intensities[quarterPeriod(t.In(p.location))] = *intensity
+			}
+		}
+	}
+	if len(intensities) == 0 {
+		return nil, fmt.Errorf("no carbon intensity published for %s", start.Format("2006-01-02"))
+	}
+
+	return cleanliness(intensities, start, end), nil
+}
+
+// forecastWindow returns the half-hours of the 24-hour forecast window
+// starting at start, national or regional
+func (p *CarbonIntensityProvider) forecastWindow(ctx context.Context, start time.Time) ([]carbonIntensityPeriod, error) {
+	from := start.UTC().Format(carbonIntensityTime)
+	path := "/intensity/" + from + "/fw24h"
+	if p.region != "" {
+		path = "/regional/intensity/" + from + "/fw24h/regionid/" + url.PathEscape(p.region)
+	} else if p.postcode != "" {
+		path = "/regional/intensity/" + from + "/fw24h/postcode/" + url.PathEscape(p.postcode)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	applyRequestOptions(req, p.request, "")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return nil, fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	// The national window is a list of half-hours, a regional one is nested
+	// in the region, itself sometimes listed alone
+	var window struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&window); err != nil {
+		return nil, fmt.Errorf("invalid Carbon Intensity response: %w", err)
+	}
+	type region struct {
+		Data []carbonIntensityPeriod `json:"data"`
+	}
+	var periods []carbonIntensityPeriod
+	switch data := bytes.TrimSpace(window.Data); {
+	case p.region == "" && p.postcode == "":
+		err = json.Unmarshal(data, &periods)
+	case bytes.HasPrefix(data, []byte("[")):
+		var regions []region
+		err = json.Unmarshal(data, &regions)
+		for _, r := range regions {
+			periods = append(periods, r.Data...)
+		}
+	default:
+		var r region
+		err = json.Unmarshal(data, &r)
+		periods = r.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid Carbon Intensity response: %w", err)
+	}
+	return periods, nil
+}
+
+// validateCarbonIntensityParams checks the Carbon Intensity provider parameters
+func validateCarbonIntensityParams(params map[string]string) error {
+	if region := params["region"]; region != "" {
+		if params["postcode"] != "" {
+			return fmt.Errorf("Carbon Intensity provider takes a region or a postcode, not both")
+		}
+		if id, err := strconv.Atoi(region); err != nil || id < 1 || id > 17 {
+			return fmt.Errorf("Carbon Intensity region must be a region ID from 1 to 17, got %q", region)
+		}
+	}
+	if postcode := strings.TrimSpace(params["postcode"]); postcode != "" {
+		if len(postcode) < 2 || len(postcode) > 4 || strings.ContainsAny(postcode, " /") {
+			return fmt.Errorf("Carbon Intensity postcode must be the outward code of a postcode, such as RG10, got %q", postcode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/datastore"
+)
+
+// compositeSource is a provider of a composite signal with its weight
+type compositeSource struct {
+	name     string
+	provider datastore.MarketDataProvider
+	weight   float64
+}
+
+// CompositeProvider merges the signals of several providers, such as a
+// market price and a carbon intensity, so the cap reflects all of them. Each
+// signal is normalised to its daily maximum, then the signals are averaged
+// by weight into a volume between 0 and 100, the periods following the first
+// provider. The price is the one of a chosen provider.
+type CompositeProvider struct {
+	sources  []compositeSource
+	price    int // Index of the source giving the price
+	parallel int
+}
+
+// compositeParam describes a provider of the providers parameter, e.g.
+// "epex:0.6"
+type compositeParam struct {
+	name   string
+	weight float64
+}
+
+// parseCompositeProviders parses the providers parameter, a comma-separated
+// list of provider names each optionally followed by :weight (default 1)
+func parseCompositeProviders(value string) ([]compositeParam, error) {
+	var sources []compositeParam
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		name, weightText, hasWeight := strings.Cut(strings.TrimSpace(entry), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if hasWeight {
+			var err error
+			weight, err = strconv.ParseFloat(strings.TrimSpace(weightText), 64)
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight %q of %s, expected a positive number", weightText, name)
+			}
+		}
+		if name == "composite" {
+			return nil, fmt.Errorf("a composite provider cannot include another one")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("provider %s is listed twice", name)
+		}
+		seen[name] = true
+		sources = append(sources, compositeParam{name: name, weight: weight})
+	}
+	if len(sources) < 2 {
+		return nil, fmt.Errorf("a composite provider needs at least two providers, got %q", value)
+	}
+	return sources, nil
+}
+
+// NewCompositeProvider creates a composite provider from the sources and
+// their weights. price names the source giving the prices, the first when
+// empty.
+func NewCompositeProvider(sources []compositeSource, price string, parallel int) *CompositeProvider {
+	p := &CompositeProvider{sources: sources, parallel: parallel}
+	for i, source := range sources {
+		if source.name == price {
+			p.price = i
+		}
+	}
+	return p
+}
+
+// GetName returns the provider name with the weights of the sources
+func (p *CompositeProvider) GetName() string {
+	var total float64
+	for _, source := range p.sources {
+		total += source.weight
+	}
+	parts := make([]string, len(p.sources))
+	for i, source := range p.sources {
+		parts[i] = fmt.Sprintf("%s %.0f%%", source.provider.GetName(), source.weight/total*100)
+	}
+	return "Composite (" + strings.Join(parts, ", ") + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *CompositeProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("composite_data_%s.csv", date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron returns the refresh schedule of the first source with
+// one, so the signal changing most often is followed
+func (p *CompositeProvider) DefaultRefreshCron() string {
+	for _, source := range p.sources {
+		if scheduler, ok := source.provider.(datastore.RefreshScheduler); ok {
+			return scheduler.DefaultRefreshCron()
+		}
+	}
+	return "0 0 * * *"
+}
+
+// Intraday reports whether one of the sources publishes through the day
+func (p *CompositeProvider) Intraday() bool {
+	for _, source := range p.sources {
+		if provider, ok := source.provider.(datastore.IntradayProvider); ok && provider.Intraday() {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchData fetches the data of every source and merges it. A source failing
+// fails the fetch rather than leaving a signal out for the whole day.
+func (p *CompositeProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	fetches := make([]fetchFunc, len(p.sources))
+	for i, source := range p.sources {
+		fetches[i] = func(ctx context.Context) ([]datastore.MarketDataPoint, error) {
+			data, err := source.provider.FetchData(ctx, date)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", source.name, err)
+			}
+			return data, nil
+		}
+	}
+	results, err := fetchConcurrently(ctx, p.parallel, fetches)
+	if err != nil {
+		return nil, err
+	}
+	return p.merge(results)
+}
+
+// compositePeriod is a period of a source with its normalised value
+type compositePeriod struct {
+	period      string
+	from, until int // Minutes since midnight
+	value       float64
+	price       float64
+}
+
+// merge combines the results of the sources on the periods of the first.
+// A period of the first source not covered by another is averaged over the
+// sources covering it.
+func (p *CompositeProvider) merge(results [][]datastore.MarketDataPoint) ([]datastore.MarketDataPoint, error) {
+	signals := make([][]compositePeriod, len(results))
+	for i, data := range results {
+		var highest float64
+		for _, point := range data {
+			highest = max(highest, point.Volume)
+		}
+		if highest <= 0 {
+			return nil, fmt.Errorf("%s: no positive value to normalise", p.sources[i].name)
+		}
+		for _, point := range data {
+			from, until, err := periodMinutes(point.Period)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p.sources[i].name, err)
+			}
+			signals[i] = append(signals[i], compositePeriod{
+				period: point.Period,
+				from:   from,
+				until:  until,
+				value:  max(point.Volume, 0) / highest,
+				price:  point.Price,
+			})
+		}
+	}
+
+	merged := make([]datastore.MarketDataPoint, 0, len(results[0]))
+	for _, period := range signals[0] {
+		var sum, weights, price float64
+		for i, signal := range signals {
+			for _, other := range signal {
+				if other.from <= period.from && period.from < other.until {
+					sum += p.sources[i].weight * other.value
+					weights += p.sources[i].weight
+					if i == p.price {
+						price = other.price
+					}
+					break
+				}
+			}
+		}
+		merged = append(merged, datastore.MarketDataPoint{
+			Period: period.period,
+			Volume: sum / weights * 100,
+			Price:  price,
+		})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Period < merged[j].Period })
+	return merged, nil
+}
+
+// periodMinutes parses a HH:MM-HH:MM period to minutes since midnight
+func periodMinutes(period string) (int, int, error) {
+	from, until, ok := strings.Cut(period, "-")
+	if !ok || len(from) != 5 || len(until) != 5 {
+		return 0, 0, fmt.Errorf("invalid period %q", period)
+	}
+	start, err := clockMinutes(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := clockMinutes(until)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// createComposite creates the providers of a composite one, each with its
+// own parameters and request options
+func (f *ProviderFactory) createComposite(cfg *config.Config) (datastore.MarketDataProvider, error) {
+	params, err := parseCompositeProviders(cfg.ProviderParams["providers"])
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]compositeSource, len(params))
+	for i, param := range params {
+		provider, err := f.createConfigured(scopedConfig(cfg, param.name))
+		if err != nil {
+			return nil, fmt.Errorf("composite provider %s: %w", param.name, err)
+		}
+		sources[i] = compositeSource{name: param.name, provider: provider, weight: param.weight}
+	}
+	return NewCompositeProvider(sources, strings.ToLower(cfg.ProviderParams["price"]), cfg.ProviderParallel), nil
+}
+
+// validateComposite checks the composite provider parameters and those of
+// its providers
+func (f *ProviderFactory) validateComposite(cfg *config.Config) error {
+	params, err := parseCompositeProviders(cfg.ProviderParams["providers"])
+	if err != nil {
+		return fmt.Errorf("composite provider: %w", err)
+	}
+	price, priceFound := strings.ToLower(cfg.ProviderParams["price"]), false
+	for _, param := range params {
+		if err := f.ValidateProviderConfig(scopedConfig(cfg, param.name)); err != nil {
+			return fmt.Errorf("composite provider %s: %w", param.name, err)
+		}
+		priceFound = priceFound || param.name == price
+	}
+	if price != "" && !priceFound {
+		return fmt.Errorf("composite provider price %q is not one of its providers", price)
+	}
+	return nil
+}
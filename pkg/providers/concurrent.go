@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"kcas/new/internal/datastore"
+)
+
+// defaultParallel is the number of requests run at once when no limit is configured
+const defaultParallel = 4
+
+// fetchFunc fetches the data of one source
+type fetchFunc func(ctx context.Context) ([]datastore.MarketDataPoint, error)
+
+// fetchConcurrently runs the fetches with at most parallel of them at once,
+// returning their results in order. The first failure cancels the others.
+func fetchConcurrently(ctx context.Context, parallel int, fetches []fetchFunc) ([][]datastore.MarketDataPoint, error) {
+	if parallel <= 0 {
+		parallel = defaultParallel
+	}
+	results := make([][]datastore.MarketDataPoint, len(fetches))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(parallel)
+	for i, fetch := range fetches {
+		g.Go(func() error {
+			data, err := fetch(ctx)
+			results[i] = data
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// mergeTotal merges the data of several sources period by period: volumes
+// are added up and prices averaged by volume (plainly when no volume was
+// traded). Periods are sorted by start time.
+func mergeTotal(results [][]datastore.MarketDataPoint) []datastore.MarketDataPoint {
+	type total struct {
+		volume, value, prices float64
+		sources               int
+	}
+	totals := make(map[string]*total)
+	for _, data := range results {
+		for _, point := range data {
+			t, ok := totals[point.Period]
+			if !ok {
+				t = &total{}
+				totals[point.Period] = t
+			}
+			t.volume += point.Volume
+			t.value += point.Volume * point.Price
+			t.prices += point.Price
+			t.sources++
+		}
+	}
+
+	merged := make([]datastore.MarketDataPoint, 0, len(totals))
+	for period, t := range totals {
+		price := t.prices / float64(t.sources)
+		if t.volume > 0 {
+			price = t.value / t.volume
+		}
+		merged = append(merged, datastore.MarketDataPoint{Period: period, Volume: t.volume, Price: price})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Period < merged[j].Period })
+	return merged
+}
+
+// mergePriority merges the data of several sources in order of priority: a
+// quarter-hour is taken from the first source covering it, so the following
+// ones only fill the gaps. A longer period, such as an hourly day-ahead
+// result, spreads its volume evenly over the quarter-hours it spans, each
+// keeping its price. The volumes of the following sources are rescaled into
+// the range of the first, by the ratio of their average quarter-hour
+// volumes, so a filled gap neither sets the maximum volume of the day nor
+// drops towards the minimum. Periods are sorted by start time.
+func mergePriority(results [][]datastore.MarketDataPoint) []datastore.MarketDataPoint {
+	midnight := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	filled := make(map[string]datastore.MarketDataPoint)
+	reference := 0.0 // Average quarter-hour volume of the first source with volumes
+	for _, data := range results {
+		quarters := make([]datastore.MarketDataPoint, 0, len(data))
+		var total float64
+		var counted int
+		for _, point := range data {
+			from, until, err := periodMinutes(point.Period)
+			if err != nil {
+				// Unparsable periods are kept unless a source before had them
+				if _, ok := filled[point.Period]; !ok {
+					filled[point.Period] = point
+				}
+				continue
+			}
+			if until <= from {
+				until = 24 * 60 // Ends at midnight, e.g. 23:00-00:00
+			}
+			from = from / 15 * 15
+			spanned := max((min(until, 24*60)-from+14)/15, 1)
+			for minute := from; minute < until && minute < 24*60; minute += 15 {
+				period := quarterPeriod(midnight.Add(time.Duration(minute) * time.Minute))
+				quarter := datastore.MarketDataPoint{Period: period, Volume: point.Volume / float64(spanned), Price: point.Price}
+				quarters = append(quarters, quarter)
+				if quarter.Volume > 0 {
+					total += quarter.Volume
+					counted++
+				}
+			}
+		}
+
+		scale := 1.0
+		if counted > 0 {
+			average := total / float64(counted)
+			if reference == 0 {
+				reference = average
+			} else {
+				scale = reference / average
+			}
+		}
+		for _, quarter := range quarters {
+			if _, ok := filled[quarter.Period]; !ok {
+				quarter.Volume *= scale
+				filled[quarter.Period] = quarter
+			}
+		}
+	}
+
+	merged := make([]datastore.MarketDataPoint, 0, len(filled))
+	for _, point := range filled {
+		merged = append(merged, point)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Period < merged[j].Period })
+	return merged
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// csvURLMaxSize bounds the size of a downloaded CSV file
+const csvURLMaxSize = 4 << 20
+
+// CSVURLProvider downloads market data published as a CSV file in the format
+// of the daily files, e.g. by a cron job uploading to a static file host
+type CSVURLProvider struct {
+	url      string
+	headers  map[string]string
+	name     string
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
+}
+
+// NewCSVURLProvider creates a CSV URL provider from the parameters: url
+// (with {{date}} and the other placeholders replaced by the market day),
+// header.<Name> request headers and name, naming the data files (default
+// csvurl)
+func NewCSVURLProvider(params map[string]string, location *time.Location) *CSVURLProvider {
+	name := params["name"]
+	if name == "" {
+		name = "csvurl"
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &CSVURLProvider{
+		url:      params["url"],
+		headers:  headerParams(params),
+		name:     name,
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of downloads
+func (p *CSVURLProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.url)
+}
+
+// GetName returns the provider name
+func (p *CSVURLProvider) GetName() string {
+	return "CSV URL (" + p.name + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *CSVURLProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("%s_%s.csv", p.name, date.Format("2006-01-02"))
+}
+
+// FetchData downloads the CSV file of the given date and checks its header.
+// A file without a valid line fails rather than storing an empty day.
+func (p *CSVURLProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, expandURL(p.url, start, end), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/csv")
+	applyRequestOptions(req, p.request, "")
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return nil, fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	parsed, err := datastore.ParseCSV(io.LimitReader(resp.Body, csvURLMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	if err := datastore.CheckCSVHeader(parsed.Header); err != nil {
+		return nil, err
+	}
+	if len(parsed.Points) == 0 {
+		problem := "no lines"
+		if len(parsed.Skipped) > 0 {
+			problem = parsed.Skipped[0]
+		}
+		return nil, fmt.Errorf("no valid line in the CSV of %s: %s", start.Format("2006-01-02"), problem)
+	}
+	return parsed.Points, nil
+}
+
+// validateCSVURLParams checks the CSV URL parameters
+func validateCSVURLParams(params map[string]string) error {
+	rawURL := params["url"]
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return fmt.Errorf("CSV URL provider requires an http(s) url parameter, got %q", rawURL)
+	}
+	if name := params["name"]; strings.ContainsAny(name, `/\ `) {
+		return fmt.Errorf("CSV URL provider name %q must not contain slashes or spaces", name)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kcas/new/internal/currency"
+	"kcas/new/internal/datastore"
+)
+
+// CurrencyProvider converts the prices of another provider into a common
+// currency, so that stored data, budgets and reports share one unit
+type CurrencyProvider struct {
+	datastore.MarketDataProvider
+	from, to  string
+	converter currency.Converter
+}
+
+// NewCurrencyProvider wraps provider, converting its prices from one currency to another
+func NewCurrencyProvider(provider datastore.MarketDataProvider, from, to string, converter currency.Converter) *CurrencyProvider {
+	return &CurrencyProvider{MarketDataProvider: provider, from: from, to: to, converter: converter}
+}
+
+// FetchData fetches the data of the wrapped provider and converts its prices
+func (p *CurrencyProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	data, err := p.MarketDataProvider.FetchData(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := p.converter.Rate(ctx, p.from, p.to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert prices from %s to %s: %w", p.from, p.to, err)
+	}
+	converted := make([]datastore.MarketDataPoint, len(data))
+	for i, point := range data {
+		point.Price *= rate
+		converted[i] = point
+	}
+	return converted, nil
+}
+
+// FetchRange fetches the days of the wrapped provider, in one request when it
+// fetches ranges, and converts their prices
+func (p *CurrencyProvider) FetchRange(ctx context.Context, from, to time.Time) (map[string][]datastore.MarketDataPoint, error) {
+	days, fetchErr := datastore.FetchRange(ctx, p.MarketDataProvider, from, to)
+	if len(days) == 0 {
+		return days, fetchErr
+	}
+
+	rate, err := p.converter.Rate(ctx, p.from, p.to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert prices from %s to %s: %w", p.from, p.to, err)
+	}
+	for day, data := range days {
+		converted := make([]datastore.MarketDataPoint, len(data))
+		for i, point := range data {
+			point.Price *= rate
+			converted[i] = point
+		}
+		days[day] = converted
+	}
+	return days, fetchErr
+}
+
+// DefaultRefreshCron returns the refresh schedule of the wrapped provider
+func (p *CurrencyProvider) DefaultRefreshCron() string {
+	if scheduler, ok := p.MarketDataProvider.(datastore.RefreshScheduler); ok {
+		return scheduler.DefaultRefreshCron()
+	}
+	return "0 0 * * *"
+}
+
+// Intraday reports whether the wrapped provider publishes through the day
+func (p *CurrencyProvider) Intraday() bool {
+	if provider, ok := p.MarketDataProvider.(datastore.IntradayProvider); ok {
+		return provider.Intraday()
+	}
+	return false
+}
+
+// Subscribe receives the updates of the wrapped provider when it pushes its
+// data
+func (p *CurrencyProvider) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	if provider, ok := p.MarketDataProvider.(datastore.PushProvider); ok {
+		return provider.Subscribe(ctx)
+	}
+	return nil, nil
+}
+
+// Clock returns the clock of the wrapped provider when it replays recorded
+// data
+func (p *CurrencyProvider) Clock() func() time.Time {
+	if provider, ok := p.MarketDataProvider.(datastore.ClockProvider); ok {
+		return provider.Clock()
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// eco2mixDataset is the real-time national dataset of éCO2mix on the ODRE
+// open data portal, one record per 15 minutes
+const eco2mixDataset = "eco2mix-national-tr"
+
+// Signals followed by the éCO2mix provider
+const (
+	eco2mixRenewable = "renewable" // Renewable share of the generation
+	eco2mixCO2       = "co2"       // Carbon intensity of the generation
+)
+
+// eco2mixRenewableFields are the renewable generation fields of a record
+var eco2mixRenewableFields = []string{"eolien", "solaire", "hydraulique", "bioenergies"}
+
+// eco2mixFields are the generation fields of a record; pumped storage and
+// exchanges are not generation
+var eco2mixFields = append([]string{"nucleaire", "fioul", "charbon", "gaz"}, eco2mixRenewableFields...)
+
+// Eco2mixProvider follows the French grid mix published in real time by RTE
+// as éCO2mix, read from the ODRE open data portal. The volume of each period
+// is the renewable share of the generation (%), or with the co2 signal its
+// cleanliness relative to the cleanest period of the day, 100 × lowest
+// intensity / intensity, as for WattTime.
+type Eco2mixProvider struct {
+	baseURL  string
+	signal   string
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
+}
+
+// NewEco2mixProvider creates an éCO2mix provider for the signal parameter,
+// renewable (default) or co2. The periods follow the market location, which
+// should be Europe/Paris.
+func NewEco2mixProvider(baseURL string, params map[string]string, location *time.Location) *Eco2mixProvider {
+	if baseURL == "" {
+		baseURL = "https://odre.opendatasoft.com"
+	}
+	signal := strings.ToLower(params["signal"])
+	if signal == "" {
+		signal = eco2mixRenewable
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &Eco2mixProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		signal:   signal,
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of éCO2mix requests
+func (p *Eco2mixProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
+// GetName returns the provider name
+func (p *Eco2mixProvider) GetName() string {
+	return "éCO2mix"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *Eco2mixProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("eco2mix_%s_%s.csv", p.signal, date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron follows the records published every 15 minutes
+func (p *Eco2mixProvider) DefaultRefreshCron() string {
+	return "*/15 * * * *"
+}
+
+// Intraday reports that the data of the current day keeps growing
+func (p *Eco2mixProvider) Intraday() bool {
+	return true
+}
+
+// FetchData fetches the grid mix of every period of the given date. Periods
+// not published yet carry the last published value forward.
+func (p *Eco2mixProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	query := url.Values{}
+	query.Set("where", fmt.Sprintf("date_heure >= date'%s' and date_heure < date'%s'",
+		start.Format(time.RFC3339), end.Format(time.RFC3339)))
+	query.Set("order_by", "date_heure")
+	query.Set("limit", "100") // 96 periods, 100 on the days clocks go back
+	endpoint := p.baseURL + "/api/explore/v2.1/catalog/datasets/" + eco2mixDataset + "/records?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	applyRequestOptions(req, p.request, "")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return nil, fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var records struct {
+		Results []map[string]any `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return nil, fmt.Errorf("invalid éCO2mix response: %w", err)
+	}
+
+	// Records of the future periods of today are listed without values
+	values := make(map[string]float64)
+	for _, record := range records.Results {
+		text, _ := record["date_heure"].(string)
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil || t.Before(start) || !t.Before(end) {
+			continue
+		}
+		if value, ok := p.value(record); ok {
+			values[quarterPeriod(t.In(p.location))] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no éCO2mix %s data published for %s", p.signal, start.Format("2006-01-02"))
+	}
+
+	if p.signal == eco2mixCO2 {
+		return cleanliness(values, start, end), nil
+	}
+	return quarterSeries(values, start, end), nil
+}
+
+// quarterSeries returns the quarter-hours from start to end with the values
+// of their periods, a period without one carrying the previous value forward
+// and those before the first value taking it
+func quarterSeries(values map[string]float64, start, end time.Time) []datastore.MarketDataPoint {
+	var data []datastore.MarketDataPoint
+	var last float64
+	for t := start; t.Before(end); t = t.Add(15 * time.Minute) {
+		period := quarterPeriod(t)
+		if value, ok := values[period]; ok {
+			last = value
+		}
+		data = append(data, datastore.MarketDataPoint{Period: period, Volume: last})
+	}
+
+	first := 0
+	for first < len(data)-1 && data[first].Volume == 0 {
+		first++
+	}
+	for i := 0; i < first; i++ {
+		data[i].Volume = data[first].Volume
+	}
+	return data
+}
+
+// cleanliness returns the quarter-hours from start to end scored by the
+// carbon intensities of their periods, 100 × lowest intensity / intensity,
+// so the cleanest period of the day scores 100
+func cleanliness(intensities map[string]float64, start, end time.Time) []datastore.MarketDataPoint {
+	var lowest float64
+	for _, intensity := range intensities {
+		if lowest == 0 || intensity < lowest {
+			lowest = intensity
+		}
+	}
+	data := quarterSeries(intensities, start, end)
+	for i := range data {
+		data[i].Volume = lowest / data[i].Volume * 100
+	}
+	return data
+}
+
+// value returns the signal of a record, false when it is not published yet.
+// The renewable share is computed from the generation fields, as éCO2mix
+// publishes no share of the national generation.
+func (p *Eco2mixProvider) value(record map[string]any) (float64, bool) {
+	if p.signal == eco2mixCO2 {
+		intensity, ok := record["taux_co2"].(float64)
+		return intensity, ok && intensity > 0
+	}
+
+	var renewable, total float64
+	for _, field := range eco2mixFields {
+		value, ok := record[field].(float64)
+		if !ok || value <= 0 {
+			continue
+		}
+		total += value
+		if slices.Contains(eco2mixRenewableFields, field) {
+			renewable += value
+		}
+	}
+	if total <= 0 {
+		return 0, false
+	}
+	return renewable / total * 100, true
+}
+
+// validateEco2mixParams checks the éCO2mix provider parameters
+func validateEco2mixParams(params map[string]string) error {
+	switch signal := strings.ToLower(params["signal"]); signal {
+	case "", eco2mixRenewable, eco2mixCO2:
+	default:
+		return fmt.Errorf("éCO2mix provider signal must be renewable or co2, got %q", signal)
+	}
+	return nil
+}
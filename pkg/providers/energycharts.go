@@ -0,0 +1,235 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// energyChartsRenewableShare is the series of the renewable share of the
+// public net generation, in %
+const energyChartsRenewableShare = "Renewable share of generation"
+
+// energyChartsRenewable lists the renewable production types, used to
+// compute the share when the API does not return it
+var energyChartsRenewable = []string{"Biomass", "Geothermal", "Hydro", "Solar", "Wind", "Others renewable"}
+
+// energyChartsExcluded lists the series that are not generation
+var energyChartsExcluded = []string{"Load", "Residual load", "Renewable share", "Cross border", "pumped storage"}
+
+// EnergyChartsProvider reads the renewable share of the grid generation from
+// the Energy-Charts API of Fraunhofer ISE. The share (%) is stored as the
+// volume of each period, so POWER_CALC_MODE=percent scales the power with it.
+type EnergyChartsProvider struct {
+	baseURL  string
+	country  string
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
+}
+
+// NewEnergyChartsProvider creates an Energy-Charts provider for the country
+// parameter (default "de"), with periods in the market location
+func NewEnergyChartsProvider(baseURL string, params map[string]string, location *time.Location) *EnergyChartsProvider {
+	if baseURL == "" {
+		baseURL = "https://api.energy-charts.info"
+	}
+	country := params["country"]
+	if country == "" {
+		country = "de"
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &EnergyChartsProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		country:  strings.ToLower(country),
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of Energy-Charts requests
+func (p *EnergyChartsProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
+// GetName returns the provider name
+func (p *EnergyChartsProvider) GetName() string {
+	return "Energy-Charts"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *EnergyChartsProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("energy_charts_%s_%s.csv", p.country, date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron follows the generation published through the day
+func (p *EnergyChartsProvider) DefaultRefreshCron() string {
+	return "*/15 * * * *"
+}
+
+// Intraday reports that the data of the current day keeps growing
+func (p *EnergyChartsProvider) Intraday() bool {
+	return true
+}
+
+// FetchData fetches the renewable share of every period of the given date.
+// Periods not published yet carry the last published share forward.
+func (p *EnergyChartsProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	days, err := p.FetchRange(ctx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	local := date.In(p.location)
+	return days[local.Format("2006-01-02")], nil
+}
+
+// FetchRange fetches the renewable share of every period of the days from
+// from to to in one request, leaving out the days with nothing published
+func (p *EnergyChartsProvider) FetchRange(ctx context.Context, from, to time.Time) (map[string][]datastore.MarketDataPoint, error) {
+	local := from.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	local = to.In(p.location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location).AddDate(0, 0, 1)
+
+	query := url.Values{}
+	query.Set("country", p.country)
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Add(-time.Minute).Format(time.RFC3339))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/public_power?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	applyRequestOptions(req, p.request, "")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return nil, fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var power struct {
+		UnixSeconds     []int64 `json:"unix_seconds"`
+		ProductionTypes []struct {
+			Name string     `json:"name"`
+			Data []*float64 `json:"data"`
+		} `json:"production_types"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&power); err != nil {
+		return nil, fmt.Errorf("invalid Energy-Charts response: %w", err)
+	}
+
+	// Renewable share of every timestamp, from the published series or
+	// computed from the production types
+	shares := make([]*float64, len(power.UnixSeconds))
+	renewable := make([]float64, len(power.UnixSeconds))
+	total := make([]float64, len(power.UnixSeconds))
+	for _, series := range power.ProductionTypes {
+		if series.Name == energyChartsRenewableShare {
+			copy(shares, series.Data)
+			continue
+		}
+		if containsAny(series.Name, energyChartsExcluded) {
+			continue
+		}
+		for i, value := range series.Data {
+			if i >= len(total) || value == nil || *value <= 0 {
+				continue
+			}
+			total[i] += *value
+			if containsAny(series.Name, energyChartsRenewable) {
+				renewable[i] += *value
+			}
+		}
+	}
+	for i := range shares {
+		if shares[i] == nil && total[i] > 0 {
+			share := renewable[i] / total[i] * 100
+			shares[i] = &share
+		}
+	}
+
+	// Each value holds until the next timestamp, e.g. for hourly countries
+	values := make(map[int64]float64) // Unix time of the period → share
+	for i, seconds := range power.UnixSeconds {
+		if shares[i] == nil {
+			continue
+		}
+		from := time.Unix(seconds, 0)
+		until := from.Add(15 * time.Minute)
+		if i+1 < len(power.UnixSeconds) {
+			until = time.Unix(power.UnixSeconds[i+1], 0)
+		}
+		for t := from; t.Before(until) && t.Before(end); t = t.Add(15 * time.Minute) {
+			if !t.Before(start) {
+				values[t.Truncate(15*time.Minute).Unix()] = *shares[i]
+			}
+		}
+	}
+
+	// Periods of a day before its first share take it
+	days := make(map[string][]datastore.MarketDataPoint)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		var last float64
+		published := false
+		for t := day; t.Before(next) && !published; t = t.Add(15 * time.Minute) {
+			last, published = values[t.Unix()]
+		}
+		if !published {
+			continue
+		}
+
+		var data []datastore.MarketDataPoint
+		for t := day; t.Before(next); t = t.Add(15 * time.Minute) {
+			if share, ok := values[t.Unix()]; ok {
+				last = share
+			}
+			data = append(data, datastore.MarketDataPoint{Period: quarterPeriod(t), Volume: last})
+		}
+		days[day.Format("2006-01-02")] = data
+	}
+	if len(days) == 0 {
+		if end.Equal(start.AddDate(0, 0, 1)) {
+			return nil, fmt.Errorf("no renewable share published for %s in %s", start.Format("2006-01-02"), strings.ToUpper(p.country))
+		}
+		return nil, fmt.Errorf("no renewable share published from %s to %s in %s",
+			start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02"), strings.ToUpper(p.country))
+	}
+	return days, nil
+}
+
+// quarterPeriod returns the 15-minute period starting at t, e.g. "13:15-13:30"
+func quarterPeriod(t time.Time) string {
+	hour, minute := t.Hour(), t.Minute()/15*15
+	if hour == 23 && minute == 45 {
+		return "23:45-24:00"
+	}
+	end := t.Truncate(time.Minute).Add(time.Duration(minute-t.Minute()+15) * time.Minute)
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", hour, minute, end.Hour(), end.Minute())
+}
+
+// containsAny reports whether s contains one of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
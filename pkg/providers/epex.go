@@ -2,26 +2,59 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"kcas/new/internal/datastore"
+	"kcas/new/internal/number"
 )
 
+// epexUserAgent is a browser User-Agent, the EPEX site rejects unknown clients
+const epexUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+// EPEX data sources
+const (
+	epexSourcePage = "page" // Scrapes the market results page
+	epexSourceAPI  = "api"  // Reads a structured JSON endpoint
+)
+
+// Ways of merging several EPEX auctions
+const (
+	epexMergeSum      = "sum"      // Adds the volumes up
+	epexMergePriority = "priority" // Fills the gaps of an auction from the next
+)
+
+// epexParams are the provider parameters configuring the provider rather
+// than the market results query
+var epexParams = []string{"source", "fallback", "merge", "api_url", "api.records", "api.period", "api.volume", "api.price"}
+
 // EPEXProvider implements MarketDataProvider for EPEX market data
 type EPEXProvider struct {
-	baseURL string
-	params  map[string]string
-	timeout time.Duration
+	baseURL  string
+	params   map[string]string
+	source   string // page or api
+	apiURL   string
+	fallback bool   // Scrapes the page when the API fails
+	merge    string // sum or priority
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
 }
 
-// NewEPEXProvider creates a new EPEX market data provider with configuration
-func NewEPEXProvider(baseURL string, params map[string]string) *EPEXProvider {
+// NewEPEXProvider creates a new EPEX market data provider with configuration.
+// With the source parameter set to api, the results are read from the JSON
+// endpoint of api_url, the api.records, api.period, api.volume and api.price
+// field paths locating them, and the page is only scraped when the endpoint
+// fails, unless fallback is false. With merge set to priority, the auctions
+// are listed by priority rather than added up.
+func NewEPEXProvider(baseURL string, params map[string]string, location *time.Location) *EPEXProvider {
 	// Set default values if not provided
 	if baseURL == "" {
 		baseURL = "https://www.epexspot.com/en/market-results"
@@ -36,16 +69,47 @@ func NewEPEXProvider(baseURL string, params map[string]string) *EPEXProvider {
 		}
 	}
 
+	source := strings.ToLower(params["source"])
+	if source == "" {
+		source = epexSourcePage
+	}
+	fallback, err := strconv.ParseBool(params["fallback"])
+	if err != nil {
+		fallback = true
+	}
+	merge := strings.ToLower(params["merge"])
+	if merge == "" {
+		merge = epexMergeSum
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
 	return &EPEXProvider{
-		baseURL: baseURL,
-		params:  params,
-		timeout: 30 * time.Second,
+		baseURL:  baseURL,
+		params:   params,
+		source:   source,
+		apiURL:   params["api_url"],
+		fallback: fallback,
+		merge:    merge,
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
 	}
 }
 
+// SetRequestOptions sets the timeout, User-Agent and headers of EPEX
+// requests. With the API source, the credentials are only sent to the API.
+func (p *EPEXProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	if p.source == epexSourceAPI {
+		opts.Auth = nil
+	}
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
 // NewDefaultEPEXProvider creates an EPEX provider with default settings
 func NewDefaultEPEXProvider() *EPEXProvider {
-	return NewEPEXProvider("", nil)
+	return NewEPEXProvider("", nil, nil)
 }
 
 // GetName returns the provider name
@@ -58,25 +122,181 @@ func (p *EPEXProvider) GetDataPath(date time.Time) string {
 	return fmt.Sprintf("epex_data_%s.csv", date.Format("2006-01-02"))
 }
 
-// FetchData fetches EPEX market data for the given date
+// DefaultRefreshCron fetches the next day's data once the IDA1 auction
+// results are published in the afternoon of the trading day
+func (p *EPEXProvider) DefaultRefreshCron() string {
+	return "30 15 * * *"
+}
+
+// FetchData fetches EPEX market data for the given date. With several
+// market areas or auctions (comma-separated in PROVIDER_PARAMS), every
+// combination is fetched concurrently and the results are merged: volumes
+// are added up and prices averaged by volume. With the priority merge, the
+// auctions of a market area are instead taken in the listed order, a period
+// missing from one, such as an IDA1 period without trades, being filled by
+// the next, such as the day-ahead auction, and an auction failing is left
+// out as long as another of its market area was fetched.
 func (p *EPEXProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	sources := p.sources()
+	if len(sources) == 1 {
+		return p.fetchSource(ctx, sources[0], date)
+	}
+
+	// With the priority merge, a failing auction, such as an IDA1 not yet
+	// published, is left out rather than cancelling the others
+	priority := p.merge == epexMergePriority
+	errs := make([]error, len(sources))
+	fetches := make([]fetchFunc, len(sources))
+	for i, params := range sources {
+		fetches[i] = func(ctx context.Context) ([]datastore.MarketDataPoint, error) {
+			data, err := p.fetchSource(ctx, params, date)
+			if err != nil {
+				err = fmt.Errorf("%s %s: %w", params["market_area"], params["auction"], err)
+				if priority {
+					errs[i] = err
+					return nil, nil
+				}
+				return nil, err
+			}
+			return data, nil
+		}
+	}
+	results, err := fetchConcurrently(ctx, p.request.Parallel, fetches)
+	if err != nil {
+		return nil, err
+	}
+	if !priority {
+		return mergeTotal(results), nil
+	}
+
+	// Sources are listed by market area, then by auction
+	auctions := len(splitParam(p.params["auction"]))
+	areas := make([][]datastore.MarketDataPoint, 0, len(results)/auctions)
+	for i := 0; i < len(results); i += auctions {
+		var fetched [][]datastore.MarketDataPoint
+		for j := i; j < i+auctions; j++ {
+			if errs[j] == nil {
+				fetched = append(fetched, results[j])
+			}
+		}
+		if len(fetched) == 0 {
+			return nil, errors.Join(errs[i : i+auctions]...)
+		}
+		areas = append(areas, mergePriority(fetched))
+	}
+	if len(areas) == 1 {
+		return areas[0], nil
+	}
+	return mergeTotal(areas), nil
+}
+
+// sources returns the parameters of every market area and auction
+// combination. A parameter prefixed by an auction name applies to that
+// auction only, e.g. "MRC.sub_modality":"DayAhead" when it is listed with
+// intraday auctions.
+func (p *EPEXProvider) sources() []map[string]string {
+	areas := splitParam(p.params["market_area"])
+	auctions := splitParam(p.params["auction"])
+
+	var sources []map[string]string
+	for _, area := range areas {
+		for _, auction := range auctions {
+			params := make(map[string]string, len(p.params))
+			for key, value := range p.params {
+				if prefix, _, ok := strings.Cut(key, "."); !ok || !slices.Contains(auctions, prefix) {
+					params[key] = value
+				}
+			}
+			for key, value := range p.params {
+				if param, ok := strings.CutPrefix(key, auction+"."); ok {
+					params[param] = value
+				}
+			}
+			params["market_area"], params["auction"] = area, auction
+			sources = append(sources, params)
+		}
+	}
+	return sources
+}
+
+// splitParam splits a comma-separated parameter, keeping a single empty value
+func splitParam(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	if len(values) == 0 {
+		return []string{value}
+	}
+	return values
+}
+
+// fetchSource fetches the data of one market area and auction from the
+// configured source, scraping the page when the API fails
+func (p *EPEXProvider) fetchSource(ctx context.Context, params map[string]string, date time.Time) ([]datastore.MarketDataPoint, error) {
+	if p.source != epexSourceAPI {
+		return p.fetchPage(ctx, params, date)
+	}
+	data, err := p.fetchAPI(ctx, params, date)
+	if err == nil || !p.fallback {
+		return data, err
+	}
+	data, pageErr := p.fetchPage(ctx, params, date)
+	if pageErr != nil {
+		return nil, fmt.Errorf("API failed (%v), page fallback failed: %w", err, pageErr)
+	}
+	return data, nil
+}
+
+// fetchAPI reads the results of one market area and auction from the JSON
+// endpoint. The {market_area}, {auction} and {trading_date} placeholders of
+// api_url are replaced along with those of the JSON API provider, {date}
+// being the delivery day.
+func (p *EPEXProvider) fetchAPI(ctx context.Context, params map[string]string, date time.Time) ([]datastore.MarketDataPoint, error) {
+	endpoint := strings.NewReplacer(
+		"{market_area}", url.QueryEscape(params["market_area"]),
+		"{auction}", url.QueryEscape(params["auction"]),
+		"{trading_date}", date.AddDate(0, 0, -1).Format("2006-01-02"),
+	).Replace(p.apiURL)
+
+	api := NewJSONAPIProvider(map[string]string{
+		"url":     endpoint,
+		"records": params["api.records"],
+		"period":  defaultParam(params["api.period"], "period"),
+		"volume":  defaultParam(params["api.volume"], "volume"),
+		"price":   defaultParam(params["api.price"], "price"),
+	}, p.location)
+	api.SetRequestOptions(p.request)
+	return api.FetchData(ctx, date)
+}
+
+// defaultParam returns value, or fallback when it is empty
+func defaultParam(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// fetchPage scrapes the market results page of one market area and auction
+func (p *EPEXProvider) fetchPage(ctx context.Context, params map[string]string, date time.Time) ([]datastore.MarketDataPoint, error) {
 	tradingDate := date.AddDate(0, 0, -1).Format("2006-01-02")
 	deliveryDate := date.Format("2006-01-02")
 
 	// Build URL with configurable parameters
-	url := p.buildURL(tradingDate, deliveryDate)
-
-	client := &http.Client{Timeout: p.timeout}
+	url := p.buildURL(params, tradingDate, deliveryDate)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	applyRequestOptions(req, p.request, epexUserAgent)
 
-	resp, err := client.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -86,19 +306,16 @@ func (p *EPEXProvider) FetchData(ctx context.Context, date time.Time) ([]datasto
 		return nil, fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return p.parseHTMLData(resp.Body)
+}
+
+// parseHTMLData parses the HTML page as it streams in to extract market data
+func (p *EPEXProvider) parseHTMLData(r io.Reader) ([]datastore.MarketDataPoint, error) {
+	periods, volumes, prices, err := parseEPEXPage(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return p.parseHTMLData(string(body))
-}
-
-// parseHTMLData parses HTML content to extract market data
-func (p *EPEXProvider) parseHTMLData(html string) ([]datastore.MarketDataPoint, error) {
-	periods := p.extractPeriods(html)
-	volumes, prices := p.extractTableData(html)
-
 	if len(periods) == 0 || len(volumes) == 0 || len(prices) == 0 {
 		return nil, fmt.Errorf("failed to extract data from HTML")
 	}
@@ -106,15 +323,19 @@ func (p *EPEXProvider) parseHTMLData(html string) ([]datastore.MarketDataPoint,
 	minLen := minInt(len(periods), len(volumes), len(prices))
 	data := make([]datastore.MarketDataPoint, 0, minLen)
 
+	var invalid error
 	for i := 0; i < minLen; i++ {
-		volume, err := strconv.ParseFloat(volumes[i], 64)
-		if err != nil {
-			continue // Skip invalid data
+		volume, err := number.Parse(volumes[i])
+		price, priceErr := number.Parse(prices[i])
+		if err == nil {
+			err = priceErr
 		}
-
-		price, err := strconv.ParseFloat(prices[i], 64)
 		if err != nil {
-			continue // Skip invalid data
+			// Periods without trades show "–" and are skipped
+			if invalid == nil && !errors.Is(err, number.ErrMissing) {
+				invalid = fmt.Errorf("period %s: %w", periods[i], err)
+			}
+			continue
 		}
 
 		data = append(data, datastore.MarketDataPoint{
@@ -125,119 +346,58 @@ func (p *EPEXProvider) parseHTMLData(html string) ([]datastore.MarketDataPoint,
 	}
 
 	if len(data) == 0 {
+		if invalid != nil {
+			return nil, fmt.Errorf("no valid data points extracted: %w", invalid)
+		}
 		return nil, fmt.Errorf("no valid data points extracted")
 	}
 
 	return data, nil
 }
 
-// extractPeriods extracts time periods from HTML
-func (p *EPEXProvider) extractPeriods(html string) []string {
-	var periods []string
-
-	re := regexp.MustCompile(`<a href="#">(\d{2}:\d{2}\s*-\s*\d{2}:\d{2})</a>`)
-	matches := re.FindAllStringSubmatch(html, -1)
-
-	for _, match := range matches {
-		if len(match) > 1 {
-			period := strings.ReplaceAll(match[1], " ", "")
-			periods = append(periods, period)
+// validateEPEXSource checks the source and merge parameters of the EPEX
+// provider
+func validateEPEXSource(params map[string]string) error {
+	switch source := strings.ToLower(params["source"]); source {
+	case "", epexSourcePage:
+	case epexSourceAPI:
+		apiURL := params["api_url"]
+		if !strings.HasPrefix(apiURL, "http://") && !strings.HasPrefix(apiURL, "https://") {
+			return fmt.Errorf("EPEX provider source api requires an http(s) api_url parameter, got %q", apiURL)
 		}
+	default:
+		return fmt.Errorf("EPEX provider source must be page or api, got %q", source)
 	}
-
-	return periods
-}
-
-// extractTableData extracts volume and price data from HTML table
-func (p *EPEXProvider) extractTableData(html string) ([]string, []string) {
-	var volumes []string
-	var prices []string
-
-	// Find tbody section
-	tbodyStart := strings.Index(html, "<tbody>")
-	tbodyEnd := strings.Index(html, "</tbody>")
-
-	if tbodyStart == -1 || tbodyEnd == -1 {
-		return volumes, prices
-	}
-
-	tbodyContent := html[tbodyStart:tbodyEnd]
-
-	// Try primary extraction method
-	if vols, prs := p.extractFromRows(tbodyContent); len(vols) > 0 {
-		return vols, prs
-	}
-
-	// Fallback to alternative method
-	return p.extractFromCells(tbodyContent)
-}
-
-// extractFromRows extracts data from table rows
-func (p *EPEXProvider) extractFromRows(tbodyContent string) ([]string, []string) {
-	var volumes []string
-	var prices []string
-
-	trRe := regexp.MustCompile(`<tr\s+class="child[^"]*"[^>]*>([\s\S]*?)</tr>`)
-	trMatches := trRe.FindAllStringSubmatch(tbodyContent, -1)
-
-	for _, trMatch := range trMatches {
-		if len(trMatch) < 2 {
-			continue
-		}
-
-		rowContent := trMatch[1]
-		tdRe := regexp.MustCompile(`<td[^>]*>([^<]+)</td>`)
-		tdMatches := tdRe.FindAllStringSubmatch(rowContent, -1)
-
-		// Each row should have 4 columns: Buy Volume, Sell Volume, Volume, Price
-		if len(tdMatches) == 4 {
-			volume := strings.TrimSpace(tdMatches[2][1]) // 3rd column = Volume
-			price := strings.TrimSpace(tdMatches[3][1])  // 4th column = Price
-
-			volumes = append(volumes, volume)
-			prices = append(prices, price)
+	if fallback := params["fallback"]; fallback != "" {
+		if _, err := strconv.ParseBool(fallback); err != nil {
+			return fmt.Errorf("EPEX provider fallback must be true or false, got %q", fallback)
 		}
 	}
-
-	return volumes, prices
-}
-
-// extractFromCells extracts data from individual cells (fallback method)
-func (p *EPEXProvider) extractFromCells(tbodyContent string) ([]string, []string) {
-	var volumes []string
-	var prices []string
-
-	tdRe := regexp.MustCompile(`<td[^>]*>([^<]+)</td>`)
-	tdMatches := tdRe.FindAllStringSubmatch(tbodyContent, -1)
-
-	// Data is in groups of 4: Buy, Sell, Volume, Price
-	for i := 0; i+3 < len(tdMatches); i += 4 {
-		volume := strings.TrimSpace(tdMatches[i+2][1]) // 3rd column
-		price := strings.TrimSpace(tdMatches[i+3][1])  // 4th column
-
-		volumes = append(volumes, volume)
-		prices = append(prices, price)
+	switch merge := strings.ToLower(params["merge"]); merge {
+	case "", epexMergeSum, epexMergePriority:
+	default:
+		return fmt.Errorf("EPEX provider merge must be sum or priority, got %q", merge)
 	}
-
-	return volumes, prices
+	return nil
 }
 
 // buildURL constructs the EPEX URL with configurable parameters
-func (p *EPEXProvider) buildURL(tradingDate, deliveryDate string) string {
+func (p *EPEXProvider) buildURL(params map[string]string, tradingDate, deliveryDate string) string {
 	baseParams := fmt.Sprintf("trading_date=%s&delivery_date=%s", tradingDate, deliveryDate)
 
 	// Add configured parameters
-	var params []string
-	params = append(params, baseParams)
-
-	for key, value := range p.params {
-		params = append(params, fmt.Sprintf("%s=%s", key, value))
+	query := []string{baseParams}
+	for key, value := range params {
+		if isAuthParam(key) || slices.Contains(epexParams, key) {
+			continue
+		}
+		query = append(query, fmt.Sprintf("%s=%s", key, value))
 	}
 
 	// Add empty parameters that EPEX expects
-	params = append(params, "underlying_year=", "technology=", "period=", "production_period=")
+	query = append(query, "underlying_year=", "technology=", "period=", "production_period=")
 
-	return fmt.Sprintf("%s?%s", p.baseURL, strings.Join(params, "&"))
+	return fmt.Sprintf("%s?%s", p.baseURL, strings.Join(query, "&"))
 }
 
 // minInt returns the minimum of three integers
@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// epexPeriodRe matches the period links of the results page, e.g. "00:00 - 00:15"
+var epexPeriodRe = regexp.MustCompile(`^\d{2}:\d{2}\s*-\s*\d{2}:\d{2}$`)
+
+// epexPage collects the periods and the results table of an EPEX page while
+// it is tokenized, keeping only the extracted values in memory
+type epexPage struct {
+	periods []string
+
+	// Volume and price of the "child" rows with four cells: buy volume,
+	// sell volume, volume and price
+	volumes, prices []string
+
+	// Every text cell of the table, read in groups of four when the rows
+	// have no "child" class
+	cells []string
+
+	inTable  bool // Inside the first tbody
+	seenBody bool // The first tbody has ended
+	inRow    bool // Inside a "child" row
+	row      []string
+
+	inLink bool // Inside a period link
+	inCell bool // Inside a table cell
+	nested bool // The current link or cell contains other tags
+	text   bytes.Buffer
+}
+
+// parseEPEXPage streams the HTML page from r and returns its periods, and
+// the volume and price of each period
+func parseEPEXPage(r io.Reader) (periods, volumes, prices []string, err error) {
+	page := &epexPage{}
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, nil, nil, err
+			}
+			volumes, prices := page.table()
+			return page.periods, volumes, prices, nil
+		case html.StartTagToken:
+			page.startTag(z)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			page.endTag(string(name))
+		case html.TextToken:
+			if page.inLink || page.inCell {
+				page.text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// startTag tracks the links, rows and cells opened by a start tag
+func (p *epexPage) startTag(z *html.Tokenizer) {
+	name, hasAttr := z.TagName()
+	attrs := map[string]string{}
+	for hasAttr {
+		var key, value []byte
+		key, value, hasAttr = z.TagAttr()
+		attrs[string(key)] = string(value)
+	}
+
+	if p.inLink || p.inCell {
+		p.nested = true
+	}
+	switch string(name) {
+	case "a":
+		if len(attrs) == 1 && attrs["href"] == "#" {
+			p.inLink, p.nested = true, false
+			p.text.Reset()
+		}
+	case "tbody":
+		if !p.seenBody {
+			p.inTable = true
+		}
+	case "tr":
+		if p.inTable {
+			p.inRow = strings.HasPrefix(attrs["class"], "child")
+			p.row = p.row[:0]
+		}
+	case "td":
+		if p.inTable {
+			p.inCell, p.nested = true, false
+			p.text.Reset()
+		}
+	}
+}
+
+// endTag records the period, cell or row closed by an end tag
+func (p *epexPage) endTag(name string) {
+	switch name {
+	case "a":
+		if p.inLink && !p.nested && epexPeriodRe.MatchString(p.text.String()) {
+			p.periods = append(p.periods, strings.ReplaceAll(p.text.String(), " ", ""))
+		}
+		p.inLink = false
+	case "td":
+		// Only cells holding nothing but text count
+		if p.inCell && !p.nested && p.text.Len() > 0 {
+			cell := strings.TrimSpace(p.text.String())
+			p.cells = append(p.cells, cell)
+			if p.inRow {
+				p.row = append(p.row, cell)
+			}
+		}
+		p.inCell = false
+	case "tr":
+		if p.inRow && len(p.row) == 4 {
+			p.volumes = append(p.volumes, p.row[2])
+			p.prices = append(p.prices, p.row[3])
+		}
+		p.inRow = false
+	case "tbody":
+		if p.inTable {
+			p.inTable, p.seenBody = false, true
+		}
+	}
+}
+
+// table returns the volumes and prices of the "child" rows, or of the cells
+// in groups of four without such rows
+func (p *epexPage) table() ([]string, []string) {
+	if len(p.volumes) > 0 {
+		return p.volumes, p.prices
+	}
+
+	var volumes, prices []string
+	for i := 0; i+3 < len(p.cells); i += 4 {
+		volumes = append(volumes, p.cells[i+2])
+		prices = append(prices, p.cells[i+3])
+	}
+	return volumes, prices
+}
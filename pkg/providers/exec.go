@@ -0,0 +1,195 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+const (
+	// defaultExecTimeout bounds a command run when no timeout is configured
+	defaultExecTimeout = 30 * time.Second
+
+	// execMaxOutput bounds the output of a command read as market data
+	execMaxOutput = 4 << 20
+
+	// execMaxStderr bounds the standard error quoted in failures
+	execMaxStderr = 512
+)
+
+// ExecProvider runs an external command printing the market data of a day on
+// its standard output, as a CSV file in the format of the daily files or a
+// JSON array of points. It is the quickest way to plug a custom source in.
+type ExecProvider struct {
+	command  []string
+	format   string
+	timeout  time.Duration
+	name     string
+	intraday bool
+	location *time.Location
+}
+
+// NewExecProvider creates an exec provider from the parameters: command, the
+// program and its arguments separated by spaces, with {{date}} and the other
+// placeholders replaced by the market day, format (csv, json, or detected
+// from the output by default), timeout (default 30s), name, naming the data
+// files (default exec), and intraday, true when the command's data of the
+// current day changes
+func NewExecProvider(params map[string]string, location *time.Location) *ExecProvider {
+	timeout, err := time.ParseDuration(params["timeout"])
+	if err != nil || timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	name := params["name"]
+	if name == "" {
+		name = "exec"
+	}
+	intraday, _ := strconv.ParseBool(params["intraday"])
+	if location == nil {
+		location = time.UTC
+	}
+	return &ExecProvider{
+		command:  strings.Fields(params["command"]),
+		format:   strings.ToLower(params["format"]),
+		timeout:  timeout,
+		name:     name,
+		intraday: intraday,
+		location: location,
+	}
+}
+
+// GetName returns the provider name
+func (p *ExecProvider) GetName() string {
+	if len(p.command) == 0 {
+		return "Exec"
+	}
+	return "Exec (" + p.command[0] + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *ExecProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("%s_%s.csv", p.name, date.Format("2006-01-02"))
+}
+
+// Intraday reports whether the command is run again on every refresh for the
+// current day
+func (p *ExecProvider) Intraday() bool {
+	return p.intraday
+}
+
+// FetchData runs the command for the given date. The bounds of the market
+// day are also passed in the POWERCAP_DATE, POWERCAP_START and POWERCAP_END
+// environment variables. A command failing, running past the timeout or
+// printing no valid line fails the fetch.
+func (p *ExecProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	if len(p.command) == 0 {
+		return nil, fmt.Errorf("exec provider has no command")
+	}
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	args := make([]string, len(p.command))
+	for i, arg := range p.command {
+		args[i] = expandTemplate(arg, start, end)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"POWERCAP_DATE="+start.Format("2006-01-02"),
+		"POWERCAP_START="+start.Format(time.RFC3339),
+		"POWERCAP_END="+end.Format(time.RFC3339),
+	)
+	// Children keeping the output open must not hold the fetch past the timeout
+	cmd.WaitDelay = time.Second
+	stdout := &cappedBuffer{max: execMaxOutput, full: cancel}
+	stderr := &cappedBuffer{max: execMaxStderr}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	if stdout.overflow {
+		return nil, fmt.Errorf("%s printed more than %d bytes", args[0], execMaxOutput)
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s timed out after %s", args[0], p.timeout)
+		}
+		if message := strings.TrimSpace(stderr.buf.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %w: %s", args[0], err, message)
+		}
+		return nil, fmt.Errorf("%s failed: %w", args[0], err)
+	}
+
+	output := bytes.TrimSpace(stdout.buf.Bytes())
+	isJSON := p.format == "json" || (p.format == "" && bytes.HasPrefix(output, []byte("[")))
+	data, err := decodeMarketData(bytes.NewReader(output), isJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output of %s: %w", args[0], err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s printed no market data for %s", args[0], start.Format("2006-01-02"))
+	}
+	return data, nil
+}
+
+// cappedBuffer keeps the first max bytes written to it, so a runaway command
+// cannot exhaust the memory, and calls full, if set, once more was written
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	full     func()
+	overflow bool
+}
+
+// Write keeps what fits and discards the rest
+func (b *cappedBuffer) Write(data []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(data) > room {
+		b.buf.Write(data[:max(room, 0)])
+		if !b.overflow && b.full != nil {
+			b.full()
+		}
+		b.overflow = true
+		return len(data), nil
+	}
+	return b.buf.Write(data)
+}
+
+// validateExecParams checks the exec provider parameters
+func validateExecParams(params map[string]string) error {
+	command := strings.Fields(params["command"])
+	if len(command) == 0 {
+		return fmt.Errorf("exec provider requires the command parameter")
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return fmt.Errorf("exec provider command %q cannot be run: %w", command[0], err)
+	}
+	switch format := strings.ToLower(params["format"]); format {
+	case "", "csv", "json":
+	default:
+		return fmt.Errorf("exec provider format must be csv or json, got %q", format)
+	}
+	if timeout := params["timeout"]; timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			return fmt.Errorf("exec provider timeout must be a positive duration such as 30s, got %q", timeout)
+		}
+	}
+	if name := params["name"]; strings.ContainsAny(name, `/\ `) {
+		return fmt.Errorf("exec provider name %q must not contain slashes or spaces", name)
+	}
+	if intraday := params["intraday"]; intraday != "" {
+		if _, err := strconv.ParseBool(intraday); err != nil {
+			return fmt.Errorf("exec provider intraday must be true or false, got %q", intraday)
+		}
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package providers
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"kcas/new/internal/config"
+	"kcas/new/internal/currency"
 	"kcas/new/internal/datastore"
 )
 
@@ -18,48 +22,208 @@ func NewProviderFactory() *ProviderFactory {
 
 // CreateProvider creates a provider based on configuration
 func (f *ProviderFactory) CreateProvider(cfg *config.Config) (datastore.MarketDataProvider, error) {
-	providerType := strings.ToLower(cfg.DataProvider)
+	if len(cfg.FailoverProviders) > 0 {
+		// The parameters of the failover providers are theirs only
+		own := *cfg
+		own.ProviderParams = withoutScoped(cfg.ProviderParams, cfg.FailoverProviders)
+		cfg = &own
+	}
 
-	switch providerType {
-	case "epex":
-		return NewEPEXProvider(cfg.ProviderURL, cfg.ProviderParams), nil
+	provider, err := f.createConfigured(cfg)
+	if err != nil {
+		return nil, err
+	}
+	provider = cached(cfg, provider)
 
-	case "mock":
-		return NewMockProvider(), nil
+	if cfg.ClosedDayProvider != "" && cfg.ClosedDayProvider != strings.ToLower(cfg.DataProvider) {
+		cal, err := cfg.MarketCalendar()
+		if err != nil {
+			return nil, err
+		}
+		if cal != nil {
+			// The closed day provider uses its own default URL, and never
+			// the credentials of DATA_PROVIDER
+			closedCfg := *cfg
+			closedCfg.DataProvider = cfg.ClosedDayProvider
+			closedCfg.ProviderURL = ""
+			closedCfg.ProviderParams = withoutAuth(cfg.ProviderParams)
+			closedDay, err := f.createConfigured(&closedCfg)
+			if err != nil {
+				return nil, fmt.Errorf("closed day provider: %w", err)
+			}
+			provider = NewCalendarProvider(provider, cached(cfg, closedDay), cal)
+		}
+	}
 
-	case "static":
-		return NewStaticProviderWithDefaults(), nil
+	if cfg.ProviderCurrency != "" && cfg.ProviderCurrency != cfg.Currency {
+		var converter currency.Converter
+		if cfg.CurrencyRate > 0 {
+			converter = currency.Static{From: cfg.ProviderCurrency, To: cfg.Currency, Value: cfg.CurrencyRate}
+		} else {
+			converter = currency.NewECB(cfg.CurrencyRatesURL)
+		}
+		provider = NewCurrencyProvider(provider, cfg.ProviderCurrency, cfg.Currency, converter)
+	}
+	return provider, nil
+}
+
+// CreateFailovers creates the providers used, in order, while the
+// configured one keeps failing, none without FAILOVER_PROVIDER. They use
+// their own default URL, and the PROVIDER_PARAMS prefixed by their name,
+// e.g. watttime.username, never those of DATA_PROVIDER.
+func (f *ProviderFactory) CreateFailovers(cfg *config.Config) ([]datastore.MarketDataProvider, error) {
+	var failovers []datastore.MarketDataProvider
+	for _, name := range cfg.FailoverProviders {
+		failoverCfg := scopedConfig(cfg, name)
+		failoverCfg.ProviderURL = ""
+		failoverCfg.FailoverProviders = nil
+		if err := f.ValidateProviderConfig(failoverCfg); err != nil {
+			return nil, fmt.Errorf("failover provider %s: %w", name, err)
+		}
+		provider, err := f.CreateProvider(failoverCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failover provider %s: %w", name, err)
+		}
+		failovers = append(failovers, provider)
+	}
+	return failovers, nil
+}
+
+// scopedConfig returns the configuration of a provider standing in for or
+// combined with the configured one, with the parameters prefixed by its
+// name, e.g. watttime.region. EPEX without parameters uses the default
+// market.
+func scopedConfig(cfg *config.Config, name string) *config.Config {
+	scoped := *cfg
+	scoped.DataProvider = name
+	scoped.ProviderParams = nil
+	for key, value := range cfg.ProviderParams {
+		if param, ok := strings.CutPrefix(key, name+"."); ok {
+			if scoped.ProviderParams == nil {
+				scoped.ProviderParams = make(map[string]string)
+			}
+			scoped.ProviderParams[param] = value
+		}
+	}
+	if scoped.ProviderParams == nil && name == "epex" {
+		json.Unmarshal([]byte(config.DefaultProviderParams), &scoped.ProviderParams)
+	}
+	return &scoped
+}
+
+// withoutScoped returns a copy of the provider parameters without those
+// prefixed by one of the names, which belong to those providers
+func withoutScoped(params map[string]string, names []string) map[string]string {
+	copied := make(map[string]string, len(params))
+	for key, value := range params {
+		prefix, _, ok := strings.Cut(key, ".")
+		if !ok || !slices.Contains(names, prefix) {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// withoutAuth returns a copy of the provider parameters without the auth.*
+// ones, for another provider than the one they authenticate to
+func withoutAuth(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(params))
+	for key, value := range params {
+		if !isAuthParam(key) {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// cached wraps a provider with the cache of PROVIDER_CACHE_TTL when set.
+// Providers pushing their data keep it in memory already and are left alone.
+func cached(cfg *config.Config, provider datastore.MarketDataProvider) datastore.MarketDataProvider {
+	if cfg.ProviderCacheTTL <= 0 {
+		return provider
+	}
+	if _, ok := provider.(datastore.PushProvider); ok {
+		return provider
+	}
+	dir := cfg.ProviderCacheDir
+	if dir == "" {
+		dir = filepath.Join(cfg.DataDir, "provider-cache")
+	}
+	return NewCachingProvider(provider, cfg.ProviderCacheTTL, dir)
+}
 
-	default:
-		return nil, fmt.Errorf("unknown provider type: %s. Supported types: epex, mock, static", cfg.DataProvider)
+// createConfigured instantiates the configured provider type with the
+// request options
+func (f *ProviderFactory) createConfigured(cfg *config.Config) (datastore.MarketDataProvider, error) {
+	provider, err := f.createProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := ParseAuth(cfg.ProviderParams)
+	if err != nil {
+		return nil, err
 	}
+
+	if configurable, ok := provider.(RequestConfigurable); ok {
+		configurable.SetRequestOptions(RequestOptions{
+			Timeout:   cfg.ProviderTimeout,
+			UserAgent: cfg.ProviderUserAgent,
+			Headers:   cfg.ProviderHeaders,
+			Parallel:  cfg.ProviderParallel,
+			Auth:      auth,
+		})
+	}
+	return provider, nil
 }
 
-// GetSupportedProviders returns a list of supported provider types
+// createProvider instantiates the configured provider type from its
+// registration
+func (f *ProviderFactory) createProvider(cfg *config.Config) (datastore.MarketDataProvider, error) {
+	r, ok := lookup(cfg.DataProvider)
+	if !ok {
+		return nil, unknownProvider(cfg.DataProvider)
+	}
+	return r.create(f, cfg)
+}
+
+// apiURL returns PROVIDER_URL for providers other than EPEX, empty when it
+// is left at the EPEX default so they use their own
+func apiURL(cfg *config.Config) string {
+	if cfg.ProviderURL == config.DefaultProviderURL {
+		return ""
+	}
+	return cfg.ProviderURL
+}
+
+// GetSupportedProviders returns the registered provider types, the
+// built-in ones first
 func (f *ProviderFactory) GetSupportedProviders() []string {
-	return []string{"epex", "mock", "static"}
+	return supportedProviders()
 }
 
 // ValidateProviderConfig validates provider configuration
 func (f *ProviderFactory) ValidateProviderConfig(cfg *config.Config) error {
-	supported := f.GetSupportedProviders()
-	providerType := strings.ToLower(cfg.DataProvider)
-
-	// Check if provider type is supported
-	for _, p := range supported {
-		if p == providerType {
-			// Provider type is valid, perform provider-specific validation
-			return f.validateSpecificProvider(providerType, cfg)
-		}
+	if _, err := ParseAuth(cfg.ProviderParams); err != nil {
+		return err
 	}
 
-	return fmt.Errorf("unsupported provider type: %s. Supported types: %v", cfg.DataProvider, supported)
+	r, ok := lookup(cfg.DataProvider)
+	if !ok {
+		return fmt.Errorf("unsupported provider type: %s. Supported types: %v", cfg.DataProvider, f.GetSupportedProviders())
+	}
+	if r.validate == nil {
+		return nil
+	}
+	return r.validate(f, cfg)
 }
 
-// validateSpecificProvider performs provider-specific validation
-func (f *ProviderFactory) validateSpecificProvider(providerType string, cfg *config.Config) error {
-	switch providerType {
-	case "epex":
+func init() {
+	register("epex", func(_ *ProviderFactory, cfg *config.Config) (datastore.MarketDataProvider, error) {
+		return NewEPEXProvider(cfg.ProviderURL, cfg.ProviderParams, cfg.MarketLocation()), nil
+	}, func(_ *ProviderFactory, cfg *config.Config) error {
 		if cfg.ProviderURL == "" {
 			return fmt.Errorf("EPEX provider requires a valid URL")
 		}
@@ -70,16 +234,103 @@ func (f *ProviderFactory) validateSpecificProvider(providerType string, cfg *con
 				return fmt.Errorf("EPEX provider missing required parameter: %s", param)
 			}
 		}
+		return validateEPEXSource(cfg.ProviderParams)
+	})
+
+	Register("mock", func(opts Options) (MarketDataProvider, error) {
+		return NewMockProviderWithScenario(opts.Params["scenario"]), nil
+	})
+	RegisterValidator("mock", func(opts Options) error {
+		if scenario := strings.ToLower(opts.Params["scenario"]); scenario != "" {
+			if _, ok := mockScenarios[scenario]; !ok {
+				return fmt.Errorf("unknown mock scenario %q, expected one of %s", scenario, strings.Join(mockScenarioNames(), ", "))
+			}
+		}
+		return nil
+	})
+
+	Register("static", func(opts Options) (MarketDataProvider, error) {
+		if path := opts.Params["path"]; path != "" {
+			return NewStaticProviderFromFile(path, opts.Location)
+		}
+		return NewStaticProviderWithDefaults(), nil
+	})
+	RegisterValidator("static", func(opts Options) error {
+		if path := opts.Params["path"]; path != "" {
+			if _, err := NewStaticProviderFromFile(path, nil); err != nil {
+				return fmt.Errorf("static provider: %w", err)
+			}
+		}
+		return nil
+	})
 
-	case "mock":
-		// Mock provider doesn't require special validation
+	Register("energy-charts", func(opts Options) (MarketDataProvider, error) {
+		return NewEnergyChartsProvider(opts.URL, opts.Params, opts.Location), nil
+	})
+	RegisterValidator("energy-charts", func(opts Options) error {
+		if country := opts.Params["country"]; country != "" && len(country) != 2 {
+			return fmt.Errorf("Energy-Charts provider expects a two-letter country code, got %q", country)
+		}
+		return nil
+	})
 
-	case "static":
-		// Static provider doesn't require special validation
+	Register("watttime", func(opts Options) (MarketDataProvider, error) {
+		return NewWattTimeProvider(opts.URL, opts.Params, opts.Location), nil
+	})
+	RegisterValidator("watttime", paramsValidator(validateWattTimeParams))
 
-	default:
-		return fmt.Errorf("unknown provider type for validation: %s", providerType)
-	}
+	Register("eco2mix", func(opts Options) (MarketDataProvider, error) {
+		return NewEco2mixProvider(opts.URL, opts.Params, opts.Location), nil
+	})
+	RegisterValidator("eco2mix", paramsValidator(validateEco2mixParams))
+
+	Register("carbonintensity", func(opts Options) (MarketDataProvider, error) {
+		return NewCarbonIntensityProvider(opts.URL, opts.Params, opts.Location), nil
+	})
+	RegisterValidator("carbonintensity", paramsValidator(validateCarbonIntensityParams))
+
+	Register("solar", func(opts Options) (MarketDataProvider, error) {
+		return NewSolarProvider(opts.URL, opts.Params, opts.Location), nil
+	})
+	RegisterValidator("solar", paramsValidator(validateSolarParams))
+
+	Register("jsonapi", func(opts Options) (MarketDataProvider, error) {
+		return NewJSONAPIProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("jsonapi", paramsValidator(validateJSONAPIParams))
 
-	return nil
+	Register("csvurl", func(opts Options) (MarketDataProvider, error) {
+		return NewCSVURLProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("csvurl", paramsValidator(validateCSVURLParams))
+
+	Register("file", func(opts Options) (MarketDataProvider, error) {
+		return NewFileProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("file", paramsValidator(validateFileParams))
+
+	Register("mqtt", func(opts Options) (MarketDataProvider, error) {
+		return NewMQTTProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("mqtt", paramsValidator(validateMQTTParams))
+
+	Register("exec", func(opts Options) (MarketDataProvider, error) {
+		return NewExecProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("exec", paramsValidator(validateExecParams))
+
+	Register("replay", func(opts Options) (MarketDataProvider, error) {
+		return NewReplayProvider(opts.Params, opts.Location), nil
+	})
+	RegisterValidator("replay", paramsValidator(validateReplayParams))
+
+	register("composite", (*ProviderFactory).createComposite, (*ProviderFactory).validateComposite)
+}
+
+// paramsValidator checks the PROVIDER_PARAMS of a provider with one of the
+// parameter checks
+func paramsValidator(validate func(params map[string]string) error) Validator {
+	return func(opts Options) error {
+		return validate(opts.Params)
+	}
 }
@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// filePatterns are the file names tried in the directory when no pattern is
+// configured
+var filePatterns = []string{"{{date}}.csv", "{{date}}.json"}
+
+// FileProvider reads pre-generated market data from a local directory,
+// synced by another process on air-gapped clusters. Each day is a CSV file
+// in the format of the daily files, or a JSON array of points with the
+// fields of the decisions' market data (period, volume_mwh, price_eur_mwh).
+type FileProvider struct {
+	dir      string
+	patterns []string
+	intraday bool
+	location *time.Location
+}
+
+// NewFileProvider creates a file provider from the parameters: dir, pattern,
+// the file name of a day with {{date}} and the other placeholders replaced
+// by the market day (default {{date}}.csv, then {{date}}.json), and intraday,
+// true when the file of the current day keeps being updated
+func NewFileProvider(params map[string]string, location *time.Location) *FileProvider {
+	patterns := filePatterns
+	if pattern := params["pattern"]; pattern != "" {
+		patterns = []string{pattern}
+	}
+	intraday, _ := strconv.ParseBool(params["intraday"])
+	if location == nil {
+		location = time.UTC
+	}
+	return &FileProvider{
+		dir:      params["dir"],
+		patterns: patterns,
+		intraday: intraday,
+		location: location,
+	}
+}
+
+// GetName returns the provider name
+func (p *FileProvider) GetName() string {
+	return "File (" + p.dir + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *FileProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("file_data_%s.csv", date.Format("2006-01-02"))
+}
+
+// Intraday reports whether the file of the current day is read again on
+// every refresh
+func (p *FileProvider) Intraday() bool {
+	return p.intraday
+}
+
+// FetchData reads the file of the given date, the first of the patterns
+// found in the directory
+func (p *FileProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	for _, pattern := range p.patterns {
+		path := filepath.Join(p.dir, expandTemplate(pattern, start, end))
+		file, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close()
+
+		data, err := decodeMarketData(file, strings.EqualFold(filepath.Ext(path), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("no market data in %s", path)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("no file for %s in %s", start.Format("2006-01-02"), p.dir)
+}
+
+// decodeMarketData reads a day of market data, a CSV file in the format of
+// the daily files or a JSON array of points
+func decodeMarketData(r io.Reader, isJSON bool) ([]datastore.MarketDataPoint, error) {
+	if isJSON {
+		var data []datastore.MarketDataPoint
+		if err := json.NewDecoder(r).Decode(&data); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return data, nil
+	}
+	parsed, err := datastore.ParseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := datastore.CheckCSVHeader(parsed.Header); err != nil {
+		return nil, err
+	}
+	return parsed.Points, nil
+}
+
+// validateFileParams checks the file provider parameters
+func validateFileParams(params map[string]string) error {
+	dir := params["dir"]
+	if dir == "" {
+		return fmt.Errorf("file provider requires the dir parameter")
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("file provider dir %q is not a readable directory", dir)
+	}
+	if pattern := params["pattern"]; pattern != "" && !strings.Contains(pattern, "{") {
+		return fmt.Errorf("file provider pattern %q has no date placeholder such as {{date}}", pattern)
+	}
+	if intraday := params["intraday"]; intraday != "" {
+		if _, err := strconv.ParseBool(intraday); err != nil {
+			return fmt.Errorf("file provider intraday must be true or false, got %q", intraday)
+		}
+	}
+	return nil
+}
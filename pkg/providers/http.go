@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// headerParamPrefix prefixes the provider parameters holding request
+// headers, e.g. header.Authorization
+const headerParamPrefix = "header."
+
+// defaultRequestTimeout bounds provider requests when no timeout is configured
+const defaultRequestTimeout = 30 * time.Second
+
+// RequestOptions configures the HTTP requests made by a provider
+type RequestOptions struct {
+	Timeout   time.Duration     // Overall request timeout, 0 for the default
+	UserAgent string            // User-Agent header, empty for the provider default
+	Headers   map[string]string // Extra headers sent with every request
+	Parallel  int               // Requests run at once when fetching several sources, 0 for the default
+	Auth      *Auth             // Credentials added to every request, nil for none
+}
+
+// RequestConfigurable is implemented by providers fetching data over HTTP
+type RequestConfigurable interface {
+	SetRequestOptions(opts RequestOptions)
+}
+
+// newHTTPClient creates a client enforcing the configured timeout and
+// authenticating the requests to the hosts of the provider endpoints
+func newHTTPClient(opts RequestOptions, endpoints ...string) *http.Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	if opts.Auth != nil {
+		client.Transport = &authTransport{base: http.DefaultTransport, auth: opts.Auth, hosts: authHosts(endpoints), timeout: timeout}
+	}
+	return client
+}
+
+// applyRequestOptions sets the User-Agent and extra headers on a request
+func applyRequestOptions(req *http.Request, opts RequestOptions, defaultUserAgent string) {
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// headerParams returns the request headers given as header.<Name> provider
+// parameters
+func headerParams(params map[string]string) map[string]string {
+	headers := make(map[string]string)
+	for key, value := range params {
+		if name, ok := strings.CutPrefix(key, headerParamPrefix); ok && name != "" {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// expandURL replaces the placeholders of a URL template with the bounds of
+// the market day, as expandTemplate does, query-escaped: the offset of
+// {start} and {end} in a zone east of UTC, such as +02:00, would otherwise
+// be read as a space
+func expandURL(template string, start, end time.Time) string {
+	return expandPlaceholders(template, start, end, url.QueryEscape)
+}
+
+// expandTemplate replaces the placeholders of a file name or command
+// argument template, {date} or {{date}} and likewise start, end, start_unix
+// and end_unix, with the bounds of the market day
+func expandTemplate(template string, start, end time.Time) string {
+	return expandPlaceholders(template, start, end, func(value string) string { return value })
+}
+
+// expandPlaceholders replaces the placeholders of a template with the
+// bounds of the market day, escaped by escape
+func expandPlaceholders(template string, start, end time.Time, escape func(string) string) string {
+	values := map[string]string{
+		"date":       start.Format("2006-01-02"),
+		"start":      start.Format(time.RFC3339),
+		"end":        end.Format(time.RFC3339),
+		"start_unix": strconv.FormatInt(start.Unix(), 10),
+		"end_unix":   strconv.FormatInt(end.Unix(), 10),
+	}
+	var replacements []string
+	for name, value := range values {
+		value = escape(value)
+		replacements = append(replacements, "{{"+name+"}}", value, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
@@ -0,0 +1,320 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/number"
+)
+
+// JSONAPIProvider reads market data from any REST API answering in JSON.
+// The parameters give the URL template, the path of the array of records
+// and the paths of the period, volume and price fields of a record, so an
+// in-house pricing API can feed the manager without a dedicated provider.
+type JSONAPIProvider struct {
+	url      string
+	records  []string
+	period   []string
+	volume   []string
+	price    []string
+	headers  map[string]string
+	name     string
+	location *time.Location
+	request  RequestOptions
+	client   *http.Client
+}
+
+// NewJSONAPIProvider creates a JSON API provider from the parameters: url
+// (with {date}, {start}, {end}, {start_unix} and {end_unix} replaced by the
+// market day), records, period, volume and price field paths, header.<Name>
+// request headers and name, naming the data files (default jsonapi)
+func NewJSONAPIProvider(params map[string]string, location *time.Location) *JSONAPIProvider {
+	name := params["name"]
+	if name == "" {
+		name = "jsonapi"
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &JSONAPIProvider{
+		url:      params["url"],
+		records:  parseFieldPath(params["records"]),
+		period:   parseFieldPath(params["period"]),
+		volume:   parseFieldPath(params["volume"]),
+		price:    parseFieldPath(params["price"]),
+		headers:  headerParams(params),
+		name:     name,
+		location: location,
+		client:   newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of API requests
+func (p *JSONAPIProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.url)
+}
+
+// GetName returns the provider name
+func (p *JSONAPIProvider) GetName() string {
+	return "JSON API (" + p.name + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *JSONAPIProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("%s_%s.csv", p.name, date.Format("2006-01-02"))
+}
+
+// FetchData fetches the records of the given date and maps them to periods.
+// A record covers the period range it names, or runs from its timestamp to
+// the next record's; records falling in the same period are averaged.
+func (p *JSONAPIProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, expandURL(p.url, start, end), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	applyRequestOptions(req, p.request, "")
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return nil, fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var body any
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	found, ok := lookupField(body, p.records)
+	if !ok {
+		return nil, fmt.Errorf("no records at %q", strings.Join(p.records, "."))
+	}
+	records, ok := found.([]any)
+	if !ok {
+		return nil, fmt.Errorf("records at %q are not an array", strings.Join(p.records, "."))
+	}
+
+	// Minutes since the market midnight covered by each record
+	type span struct {
+		from, until   int
+		volume, price float64
+		timestamp     bool
+		missing       bool // Ends the previous record without a value of its own
+	}
+	var spans []span
+	for i, record := range records {
+		value, ok := lookupField(record, p.period)
+		if !ok {
+			return nil, fmt.Errorf("record %d has no period", i)
+		}
+		from, until, timestamp, err := p.recordMinutes(value, start)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		volume, err := fieldNumber(record, p.volume)
+		if errors.Is(err, number.ErrMissing) {
+			spans = append(spans, span{from: from, until: until, timestamp: timestamp, missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d volume: %w", i, err)
+		}
+		var price float64
+		if len(p.price) > 0 {
+			if price, err = fieldNumber(record, p.price); err != nil && !errors.Is(err, number.ErrMissing) {
+				return nil, fmt.Errorf("record %d price: %w", i, err)
+			}
+		}
+		spans = append(spans, span{from: from, until: until, volume: volume, price: price, timestamp: timestamp})
+	}
+
+	// A timestamp holds until the next one, such as hourly records
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].from < spans[j].from })
+	for i := range spans {
+		if spans[i].timestamp && i+1 < len(spans) && spans[i+1].from > spans[i].from {
+			spans[i].until = spans[i+1].from
+		}
+	}
+
+	day := int(end.Sub(start).Minutes())
+	volumes := make(map[int]float64)
+	prices := make(map[int]float64)
+	counts := make(map[int]int)
+	for _, s := range spans {
+		if s.missing {
+			continue
+		}
+		for minute := s.from / 15 * 15; minute < s.until && minute < day; minute += 15 {
+			if minute < 0 {
+				continue
+			}
+			volumes[minute] += s.volume
+			prices[minute] += s.price
+			counts[minute]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no records for %s", start.Format("2006-01-02"))
+	}
+
+	var data []datastore.MarketDataPoint
+	for minute := 0; minute < day; minute += 15 {
+		if count := counts[minute]; count > 0 {
+			data = append(data, datastore.MarketDataPoint{
+				Period: quarterPeriod(start.Add(time.Duration(minute) * time.Minute)),
+				Volume: volumes[minute] / float64(count),
+				Price:  prices[minute] / float64(count),
+			})
+		}
+	}
+	return data, nil
+}
+
+// recordMinutes returns the minutes since the market midnight a period field
+// covers: a "HH:MM-HH:MM" range, or a timestamp (RFC 3339 or Unix seconds)
+// holding for 15 minutes until the next record is known
+func (p *JSONAPIProvider) recordMinutes(value any, start time.Time) (int, int, bool, error) {
+	if text, ok := value.(string); ok {
+		if from, until, found := strings.Cut(text, "-"); found && len(from) == 5 && len(until) == 5 {
+			fromMinutes, err1 := clockMinutes(from)
+			untilMinutes, err2 := clockMinutes(until)
+			if err1 != nil || err2 != nil {
+				return 0, 0, false, fmt.Errorf("invalid period %q", text)
+			}
+			if untilMinutes <= fromMinutes {
+				untilMinutes += 24 * 60
+			}
+			return fromMinutes, untilMinutes, false, nil
+		}
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("invalid period %q, expected HH:MM-HH:MM or an RFC 3339 time", text)
+		}
+		from := int(t.Sub(start).Minutes())
+		return from, from + 15, true, nil
+	}
+
+	seconds, err := fieldValueNumber(value)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid period %v", value)
+	}
+	from := int(time.Unix(int64(seconds), 0).Sub(start).Minutes())
+	return from, from + 15, true, nil
+}
+
+// clockMinutes parses "HH:MM" to minutes since midnight, 24:00 included
+func clockMinutes(value string) (int, error) {
+	hour, err := strconv.Atoi(value[:2])
+	if err != nil || value[2] != ':' {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	minute, err := strconv.Atoi(value[3:])
+	if err != nil || hour > 24 || minute > 59 || (hour == 24 && minute > 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// parseFieldPath splits a dotted path such as data.items or a JSONPath such
+// as $.data.items[*] into its keys, array indices being kept as keys
+func parseFieldPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	path = strings.TrimSuffix(path, "[*]")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	var keys []string
+	for _, key := range strings.Split(path, ".") {
+		if key = strings.Trim(key, `'"`); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// lookupField follows a field path through decoded JSON, indexing arrays
+// with numeric keys
+func lookupField(value any, path []string) (any, bool) {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// fieldNumber returns the number at a field path of a record, null and
+// missing placeholders returning number.ErrMissing
+func fieldNumber(record any, path []string) (float64, error) {
+	value, ok := lookupField(record, path)
+	if !ok {
+		return 0, fmt.Errorf("no field %q", strings.Join(path, "."))
+	}
+	return fieldValueNumber(value)
+}
+
+// fieldValueNumber converts a JSON number or numeric string
+func fieldValueNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, number.ErrMissing
+	case json.Number:
+		return v.Float64()
+	case string:
+		return number.Parse(v)
+	default:
+		return 0, fmt.Errorf("not a number: %v", value)
+	}
+}
+
+// validateJSONAPIParams checks the JSON API parameters
+func validateJSONAPIParams(params map[string]string) error {
+	rawURL := params["url"]
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return fmt.Errorf("JSON API provider requires an http(s) url parameter, got %q", rawURL)
+	}
+	for _, name := range []string{"period", "volume"} {
+		if len(parseFieldPath(params[name])) == 0 {
+			return fmt.Errorf("JSON API provider requires the %s field path parameter", name)
+		}
+	}
+	if name := params["name"]; strings.ContainsAny(name, `/\ `) {
+		return fmt.Errorf("JSON API provider name %q must not contain slashes or spaces", name)
+	}
+	return nil
+}
@@ -4,35 +4,113 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"kcas/new/internal/datastore"
 )
 
+// mockSine is the default scenario of the mock provider
+const mockSine = "sine"
+
+// mockScenarios reshape the sine day of the mock provider, so tests and demos
+// exercise the edge cases of real markets deterministically
+var mockScenarios = map[string]func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint{
+	mockSine: func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint {
+		return data
+	},
+
+	// The same volume and price all day
+	"flat": func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint {
+		for i := range data {
+			data[i].Volume, data[i].Price = 50, 50
+		}
+		return data
+	},
+
+	// An evening scarcity: 17:00 to 19:00 trade little at ten times the price
+	"price_spike": func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint {
+		for i := 68; i < 76; i++ {
+			data[i].Volume = 20
+			data[i].Price = math.Round(data[i].Price*1000) / 100
+		}
+		return data
+	},
+
+	// No data published from 10:00 to 12:00
+	"data_gap": func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint {
+		return slices.Delete(data, 40, 48)
+	},
+
+	// A midday solar surplus: 11:00 to 15:00 trade much below zero
+	"negative_prices": func(data []datastore.MarketDataPoint) []datastore.MarketDataPoint {
+		for i := 44; i < 60; i++ {
+			data[i].Volume = math.Round(data[i].Volume*15) / 10
+			data[i].Price = -math.Round((5+15*math.Sin(float64(i-44)*math.Pi/16))*100) / 100
+		}
+		return data
+	},
+}
+
+// mockScenarioNames returns the names of the mock provider scenarios
+func mockScenarioNames() []string {
+	names := make([]string, 0, len(mockScenarios))
+	for name := range mockScenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // MockProvider implements MarketDataProvider for testing/simulation
 type MockProvider struct {
-	name string
+	name     string
+	scenario string
 }
 
 // NewMockProvider creates a new mock market data provider
 func NewMockProvider() *MockProvider {
+	return NewMockProviderWithScenario(mockSine)
+}
+
+// NewMockProviderWithScenario creates a mock provider generating the named
+// scenario, the sine pattern when empty or unknown
+func NewMockProviderWithScenario(scenario string) *MockProvider {
+	scenario = strings.ToLower(scenario)
+	if _, ok := mockScenarios[scenario]; !ok {
+		scenario = mockSine
+	}
 	return &MockProvider{
-		name: "Mock",
+		name:     "Mock",
+		scenario: scenario,
 	}
 }
 
 // GetName returns the provider name
 func (p *MockProvider) GetName() string {
+	if p.scenario != mockSine {
+		return p.name + " (" + p.scenario + ")"
+	}
 	return p.name
 }
 
 // GetDataPath returns the file path for the given date
 func (p *MockProvider) GetDataPath(date time.Time) string {
+	if p.scenario != mockSine {
+		return fmt.Sprintf("mock_%s_%s.csv", p.scenario, date.Format("2006-01-02"))
+	}
 	return fmt.Sprintf("mock_data_%s.csv", date.Format("2006-01-02"))
 }
 
 // FetchData generates mock market data for the given date
 func (p *MockProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	return mockScenarios[p.scenario](sineDay()), nil
+}
+
+// sineDay generates a day of realistic-looking market data from sine waves
+func sineDay() []datastore.MarketDataPoint {
 	var data []datastore.MarketDataPoint
 
 	// Generate 96 periods (24 hours * 4 periods per hour)
@@ -81,5 +159,5 @@ func (p *MockProvider) FetchData(ctx context.Context, date time.Time) ([]datasto
 		}
 	}
 
-	return data, nil
+	return data
 }
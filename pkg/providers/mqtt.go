@@ -0,0 +1,384 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/datastore"
+	"kcas/new/internal/mqtt"
+	"kcas/new/internal/number"
+)
+
+const (
+	// mqttFirstMessageWait is how long a fetch waits for the retained
+	// messages after subscribing
+	mqttFirstMessageWait = 2 * time.Second
+
+	// mqttReconnectDelay spaces out the connections to a lost broker
+	mqttReconnectDelay = 10 * time.Second
+)
+
+// MQTTProvider keeps the market data published on an MQTT topic as a rolling
+// in-memory dataset of the recent days. Each message is a JSON object, or an
+// array of them, carrying the volume and price of a period: a HH:MM-HH:MM
+// period of a date (the current market day when absent), or a time. The
+// data store is told of every updated day, so the cap follows the signal
+// as it is published rather than on the refresh schedule.
+type MQTTProvider struct {
+	opts     mqtt.Options
+	broker   string
+	topic    string
+	period   []string
+	time     []string
+	date     []string
+	volume   []string
+	price    []string
+	retain   int // Days kept, today included
+	location *time.Location
+
+	first     chan struct{} // Closed on the first message
+	firstOnce sync.Once
+
+	mu      sync.Mutex
+	client  *mqtt.Client
+	days    map[string]map[string]datastore.MarketDataPoint // Market day → period → point
+	updates chan time.Time
+	err     error // Why the last message could not be used
+}
+
+// NewMQTTProvider creates an MQTT provider from the parameters: broker
+// (mqtt://host:port or mqtts://, optionally with credentials), username and
+// password, topic, which may hold wildcards, the period, time, date, volume
+// and price field paths, and retain_days, the days kept (default 2)
+func NewMQTTProvider(params map[string]string, location *time.Location) *MQTTProvider {
+	broker := params["broker"]
+	opts := mqtt.Options{
+		ClientID:  fmt.Sprintf("powercap-provider-%d", time.Now().UnixNano()%1000000),
+		Username:  params["username"],
+		Password:  params["password"],
+		KeepAlive: time.Minute,
+	}
+	if u, err := url.Parse(broker); err == nil && u.Host != "" {
+		// Credentials in the URL, kept out of the name and the logs
+		if u.User != nil {
+			opts.Username = u.User.Username()
+			opts.Password, _ = u.User.Password()
+			u.User = nil
+		}
+		broker = u.Host
+		opts.Broker = u.String()
+	} else {
+		opts.Broker = broker
+	}
+	retain, err := strconv.Atoi(params["retain_days"])
+	if err != nil || retain < 1 {
+		retain = 2
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &MQTTProvider{
+		opts:     opts,
+		broker:   broker,
+		topic:    params["topic"],
+		period:   fieldParam(params, "period"),
+		time:     fieldParam(params, "time"),
+		date:     fieldParam(params, "date"),
+		volume:   fieldParam(params, "volume"),
+		price:    fieldParam(params, "price"),
+		retain:   retain,
+		location: location,
+		first:    make(chan struct{}),
+		days:     make(map[string]map[string]datastore.MarketDataPoint),
+	}
+}
+
+// fieldParam returns the path of a field parameter, the parameter name when
+// it is not set
+func fieldParam(params map[string]string, name string) []string {
+	if path := parseFieldPath(params[name]); len(path) > 0 {
+		return path
+	}
+	return []string{name}
+}
+
+// GetName returns the provider name
+func (p *MQTTProvider) GetName() string {
+	return "MQTT (" + p.broker + "/" + p.topic + ")"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *MQTTProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("mqtt_data_%s.csv", date.Format("2006-01-02"))
+}
+
+// Intraday reports that the data of the current day changes with every
+// message
+func (p *MQTTProvider) Intraday() bool {
+	return true
+}
+
+// FetchData returns the periods received for the given date, subscribing
+// first when not connected
+func (p *MQTTProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	if err := p.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	day := date.In(p.location).Format("2006-01-02")
+	points := p.days[day]
+	if len(points) == 0 {
+		if p.err != nil {
+			return nil, fmt.Errorf("no data received for %s: %w", day, p.err)
+		}
+		return nil, fmt.Errorf("no data received for %s on %s", day, p.topic)
+	}
+	data := make([]datastore.MarketDataPoint, 0, len(points))
+	for _, point := range points {
+		data = append(data, point)
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].Period < data[j].Period })
+	return data, nil
+}
+
+// Subscribe connects to the broker and sends the market day of every message
+// received until the context ends, connecting again when the broker is lost
+func (p *MQTTProvider) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	updates := make(chan time.Time, 16)
+	p.mu.Lock()
+	p.updates = updates
+	p.mu.Unlock()
+	if err := p.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(updates)
+		defer p.Close()
+		defer func() {
+			p.mu.Lock()
+			p.updates = nil
+			p.mu.Unlock()
+		}()
+		for {
+			p.mu.Lock()
+			client := p.client
+			p.mu.Unlock()
+			if client != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-client.Done():
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(mqttReconnectDelay):
+			}
+			p.connect(ctx)
+		}
+	}()
+	return updates, nil
+}
+
+// connect subscribes to the topic unless connected, waiting briefly for the
+// retained messages the first time
+func (p *MQTTProvider) connect(ctx context.Context) error {
+	p.mu.Lock()
+	if p.client != nil && p.client.Err() == nil {
+		p.mu.Unlock()
+		return nil
+	}
+	p.client = nil
+	client, err := mqtt.Dial(p.opts)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	if err := client.Subscribe(p.topic, p.receive); err != nil {
+		client.Close()
+		p.mu.Unlock()
+		return fmt.Errorf("failed to subscribe to %s: %w", p.topic, err)
+	}
+	p.client = client
+	p.mu.Unlock()
+
+	timer := time.NewTimer(mqttFirstMessageWait)
+	defer timer.Stop()
+	select {
+	case <-p.first:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// receive adds the periods of a message to the dataset, drops the days no
+// longer kept and notifies the updated days
+func (p *MQTTProvider) receive(topic string, payload []byte) {
+	now := time.Now().In(p.location)
+	points, err := p.parse(payload, now)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.err = fmt.Errorf("invalid message on %s: %w", topic, err)
+		return
+	}
+	p.err = nil
+
+	updated := make(map[string]time.Time)
+	for _, point := range points {
+		day := point.date.Format("2006-01-02")
+		if p.days[day] == nil {
+			p.days[day] = make(map[string]datastore.MarketDataPoint)
+		}
+		p.days[day][point.Period] = point.MarketDataPoint
+		updated[day] = point.date
+	}
+	oldest := time.Date(now.Year(), now.Month(), now.Day()-p.retain+1, 0, 0, 0, 0, p.location).Format("2006-01-02")
+	for day := range p.days {
+		if day < oldest {
+			delete(p.days, day)
+		}
+	}
+	for day, date := range updated {
+		if p.updates != nil && day >= oldest {
+			select {
+			case p.updates <- date:
+			default:
+				// A pending update of the same day covers this one
+			}
+		}
+	}
+	if len(points) > 0 {
+		p.firstOnce.Do(func() { close(p.first) })
+	}
+}
+
+// mqttPoint is a period of a message with the market day it belongs to
+type mqttPoint struct {
+	datastore.MarketDataPoint
+	date time.Time
+}
+
+// parse reads the periods of a message, a JSON object or an array of them
+func (p *MQTTProvider) parse(payload []byte, now time.Time) ([]mqttPoint, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(payload)))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	records, ok := document.([]any)
+	if !ok {
+		records = []any{document}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.location)
+	var points []mqttPoint
+	for i, record := range records {
+		var point mqttPoint
+		if value, ok := lookupField(record, p.time); ok {
+			t, err := messageTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			t = t.In(p.location)
+			point.date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.location)
+			point.Period = quarterPeriod(t)
+		} else if value, ok := lookupField(record, p.period); ok {
+			period, _ := value.(string)
+			from, until, found := strings.Cut(period, "-")
+			if !found || len(from) != 5 || len(until) != 5 {
+				return nil, fmt.Errorf("record %d: invalid period %v, expected HH:MM-HH:MM", i, value)
+			}
+			if _, err := clockMinutes(from); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			if _, err := clockMinutes(until); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			point.Period, point.date = period, today
+			if value, ok := lookupField(record, p.date); ok {
+				text, _ := value.(string)
+				date, err := time.ParseInLocation("2006-01-02", text, p.location)
+				if err != nil {
+					return nil, fmt.Errorf("record %d: invalid date %v", i, value)
+				}
+				point.date = date
+			}
+		} else {
+			return nil, fmt.Errorf("record %d has neither %s nor %s", i, strings.Join(p.time, "."), strings.Join(p.period, "."))
+		}
+
+		volume, err := fieldNumber(record, p.volume)
+		if errors.Is(err, number.ErrMissing) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d volume: %w", i, err)
+		}
+		point.Volume = volume
+		if value, ok := lookupField(record, p.price); ok {
+			price, err := fieldValueNumber(value)
+			if err != nil && !errors.Is(err, number.ErrMissing) {
+				return nil, fmt.Errorf("record %d price: %w", i, err)
+			}
+			point.Price = price
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// messageTime parses a time of a message, RFC 3339 or Unix seconds
+func messageTime(value any) (time.Time, error) {
+	if text, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t, nil
+		}
+	}
+	seconds, err := fieldValueNumber(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %v, expected RFC 3339 or Unix seconds", value)
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// Close disconnects from the broker
+func (p *MQTTProvider) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
+
+// validateMQTTParams checks the MQTT provider parameters
+func validateMQTTParams(params map[string]string) error {
+	if params["broker"] == "" {
+		return fmt.Errorf("MQTT provider requires the broker parameter")
+	}
+	if params["topic"] == "" {
+		return fmt.Errorf("MQTT provider requires the topic parameter")
+	}
+	if retain := params["retain_days"]; retain != "" {
+		if days, err := strconv.Atoi(retain); err != nil || days < 1 {
+			return fmt.Errorf("MQTT provider retain_days must be a positive number of days, got %q", retain)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/config"
+	"kcas/new/internal/datastore"
+)
+
+// MarketDataProvider and MarketDataPoint are the provider types of the data
+// store, for providers of other modules, which cannot import it
+type (
+	MarketDataProvider = datastore.MarketDataProvider
+	MarketDataPoint    = datastore.MarketDataPoint
+)
+
+// Options is the configuration a registered provider is created from
+type Options struct {
+	Name     string            // Provider type, as set in DATA_PROVIDER
+	URL      string            // PROVIDER_URL, empty when left at the EPEX default
+	Params   map[string]string // PROVIDER_PARAMS
+	Location *time.Location    // Market timezone, which the periods follow
+}
+
+// Constructor creates a provider from its options
+type Constructor func(opts Options) (MarketDataProvider, error)
+
+// Validator checks the options of a provider before it is created
+type Validator func(opts Options) error
+
+// registration creates and validates a provider type from the configuration
+type registration struct {
+	create   func(f *ProviderFactory, cfg *config.Config) (datastore.MarketDataProvider, error)
+	validate func(f *ProviderFactory, cfg *config.Config) error // Nil without checks
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*registration)
+	registered []string // Provider types, in registration order
+)
+
+// Register makes a provider type available to DATA_PROVIDER, as well as to
+// FAILOVER_PROVIDER, CLOSED_DAY_PROVIDER and composite providers. Modules
+// compiling in providers of their own call it from an init function, before
+// the command runs. Like the built-in providers, a provider implementing
+// RequestConfigurable gets the request options, and is cached, converted
+// and wrapped in the market calendar. Register panics when the name is
+// empty or already registered, or the constructor is nil.
+func Register(name string, constructor Constructor) {
+	if constructor == nil {
+		panic("providers: Register constructor is nil for " + name)
+	}
+	register(name, func(_ *ProviderFactory, cfg *config.Config) (datastore.MarketDataProvider, error) {
+		return constructor(options(cfg))
+	}, nil)
+}
+
+// RegisterValidator sets the checks of a registered provider type, run by
+// ValidateProviderConfig when the configuration is loaded or checked. It
+// panics when the name is not registered.
+func RegisterValidator(name string, validate Validator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	r, ok := registry[strings.ToLower(name)]
+	if !ok {
+		panic("providers: RegisterValidator called for unregistered provider " + name)
+	}
+	if validate == nil {
+		r.validate = nil
+		return
+	}
+	r.validate = func(_ *ProviderFactory, cfg *config.Config) error {
+		return validate(options(cfg))
+	}
+}
+
+// register adds a provider type created from the whole configuration
+func register(name string,
+	create func(f *ProviderFactory, cfg *config.Config) (datastore.MarketDataProvider, error),
+	validate func(f *ProviderFactory, cfg *config.Config) error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" {
+		panic("providers: Register called with an empty name")
+	}
+	if _, exists := registry[name]; exists {
+		panic("providers: Register called twice for provider " + name)
+	}
+	registry[name] = &registration{create: create, validate: validate}
+	registered = append(registered, name)
+}
+
+// lookup returns the registration of a provider type
+func lookup(name string) (registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return registration{}, false
+	}
+	return *r, true
+}
+
+// options returns the options of the configured provider
+func options(cfg *config.Config) Options {
+	return Options{
+		Name:     strings.ToLower(cfg.DataProvider),
+		URL:      apiURL(cfg),
+		Params:   cfg.ProviderParams,
+		Location: cfg.MarketLocation(),
+	}
+}
+
+// unknownProvider returns the error of a provider type that is not registered
+func unknownProvider(name string) error {
+	return fmt.Errorf("unknown provider type: %s. Supported types: %s", name, strings.Join(supportedProviders(), ", "))
+}
+
+// supportedProviders returns the registered provider types, the built-in
+// ones first
+func supportedProviders() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), registered...)
+}
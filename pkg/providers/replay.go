@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+const (
+	// replayMaxDays bounds the recorded days a replay loops over
+	replayMaxDays = 366
+
+	// replayMaxSpeed bounds the speed of a replay, a day then lasting 9 seconds
+	replayMaxSpeed = 10000
+)
+
+// ReplayProvider plays recorded days of market data back on a clock of its
+// own, running speed times faster than the system clock, so a whole day of
+// decisions can be exercised in minutes during development and load tests.
+// The clock starts at midnight of the first day and loops over the days.
+type ReplayProvider struct {
+	files   *FileProvider
+	from    time.Time // Midnight of the first day
+	until   time.Time // Midnight after the last day
+	speed   float64
+	started time.Time
+}
+
+// NewReplayProvider creates a replay provider from the parameters: dir and
+// pattern, the recorded files as for the file provider, e.g. pattern
+// epex_data_{{date}}.csv to replay a copy of DATA_DIR, from and to, the first
+// and last days replayed (YYYY-MM-DD, to defaulting to from), and speed, the
+// time-scale factor (default 1, 96 replaying a day in 15 minutes)
+func NewReplayProvider(params map[string]string, location *time.Location) *ReplayProvider {
+	if location == nil {
+		location = time.UTC
+	}
+	from, err := time.ParseInLocation("2006-01-02", params["from"], location)
+	if err != nil {
+		now := time.Now().In(location)
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	}
+	to, err := time.ParseInLocation("2006-01-02", params["to"], location)
+	if err != nil || to.Before(from) {
+		to = from
+	}
+	speed, err := strconv.ParseFloat(params["speed"], 64)
+	if err != nil || speed <= 0 {
+		speed = 1
+	}
+	return &ReplayProvider{
+		files:   NewFileProvider(map[string]string{"dir": params["dir"], "pattern": params["pattern"]}, location),
+		from:    from,
+		until:   to.AddDate(0, 0, 1),
+		speed:   speed,
+		started: time.Now(),
+	}
+}
+
+// GetName returns the provider name with the speed of the replay
+func (p *ReplayProvider) GetName() string {
+	return fmt.Sprintf("Replay (%s, ×%g)", p.files.dir, p.speed)
+}
+
+// GetDataPath returns the file path for the given date, apart from the
+// recorded files should they be in the data directory
+func (p *ReplayProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("replay_%s.csv", date.Format("2006-01-02"))
+}
+
+// FetchData reads the recorded file of the given date
+func (p *ReplayProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	return p.files.FetchData(ctx, date)
+}
+
+// Now returns the current time of the replay
+func (p *ReplayProvider) Now() time.Time {
+	elapsed := time.Duration(float64(time.Since(p.started)) * p.speed)
+	return p.from.Add(elapsed % p.until.Sub(p.from))
+}
+
+// Clock returns the clock of the replay, followed by the market periods
+func (p *ReplayProvider) Clock() func() time.Time {
+	return p.Now
+}
+
+// Subscribe sends the day of the replay whenever it starts, so its data is
+// loaded without waiting for the refresh schedule, which follows the
+// system clock
+func (p *ReplayProvider) Subscribe(ctx context.Context) (<-chan time.Time, error) {
+	// About a minute of the replay, within bounds
+	tick := min(max(time.Duration(float64(time.Minute)/p.speed), 100*time.Millisecond), time.Minute)
+	updates := make(chan time.Time)
+	go func() {
+		defer close(updates)
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+
+		day := p.Now().Format("2006-01-02")
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			now := p.Now()
+			if now.Format("2006-01-02") == day {
+				continue
+			}
+			day = now.Format("2006-01-02")
+			select {
+			case updates <- now:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// validateReplayParams checks the replay provider parameters
+func validateReplayParams(params map[string]string) error {
+	if err := validateFileParams(map[string]string{"dir": params["dir"], "pattern": params["pattern"]}); err != nil {
+		return fmt.Errorf("replay provider: %w", err)
+	}
+	from, err := time.Parse("2006-01-02", params["from"])
+	if err != nil {
+		return fmt.Errorf("replay provider requires the from parameter, the first day replayed as YYYY-MM-DD, got %q", params["from"])
+	}
+	if text := params["to"]; text != "" {
+		to, err := time.Parse("2006-01-02", text)
+		if err != nil || to.Before(from) {
+			return fmt.Errorf("replay provider to must be a day from %s on as YYYY-MM-DD, got %q", params["from"], text)
+		}
+		if days := int(to.Sub(from).Hours()/24) + 1; days > replayMaxDays {
+			return fmt.Errorf("replay provider replays at most %d days, got %d", replayMaxDays, days)
+		}
+	}
+	if text := params["speed"]; text != "" {
+		speed, err := strconv.ParseFloat(text, 64)
+		if err != nil || speed <= 0 || speed > replayMaxSpeed {
+			return fmt.Errorf("replay provider speed must be a number in (0, %d], got %q", replayMaxSpeed, text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+// Solar forecast services
+const (
+	solarForecastSolar = "forecast.solar"
+	solarSolcast       = "solcast"
+)
+
+// solarSample is the forecast power of the panels at a time
+type solarSample struct {
+	at    time.Time
+	watts float64
+}
+
+// SolarProvider follows the production forecast of local solar panels, from
+// Forecast.Solar or Solcast, so the cap rises when the site produces its own
+// power. The volume of each period is the forecast production as a
+// percentage of the panel capacity, for POWER_CALC_MODE=percent.
+type SolarProvider struct {
+	baseURL   string
+	service   string
+	latitude  string
+	longitude string
+	tilt      string // Degrees from horizontal
+	azimuth   string // Degrees from south, -90 east and 90 west
+	capacity  float64
+	apiKey    string
+	location  *time.Location
+	request   RequestOptions
+	client    *http.Client
+}
+
+// NewSolarProvider creates a solar provider from the parameters: service
+// (forecast.solar, the default, or solcast), latitude and longitude of the
+// panels, tilt (default 30), azimuth (default 0, south), capacity, the peak
+// power in kW, and api_key, required by Solcast
+func NewSolarProvider(baseURL string, params map[string]string, location *time.Location) *SolarProvider {
+	service := strings.ToLower(params["service"])
+	if service == "" {
+		service = solarForecastSolar
+	}
+	if baseURL == "" {
+		baseURL = "https://api.forecast.solar"
+		if service == solarSolcast {
+			baseURL = "https://api.solcast.com.au"
+		}
+	}
+	capacity, _ := strconv.ParseFloat(params["capacity"], 64)
+	if location == nil {
+		location = time.UTC
+	}
+	return &SolarProvider{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		service:   service,
+		latitude:  params["latitude"],
+		longitude: params["longitude"],
+		tilt:      defaultParam(params["tilt"], "30"),
+		azimuth:   defaultParam(params["azimuth"], "0"),
+		capacity:  capacity,
+		apiKey:    params["api_key"],
+		location:  location,
+		client:    newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of solar forecast requests
+func (p *SolarProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
+// GetName returns the provider name
+func (p *SolarProvider) GetName() string {
+	if p.service == solarSolcast {
+		return "Solcast"
+	}
+	return "Forecast.Solar"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *SolarProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("solar_%s_%s_%s.csv", p.latitude, p.longitude, date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron follows the forecast hourly, and every 3 hours with
+// Solcast, whose free plan allows 10 requests a day
+func (p *SolarProvider) DefaultRefreshCron() string {
+	if p.service == solarSolcast {
+		return "0 */3 * * *"
+	}
+	return "0 * * * *"
+}
+
+// Intraday reports that the data of the current day changes with every
+// forecast
+func (p *SolarProvider) Intraday() bool {
+	return true
+}
+
+// FetchData fetches the production forecast of every period of the given
+// date, interpolated at the middle of the period. Periods outside the
+// forecast, such as the night or the past hours with Solcast, produce
+// nothing.
+func (p *SolarProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+
+	var samples []solarSample
+	var err error
+	if p.service == solarSolcast {
+		samples, err = p.fetchSolcast(ctx)
+	} else {
+		samples, err = p.fetchForecastSolar(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s forecast: %w", p.GetName(), err)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].at.Before(samples[j].at) })
+
+	var data []datastore.MarketDataPoint
+	covered := false
+	for t := start; t.Before(end); t = t.Add(15 * time.Minute) {
+		watts, ok := interpolateSolar(samples, t.Add(7*time.Minute+30*time.Second))
+		covered = covered || ok
+		data = append(data, datastore.MarketDataPoint{
+			Period: quarterPeriod(t),
+			Volume: min(watts/(p.capacity*1000)*100, 100),
+		})
+	}
+	if !covered {
+		return nil, fmt.Errorf("no %s forecast for %s", p.GetName(), start.Format("2006-01-02"))
+	}
+	return data, nil
+}
+
+// interpolateSolar returns the power at t between the samples around it,
+// false when t is outside the samples
+func interpolateSolar(samples []solarSample, t time.Time) (float64, bool) {
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].at.Before(t) })
+	switch {
+	case i == len(samples) || (i == 0 && samples[0].at.After(t)):
+		return 0, false
+	case samples[i].at.Equal(t):
+		return samples[i].watts, true
+	}
+	before, after := samples[i-1], samples[i]
+	ratio := float64(t.Sub(before.at)) / float64(after.at.Sub(before.at))
+	return max(before.watts+(after.watts-before.watts)*ratio, 0), true
+}
+
+// fetchForecastSolar reads the power estimate of Forecast.Solar, whose times
+// are local to the panels
+func (p *SolarProvider) fetchForecastSolar(ctx context.Context) ([]solarSample, error) {
+	path := "/estimate/watts/" + strings.Join([]string{
+		url.PathEscape(p.latitude), url.PathEscape(p.longitude),
+		url.PathEscape(p.tilt), url.PathEscape(p.azimuth),
+		strconv.FormatFloat(p.capacity, 'f', -1, 64),
+	}, "/")
+	if p.apiKey != "" {
+		path = "/" + url.PathEscape(p.apiKey) + path
+	}
+
+	var estimate struct {
+		Result  map[string]float64 `json:"result"`
+		Message struct {
+			Info struct {
+				Timezone string `json:"timezone"`
+			} `json:"info"`
+		} `json:"message"`
+	}
+	if err := p.get(ctx, p.baseURL+path, &estimate); err != nil {
+		return nil, err
+	}
+	zone, err := time.LoadLocation(estimate.Message.Info.Timezone)
+	if err != nil {
+		zone = p.location
+	}
+
+	samples := make([]solarSample, 0, len(estimate.Result))
+	for text, watts := range estimate.Result {
+		at, err := time.ParseInLocation(time.DateTime, text, zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", text)
+		}
+		samples = append(samples, solarSample{at: at, watts: watts})
+	}
+	return samples, nil
+}
+
+// fetchSolcast reads the rooftop PV forecast of Solcast: the average power
+// (kW) of each half-hour, sampled at its middle. Solcast measures the
+// azimuth from north, east being 90 and west -90.
+func (p *SolarProvider) fetchSolcast(ctx context.Context) ([]solarSample, error) {
+	azimuth, _ := strconv.ParseFloat(p.azimuth, 64)
+	azimuth = math.Mod(azimuth+540, 360) // From north, 180 facing south
+	if azimuth > 180 {
+		azimuth -= 360
+	}
+
+	query := url.Values{}
+	query.Set("latitude", p.latitude)
+	query.Set("longitude", p.longitude)
+	query.Set("capacity", strconv.FormatFloat(p.capacity, 'f', -1, 64))
+	query.Set("tilt", p.tilt)
+	query.Set("azimuth", strconv.FormatFloat(azimuth, 'f', -1, 64))
+	query.Set("period", "PT30M")
+	query.Set("hours", "48")
+	query.Set("output_parameters", "pv_power_rooftop")
+	query.Set("format", "json")
+
+	var forecast struct {
+		Forecasts []struct {
+			Power     float64 `json:"pv_power_rooftop"`
+			PeriodEnd string  `json:"period_end"`
+		} `json:"forecasts"`
+	}
+	if err := p.get(ctx, p.baseURL+"/data/forecast/rooftop_pv_power?"+query.Encode(), &forecast); err != nil {
+		return nil, err
+	}
+
+	samples := make([]solarSample, 0, len(forecast.Forecasts))
+	for _, period := range forecast.Forecasts {
+		end, err := time.Parse(time.RFC3339, period.PeriodEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period end %q", period.PeriodEnd)
+		}
+		samples = append(samples, solarSample{at: end.Add(-15 * time.Minute), watts: period.Power * 1000})
+	}
+	return samples, nil
+}
+
+// get sends a request to the solar forecast service and decodes the JSON
+// response into v. The Solcast API key is sent as a bearer token.
+func (p *SolarProvider) get(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.service == solarSolcast && p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	applyRequestOptions(req, p.request, "")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// validateSolarParams checks the solar provider parameters
+func validateSolarParams(params map[string]string) error {
+	service := strings.ToLower(params["service"])
+	switch service {
+	case "", solarForecastSolar:
+	case solarSolcast:
+		if params["api_key"] == "" && params["auth"] == "" {
+			return fmt.Errorf("solar provider service solcast requires the api_key parameter")
+		}
+	default:
+		return fmt.Errorf("solar provider service must be forecast.solar or solcast, got %q", service)
+	}
+
+	ranges := []struct {
+		name     string
+		low, top float64
+		required bool
+	}{
+		{"latitude", -90, 90, true},
+		{"longitude", -180, 180, true},
+		{"tilt", 0, 90, false},
+		{"azimuth", -180, 180, false},
+	}
+	for _, r := range ranges {
+		text := params[r.name]
+		if text == "" {
+			if r.required {
+				return fmt.Errorf("solar provider requires the %s parameter", r.name)
+			}
+			continue
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < r.low || value > r.top {
+			return fmt.Errorf("solar provider %s must be a number in [%g, %g], got %q", r.name, r.low, r.top, text)
+		}
+	}
+	if capacity, err := strconv.ParseFloat(params["capacity"], 64); err != nil || capacity <= 0 {
+		return fmt.Errorf("solar provider capacity must be the positive peak power of the panels in kW, got %q", params["capacity"])
+	}
+	return nil
+}
@@ -3,15 +3,44 @@ package providers
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"kcas/new/internal/datastore"
+
+	"sigs.k8s.io/yaml"
 )
 
+// staticDays maps the keys of the days of a static dataset to the weekdays
+// they cover, weekdays and weekend standing for several
+var staticDays = map[string][]time.Weekday{
+	"monday":    {time.Monday},
+	"tuesday":   {time.Tuesday},
+	"wednesday": {time.Wednesday},
+	"thursday":  {time.Thursday},
+	"friday":    {time.Friday},
+	"saturday":  {time.Saturday},
+	"sunday":    {time.Sunday},
+	"weekdays":  {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":   {time.Saturday, time.Sunday},
+}
+
+// staticDataset is the YAML or JSON file of a static provider: the periods
+// of every day, and the days with a profile of their own. Periods longer
+// than 15 minutes, such as 00:00-06:00, cover each of their quarter-hours.
+type staticDataset struct {
+	Name    string                                 `json:"name"`
+	Periods []datastore.MarketDataPoint            `json:"periods"`
+	Days    map[string][]datastore.MarketDataPoint `json:"days"`
+}
+
 // StaticProvider implements MarketDataProvider with static data
 type StaticProvider struct {
-	name string
-	data []datastore.MarketDataPoint
+	name     string
+	data     []datastore.MarketDataPoint
+	days     map[time.Weekday][]datastore.MarketDataPoint // Profiles replacing data on their weekday
+	location *time.Location
 }
 
 // NewStaticProvider creates a new static market data provider
@@ -70,6 +99,103 @@ func NewStaticProviderWithDefaults() *StaticProvider {
 	}
 }
 
+// NewStaticProviderFromFile creates a static provider from a YAML or JSON
+// dataset, whose day-of-week profiles follow the market location
+func NewStaticProviderFromFile(path string, location *time.Location) (*StaticProvider, error) {
+	dataset, err := loadStaticDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
+	p := &StaticProvider{
+		name:     "Static",
+		location: location,
+	}
+	if dataset.Name != "" {
+		p.name = "Static (" + dataset.Name + ")"
+	}
+	if p.data, err = quarterHours(dataset.Periods); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// A single day takes precedence over weekdays and weekend
+	p.days = make(map[time.Weekday][]datastore.MarketDataPoint)
+	for _, group := range []bool{true, false} {
+		for key, periods := range dataset.Days {
+			weekdays := staticDays[key]
+			if (len(weekdays) > 1) != group {
+				continue
+			}
+			data, err := quarterHours(periods)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+			}
+			for _, weekday := range weekdays {
+				p.days[weekday] = data
+			}
+		}
+	}
+	return p, nil
+}
+
+// loadStaticDataset reads and checks a static dataset. Every day must have
+// periods, of its own or from the periods of every day.
+func loadStaticDataset(path string) (*staticDataset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dataset staticDataset
+	if err := yaml.UnmarshalStrict(content, &dataset); err != nil {
+		return nil, fmt.Errorf("invalid static dataset %s: %w", path, err)
+	}
+
+	covered := make(map[time.Weekday]bool)
+	for key, periods := range dataset.Days {
+		weekdays, ok := staticDays[key]
+		if !ok {
+			return nil, fmt.Errorf("static dataset %s: unknown day %q, expected a weekday such as monday, weekdays or weekend", path, key)
+		}
+		if len(periods) == 0 {
+			return nil, fmt.Errorf("static dataset %s: %s has no periods", path, key)
+		}
+		for _, weekday := range weekdays {
+			covered[weekday] = true
+		}
+	}
+	if len(dataset.Periods) == 0 && len(covered) < 7 {
+		return nil, fmt.Errorf("static dataset %s needs periods for the days without a profile", path)
+	}
+	return &dataset, nil
+}
+
+// quarterHours splits the periods longer than 15 minutes into their
+// quarter-hours, the market periods, each with the volume and price of the
+// period. A period ending at or before its start ends at midnight.
+func quarterHours(periods []datastore.MarketDataPoint) ([]datastore.MarketDataPoint, error) {
+	var data []datastore.MarketDataPoint
+	for _, point := range periods {
+		from, until, err := periodMinutes(strings.TrimSpace(point.Period))
+		if err != nil {
+			return nil, err
+		}
+		if until <= from {
+			until = 24 * 60
+		}
+		if from%15 != 0 || until%15 != 0 {
+			return nil, fmt.Errorf("period %s does not start and end on a quarter-hour", point.Period)
+		}
+		for minute := from; minute < until; minute += 15 {
+			t := time.Date(2000, 1, 1, minute/60, minute%60, 0, 0, time.UTC)
+			data = append(data, datastore.MarketDataPoint{Period: quarterPeriod(t), Volume: point.Volume, Price: point.Price})
+		}
+	}
+	return data, nil
+}
+
 // GetName returns the provider name
 func (p *StaticProvider) GetName() string {
 	return p.name
@@ -80,11 +206,19 @@ func (p *StaticProvider) GetDataPath(date time.Time) string {
 	return fmt.Sprintf("static_data_%s.csv", date.Format("2006-01-02"))
 }
 
-// FetchData returns the static data (ignores date parameter)
+// FetchData returns the static data, or the profile of the weekday of the
+// given date when it has one
 func (p *StaticProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	data := p.data
+	if p.location != nil {
+		if profile, ok := p.days[date.In(p.location).Weekday()]; ok {
+			data = profile
+		}
+	}
+
 	// Return a copy of the static data
-	result := make([]datastore.MarketDataPoint, len(p.data))
-	copy(result, p.data)
+	result := make([]datastore.MarketDataPoint, len(data))
+	copy(result, data)
 	return result, nil
 }
 
@@ -0,0 +1,401 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kcas/new/internal/datastore"
+)
+
+const (
+	// wattTimeTokenTTL is how long a WattTime login token is used, renewed
+	// ahead of its 30-minute expiry
+	wattTimeTokenTTL = 25 * time.Minute
+
+	// wattTimeCacheTTL is how long a forecast is reused by default, WattTime
+	// generating a new one every 5 minutes
+	wattTimeCacheTTL = 10 * time.Minute
+)
+
+// errWattTimeForbidden is returned for data the account's plan does not cover
+var errWattTimeForbidden = errors.New("not covered by the WattTime plan")
+
+// wattTimeData is the response of the WattTime forecast and historical
+// endpoints: one point per 5 minutes
+type wattTimeData struct {
+	Data []struct {
+		PointTime time.Time `json:"point_time"`
+		Value     float64   `json:"value"`
+	} `json:"data"`
+}
+
+// wattTimeForecast is a forecast kept for reuse
+type wattTimeForecast struct {
+	data      wattTimeData
+	fetchedAt time.Time
+}
+
+// WattTimeProvider follows the marginal operating emissions rate (MOER) of
+// a grid region from the WattTime API, for US regions among others. The
+// volume of each period is its cleanliness relative to the cleanest period
+// of the day, 100 × lowest MOER / MOER, so POWER_CALC_MODE=percent gives the
+// full power at the cleanest period and half of it at twice its emissions.
+// The MOER itself (lbs/MWh) is not a price and is not stored.
+type WattTimeProvider struct {
+	baseURL    string
+	username   string
+	password   string
+	signal     string
+	latitude   string
+	longitude  string
+	cacheTTL   time.Duration
+	location   *time.Location
+	request    RequestOptions
+	client     *http.Client
+	mu         sync.Mutex
+	region     string // Configured or looked up
+	place      string // Region or coordinates naming the data files
+	token      string
+	tokenAt    time.Time
+	forecast   *wattTimeForecast
+	forecastOf string // Region of the cached forecast
+}
+
+// NewWattTimeProvider creates a WattTime provider from the parameters:
+// username and password of the account, region or latitude and longitude
+// to look it up, signal_type (default co2_moer) and cache_ttl, how long a
+// forecast is reused (default 10m)
+func NewWattTimeProvider(baseURL string, params map[string]string, location *time.Location) *WattTimeProvider {
+	if baseURL == "" {
+		baseURL = "https://api.watttime.org"
+	}
+	signal := params["signal_type"]
+	if signal == "" {
+		signal = "co2_moer"
+	}
+	cacheTTL := wattTimeCacheTTL
+	if ttl, err := time.ParseDuration(params["cache_ttl"]); err == nil {
+		cacheTTL = ttl
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	place := params["region"]
+	if place == "" {
+		place = params["latitude"] + "_" + params["longitude"]
+	}
+	return &WattTimeProvider{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		username:  params["username"],
+		password:  params["password"],
+		signal:    signal,
+		region:    params["region"],
+		place:     strings.ToLower(place),
+		latitude:  params["latitude"],
+		longitude: params["longitude"],
+		cacheTTL:  cacheTTL,
+		location:  location,
+		client:    newHTTPClient(RequestOptions{}),
+	}
+}
+
+// SetRequestOptions sets the timeout, User-Agent and headers of WattTime requests
+func (p *WattTimeProvider) SetRequestOptions(opts RequestOptions) {
+	p.request = opts
+	p.client = newHTTPClient(opts, p.baseURL)
+}
+
+// GetName returns the provider name
+func (p *WattTimeProvider) GetName() string {
+	return "WattTime"
+}
+
+// GetDataPath returns the file path for the given date
+func (p *WattTimeProvider) GetDataPath(date time.Time) string {
+	return fmt.Sprintf("watttime_%s_%s.csv", p.place, date.Format("2006-01-02"))
+}
+
+// DefaultRefreshCron follows the forecast, generated every 5 minutes
+func (p *WattTimeProvider) DefaultRefreshCron() string {
+	return "*/15 * * * *"
+}
+
+// Intraday reports that the data of the current day changes with every
+// forecast
+func (p *WattTimeProvider) Intraday() bool {
+	return true
+}
+
+// FetchData fetches the MOER of every period of the given date: measured
+// for the periods already past, forecast for the others. Past periods the
+// account's plan has no history for take the first forecast value, and
+// periods beyond the forecast carry the last value forward.
+func (p *WattTimeProvider) FetchData(ctx context.Context, date time.Time) ([]datastore.MarketDataPoint, error) {
+	local := date.In(p.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, p.location)
+	end := start.AddDate(0, 0, 1)
+	now := time.Now()
+
+	region, err := p.lookupRegion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var points wattTimeData
+	if start.Before(now) {
+		until := end
+		if now.Before(until) {
+			until = now
+		}
+		query := url.Values{}
+		query.Set("region", region)
+		query.Set("signal_type", p.signal)
+		query.Set("start", start.UTC().Format(time.RFC3339))
+		query.Set("end", until.UTC().Format(time.RFC3339))
+		var historical wattTimeData
+		err := p.get(ctx, "/v3/historical", query, &historical)
+		if err != nil && !(errors.Is(err, errWattTimeForbidden) && end.After(now)) {
+			return nil, fmt.Errorf("WattTime history of %s: %w", region, err)
+		}
+		points.Data = append(points.Data, historical.Data...)
+	}
+	if end.After(now) {
+		forecast, err := p.cachedForecast(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("WattTime forecast of %s: %w", region, err)
+		}
+		points.Data = append(points.Data, forecast.Data...)
+	}
+
+	// Average the 5-minute points of every period
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, point := range points.Data {
+		if point.PointTime.Before(start) || !point.PointTime.Before(end) || point.Value <= 0 {
+			continue
+		}
+		period := quarterPeriod(point.PointTime.In(p.location))
+		sums[period] += point.Value
+		counts[period]++
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no WattTime %s data for %s in %s", p.signal, start.Format("2006-01-02"), region)
+	}
+
+	var periods []string
+	var moers []float64
+	lowest := 0.0
+	for t := start; t.Before(end); t = t.Add(15 * time.Minute) {
+		period := quarterPeriod(t)
+		moer := 0.0
+		if counts[period] > 0 {
+			moer = sums[period] / float64(counts[period])
+			if lowest == 0 || moer < lowest {
+				lowest = moer
+			}
+		}
+		periods = append(periods, period)
+		moers = append(moers, moer)
+	}
+
+	// Fill the periods without data from their neighbours
+	first := 0
+	for moers[first] == 0 {
+		first++
+	}
+	for i := range moers {
+		if moers[i] == 0 {
+			if i < first {
+				moers[i] = moers[first]
+			} else {
+				moers[i] = moers[i-1]
+			}
+		}
+	}
+
+	data := make([]datastore.MarketDataPoint, len(periods))
+	for i, period := range periods {
+		data[i] = datastore.MarketDataPoint{Period: period, Volume: lowest / moers[i] * 100}
+	}
+	return data, nil
+}
+
+// cachedForecast returns the forecast of a region, fetched again once it is
+// older than the cache TTL
+func (p *WattTimeProvider) cachedForecast(ctx context.Context, region string) (wattTimeData, error) {
+	p.mu.Lock()
+	cached := p.forecast
+	if p.forecastOf != region {
+		cached = nil
+	}
+	p.mu.Unlock()
+	if cached != nil && time.Since(cached.fetchedAt) < p.cacheTTL {
+		return cached.data, nil
+	}
+
+	query := url.Values{}
+	query.Set("region", region)
+	query.Set("signal_type", p.signal)
+	query.Set("horizon_hours", "24")
+	var forecast wattTimeData
+	if err := p.get(ctx, "/v3/forecast", query, &forecast); err != nil {
+		return wattTimeData{}, err
+	}
+
+	p.mu.Lock()
+	p.forecast = &wattTimeForecast{data: forecast, fetchedAt: time.Now()}
+	p.forecastOf = region
+	p.mu.Unlock()
+	return forecast, nil
+}
+
+// lookupRegion returns the configured region, or looks up the region of the
+// latitude and longitude once
+func (p *WattTimeProvider) lookupRegion(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	region := p.region
+	p.mu.Unlock()
+	if region != "" {
+		return region, nil
+	}
+	if p.latitude == "" || p.longitude == "" {
+		return "", fmt.Errorf("WattTime provider needs a region or a latitude and longitude")
+	}
+
+	query := url.Values{}
+	query.Set("latitude", p.latitude)
+	query.Set("longitude", p.longitude)
+	query.Set("signal_type", p.signal)
+	var found struct {
+		Region string `json:"region"`
+	}
+	if err := p.get(ctx, "/v3/region-from-loc", query, &found); err != nil {
+		return "", fmt.Errorf("WattTime region of %s,%s: %w", p.latitude, p.longitude, err)
+	}
+	if found.Region == "" {
+		return "", fmt.Errorf("no WattTime region at %s,%s", p.latitude, p.longitude)
+	}
+
+	p.mu.Lock()
+	p.region = found.Region
+	p.mu.Unlock()
+	return found.Region, nil
+}
+
+// get decodes the JSON response of an API request, logging in first and
+// again once when the token is rejected
+func (p *WattTimeProvider) get(ctx context.Context, path string, query url.Values, v any) error {
+	for attempt := 0; ; attempt++ {
+		token, err := p.loginToken(ctx, attempt > 0)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		applyRequestOptions(req, p.request, "")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("HTTP request failed: %w", err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			continue
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusForbidden:
+			return errWattTimeForbidden
+		default:
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+			return fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("invalid WattTime response: %w", err)
+		}
+		return nil
+	}
+}
+
+// loginToken returns a login token, logging in when there is none, when it
+// is about to expire or when renew is set
+func (p *WattTimeProvider) loginToken(ctx context.Context, renew bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !renew && p.token != "" && time.Since(p.tokenAt) < wattTimeTokenTTL {
+		return p.token, nil
+	}
+	if p.username == "" || p.password == "" {
+		return "", fmt.Errorf("WattTime provider needs a username and password")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/login", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	applyRequestOptions(req, p.request, "")
+	req.SetBasicAuth(p.username, p.password)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("WattTime login failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("WattTime login failed with status %d", resp.StatusCode)
+	}
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&login); err != nil || login.Token == "" {
+		return "", fmt.Errorf("invalid WattTime login response")
+	}
+	p.token, p.tokenAt = login.Token, time.Now()
+	return p.token, nil
+}
+
+// validateWattTimeParams checks the WattTime parameters
+func validateWattTimeParams(params map[string]string) error {
+	if params["username"] == "" || params["password"] == "" {
+		return fmt.Errorf("WattTime provider requires the username and password parameters")
+	}
+	if params["region"] == "" {
+		for _, name := range []string{"latitude", "longitude"} {
+			if params[name] == "" {
+				return fmt.Errorf("WattTime provider requires a region or the latitude and longitude parameters")
+			}
+		}
+		latitude, err := strconv.ParseFloat(params["latitude"], 64)
+		if err != nil || latitude < -90 || latitude > 90 {
+			return fmt.Errorf("WattTime latitude must be a number in [-90, 90], got %q", params["latitude"])
+		}
+		longitude, err := strconv.ParseFloat(params["longitude"], 64)
+		if err != nil || longitude < -180 || longitude > 180 {
+			return fmt.Errorf("WattTime longitude must be a number in [-180, 180], got %q", params["longitude"])
+		}
+	}
+	if ttl := params["cache_ttl"]; ttl != "" {
+		if d, err := time.ParseDuration(ttl); err != nil || d < 0 {
+			return fmt.Errorf("WattTime cache_ttl must be a duration such as 10m, got %q", ttl)
+		}
+	}
+	return nil
+}